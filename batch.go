@@ -0,0 +1,99 @@
+// Copyright 2023 Princess B33f Heavy Industries / Dave Shanley
+// SPDX-License-Identifier: MIT
+
+package validator
+
+import (
+	"context"
+	"github.com/pb33f/libopenapi-validator/errors"
+	"github.com/pb33f/libopenapi-validator/helpers"
+	"github.com/pb33f/libopenapi-validator/paths"
+	"github.com/pb33f/libopenapi/datamodel/high/v3"
+	"net/http"
+	"runtime"
+	"sync"
+)
+
+// Exchange is a request, and the response that was sent for it, for example recorded from live traffic. The
+// Response may be nil, then only the request is validated.
+type Exchange struct {
+	Request  *http.Request
+	Response *http.Response
+}
+
+// ExchangeResult is the result of validating an Exchange.
+type ExchangeResult struct {
+	// Exchange is the request and response that were validated.
+	Exchange *Exchange
+
+	// Valid is true if the request and response are valid, warnings do not make an exchange invalid.
+	Valid bool
+
+	// Path is the templated path (e.g. /pet/{petId}) the request matched, or empty if no path matched.
+	Path string
+
+	// Operation is the operation the request matched, or nil if no operation matched.
+	Operation *v3.Operation
+
+	// Errors are the errors (and warnings) found in the request and the response.
+	Errors []*errors.ValidationError
+}
+
+// ValidateExchanges will validate a batch of exchanges, each request (and its response) is matched to an operation
+// and validated, just like ValidateHttpRequestResponse. Exchanges are validated concurrently, using the same
+// compiled schemas, and a result is returned for every exchange, in the same order as the exchanges.
+func (v *validator) ValidateExchanges(exchanges []*Exchange) []*ExchangeResult {
+	return v.ValidateExchangesWithContext(context.Background(), exchanges)
+}
+
+func (v *validator) ValidateExchangesWithContext(ctx context.Context, exchanges []*Exchange) []*ExchangeResult {
+	results := make([]*ExchangeResult, len(exchanges))
+	queue := make(chan int)
+	var wg sync.WaitGroup
+	workers := runtime.GOMAXPROCS(0)
+	if workers > len(exchanges) {
+		workers = len(exchanges)
+	}
+	for w := 0; w < workers; w++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := range queue {
+				results[i] = v.validateExchange(ctx, exchanges[i])
+			}
+		}()
+	}
+	for i := range exchanges {
+		queue <- i
+	}
+	close(queue)
+	wg.Wait()
+	return results
+}
+
+// validateExchange will validate the request of an exchange, and the response if there is one.
+func (v *validator) validateExchange(ctx context.Context, exchange *Exchange) *ExchangeResult {
+	result := &ExchangeResult{Exchange: exchange}
+	if ctx.Err() != nil {
+		result.Errors = []*errors.ValidationError{errors.ValidationCancelled(ctx)}
+		return result
+	}
+
+	pathItem, errs, pathValue := paths.FindPathWithOptions(exchange.Request, v.v3Model, v.options)
+	if pathItem == nil || errs != nil {
+		result.Errors = errs
+		return result
+	}
+	result.Path = pathValue
+	result.Operation = helpers.ExtractOperation(exchange.Request, pathItem)
+
+	var valid bool
+	valid, result.Errors = v.validateHttpRequest(ctx, exchange.Request, pathItem, pathValue)
+	if exchange.Response != nil && (valid || !v.options.FailFast) {
+		_, responseErrors := v.responseValidator.ValidateResponseBodyWithPathItem(
+			exchange.Request, exchange.Response, pathItem, pathValue)
+		result.Errors = v.prepareErrors(append(result.Errors, responseErrors...))
+	}
+	result.Valid = !errors.HasErrors(result.Errors)
+	return result
+}
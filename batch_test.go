@@ -0,0 +1,113 @@
+// Copyright 2023 Princess B33f Heavy Industries / Dave Shanley
+// SPDX-License-Identifier: MIT
+
+package validator
+
+import (
+	"bytes"
+	"context"
+	"github.com/pb33f/libopenapi"
+	"github.com/stretchr/testify/assert"
+	"io"
+	"net/http"
+	"testing"
+)
+
+var batchSpec = `openapi: 3.1.0
+paths:
+  /burgers:
+    post:
+      operationId: createBurger
+      requestBody:
+        content:
+          application/json:
+            schema:
+              $ref: '#/components/schemas/Burger'
+      responses:
+        '200':
+          content:
+            application/json:
+              schema:
+                $ref: '#/components/schemas/Burger'
+components:
+  schemas:
+    Burger:
+      type: object
+      required: [name]
+      properties:
+        name:
+          type: string
+        patties:
+          type: integer`
+
+func recordedExchange(path, requestBody, responseBody string) *Exchange {
+	request, _ := http.NewRequest(http.MethodPost, "https://things.com"+path, bytes.NewBufferString(requestBody))
+	request.Header.Set("Content-Type", "application/json")
+	response := &http.Response{
+		StatusCode: http.StatusOK,
+		Header:     http.Header{"Content-Type": {"application/json"}},
+		Body:       io.NopCloser(bytes.NewBufferString(responseBody)),
+		Request:    request,
+	}
+	return &Exchange{Request: request, Response: response}
+}
+
+func TestNewValidator_ValidateExchanges(t *testing.T) {
+
+	doc, _ := libopenapi.NewDocument([]byte(batchSpec))
+	v, _ := NewValidator(doc)
+
+	exchanges := []*Exchange{
+		recordedExchange("/burgers", `{"name": "Big Mac", "patties": 2}`, `{"name": "Big Mac", "patties": 2}`),
+		recordedExchange("/burgers", `{"name": "Whopper"}`, `{"patties": "one"}`),
+		recordedExchange("/fries", `{"name": "Large"}`, `{"name": "Large"}`),
+		{Request: recordedExchange("/burgers", `{"patties": 1}`, "").Request},
+	}
+
+	results := v.ValidateExchanges(exchanges)
+	assert.Len(t, results, 4)
+
+	// the results are in the same order as the exchanges.
+	for i, result := range results {
+		assert.Same(t, exchanges[i], result.Exchange)
+	}
+
+	assert.True(t, results[0].Valid)
+	assert.Empty(t, results[0].Errors)
+	assert.Equal(t, "/burgers", results[0].Path)
+	assert.Equal(t, "createBurger", results[0].Operation.OperationId)
+
+	// the request is valid, the response is not.
+	assert.False(t, results[1].Valid)
+	assert.Len(t, results[1].Errors, 1)
+	assert.Equal(t, "createBurger", results[1].Errors[0].OperationId)
+	assert.Equal(t, "/burgers", results[1].Path)
+
+	// no operation matched.
+	assert.False(t, results[2].Valid)
+	assert.Len(t, results[2].Errors, 1)
+	assert.Empty(t, results[2].Path)
+	assert.Nil(t, results[2].Operation)
+
+	// only the request is validated, when there is no response.
+	assert.False(t, results[3].Valid)
+	assert.Len(t, results[3].Errors, 1)
+	assert.Equal(t, "createBurger", results[3].Operation.OperationId)
+}
+
+func TestNewValidator_ValidateExchangesCancelled(t *testing.T) {
+
+	doc, _ := libopenapi.NewDocument([]byte(batchSpec))
+	v, _ := NewValidator(doc)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	results := v.ValidateExchangesWithContext(ctx, []*Exchange{
+		recordedExchange("/burgers", `{"name": "Big Mac"}`, `{"name": "Big Mac"}`),
+	})
+	assert.Len(t, results, 1)
+	assert.False(t, results[0].Valid)
+	assert.Len(t, results[0].Errors, 1)
+	assert.True(t, results[0].Errors[0].IsCancelledError())
+}
@@ -0,0 +1,64 @@
+// Copyright 2023 Princess B33f Heavy Industries / Dave Shanley
+// SPDX-License-Identifier: MIT
+
+// Command validate-har checks the traffic recorded in a HAR (HTTP Archive) file against an OpenAPI 3+ document, and
+// prints a JSON report of every entry. The exit code is 1 if any entry violates the specification.
+//
+//	validate-har -spec openapi.yaml -har traffic.har
+package main
+
+import (
+	"encoding/json"
+	"flag"
+	"fmt"
+	"github.com/pb33f/libopenapi"
+	validator "github.com/pb33f/libopenapi-validator"
+	"github.com/pb33f/libopenapi-validator/har"
+	"os"
+)
+
+func main() {
+	specPath := flag.String("spec", "", "the OpenAPI 3+ document to validate against")
+	harPath := flag.String("har", "", "the HAR file to validate")
+	flag.Parse()
+	if *specPath == "" || *harPath == "" {
+		flag.Usage()
+		os.Exit(2)
+	}
+
+	report, err := check(*specPath, *harPath)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(2)
+	}
+	encoder := json.NewEncoder(os.Stdout)
+	encoder.SetIndent("", "  ")
+	if err = encoder.Encode(report); err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(2)
+	}
+	if len(report.Violations()) > 0 {
+		os.Exit(1)
+	}
+}
+
+func check(specPath, harPath string) (*har.Report, error) {
+	spec, err := os.ReadFile(specPath)
+	if err != nil {
+		return nil, err
+	}
+	document, err := libopenapi.NewDocument(spec)
+	if err != nil {
+		return nil, err
+	}
+	v, errs := validator.NewValidator(document)
+	if len(errs) > 0 {
+		return nil, fmt.Errorf("unable to build a validator for '%s': %v", specPath, errs)
+	}
+	archive, err := os.Open(harPath)
+	if err != nil {
+		return nil, err
+	}
+	defer archive.Close()
+	return har.Check(v, archive)
+}
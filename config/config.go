@@ -0,0 +1,372 @@
+// Copyright 2023 Princess B33f Heavy Industries / Dave Shanley
+// SPDX-License-Identifier: MIT
+
+package config
+
+import (
+	"log/slog"
+	"net/http"
+	"net/url"
+)
+
+// ValidationOptions is a container for the configuration used by the validators. The zero value of every option
+// matches the default behavior of the validators, so options only need to be set to change that behavior.
+type ValidationOptions struct {
+
+	// StrictContentType will require the full Content-Type header (including any parameters, such as charset) to
+	// exactly match one of the media types defined in the specification. When false (the default), parameters are
+	// ignored and only the media type itself (e.g. application/json) needs to match.
+	StrictContentType bool
+
+	// SkipResponseBodyValidation will disable validation of response bodies. The response code and content type
+	// are still checked. When false (the default), response bodies are validated against their schemas.
+	SkipResponseBodyValidation bool
+
+	// FormatAssertions will validate string formats (date-time, email, uuid, ipv4 etc.) when validating the schemas
+	// of bodies, parameters and headers. OpenAPI 3.1 (and JSON Schema 2020-12) treats format as an annotation, so
+	// when false (the default) formats are not checked.
+	FormatAssertions bool
+
+	// Formats holds custom string formats, keyed by the name of the format. Custom formats are always validated,
+	// regardless of FormatAssertions, and replace any built-in format with the same name.
+	Formats map[string]func(string) bool
+
+	// ApplyDefaults will treat the schema default of an optional query parameter as the value of the parameter
+	// when it's missing from the request. The default is validated, and added to the query of the request (once
+	// validation has finished) so it can be read by the caller. When false (the default), missing optional
+	// parameters are ignored.
+	ApplyDefaults bool
+
+	// StrictWriteOnly will report writeOnly properties that appear in response bodies. writeOnly properties are
+	// never required in responses, when false (the default) they are allowed to appear.
+	StrictWriteOnly bool
+
+	// SecurityVerifiers holds functions that verify credentials, keyed by the name of the security scheme. When a
+	// security scheme has no verifier (the default), only the presence of its credentials is checked.
+	SecurityVerifiers map[string]func(string) bool
+
+	// SkipBasePathStripping will match request paths against the paths in the specification as they are. When false
+	// (the default), the base path of the matching server (e.g. /v2 for https://{region}.api.com/v2) is stripped
+	// from the request path first.
+	SkipBasePathStripping bool
+
+	// StrictPathMatching will match request paths exactly as they are sent. When false (the default), duplicate
+	// slashes are collapsed and trailing slashes are ignored, so /pet/ and /pet//123 match /pet and /pet/{petId}.
+	StrictPathMatching bool
+
+	// MaxBodyBytes is the largest request body (in bytes) that will be read for validation, larger bodies fail
+	// validation without being buffered. Zero (the default) means there is no limit. It also limits the size that
+	// compressed (gzip or deflate) request and response bodies are decompressed to, which is 32MB when it's zero.
+	MaxBodyBytes int64
+
+	// RejectUnexpectedBody will fail validation of requests that carry a body, when the operation does not
+	// declare a request body. When false (the default), the body of such requests is ignored.
+	RejectUnexpectedBody bool
+
+	// CaseInsensitiveEnums will match the values of string parameters against their enum without regard to case,
+	// so 'Sold' matches an enum value of 'sold'. When false (the default), values must match exactly.
+	CaseInsensitiveEnums bool
+
+	// RejectDuplicateKeys will fail validation of JSON request bodies that use the same key more than once in an
+	// object. When false (the default), the last value of a duplicate key is validated, and the others are ignored.
+	RejectDuplicateKeys bool
+
+	// RejectUndeclaredQueryParams will fail validation of requests that send query parameters that are not declared
+	// by the operation (or its path item). When false (the default), undeclared query parameters are ignored.
+	RejectUndeclaredQueryParams bool
+
+	// WarnWriteOnly will report writeOnly properties that appear in response bodies as warnings, which do not fail
+	// validation. StrictWriteOnly takes precedence, when both are set the properties are reported as errors.
+	WarnWriteOnly bool
+
+	// DeprecationWarnings will report requests to operations that are marked as deprecated, and query, header and
+	// cookie parameters that are sent but are marked as deprecated, as warnings. When false (the default), they
+	// are validated like any other.
+	DeprecationWarnings bool
+
+	// ReferenceBasePath is the directory that relative file references (e.g. $ref: './schemas/pet.yaml#/Pet') are
+	// resolved from, usually the directory of the root specification. When empty (the default), file references
+	// cannot be resolved.
+	ReferenceBasePath string
+
+	// AllowRemoteReferences will resolve remote (HTTP) references, relative references are resolved from the
+	// RemoteReferenceBaseURL (if it's set). When false (the default), remote references are denied.
+	AllowRemoteReferences bool
+
+	// RemoteReferenceBaseURL is the URL that relative references are resolved from, when they are not found locally.
+	RemoteReferenceBaseURL *url.URL
+
+	// RemoteReferenceHandler is used to fetch remote references, it replaces the default HTTP client. It is only
+	// used when remote references are allowed.
+	RemoteReferenceHandler func(url string) (*http.Response, error)
+
+	// Observer receives how long schema compilation, body parsing and schema validation take. When nil (the
+	// default), nothing is observed.
+	Observer *Observer
+
+	// SkipErrorDeduplication will return every error found, even when the same error is found more than once (for
+	// example when a header is declared twice, or a constraint is repeated in an allOf). When false (the default),
+	// duplicate errors, and duplicate schema failures within an error, are removed (see errors.Deduplicate).
+	SkipErrorDeduplication bool
+
+	// CheckAcceptHeader will compare the Content-Type of a response with the Accept header of the request it was
+	// sent for, a response the client did not accept is reported as a warning. When false (the default), the Accept
+	// header is ignored.
+	CheckAcceptHeader bool
+
+	// FailFast will stop validating at the first error, and return only that error. Stages are validated in turn
+	// (path parameters, cookies, headers, query parameters, security, the request body and then the response),
+	// rather than concurrently. When false (the default), every error is collected and returned.
+	FailFast bool
+
+	// Logger receives debug messages describing the decisions made during validation: the path and operation that
+	// matched, the media type and response that were selected, and which validation stages ran. When nil (the
+	// default), nothing is logged.
+	Logger *slog.Logger
+}
+
+// Option is a function that modifies ValidationOptions, used when creating new validators.
+type Option func(*ValidationOptions)
+
+// NewValidationOptions will create a new ValidationOptions with the defaults set, and then apply
+// any supplied options.
+func NewValidationOptions(opts ...Option) *ValidationOptions {
+	o := &ValidationOptions{}
+	for _, opt := range opts {
+		if opt != nil {
+			opt(o)
+		}
+	}
+	return o
+}
+
+// Debug will log a debug message (with key-value attributes) to the Logger, nothing is logged when the options or
+// the Logger are nil.
+func (o *ValidationOptions) Debug(msg string, args ...any) {
+	if o != nil && o.Logger != nil {
+		o.Logger.Debug(msg, args...)
+	}
+}
+
+// WithExistingOpts will copy an existing set of ValidationOptions, so validators can share the options of
+// another validator. Options supplied after this one are applied on top.
+func WithExistingOpts(options *ValidationOptions) Option {
+	return func(o *ValidationOptions) {
+		if options != nil {
+			*o = *options
+		}
+	}
+}
+
+// WithStrictContentType will require Content-Type headers to exactly match a media type declared in the
+// specification, including any parameters (e.g. 'application/json; charset=utf-8').
+func WithStrictContentType() Option {
+	return func(o *ValidationOptions) {
+		o.StrictContentType = true
+	}
+}
+
+// WithoutResponseBodyValidation will skip schema validation of response bodies, this is useful when response
+// bodies are large and validating them is too expensive.
+func WithoutResponseBodyValidation() Option {
+	return func(o *ValidationOptions) {
+		o.SkipResponseBodyValidation = true
+	}
+}
+
+// WithFormatAssertions will validate the format of strings (for example 'date-time', 'email' and 'uuid') in
+// request and response bodies, and when validating schemas. By default, formats are treated as annotations.
+func WithFormatAssertions() Option {
+	return func(o *ValidationOptions) {
+		o.FormatAssertions = true
+	}
+}
+
+// WithCustomFormat will register a custom string format (for example 'iso-currency' or 'e164-phone'). Any string
+// declared with the format is checked using the supplied function, which should return false if the value is not
+// valid. Non string values are ignored, they are left to the 'type' keyword.
+func WithCustomFormat(name string, fn func(string) bool) Option {
+	return func(o *ValidationOptions) {
+		if o.Formats == nil {
+			o.Formats = make(map[string]func(string) bool)
+		}
+		o.Formats[name] = fn
+	}
+}
+
+// WithApplyDefaults will apply the schema default of optional query parameters that are missing from a request.
+// The default is validated as if it had been sent, and is added to the query of the request once validation has
+// finished, so the resolved value can be read using request.URL.Query(). Required parameters never have defaults
+// applied.
+func WithApplyDefaults() Option {
+	return func(o *ValidationOptions) {
+		o.ApplyDefaults = true
+	}
+}
+
+// WithStrictWriteOnly will report any property marked as writeOnly that appears in a response body, writeOnly
+// properties (such as passwords) should never be returned by the server.
+func WithStrictWriteOnly() Option {
+	return func(o *ValidationOptions) {
+		o.StrictWriteOnly = true
+	}
+}
+
+// WithSecurityVerifier will register a function that verifies the credentials of a security scheme, using the name
+// of the scheme in components.securitySchemes. The function receives the credential extracted from the request
+// (the api key, or the credentials of the Authorization header without the scheme, such as a bearer token) and
+// should return false if the credential is not valid.
+func WithSecurityVerifier(scheme string, verifier func(credential string) bool) Option {
+	return func(o *ValidationOptions) {
+		if o.SecurityVerifiers == nil {
+			o.SecurityVerifiers = make(map[string]func(string) bool)
+		}
+		o.SecurityVerifiers[scheme] = verifier
+	}
+}
+
+// WithoutBasePathStripping will stop the base path of servers being stripped from request paths before they are
+// matched against the paths in the specification, this is useful for routers that have already stripped it.
+func WithoutBasePathStripping() Option {
+	return func(o *ValidationOptions) {
+		o.SkipBasePathStripping = true
+	}
+}
+
+// WithStrictPathMatching will preserve the distinction between /pet and /pet/ (and /pet//123 and /pet/123) when
+// matching request paths against the paths in the specification.
+func WithStrictPathMatching() Option {
+	return func(o *ValidationOptions) {
+		o.StrictPathMatching = true
+	}
+}
+
+// WithMaxBodyBytes will limit the size of request bodies that are read for validation to n bytes. Requests with a
+// Content-Length larger than n fail without reading the body, and bodies without a Content-Length stop being read
+// once they cross the limit. This protects against large payloads being buffered in memory to be parsed. The
+// Middleware and ResponseMiddleware of the validator package buffer no more than n bytes of a request body either.
+func WithMaxBodyBytes(n int64) Option {
+	return func(o *ValidationOptions) {
+		o.MaxBodyBytes = n
+	}
+}
+
+// WithRejectUnexpectedBody will fail validation of requests that carry a body (a GET or DELETE sent with a JSON
+// body for example), when the operation does not declare a request body.
+func WithRejectUnexpectedBody() Option {
+	return func(o *ValidationOptions) {
+		o.RejectUnexpectedBody = true
+	}
+}
+
+// WithCaseInsensitiveEnums will match string parameter values (path, query, header and cookie) against their enum
+// values without regard to case. Values that match are validated as the canonical enum value.
+func WithCaseInsensitiveEnums() Option {
+	return func(o *ValidationOptions) {
+		o.CaseInsensitiveEnums = true
+	}
+}
+
+// WithRejectDuplicateKeys will reject JSON request bodies that use the same key more than once in an object, each
+// duplicate is reported with its JSON Pointer. Bodies are scanned an extra time to find duplicates.
+func WithRejectDuplicateKeys() Option {
+	return func(o *ValidationOptions) {
+		o.RejectDuplicateKeys = true
+	}
+}
+
+// WithRejectUndeclaredQueryParams will reject requests that send a query parameter which is not declared by the
+// operation, or by the path item the operation belongs to. Each undeclared parameter is reported.
+func WithRejectUndeclaredQueryParams() Option {
+	return func(o *ValidationOptions) {
+		o.RejectUndeclaredQueryParams = true
+	}
+}
+
+// WithWarnWriteOnly will report any property marked as writeOnly that appears in a response body as a warning, the
+// response is still valid. Warnings are returned with the validation errors, use IsWarning to tell them apart.
+func WithWarnWriteOnly() Option {
+	return func(o *ValidationOptions) {
+		o.WarnWriteOnly = true
+	}
+}
+
+// WithDeprecationWarnings will report a warning for a request to an operation that is marked as deprecated by the
+// specification, and for every deprecated parameter that is sent with a request. The request is still valid. The
+// 'x-replaced-by' extension of the operation or parameter (if it has one) is included in the warning.
+func WithDeprecationWarnings() Option {
+	return func(o *ValidationOptions) {
+		o.DeprecationWarnings = true
+	}
+}
+
+// WithAcceptHeaderCheck will report a warning for a response with a Content-Type that the Accept header of the
+// request does not allow, a handler that ignores the Accept header should respond with 406 (Not Acceptable).
+// 406 responses, and responses without a Content-Type, are not checked.
+func WithAcceptHeaderCheck() Option {
+	return func(o *ValidationOptions) {
+		o.CheckAcceptHeader = true
+	}
+}
+
+// WithFailFast will stop validation at the first stage that fails, and return only the first error found, which
+// is useful to reject bad requests quickly. Later stages are never validated, so the error returned may not be the
+// first of the errors returned when every error is collected (the parameters and the body are validated
+// concurrently then). Warnings are only returned when there is no error.
+func WithFailFast() Option {
+	return func(o *ValidationOptions) {
+		o.FailFast = true
+	}
+}
+
+// WithReferenceBasePath will resolve relative file references (e.g. $ref: './schemas/pet.yaml#/Pet') from the
+// supplied directory, usually the directory of the root specification. Every YAML and JSON file below the directory
+// can be read, so keep it as narrow as possible.
+func WithReferenceBasePath(basePath string) Option {
+	return func(o *ValidationOptions) {
+		o.ReferenceBasePath = basePath
+	}
+}
+
+// WithRemoteReferences will allow remote (HTTP) references to be fetched and resolved. Relative references that
+// cannot be found locally are resolved from the baseURL, which can be nil when only absolute URLs are used.
+func WithRemoteReferences(baseURL *url.URL) Option {
+	return func(o *ValidationOptions) {
+		o.AllowRemoteReferences = true
+		o.RemoteReferenceBaseURL = baseURL
+	}
+}
+
+// WithRemoteReferenceHandler will allow remote references, and fetch them using the supplied handler rather than
+// the default HTTP client. Use it to add authentication, to cache, or to serve references from somewhere else.
+func WithRemoteReferenceHandler(handler func(url string) (*http.Response, error)) Option {
+	return func(o *ValidationOptions) {
+		o.AllowRemoteReferences = true
+		o.RemoteReferenceHandler = handler
+	}
+}
+
+// WithoutErrorDeduplication will return the raw set of errors found during validation, including duplicates. By
+// default, errors that are found more than once are only returned once.
+func WithoutErrorDeduplication() Option {
+	return func(o *ValidationOptions) {
+		o.SkipErrorDeduplication = true
+	}
+}
+
+// WithObserver will report how long each stage of validation takes to the callbacks of the observer, for example
+// to record metrics. Callbacks that are nil are ignored.
+func WithObserver(observer Observer) Option {
+	return func(o *ValidationOptions) {
+		o.Observer = &observer
+	}
+}
+
+// WithLogger will log the decisions made during validation at the debug level, such as the path template and
+// operation that matched the request, the content type and media type that were selected, and which validation
+// stages ran. Use it to find out why a request or response passed or failed.
+func WithLogger(logger *slog.Logger) Option {
+	return func(o *ValidationOptions) {
+		o.Logger = logger
+	}
+}
@@ -0,0 +1,44 @@
+// Copyright 2023 Princess B33f Heavy Industries / Dave Shanley
+// SPDX-License-Identifier: MIT
+
+package config
+
+import "time"
+
+// Observer holds callbacks that receive how long each stage of validation took, so validation can be monitored
+// (for example, recorded as Prometheus histograms) without wrapping the validator. Every callback is optional, a nil
+// callback is never called. Callbacks are called from the goroutine performing the validation, so they must be
+// safe for concurrent use, and should return quickly.
+type Observer struct {
+
+	// OnSchemaCompiled is called when the schema of a request or response body has been rendered and compiled.
+	// Compiled schemas are cached, so it's only called the first time each schema is used.
+	OnSchemaCompiled func(duration time.Duration)
+
+	// OnBodyParsed is called when a request or response body has been read and decoded (JSON, XML, forms etc.).
+	OnBodyParsed func(duration time.Duration)
+
+	// OnValidated is called when a decoded request or response body has been validated against its schema.
+	OnValidated func(duration time.Duration)
+}
+
+// SchemaCompiled will report the time since start to OnSchemaCompiled, it's safe to call on a nil Observer.
+func (o *Observer) SchemaCompiled(start time.Time) {
+	if o != nil && o.OnSchemaCompiled != nil {
+		o.OnSchemaCompiled(time.Since(start))
+	}
+}
+
+// BodyParsed will report the time since start to OnBodyParsed, it's safe to call on a nil Observer.
+func (o *Observer) BodyParsed(start time.Time) {
+	if o != nil && o.OnBodyParsed != nil {
+		o.OnBodyParsed(time.Since(start))
+	}
+}
+
+// Validated will report the time since start to OnValidated, it's safe to call on a nil Observer.
+func (o *Observer) Validated(start time.Time) {
+	if o != nil && o.OnValidated != nil {
+		o.OnValidated(time.Since(start))
+	}
+}
@@ -0,0 +1,161 @@
+// Copyright 2023 Princess B33f Heavy Industries / Dave Shanley
+// SPDX-License-Identifier: MIT
+
+package errors
+
+import (
+	"fmt"
+	"github.com/pb33f/libopenapi-validator/helpers"
+	"github.com/pb33f/libopenapi/datamodel/high/base"
+	"github.com/pb33f/libopenapi/datamodel/high/v3"
+	"gopkg.in/yaml.v3"
+	"strings"
+)
+
+// DocumentSchemaUnresolved returns a ValidationError for a schema in the document that cannot be built, for
+// example when a $ref cannot be resolved. The method is empty for parameters defined by a path item, the
+// location describes where the schema is used (e.g. "request body 'application/json'").
+func DocumentSchemaUnresolved(method, path, location string, schema *base.SchemaProxy, err error) *ValidationError {
+	specLine, specCol := schemaProxyPosition(schema)
+	return &ValidationError{
+		Code:              ErrorCodeDocument,
+		ValidationType:    helpers.DocumentValidation,
+		ValidationSubType: helpers.Schema,
+		Message:           fmt.Sprintf("The %s schema of %s cannot be resolved", location, operationLabel(method, path)),
+		Reason:            err.Error(),
+		SpecLine:          specLine,
+		SpecCol:           specCol,
+		HowToFix:          HowToFixSchemaReference,
+		Context:           schema,
+	}
+}
+
+// DocumentSchemaInvalid returns a ValidationError for a schema in the document that cannot be rendered or
+// compiled, so it cannot be used to validate anything.
+func DocumentSchemaInvalid(method, path, location string, schema *base.SchemaProxy, err error) *ValidationError {
+	specLine, specCol := schemaProxyPosition(schema)
+	return &ValidationError{
+		Code:              ErrorCodeDocument,
+		ValidationType:    helpers.DocumentValidation,
+		ValidationSubType: helpers.Schema,
+		Message:           fmt.Sprintf("The %s schema of %s cannot be compiled", location, operationLabel(method, path)),
+		Reason:            err.Error(),
+		SpecLine:          specLine,
+		SpecCol:           specCol,
+		HowToFix:          HowToFixSchemaCompile,
+		Context:           schema,
+	}
+}
+
+// DocumentParameterStyleInvalid returns a ValidationError for a parameter that uses a style that is not allowed
+// for its location, for example a header parameter using 'form'.
+func DocumentParameterStyleInvalid(method, path string, param *v3.Parameter, allowed []string) *ValidationError {
+	var specLine, specCol int
+	if low := param.GoLow(); low != nil && low.Style.ValueNode != nil {
+		specLine = low.Style.ValueNode.Line
+		specCol = low.Style.ValueNode.Column
+	}
+	return &ValidationError{
+		Code:              ErrorCodeDocument,
+		ValidationType:    helpers.DocumentValidation,
+		ValidationSubType: helpers.ParameterStyle,
+		Message: fmt.Sprintf("The %s parameter '%s' of %s uses the '%s' style, which is not allowed",
+			param.In, param.Name, operationLabel(method, path), param.Style),
+		Reason: fmt.Sprintf("The '%s' style cannot be used for %s parameters, only '%s' can be used",
+			param.Style, param.In, strings.Join(allowed, "', '")),
+		SpecLine: specLine,
+		SpecCol:  specCol,
+		HowToFix: fmt.Sprintf(HowToFixParameterStyle, strings.Join(allowed, "', '")),
+		Context:  param,
+	}
+}
+
+// DocumentExampleInvalid returns a ValidationError for an example in the document that does not match its schema.
+// The name is empty for the singular 'example', otherwise it's the name the example is defined with in 'examples'.
+func DocumentExampleInvalid(method, path, location, name string,
+	example *yaml.Node, failures []*SchemaValidationFailure) *ValidationError {
+	label := "example"
+	if name != "" {
+		label = fmt.Sprintf("example '%s'", name)
+	}
+	var specLine, specCol int
+	if example != nil {
+		specLine = example.Line
+		specCol = example.Column
+	}
+	return &ValidationError{
+		Code:              ErrorCodeDocument,
+		ValidationType:    helpers.DocumentValidation,
+		ValidationSubType: helpers.Example,
+		Message: fmt.Sprintf("The %s of the %s of %s does not match its schema",
+			label, location, operationLabel(method, path)),
+		Reason:                 fmt.Sprintf("The %s does not validate against its schema: %s", label, failureReasons(failures)),
+		SpecLine:               specLine,
+		SpecCol:                specCol,
+		SchemaValidationErrors: failures,
+		HowToFix:               HowToFixExample,
+		Context:                example,
+	}
+}
+
+// failureReasons joins the reasons of schema validation failures, for use in a reason.
+func failureReasons(failures []*SchemaValidationFailure) string {
+	reasons := make([]string, 0, len(failures))
+	for _, failure := range failures {
+		reasons = append(reasons, failure.Reason)
+	}
+	return strings.Join(reasons, "; ")
+}
+
+// DocumentReferenceUnresolved returns a ValidationError for a $ref in the document that cannot be resolved, for
+// example a file that does not exist, or a remote reference when remote references are not allowed.
+func DocumentReferenceUnresolved(reference string, node *yaml.Node, err error) *ValidationError {
+	var specLine, specCol int
+	if node != nil {
+		specLine = node.Line
+		specCol = node.Column
+	}
+	return &ValidationError{
+		Code:              ErrorCodeDocument,
+		ValidationType:    helpers.DocumentValidation,
+		ValidationSubType: helpers.Reference,
+		Message:           fmt.Sprintf("The reference '%s' cannot be resolved", reference),
+		Reason:            err.Error(),
+		SpecLine:          specLine,
+		SpecCol:           specCol,
+		HowToFix:          HowToFixReference,
+		Context:           node,
+	}
+}
+
+// schemaBuildReason explains why a schema cannot be built, using the reference it points to.
+func schemaBuildReason(schema *base.SchemaProxy) string {
+	reason := fmt.Sprintf("The schema '%s' cannot be built", schema.GetReference())
+	if err := schema.GetBuildError(); err != nil {
+		reason = fmt.Sprintf("%s: %s", reason, err.Error())
+	}
+	return reason
+}
+
+// operationLabel describes an operation (or a path item, when there is no method) for use in a message.
+func operationLabel(method, path string) string {
+	if method == "" {
+		return fmt.Sprintf("path '%s'", path)
+	}
+	return fmt.Sprintf("the %s operation for '%s'", strings.ToUpper(method), path)
+}
+
+func schemaProxyPosition(schema *base.SchemaProxy) (int, int) {
+	if schema == nil || schema.GoLow() == nil || schema.GoLow().GetValueNode() == nil {
+		return 0, 0
+	}
+	return schema.GoLow().GetValueNode().Line, schema.GoLow().GetValueNode().Column
+}
+
+// schemaPosition returns the line and column of the type of a schema, or line 1 (column 0) if it has no type.
+func schemaPosition(schema *base.Schema) (int, int) {
+	if schema == nil || schema.GoLow() == nil || schema.GoLow().Type.KeyNode == nil {
+		return 1, 0
+	}
+	return schema.GoLow().Type.KeyNode.Line, schema.GoLow().Type.KeyNode.Column
+}
@@ -0,0 +1,92 @@
+// Copyright 2023 Princess B33f Heavy Industries / Dave Shanley
+// SPDX-License-Identifier: MIT
+
+package errors
+
+// ErrorCode is the category of a ValidationError. Codes are stable across versions of the library, unlike the
+// Message and Reason, so they can be used to handle errors programmatically, without matching text.
+type ErrorCode string
+
+const (
+	// ErrorCodePathNotFound is used when no path in the specification matches the request.
+	ErrorCodePathNotFound ErrorCode = "path_not_found"
+
+	// ErrorCodeOperationNotFound is used when the operation (or webhook, or callback) asked for does not exist.
+	ErrorCodeOperationNotFound ErrorCode = "operation_not_found"
+
+	// ErrorCodeMethodMismatch is used when the method of the request is not the method of the operation.
+	ErrorCodeMethodMismatch ErrorCode = "method_mismatch"
+
+	// ErrorCodeOperationDeprecated is used for the warning returned when a deprecated operation is called.
+	ErrorCodeOperationDeprecated ErrorCode = "operation_deprecated"
+
+	// ErrorCodeQueryParamMissing is used when a required query parameter is not sent.
+	ErrorCodeQueryParamMissing ErrorCode = "query_parameter_missing"
+
+	// ErrorCodeQueryParamNotDeclared is used when a query parameter is sent that the operation does not declare.
+	ErrorCodeQueryParamNotDeclared ErrorCode = "query_parameter_not_declared"
+
+	// ErrorCodeHeaderParamMissing is used when a required header parameter is not sent.
+	ErrorCodeHeaderParamMissing ErrorCode = "header_parameter_missing"
+
+	// ErrorCodeCookieParamMissing is used when a required cookie parameter is not sent.
+	ErrorCodeCookieParamMissing ErrorCode = "cookie_parameter_missing"
+
+	// ErrorCodePathParamMissing is used when a path parameter has no value.
+	ErrorCodePathParamMissing ErrorCode = "path_parameter_missing"
+
+	// ErrorCodeParamType is used when the value of a parameter is not of the type declared by its schema.
+	ErrorCodeParamType ErrorCode = "parameter_type"
+
+	// ErrorCodeParamEnum is used when the value of a parameter is not one of the values of its enum.
+	ErrorCodeParamEnum ErrorCode = "parameter_enum"
+
+	// ErrorCodeParamEncoding is used when a parameter is not serialized using its style (or media type), or
+	// cannot be decoded.
+	ErrorCodeParamEncoding ErrorCode = "parameter_encoding"
+
+	// ErrorCodeParamSchema is used when the value of a parameter fails to validate against its schema.
+	ErrorCodeParamSchema ErrorCode = "parameter_schema"
+
+	// ErrorCodeParamDeprecated is used for the warning returned when a deprecated parameter is sent.
+	ErrorCodeParamDeprecated ErrorCode = "parameter_deprecated"
+
+	// ErrorCodeContentType is used when the content type of a body is missing, or is not declared.
+	ErrorCodeContentType ErrorCode = "content_type"
+
+	// ErrorCodeNotAcceptable is used for the warning returned when the content type of a response is not allowed by
+	// the Accept header of the request.
+	ErrorCodeNotAcceptable ErrorCode = "not_acceptable"
+
+	// ErrorCodeBodyMissing is used when a required request body is not sent (or is empty).
+	ErrorCodeBodyMissing ErrorCode = "body_missing"
+
+	// ErrorCodeBodyNotExpected is used when a request body is sent to an operation that does not accept one.
+	ErrorCodeBodyNotExpected ErrorCode = "body_not_expected"
+
+	// ErrorCodeBodyTooLarge is used when a body is larger than the limit set by config.WithMaxBodyBytes.
+	ErrorCodeBodyTooLarge ErrorCode = "body_too_large"
+
+	// ErrorCodeBodyMalformed is used when a body cannot be decoded using its content type (or content encoding).
+	ErrorCodeBodyMalformed ErrorCode = "body_malformed"
+
+	// ErrorCodeSchema is used when a body fails to validate against its schema.
+	ErrorCodeSchema ErrorCode = "schema"
+
+	// ErrorCodeResponseCode is used when the status code of a response is not declared by the operation.
+	ErrorCodeResponseCode ErrorCode = "response_code"
+
+	// ErrorCodeResponseHeaderMissing is used when a required response header (trailer, or cookie) is not sent.
+	ErrorCodeResponseHeaderMissing ErrorCode = "response_header_missing"
+
+	// ErrorCodeSecurity is used when none of the security requirements of the operation are met, because credentials
+	// are missing, or were rejected by a verifier.
+	ErrorCodeSecurity ErrorCode = "security"
+
+	// ErrorCodeDocument is used when the specification itself is invalid, for example a schema that cannot be
+	// resolved (or compiled), so the request or response cannot be validated.
+	ErrorCodeDocument ErrorCode = "document"
+
+	// ErrorCodeCancelled is used when the context used for validation is done before validation completes.
+	ErrorCodeCancelled ErrorCode = "cancelled"
+)
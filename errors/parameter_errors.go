@@ -5,12 +5,14 @@ import (
 	"github.com/pb33f/libopenapi-validator/helpers"
 	"github.com/pb33f/libopenapi/datamodel/high/base"
 	"github.com/pb33f/libopenapi/datamodel/high/v3"
+	"net/http"
 	"net/url"
 	"strings"
 )
 
 func IncorrectFormEncoding(param *v3.Parameter, qp *helpers.QueryParam, i int) *ValidationError {
 	return &ValidationError{
+		Code:              ErrorCodeParamEncoding,
 		ValidationType:    helpers.ParameterValidation,
 		ValidationSubType: helpers.ParameterValidationQuery,
 		Message:           fmt.Sprintf("Query parameter '%s' is not exploded correctly", param.Name),
@@ -27,6 +29,7 @@ func IncorrectFormEncoding(param *v3.Parameter, qp *helpers.QueryParam, i int) *
 
 func IncorrectSpaceDelimiting(param *v3.Parameter, qp *helpers.QueryParam) *ValidationError {
 	return &ValidationError{
+		Code:              ErrorCodeParamEncoding,
 		ValidationType:    helpers.ParameterValidation,
 		ValidationSubType: helpers.ParameterValidationQuery,
 		Message:           fmt.Sprintf("Query parameter '%s' delimited incorrectly", param.Name),
@@ -43,6 +46,7 @@ func IncorrectSpaceDelimiting(param *v3.Parameter, qp *helpers.QueryParam) *Vali
 
 func IncorrectPipeDelimiting(param *v3.Parameter, qp *helpers.QueryParam) *ValidationError {
 	return &ValidationError{
+		Code:              ErrorCodeParamEncoding,
 		ValidationType:    helpers.ParameterValidation,
 		ValidationSubType: helpers.ParameterValidationQuery,
 		Message:           fmt.Sprintf("Query parameter '%s' delimited incorrectly", param.Name),
@@ -57,8 +61,30 @@ func IncorrectPipeDelimiting(param *v3.Parameter, qp *helpers.QueryParam) *Valid
 	}
 }
 
+func IncorrectQueryParamArrayDelimiter(param *v3.Parameter, value string) *ValidationError {
+	howToFix := fmt.Sprintf(HowToFixParamInvalidPipeDelimitedObjectExplode,
+		helpers.CollapseCSVIntoPipeDelimitedStyle(param.Name, strings.Split(value, helpers.Comma)))
+	if param.Style == helpers.SpaceDelimited {
+		howToFix = fmt.Sprintf(HowToFixParamInvalidSpaceDelimitedObjectExplode,
+			helpers.CollapseCSVIntoSpaceDelimitedStyle(param.Name, strings.Split(value, helpers.Comma)))
+	}
+	return &ValidationError{
+		Code:              ErrorCodeParamEncoding,
+		ValidationType:    helpers.ParameterValidation,
+		ValidationSubType: helpers.ParameterValidationQuery,
+		Message:           fmt.Sprintf("Query array parameter '%s' delimited incorrectly", param.Name),
+		Reason: fmt.Sprintf("The query parameter (which is an array) '%s' has '%s' style defined, "+
+			"however the value '%s' is delimited using commas", param.Name, param.Style, value),
+		SpecLine: param.GoLow().Style.ValueNode.Line,
+		SpecCol:  param.GoLow().Style.ValueNode.Column,
+		Context:  param,
+		HowToFix: howToFix,
+	}
+}
+
 func InvalidDeepObject(param *v3.Parameter, qp *helpers.QueryParam) *ValidationError {
 	return &ValidationError{
+		Code:              ErrorCodeParamEncoding,
 		ValidationType:    helpers.ParameterValidation,
 		ValidationSubType: helpers.ParameterValidationQuery,
 		Message:           fmt.Sprintf("Query parameter '%s' is not a valid deepObject", param.Name),
@@ -75,6 +101,7 @@ func InvalidDeepObject(param *v3.Parameter, qp *helpers.QueryParam) *ValidationE
 
 func QueryParameterMissing(param *v3.Parameter) *ValidationError {
 	return &ValidationError{
+		Code:              ErrorCodeQueryParamMissing,
 		ValidationType:    helpers.ParameterValidation,
 		ValidationSubType: helpers.ParameterValidationQuery,
 		Message:           fmt.Sprintf("Query parameter '%s' is missing", param.Name),
@@ -86,8 +113,25 @@ func QueryParameterMissing(param *v3.Parameter) *ValidationError {
 	}
 }
 
+// QueryParameterNotDeclared returns a ValidationError for a query parameter that is sent, but is not declared by the
+// operation (or its path item).
+func QueryParameterNotDeclared(name string, request *http.Request) *ValidationError {
+	return &ValidationError{
+		Code:              ErrorCodeQueryParamNotDeclared,
+		ValidationType:    helpers.ParameterValidation,
+		ValidationSubType: helpers.ParameterValidationQuery,
+		Message:           fmt.Sprintf("Query parameter '%s' is not declared", name),
+		Reason: fmt.Sprintf("The query parameter '%s' is not declared by the %s operation for '%s', "+
+			"only declared query parameters can be sent", name, request.Method, request.URL.Path),
+		SpecLine: -1,
+		SpecCol:  -1,
+		HowToFix: fmt.Sprintf(HowToFixUndeclaredQueryParam, name),
+	}
+}
+
 func HeaderParameterMissing(param *v3.Parameter) *ValidationError {
 	return &ValidationError{
+		Code:              ErrorCodeHeaderParamMissing,
 		ValidationType:    helpers.ParameterValidation,
 		ValidationSubType: helpers.ParameterValidationHeader,
 		Message:           fmt.Sprintf("Header parameter '%s' is missing", param.Name),
@@ -95,12 +139,13 @@ func HeaderParameterMissing(param *v3.Parameter) *ValidationError {
 			"however it's missing from the requests", param.Name),
 		SpecLine: param.GoLow().Required.KeyNode.Line,
 		SpecCol:  param.GoLow().Required.KeyNode.Column,
-		HowToFix: HowToFixMissingValue,
+		HowToFix: fmt.Sprintf(HowToFixMissingHeader, param.Name),
 	}
 }
 
 func HeaderParameterCannotBeDecoded(param *v3.Parameter, val string) *ValidationError {
 	return &ValidationError{
+		Code:              ErrorCodeParamEncoding,
 		ValidationType:    helpers.ParameterValidation,
 		ValidationSubType: helpers.ParameterValidationHeader,
 		Message:           fmt.Sprintf("Header parameter '%s' cannot be decoded", param.Name),
@@ -119,6 +164,7 @@ func IncorrectHeaderParamEnum(param *v3.Parameter, ef string, sch *base.Schema)
 	}
 	validEnums := strings.Join(enums, ", ")
 	return &ValidationError{
+		Code:              ErrorCodeParamEnum,
 		ValidationType:    helpers.ParameterValidation,
 		ValidationSubType: helpers.ParameterValidationHeader,
 		Message:           fmt.Sprintf("Header parameter '%s' does not match allowed values", param.Name),
@@ -134,6 +180,7 @@ func IncorrectHeaderParamEnum(param *v3.Parameter, ef string, sch *base.Schema)
 func IncorrectQueryParamArrayBoolean(
 	param *v3.Parameter, item string, sch *base.Schema, itemsSchema *base.Schema) *ValidationError {
 	return &ValidationError{
+		Code:              ErrorCodeParamType,
 		ValidationType:    helpers.ParameterValidation,
 		ValidationSubType: helpers.ParameterValidationQuery,
 		Message:           fmt.Sprintf("Query array parameter '%s' is not a valid boolean", param.Name),
@@ -149,6 +196,7 @@ func IncorrectQueryParamArrayBoolean(
 func IncorrectCookieParamArrayBoolean(
 	param *v3.Parameter, item string, sch *base.Schema, itemsSchema *base.Schema) *ValidationError {
 	return &ValidationError{
+		Code:              ErrorCodeParamType,
 		ValidationType:    helpers.ParameterValidation,
 		ValidationSubType: helpers.ParameterValidationCookie,
 		Message:           fmt.Sprintf("Cookie array parameter '%s' is not a valid boolean", param.Name),
@@ -164,6 +212,7 @@ func IncorrectCookieParamArrayBoolean(
 func IncorrectQueryParamArrayNumber(
 	param *v3.Parameter, item string, sch *base.Schema, itemsSchema *base.Schema) *ValidationError {
 	return &ValidationError{
+		Code:              ErrorCodeParamType,
 		ValidationType:    helpers.ParameterValidation,
 		ValidationSubType: helpers.ParameterValidationQuery,
 		Message:           fmt.Sprintf("Query array parameter '%s' is not a valid number", param.Name),
@@ -179,6 +228,7 @@ func IncorrectQueryParamArrayNumber(
 func IncorrectCookieParamArrayNumber(
 	param *v3.Parameter, item string, sch *base.Schema, itemsSchema *base.Schema) *ValidationError {
 	return &ValidationError{
+		Code:              ErrorCodeParamType,
 		ValidationType:    helpers.ParameterValidation,
 		ValidationSubType: helpers.ParameterValidationCookie,
 		Message:           fmt.Sprintf("Cookie array parameter '%s' is not a valid number", param.Name),
@@ -192,14 +242,24 @@ func IncorrectCookieParamArrayNumber(
 }
 
 func IncorrectParamEncodingJSON(param *v3.Parameter, ef string, sch *base.Schema) *ValidationError {
+	// a parameter has a single media type, the media type is used to locate the error.
+	var contentType string
+	for k := range param.Content {
+		contentType = k
+	}
+	var specLine, specCol int
+	if content := param.GoLow().FindContent(contentType); content != nil && content.ValueNode != nil {
+		specLine, specCol = content.ValueNode.Line, content.ValueNode.Column
+	}
 	return &ValidationError{
+		Code:              ErrorCodeParamEncoding,
 		ValidationType:    helpers.ParameterValidation,
 		ValidationSubType: helpers.ParameterValidationQuery,
 		Message:           fmt.Sprintf("Query parameter '%s' is not valid JSON", param.Name),
-		Reason: fmt.Sprintf("The query parameter '%s' is defined as being a JSON object, "+
-			"however the value '%s' is not valid JSON", param.Name, ef),
-		SpecLine: param.GoLow().FindContent(helpers.JSONContentType).ValueNode.Line,
-		SpecCol:  param.GoLow().FindContent(helpers.JSONContentType).ValueNode.Column,
+		Reason: fmt.Sprintf("The query parameter '%s' is defined as being JSON ('%s'), "+
+			"however the value '%s' is not valid JSON", param.Name, contentType, ef),
+		SpecLine: specLine,
+		SpecCol:  specCol,
 		Context:  sch,
 		HowToFix: HowToFixInvalidJSON,
 	}
@@ -207,6 +267,7 @@ func IncorrectParamEncodingJSON(param *v3.Parameter, ef string, sch *base.Schema
 
 func IncorrectQueryParamBool(param *v3.Parameter, ef string, sch *base.Schema) *ValidationError {
 	return &ValidationError{
+		Code:              ErrorCodeParamType,
 		ValidationType:    helpers.ParameterValidation,
 		ValidationSubType: helpers.ParameterValidationQuery,
 		Message:           fmt.Sprintf("Query parameter '%s' is not a valid boolean", param.Name),
@@ -221,6 +282,7 @@ func IncorrectQueryParamBool(param *v3.Parameter, ef string, sch *base.Schema) *
 
 func InvalidQueryParamNumber(param *v3.Parameter, ef string, sch *base.Schema) *ValidationError {
 	return &ValidationError{
+		Code:              ErrorCodeParamType,
 		ValidationType:    helpers.ParameterValidation,
 		ValidationSubType: helpers.ParameterValidationQuery,
 		Message:           fmt.Sprintf("Query parameter '%s' is not a valid number", param.Name),
@@ -233,6 +295,85 @@ func InvalidQueryParamNumber(param *v3.Parameter, ef string, sch *base.Schema) *
 	}
 }
 
+func InvalidQueryParamInteger(param *v3.Parameter, ef string, sch *base.Schema) *ValidationError {
+	return &ValidationError{
+		Code:              ErrorCodeParamType,
+		ValidationType:    helpers.ParameterValidation,
+		ValidationSubType: helpers.ParameterValidationQuery,
+		Message:           fmt.Sprintf("Query parameter '%s' is not a valid integer", param.Name),
+		Reason: fmt.Sprintf("The query parameter '%s' is defined as being an integer, "+
+			"however the value '%s' is not a valid integer", param.Name, ef),
+		SpecLine: param.GoLow().Schema.KeyNode.Line,
+		SpecCol:  param.GoLow().Schema.KeyNode.Column,
+		Context:  sch,
+		HowToFix: fmt.Sprintf(HowToFixParamInvalidInteger, ef),
+	}
+}
+
+func InvalidQueryParamType(param *v3.Parameter, ef string, sch *base.Schema) *ValidationError {
+	types := strings.Join(sch.Type, ", ")
+	return &ValidationError{
+		Code:              ErrorCodeParamType,
+		ValidationType:    helpers.ParameterValidation,
+		ValidationSubType: helpers.ParameterValidationQuery,
+		Message:           fmt.Sprintf("Query parameter '%s' is not a valid '%s'", param.Name, types),
+		Reason: fmt.Sprintf("The query parameter '%s' is defined as being one of '%s', "+
+			"however the value '%s' cannot be converted into any of those types", param.Name, types, ef),
+		SpecLine: param.GoLow().Schema.KeyNode.Line,
+		SpecCol:  param.GoLow().Schema.KeyNode.Column,
+		Context:  sch,
+		HowToFix: fmt.Sprintf(HowToFixParamInvalidType, ef, types),
+	}
+}
+
+func InvalidHeaderParamType(param *v3.Parameter, ef string, sch *base.Schema) *ValidationError {
+	types := strings.Join(sch.Type, ", ")
+	return &ValidationError{
+		Code:              ErrorCodeParamType,
+		ValidationType:    helpers.ParameterValidation,
+		ValidationSubType: helpers.ParameterValidationHeader,
+		Message:           fmt.Sprintf("Header parameter '%s' is not a valid '%s'", param.Name, types),
+		Reason: fmt.Sprintf("The header parameter '%s' is defined as being one of '%s', "+
+			"however the value '%s' cannot be converted into any of those types", param.Name, types, ef),
+		SpecLine: param.GoLow().Schema.KeyNode.Line,
+		SpecCol:  param.GoLow().Schema.KeyNode.Column,
+		Context:  sch,
+		HowToFix: fmt.Sprintf(HowToFixParamInvalidType, ef, types),
+	}
+}
+
+func InvalidCookieParamType(param *v3.Parameter, ef string, sch *base.Schema) *ValidationError {
+	types := strings.Join(sch.Type, ", ")
+	return &ValidationError{
+		Code:              ErrorCodeParamType,
+		ValidationType:    helpers.ParameterValidation,
+		ValidationSubType: helpers.ParameterValidationCookie,
+		Message:           fmt.Sprintf("Cookie parameter '%s' is not a valid '%s'", param.Name, types),
+		Reason: fmt.Sprintf("The cookie parameter '%s' is defined as being one of '%s', "+
+			"however the value '%s' cannot be converted into any of those types", param.Name, types, ef),
+		SpecLine: param.GoLow().Schema.KeyNode.Line,
+		SpecCol:  param.GoLow().Schema.KeyNode.Column,
+		Context:  sch,
+		HowToFix: fmt.Sprintf(HowToFixParamInvalidType, ef, types),
+	}
+}
+
+func InvalidPathParamType(param *v3.Parameter, item string, sch *base.Schema) *ValidationError {
+	types := strings.Join(sch.Type, ", ")
+	return &ValidationError{
+		Code:              ErrorCodeParamType,
+		ValidationType:    helpers.ParameterValidation,
+		ValidationSubType: helpers.ParameterValidationPath,
+		Message:           fmt.Sprintf("Path parameter '%s' is not a valid '%s'", param.Name, types),
+		Reason: fmt.Sprintf("The path parameter '%s' is defined as being one of '%s', "+
+			"however the value '%s' cannot be converted into any of those types", param.Name, types, item),
+		SpecLine: param.GoLow().Schema.KeyNode.Line,
+		SpecCol:  param.GoLow().Schema.KeyNode.Column,
+		Context:  sch,
+		HowToFix: fmt.Sprintf(HowToFixParamInvalidType, item, types),
+	}
+}
+
 func IncorrectQueryParamEnum(param *v3.Parameter, ef string, sch *base.Schema) *ValidationError {
 	var enums []string
 	for i := range sch.Enum {
@@ -240,6 +381,7 @@ func IncorrectQueryParamEnum(param *v3.Parameter, ef string, sch *base.Schema) *
 	}
 	validEnums := strings.Join(enums, ", ")
 	return &ValidationError{
+		Code:              ErrorCodeParamEnum,
 		ValidationType:    helpers.ParameterValidation,
 		ValidationSubType: helpers.ParameterValidationQuery,
 		Message:           fmt.Sprintf("Query parameter '%s' does not match allowed values", param.Name),
@@ -261,6 +403,7 @@ func IncorrectQueryParamEnumArray(param *v3.Parameter, ef string, sch *base.Sche
 	}
 	validEnums := strings.Join(enums, ", ")
 	return &ValidationError{
+		Code:              ErrorCodeParamEnum,
 		ValidationType:    helpers.ParameterValidation,
 		ValidationSubType: helpers.ParameterValidationQuery,
 		Message:           fmt.Sprintf("Query array parameter '%s' does not match allowed values", param.Name),
@@ -273,8 +416,26 @@ func IncorrectQueryParamEnumArray(param *v3.Parameter, ef string, sch *base.Sche
 	}
 }
 
+// IncorrectQueryParamEncoding returns a ValidationError for a query parameter value that has a malformed
+// percent-encoded sequence, so it cannot be decoded (and validated).
+func IncorrectQueryParamEncoding(param *v3.Parameter, raw string) *ValidationError {
+	return &ValidationError{
+		Code:              ErrorCodeParamEncoding,
+		ValidationType:    helpers.ParameterValidation,
+		ValidationSubType: helpers.ParameterValidationQuery,
+		Message:           fmt.Sprintf("Query parameter '%s' is not correctly percent-encoded", param.Name),
+		Reason: fmt.Sprintf("The query parameter '%s' has the value '%s', which contains a malformed "+
+			"percent-encoded sequence, so it cannot be decoded", param.Name, raw),
+		SpecLine: param.GoLow().Name.KeyNode.Line,
+		SpecCol:  param.GoLow().Name.KeyNode.Column,
+		Context:  param,
+		HowToFix: fmt.Sprintf(HowToFixPercentEncoding, raw),
+	}
+}
+
 func IncorrectReservedValues(param *v3.Parameter, ef string, sch *base.Schema) *ValidationError {
 	return &ValidationError{
+		Code:              ErrorCodeParamEncoding,
 		ValidationType:    helpers.ParameterValidation,
 		ValidationSubType: helpers.ParameterValidationQuery,
 		Message:           fmt.Sprintf("Query parameter '%s' value contains reserved values", param.Name),
@@ -289,6 +450,7 @@ func IncorrectReservedValues(param *v3.Parameter, ef string, sch *base.Schema) *
 
 func InvalidHeaderParamNumber(param *v3.Parameter, ef string, sch *base.Schema) *ValidationError {
 	return &ValidationError{
+		Code:              ErrorCodeParamType,
 		ValidationType:    helpers.ParameterValidation,
 		ValidationSubType: helpers.ParameterValidationHeader,
 		Message:           fmt.Sprintf("Header parameter '%s' is not a valid number", param.Name),
@@ -303,6 +465,7 @@ func InvalidHeaderParamNumber(param *v3.Parameter, ef string, sch *base.Schema)
 
 func InvalidCookieParamNumber(param *v3.Parameter, ef string, sch *base.Schema) *ValidationError {
 	return &ValidationError{
+		Code:              ErrorCodeParamType,
 		ValidationType:    helpers.ParameterValidation,
 		ValidationSubType: helpers.ParameterValidationCookie,
 		Message:           fmt.Sprintf("Cookie parameter '%s' is not a valid number", param.Name),
@@ -317,6 +480,7 @@ func InvalidCookieParamNumber(param *v3.Parameter, ef string, sch *base.Schema)
 
 func IncorrectHeaderParamBool(param *v3.Parameter, ef string, sch *base.Schema) *ValidationError {
 	return &ValidationError{
+		Code:              ErrorCodeParamType,
 		ValidationType:    helpers.ParameterValidation,
 		ValidationSubType: helpers.ParameterValidationHeader,
 		Message:           fmt.Sprintf("Header parameter '%s' is not a valid boolean", param.Name),
@@ -331,6 +495,7 @@ func IncorrectHeaderParamBool(param *v3.Parameter, ef string, sch *base.Schema)
 
 func IncorrectCookieParamBool(param *v3.Parameter, ef string, sch *base.Schema) *ValidationError {
 	return &ValidationError{
+		Code:              ErrorCodeParamType,
 		ValidationType:    helpers.ParameterValidation,
 		ValidationSubType: helpers.ParameterValidationCookie,
 		Message:           fmt.Sprintf("Cookie parameter '%s' is not a valid boolean", param.Name),
@@ -350,6 +515,7 @@ func IncorrectCookieParamEnum(param *v3.Parameter, ef string, sch *base.Schema)
 	}
 	validEnums := strings.Join(enums, ", ")
 	return &ValidationError{
+		Code:              ErrorCodeParamEnum,
 		ValidationType:    helpers.ParameterValidation,
 		ValidationSubType: helpers.ParameterValidationCookie,
 		Message:           fmt.Sprintf("Cookie parameter '%s' does not match allowed values", param.Name),
@@ -365,6 +531,7 @@ func IncorrectCookieParamEnum(param *v3.Parameter, ef string, sch *base.Schema)
 func IncorrectHeaderParamArrayBoolean(
 	param *v3.Parameter, item string, sch *base.Schema, itemsSchema *base.Schema) *ValidationError {
 	return &ValidationError{
+		Code:              ErrorCodeParamType,
 		ValidationType:    helpers.ParameterValidation,
 		ValidationSubType: helpers.ParameterValidationHeader,
 		Message:           fmt.Sprintf("Header array parameter '%s' is not a valid boolean", param.Name),
@@ -380,6 +547,7 @@ func IncorrectHeaderParamArrayBoolean(
 func IncorrectHeaderParamArrayNumber(
 	param *v3.Parameter, item string, sch *base.Schema, itemsSchema *base.Schema) *ValidationError {
 	return &ValidationError{
+		Code:              ErrorCodeParamType,
 		ValidationType:    helpers.ParameterValidation,
 		ValidationSubType: helpers.ParameterValidationHeader,
 		Message:           fmt.Sprintf("Header array parameter '%s' is not a valid number", param.Name),
@@ -394,6 +562,7 @@ func IncorrectHeaderParamArrayNumber(
 
 func IncorrectPathParamBool(param *v3.Parameter, item string, sch *base.Schema) *ValidationError {
 	return &ValidationError{
+		Code:              ErrorCodeParamType,
 		ValidationType:    helpers.ParameterValidation,
 		ValidationSubType: helpers.ParameterValidationPath,
 		Message:           fmt.Sprintf("Path parameter '%s' is not a valid boolean", param.Name),
@@ -413,6 +582,7 @@ func IncorrectPathParamEnum(param *v3.Parameter, ef string, sch *base.Schema) *V
 	}
 	validEnums := strings.Join(enums, ", ")
 	return &ValidationError{
+		Code:              ErrorCodeParamEnum,
 		ValidationType:    helpers.ParameterValidation,
 		ValidationSubType: helpers.ParameterValidationPath,
 		Message:           fmt.Sprintf("Path parameter '%s' does not match allowed values", param.Name),
@@ -427,6 +597,7 @@ func IncorrectPathParamEnum(param *v3.Parameter, ef string, sch *base.Schema) *V
 
 func IncorrectPathParamNumber(param *v3.Parameter, item string, sch *base.Schema) *ValidationError {
 	return &ValidationError{
+		Code:              ErrorCodeParamType,
 		ValidationType:    helpers.ParameterValidation,
 		ValidationSubType: helpers.ParameterValidationPath,
 		Message:           fmt.Sprintf("Path parameter '%s' is not a valid number", param.Name),
@@ -439,9 +610,25 @@ func IncorrectPathParamNumber(param *v3.Parameter, item string, sch *base.Schema
 	}
 }
 
+func IncorrectPathParamInteger(param *v3.Parameter, item string, sch *base.Schema) *ValidationError {
+	return &ValidationError{
+		Code:              ErrorCodeParamType,
+		ValidationType:    helpers.ParameterValidation,
+		ValidationSubType: helpers.ParameterValidationPath,
+		Message:           fmt.Sprintf("Path parameter '%s' is not a valid integer", param.Name),
+		Reason: fmt.Sprintf("The path parameter '%s' is defined as being an integer, "+
+			"however the value '%s' is not a valid integer", param.Name, item),
+		SpecLine: param.GoLow().Schema.KeyNode.Line,
+		SpecCol:  param.GoLow().Schema.KeyNode.Column,
+		Context:  sch,
+		HowToFix: fmt.Sprintf(HowToFixParamInvalidInteger, item),
+	}
+}
+
 func IncorrectPathParamArrayNumber(
 	param *v3.Parameter, item string, sch *base.Schema, itemsSchema *base.Schema) *ValidationError {
 	return &ValidationError{
+		Code:              ErrorCodeParamType,
 		ValidationType:    helpers.ParameterValidation,
 		ValidationSubType: helpers.ParameterValidationPath,
 		Message:           fmt.Sprintf("Path array parameter '%s' is not a valid number", param.Name),
@@ -457,6 +644,7 @@ func IncorrectPathParamArrayNumber(
 func IncorrectPathParamArrayBoolean(
 	param *v3.Parameter, item string, sch *base.Schema, itemsSchema *base.Schema) *ValidationError {
 	return &ValidationError{
+		Code:              ErrorCodeParamType,
 		ValidationType:    helpers.ParameterValidation,
 		ValidationSubType: helpers.ParameterValidationPath,
 		Message:           fmt.Sprintf("Path array parameter '%s' is not a valid boolean", param.Name),
@@ -468,3 +656,185 @@ func IncorrectPathParamArrayBoolean(
 		HowToFix: fmt.Sprintf(HowToFixParamInvalidBoolean, item),
 	}
 }
+
+func IncorrectPathParamMatrixSegment(param *v3.Parameter, segment string) *ValidationError {
+	return &ValidationError{
+		Code:              ErrorCodeParamEncoding,
+		ValidationType:    helpers.ParameterValidation,
+		ValidationSubType: helpers.ParameterValidationPath,
+		Message: fmt.Sprintf("Path parameter '%s' matrix segment '%s' is not valid",
+			param.Name, segment),
+		Reason: fmt.Sprintf("The path parameter '%s' has the 'matrix' style defined, "+
+			"however the segment '%s' is not a valid matrix encoding for the parameter", param.Name, segment),
+		SpecLine: param.GoLow().Style.ValueNode.Line,
+		SpecCol:  param.GoLow().Style.ValueNode.Column,
+		Context:  param,
+		HowToFix: fmt.Sprintf(HowToFixParamInvalidMatrixEncoding, param.Name),
+	}
+}
+
+func IncorrectPathParamLabelSegment(param *v3.Parameter, segment string) *ValidationError {
+	return &ValidationError{
+		Code:              ErrorCodeParamEncoding,
+		ValidationType:    helpers.ParameterValidation,
+		ValidationSubType: helpers.ParameterValidationPath,
+		Message: fmt.Sprintf("Path parameter '%s' label segment '%s' is not valid",
+			param.Name, segment),
+		Reason: fmt.Sprintf("The path parameter '%s' has the 'label' style defined, "+
+			"however the segment '%s' does not contain a value after the period", param.Name, segment),
+		SpecLine: param.GoLow().Style.ValueNode.Line,
+		SpecCol:  param.GoLow().Style.ValueNode.Column,
+		Context:  param,
+		HowToFix: HowToFixParamInvalidLabelEncoding,
+	}
+}
+
+func IncorrectPathParamArrayEnum(
+	param *v3.Parameter, item string, index int, sch *base.Schema, itemsSchema *base.Schema) *ValidationError {
+	var enums []string
+	for i := range itemsSchema.Enum {
+		enums = append(enums, fmt.Sprint(itemsSchema.Enum[i]))
+	}
+	validEnums := strings.Join(enums, ", ")
+	return &ValidationError{
+		Code:              ErrorCodeParamEnum,
+		ValidationType:    helpers.ParameterValidation,
+		ValidationSubType: helpers.ParameterValidationPath,
+		Message:           fmt.Sprintf("Path array parameter '%s' does not match allowed values", param.Name),
+		Reason: fmt.Sprintf("The path array parameter '%s' has pre-defined "+
+			"values set via an enum. The value '%s' at index %d is not one of those values.", param.Name, item, index),
+		SpecLine: sch.Items.A.GoLow().Schema().Enum.KeyNode.Line,
+		SpecCol:  sch.Items.A.GoLow().Schema().Enum.KeyNode.Column,
+		Context:  itemsSchema,
+		HowToFix: fmt.Sprintf(HowToFixParamInvalidEnum, item, validEnums),
+	}
+}
+
+func CookieParameterMissing(param *v3.Parameter) *ValidationError {
+	return &ValidationError{
+		Code:              ErrorCodeCookieParamMissing,
+		ValidationType:    helpers.ParameterValidation,
+		ValidationSubType: helpers.ParameterValidationCookie,
+		Message:           fmt.Sprintf("Cookie parameter '%s' is missing", param.Name),
+		Reason: fmt.Sprintf("The cookie parameter '%s' is defined as being required, "+
+			"however it's missing from the requests", param.Name),
+		SpecLine: param.GoLow().Required.KeyNode.Line,
+		SpecCol:  param.GoLow().Required.KeyNode.Column,
+		HowToFix: HowToFixMissingValue,
+	}
+}
+
+// CookieParameterMalformed returns a ValidationError for a cookie parameter that was sent, but with a value that
+// cannot be parsed as a cookie (for example, it contains a backslash or an unbalanced double quote).
+func CookieParameterMalformed(param *v3.Parameter, value string) *ValidationError {
+	return &ValidationError{
+		Code:              ErrorCodeParamEncoding,
+		ValidationType:    helpers.ParameterValidation,
+		ValidationSubType: helpers.ParameterValidationCookie,
+		Message:           fmt.Sprintf("Cookie parameter '%s' is malformed", param.Name),
+		Reason: fmt.Sprintf("The cookie parameter '%s' was sent, however the value '%s' "+
+			"is not a valid cookie value", param.Name, value),
+		SpecLine: param.GoLow().Name.KeyNode.Line,
+		SpecCol:  param.GoLow().Name.KeyNode.Column,
+		HowToFix: fmt.Sprintf(HowToFixMalformedCookie, value),
+	}
+}
+
+// PathParameterNotInTemplate returns a ValidationError for a path parameter that is declared by an operation (or
+// path item), but that does not appear as a {name} in the templated path.
+func PathParameterNotInTemplate(param *v3.Parameter, path string) *ValidationError {
+	return &ValidationError{
+		Code:              ErrorCodeDocument,
+		ValidationType:    helpers.ParameterValidation,
+		ValidationSubType: helpers.ParameterValidationPath,
+		Message:           fmt.Sprintf("Path parameter '%s' is not part of the path '%s'", param.Name, path),
+		Reason: fmt.Sprintf("The path parameter '%s' is defined in the specification, "+
+			"however the path '%s' does not contain '{%s}'", param.Name, path, param.Name),
+		SpecLine: param.GoLow().Name.KeyNode.Line,
+		SpecCol:  param.GoLow().Name.KeyNode.Column,
+		HowToFix: HowToFixPathTemplate,
+		Context:  param,
+	}
+}
+
+// PathTemplateParameterNotDeclared returns a ValidationError for a {name} in a templated path, that is not declared
+// as a path parameter by the operation (or path item).
+func PathTemplateParameterNotDeclared(name, path string) *ValidationError {
+	return &ValidationError{
+		Code:              ErrorCodeDocument,
+		ValidationType:    helpers.ParameterValidation,
+		ValidationSubType: helpers.ParameterValidationPath,
+		Message:           fmt.Sprintf("Path parameter '%s' of the path '%s' is not defined", name, path),
+		Reason: fmt.Sprintf("The path '%s' contains '{%s}', however there is no path parameter named '%s' "+
+			"defined in the specification", path, name, name),
+		SpecLine: -1,
+		SpecCol:  -1,
+		HowToFix: HowToFixPathTemplate,
+	}
+}
+
+// PathParameterMissing returns a ValidationError for a path parameter that has an empty value.
+func PathParameterMissing(param *v3.Parameter) *ValidationError {
+	return &ValidationError{
+		Code:              ErrorCodePathParamMissing,
+		ValidationType:    helpers.ParameterValidation,
+		ValidationSubType: helpers.ParameterValidationPath,
+		Message:           fmt.Sprintf("Path parameter '%s' is missing", param.Name),
+		Reason: fmt.Sprintf("The path parameter '%s' is required, "+
+			"however the value in the path is empty", param.Name),
+		SpecLine: param.GoLow().Name.KeyNode.Line,
+		SpecCol:  param.GoLow().Name.KeyNode.Column,
+		HowToFix: HowToFixMissingValue,
+		Context:  param,
+	}
+}
+
+// ParameterDeprecated returns a warning for a parameter that is sent, but is marked as deprecated in the
+// specification. It does not fail validation.
+func ParameterDeprecated(param *v3.Parameter) *ValidationError {
+	var specLine, specCol int
+	if low := param.GoLow(); low != nil && low.Deprecated.KeyNode != nil {
+		specLine = low.Deprecated.KeyNode.Line
+		specCol = low.Deprecated.KeyNode.Column
+	}
+	return &ValidationError{
+		Code:              ErrorCodeParamDeprecated,
+		ValidationType:    helpers.ParameterValidation,
+		ValidationSubType: param.In,
+		Severity:          helpers.SeverityWarning,
+		Message: fmt.Sprintf("%s%s parameter '%s' is deprecated",
+			strings.ToUpper(param.In[:1]), param.In[1:], param.Name),
+		Reason: fmt.Sprintf("The %s parameter '%s' is marked as deprecated in the specification, "+
+			"however it's sent with the request%s", param.In, param.Name, replacedBy(param.Extensions)),
+		SpecLine: specLine,
+		SpecCol:  specCol,
+		HowToFix: fmt.Sprintf(HowToFixDeprecatedParam, param.Name),
+	}
+}
+
+// replacedBy describes the 'x-replaced-by' extension of a deprecated element, if it has one.
+func replacedBy(extensions map[string]any) string {
+	if replacement, ok := extensions[helpers.ReplacedByExtension]; ok && replacement != nil {
+		return fmt.Sprintf(", it's replaced by '%v'", replacement)
+	}
+	return ""
+}
+
+// ParameterSchemaInvalid returns an error for a parameter (or header) that cannot be validated, because its schema
+// cannot be compiled.
+func ParameterSchemaInvalid(schema *base.Schema, entity, reasonEntity, name, validationType, subType string,
+	err error) *ValidationError {
+	specLine, specCol := schemaPosition(schema)
+	return &ValidationError{
+		Code:              ErrorCodeDocument,
+		ValidationType:    validationType,
+		ValidationSubType: subType,
+		Message:           fmt.Sprintf("%s '%s' cannot be validated, the schema cannot be compiled", entity, name),
+		Reason: fmt.Sprintf("%s '%s' is defined by a schema that is not valid JSON Schema: %v",
+			reasonEntity, name, err),
+		SpecLine: specLine,
+		SpecCol:  specCol,
+		HowToFix: HowToFixSchemaCompile,
+		Context:  schema,
+	}
+}
@@ -9,6 +9,8 @@ const (
 	HowToFixParamInvalidNumber                      string = "Convert the value '%s' into a number"
 	HowToFixParamInvalidString                      string = "Convert the value '%s' into a string (cannot start with a number, or be a floating point)"
 	HowToFixParamInvalidBoolean                     string = "Convert the value '%s' into a true/false value"
+	HowToFixParamInvalidInteger                     string = "Convert the value '%s' into an integer (a whole number, without a decimal point)"
+	HowToFixParamInvalidType                        string = "Convert the value '%s' into one of the following types: '%s'"
 	HowToFixParamInvalidEnum                        string = "Instead of '%s', use one of the allowed values: '%s'"
 	HowToFixParamInvalidFormEncode                  string = "Use a form style encoding for parameter values, for example: '%s'"
 	HowToFixInvalidSchema                           string = "Ensure that the object being submitted, matches the schema correctly"
@@ -18,11 +20,49 @@ const (
 		"they should be separated by pipes '|'. For example: '%s'"
 	HowToFixParamInvalidDeepObjectMultipleValues string = "There can only be a single value per property name, " +
 		"deepObject parameters should contain the property key in square brackets next to the parameter name. For example: '%s'"
+	HowToFixParamInvalidMatrixEncoding string = "Matrix style path parameters must start with a semicolon, followed by " +
+		"the parameter name and value. For example: ';%s=value'"
+	HowToFixParamInvalidLabelEncoding string = "Label style path parameters must start with a period, followed by " +
+		"the value. For example: '.value', arrays are either '.a,b,c' or exploded as '.a.b.c'"
 	HowToFixInvalidJSON         string = "The JSON submitted is invalid, please check the syntax"
-	HowToFixDecodingError              = "The object can't be decoded, so make sure it's being encoded correctly according to the spec."
-	HowToFixInvalidContentType         = "The content type is invalid, Use one of the %d supported types for this operation: %s"
-	HowToFixInvalidResponseCode        = "The service is responding with a code that is not defined in the spec, fix the service or add the code to the specification"
-	HowToFixInvalidEncoding            = "Ensure the correct encoding has been used on the object"
-	HowToFixMissingValue               = "Ensure the value has been set"
-	HowToFixPath                       = "Check the path is correct, and check that the correct HTTP method has been used (e.g. GET, POST, PUT, DELETE)"
+	HowToFixInvalidXML          string = "The XML submitted is invalid, please check the syntax"
+	HowToFixInvalidFormEncoding string = "The form submitted is not correctly URL encoded, please check the encoding"
+	HowToFixInvalidMultipart    string = "The multipart body is not correctly encoded, please check the boundary " +
+		"of the content type and the headers of each part"
+	HowToFixInvalidPartContentType string = "Send the part '%s' using one of the following content types: '%s'"
+	HowToFixDecodingError                 = "The object can't be decoded, so make sure it's being encoded correctly according to the spec."
+	HowToFixInvalidContentType            = "The content type is invalid, Use one of the %d supported types for this operation: %s"
+	HowToFixMissingContentType            = "Set the Content-Type header to one of the %d supported types for this operation: %s"
+	HowToFixInvalidResponseCode           = "The service is responding with a code that is not defined in the spec, fix the service to respond with one of the declared responses (%s), or add the code (or its range '%s', or a default response) to the specification"
+	HowToFixInvalidEncoding               = "Ensure the correct encoding has been used on the object"
+	HowToFixMissingValue                  = "Ensure the value has been set"
+	HowToFixMalformedCookie               = "Encode the cookie value '%s' (for example, URL encode it), cookie values cannot contain double quotes, semicolons, backslashes or control characters"
+	HowToFixUndeclaredQueryParam          = "Remove the query parameter '%s', or declare it in the specification"
+	HowToFixDeprecatedParam               = "Stop sending the parameter '%s', it's deprecated and may be removed"
+	HowToFixDeprecatedOperation           = "Stop using the operation, it's deprecated and may be removed"
+	HowToFixWriteOnlyProperty             = "Remove the writeOnly properties from the response, they must never be returned by the service"
+	HowToFixMissingCredentials            = "Send the credentials required by at least one of the security requirements of the operation"
+	HowToFixRejectedCredentials           = "Check the credentials sent are correct (and have not expired), or send the credentials required by another of the security requirements of the operation"
+	HowToFixDiscriminator                 = "Set the '%s' property to one of the following values: '%s'"
+	HowToFixValidationCancelled           = "Extend the deadline of the context, or validate again with a context that is not done"
+	HowToFixWebhook                       = "Check the name of the webhook is correct, and that the webhook defines an operation for the HTTP method used (e.g. POST)"
+	HowToFixCallback                      = "Check the name of the callback is correct, and that the callback defines an operation for the URL and HTTP method used (e.g. POST)"
+	HowToFixRequestBodyTooLarge           = "Reduce the size of the request body to %d bytes or less"
+	HowToFixContentEncoding               = "Compress the body using the encoding declared by the Content-Encoding header ('gzip' or 'deflate'), and keep it under %d bytes once decompressed"
+	HowToFixDuplicateKey                  = "Remove the duplicate '%s' key, each key of a JSON object can only be used once"
+	HowToFixMissingRequestBody            = "Send a request body, the operation requires one"
+	HowToFixUnexpectedRequestBody         = "Remove the request body, the operation does not accept one"
+	HowToFixPathTemplate                  = "Make sure every path parameter is declared, and matches a {name} in the path exactly (names are case-sensitive)"
+	HowToFixSchemaReference               = "Check the $ref points to a schema that exists in the specification, or in a document it references"
+	HowToFixReference                     = "Check the reference is correct. File references need a base path, and remote references must be allowed (see config.WithReferenceBasePath and config.WithRemoteReferences)"
+	HowToFixSchemaCompile                 = "Correct the schema, so it is a valid JSON Schema that can be compiled"
+	HowToFixParameterStyle                = "Use a style that is allowed for the location of the parameter: '%s'"
+	HowToFixExample                       = "Correct the example, so it matches the schema it's declared with"
+	HowToFixPercentEncoding               = "Percent-encode the value '%s' correctly, a '%%' must be followed by two hexadecimal digits (a '%%' on its own is encoded as '%%25')"
+	HowToFixMissingTrailer                = "Send the trailer after the response body, or stop announcing it in the Trailer header"
+	HowToFixPath                          = "Check the path is correct, and check that the correct HTTP method has been used (e.g. GET, POST, PUT, DELETE)"
+	HowToFixNotAcceptable                 = "Respond with a content type the Accept header allows ('%s'), or with 406 (Not Acceptable)"
+	HowToFixMissingCookie                 = "Set the '%s' cookie in the response (using a Set-Cookie header), it's required by the specification"
+	HowToFixMissingHeader                 = "Send the '%s' header with the request, it's required by the operation"
+	HowToFixPathNotCovered                = "Check the path is correct, it must start with one of the base paths: %s"
 )
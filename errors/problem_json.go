@@ -0,0 +1,115 @@
+// Copyright 2023 Princess B33f Heavy Industries / Dave Shanley
+// SPDX-License-Identifier: MIT
+
+package errors
+
+import (
+	"encoding/json"
+	"fmt"
+	"github.com/pb33f/libopenapi-validator/helpers"
+	"net/http"
+)
+
+// ProblemDetails is an RFC 7807 problem details object, describing a set of validation errors. It should be
+// returned to clients with a content type of helpers.ProblemJSONContentType (application/problem+json).
+type ProblemDetails struct {
+	// Type is a URI reference that identifies the problem type, 'about:blank' is used for all validation errors.
+	Type string `json:"type"`
+
+	// Title is a short, human-readable summary of the problem type, this is the text of the status code.
+	Title string `json:"title"`
+
+	// Status is the HTTP status code for this occurrence of the problem.
+	Status int `json:"status"`
+
+	// Detail is a human-readable explanation specific to this occurrence of the problem.
+	Detail string `json:"detail"`
+
+	// Errors holds an entry for every schema validation failure, and for every validation error that
+	// is not caused by a schema.
+	Errors []*ProblemError `json:"errors,omitempty"`
+}
+
+// ProblemError is a single entry of the errors array held by ProblemDetails.
+type ProblemError struct {
+	// Message is the message of the ValidationError the entry belongs to.
+	Message string `json:"message"`
+
+	// Reason is the reason of the schema validation failure, or of the ValidationError when there is no schema
+	// validation failure.
+	Reason string `json:"reason"`
+
+	// ValidationType is the type of validation that failed.
+	ValidationType string `json:"validationType,omitempty"`
+
+	// ValidationSubType is the subtype of validation that failed.
+	ValidationSubType string `json:"validationSubType,omitempty"`
+
+	// Code is the category of the ValidationError the entry belongs to.
+	Code ErrorCode `json:"code,omitempty"`
+
+	// Pointer is a JSON Pointer (RFC 6901) to the value that failed validation.
+	Pointer string `json:"pointer,omitempty"`
+
+	// SchemaLocation is the location of the failing keyword within the schema.
+	SchemaLocation string `json:"schemaLocation,omitempty"`
+
+	// HowToFix is a human-readable message describing how to fix the error.
+	HowToFix string `json:"howToFix,omitempty"`
+}
+
+// ToProblemJSON will serialize validation errors as an RFC 7807 problem details object. The status is 500 when all
+// the errors are response errors (the server broke the contract), otherwise the status is 400.
+func ToProblemJSON(validationErrors []*ValidationError) ([]byte, error) {
+	status := http.StatusInternalServerError
+	for _, validationError := range validationErrors {
+		if validationError.ValidationType != helpers.ResponseBodyValidation {
+			status = http.StatusBadRequest
+			break
+		}
+	}
+	return ToProblemJSONWithStatus(validationErrors, status)
+}
+
+// ToProblemJSONWithStatus will serialize validation errors as an RFC 7807 problem details object, using the
+// supplied HTTP status code.
+func ToProblemJSONWithStatus(validationErrors []*ValidationError, status int) ([]byte, error) {
+	return json.Marshal(NewProblemDetails(validationErrors, status))
+}
+
+// NewProblemDetails will create an RFC 7807 problem details object from validation errors, using the
+// supplied HTTP status code.
+func NewProblemDetails(validationErrors []*ValidationError, status int) *ProblemDetails {
+	problem := &ProblemDetails{
+		Type:   "about:blank",
+		Title:  http.StatusText(status),
+		Status: status,
+		Detail: fmt.Sprintf("validation failed with %d error(s)", len(validationErrors)),
+	}
+	for _, validationError := range validationErrors {
+		if len(validationError.SchemaValidationErrors) == 0 {
+			problem.Errors = append(problem.Errors, &ProblemError{
+				Message:           validationError.Message,
+				Reason:            validationError.Reason,
+				ValidationType:    validationError.ValidationType,
+				ValidationSubType: validationError.ValidationSubType,
+				Code:              validationError.Code,
+				HowToFix:          validationError.HowToFix,
+			})
+			continue
+		}
+		for _, failure := range validationError.SchemaValidationErrors {
+			problem.Errors = append(problem.Errors, &ProblemError{
+				Message:           validationError.Message,
+				Reason:            failure.Reason,
+				ValidationType:    validationError.ValidationType,
+				ValidationSubType: validationError.ValidationSubType,
+				Code:              validationError.Code,
+				Pointer:           failure.InstanceLocation,
+				SchemaLocation:    failure.DeepLocation,
+				HowToFix:          validationError.HowToFix,
+			})
+		}
+	}
+	return problem
+}
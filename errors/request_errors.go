@@ -6,27 +6,416 @@ package errors
 import (
 	"fmt"
 	"github.com/pb33f/libopenapi-validator/helpers"
+	"github.com/pb33f/libopenapi/datamodel/high/base"
 	v3 "github.com/pb33f/libopenapi/datamodel/high/v3"
 	"net/http"
+	"sort"
 	"strings"
 )
 
 func RequestContentTypeNotFound(op *v3.Operation, request *http.Request) *ValidationError {
 	ct := request.Header.Get(helpers.ContentTypeHeader)
-	var ctypes []string
-	for k := range op.RequestBody.Content {
-		ctypes = append(ctypes, k)
-	}
+	ctypes := requestContentTypes(op)
 	return &ValidationError{
+		Code:              ErrorCodeContentType,
 		ValidationType:    helpers.RequestBodyValidation,
 		ValidationSubType: helpers.RequestBodyContentType,
 		Message: fmt.Sprintf("%s operation request content type '%s' does not exist",
 			request.Method, ct),
 		Reason: fmt.Sprintf("The content type '%s' of the %s request submitted has not "+
-			"been defined, it's an unknown type", ct, request.Method),
+			"been defined, it's an unknown type. The request body accepts: %s", ct, request.Method,
+			strings.Join(ctypes, ", ")),
+		SpecLine: op.RequestBody.GoLow().Content.KeyNode.Line,
+		SpecCol:  op.RequestBody.GoLow().Content.KeyNode.Column,
+		Context:  op,
+		HowToFix: fmt.Sprintf(HowToFixInvalidContentType, len(ctypes), strings.Join(ctypes, ", ")),
+	}
+}
+
+// RequestContentTypeMissing returns a ValidationError for a request that sends a body without a Content-Type
+// header, so the body cannot be matched to a media type of the operation.
+func RequestContentTypeMissing(op *v3.Operation, request *http.Request) *ValidationError {
+	ctypes := requestContentTypes(op)
+	return &ValidationError{
+		Code:              ErrorCodeContentType,
+		ValidationType:    helpers.RequestBodyValidation,
+		ValidationSubType: helpers.RequestBodyContentType,
+		Message: fmt.Sprintf("%s request body for '%s' has no content type",
+			request.Method, request.URL.Path),
+		Reason: fmt.Sprintf("The %s request contains a body, however there is no Content-Type header, "+
+			"so the body cannot be validated. The request body accepts: %s", request.Method,
+			strings.Join(ctypes, ", ")),
 		SpecLine: op.RequestBody.GoLow().Content.KeyNode.Line,
 		SpecCol:  op.RequestBody.GoLow().Content.KeyNode.Column,
 		Context:  op,
-		HowToFix: fmt.Sprintf(HowToFixInvalidContentType, len(op.RequestBody.Content), strings.Join(ctypes, ", ")),
+		HowToFix: fmt.Sprintf(HowToFixMissingContentType, len(ctypes), strings.Join(ctypes, ", ")),
+	}
+}
+
+// requestContentTypes returns the media types accepted by the request body of an operation, sorted.
+func requestContentTypes(op *v3.Operation) []string {
+	var ctypes []string
+	for k := range op.RequestBody.Content {
+		ctypes = append(ctypes, k)
+	}
+	sort.Strings(ctypes)
+	return ctypes
+}
+
+func RequestBodyInvalidXML(request *http.Request, err error) *ValidationError {
+	return &ValidationError{
+		Code:              ErrorCodeBodyMalformed,
+		ValidationType:    helpers.RequestBodyValidation,
+		ValidationSubType: helpers.XMLType,
+		Message: fmt.Sprintf("%s request body for '%s' is not valid XML",
+			request.Method, request.URL.Path),
+		Reason:   fmt.Sprintf("The request body cannot be parsed as XML: %s", err.Error()),
+		SpecLine: 1,
+		SpecCol:  0,
+		HowToFix: HowToFixInvalidXML,
+	}
+}
+
+func RequestBodyInvalidFormEncoding(request *http.Request, err error) *ValidationError {
+	return &ValidationError{
+		Code:              ErrorCodeBodyMalformed,
+		ValidationType:    helpers.RequestBodyValidation,
+		ValidationSubType: helpers.Form,
+		Message: fmt.Sprintf("%s request body for '%s' is not a valid URL encoded form",
+			request.Method, request.URL.Path),
+		Reason:   fmt.Sprintf("The request body cannot be decoded as a URL encoded form: %s", err.Error()),
+		SpecLine: 1,
+		SpecCol:  0,
+		HowToFix: HowToFixInvalidFormEncoding,
+	}
+}
+
+func RequestBodyInvalidMultipart(request *http.Request, err error) *ValidationError {
+	return &ValidationError{
+		Code:              ErrorCodeBodyMalformed,
+		ValidationType:    helpers.RequestBodyValidation,
+		ValidationSubType: helpers.Multipart,
+		Message: fmt.Sprintf("%s request body for '%s' is not a valid multipart form",
+			request.Method, request.URL.Path),
+		Reason:   fmt.Sprintf("The request body cannot be decoded as a multipart form: %s", err.Error()),
+		SpecLine: 1,
+		SpecCol:  0,
+		HowToFix: HowToFixInvalidMultipart,
+	}
+}
+
+func RequestBodyPartContentTypeInvalid(request *http.Request, part, contentType string, encoding *v3.Encoding) *ValidationError {
+	return &ValidationError{
+		Code:              ErrorCodeContentType,
+		ValidationType:    helpers.RequestBodyValidation,
+		ValidationSubType: helpers.Multipart,
+		Message: fmt.Sprintf("%s request body part '%s' content type '%s' is not allowed",
+			request.Method, part, contentType),
+		Reason: fmt.Sprintf("The part '%s' of the multipart request body has a content type of '%s', "+
+			"however the encoding for the part only allows '%s'", part, contentType, encoding.ContentType),
+		SpecLine: encoding.GoLow().ContentType.ValueNode.Line,
+		SpecCol:  encoding.GoLow().ContentType.ValueNode.Column,
+		Context:  encoding,
+		HowToFix: fmt.Sprintf(HowToFixInvalidPartContentType, part, encoding.ContentType),
+	}
+}
+
+func RequestBodyPartHeaderMissing(request *http.Request, part, name string, header *v3.Header) *ValidationError {
+	return &ValidationError{
+		Code:              ErrorCodeSchema,
+		ValidationType:    helpers.RequestBodyValidation,
+		ValidationSubType: helpers.Multipart,
+		Message: fmt.Sprintf("%s request body part '%s' header '%s' is missing",
+			request.Method, part, name),
+		Reason: fmt.Sprintf("The header '%s' is defined as being required for the part '%s', "+
+			"however it's missing from the part", name, part),
+		SpecLine: header.GoLow().Required.KeyNode.Line,
+		SpecCol:  header.GoLow().Required.KeyNode.Column,
+		Context:  header,
+		HowToFix: HowToFixMissingValue,
+	}
+}
+
+func RequestBodyDiscriminatorMissing(request *http.Request, schema *base.Schema,
+	discriminated *helpers.DiscriminatedSchemas) *ValidationError {
+	return &ValidationError{
+		Code:              ErrorCodeSchema,
+		ValidationType:    helpers.RequestBodyValidation,
+		ValidationSubType: helpers.Discriminator,
+		Message: fmt.Sprintf("%s request body for '%s' is missing the discriminator property '%s'",
+			request.Method, request.URL.Path, discriminated.PropertyName),
+		Reason: fmt.Sprintf("The property '%s' is used to select the schema the request body is validated against, "+
+			"however the property is missing", discriminated.PropertyName),
+		SpecLine: schema.GoLow().Discriminator.KeyNode.Line,
+		SpecCol:  schema.GoLow().Discriminator.KeyNode.Column,
+		Context:  schema,
+		HowToFix: fmt.Sprintf(HowToFixDiscriminator, discriminated.PropertyName,
+			strings.Join(discriminated.Values(), ", ")),
+	}
+}
+
+func RequestBodyDiscriminatorInvalid(request *http.Request, schema *base.Schema,
+	discriminated *helpers.DiscriminatedSchemas, value string) *ValidationError {
+	return &ValidationError{
+		Code:              ErrorCodeSchema,
+		ValidationType:    helpers.RequestBodyValidation,
+		ValidationSubType: helpers.Discriminator,
+		Message: fmt.Sprintf("%s request body for '%s' has an unknown discriminator value '%s'",
+			request.Method, request.URL.Path, value),
+		Reason: fmt.Sprintf("The discriminator property '%s' has the value '%s', which does not map to "+
+			"any of the schemas defined", discriminated.PropertyName, value),
+		SpecLine: schema.GoLow().Discriminator.KeyNode.Line,
+		SpecCol:  schema.GoLow().Discriminator.KeyNode.Column,
+		Context:  schema,
+		HowToFix: fmt.Sprintf(HowToFixDiscriminator, discriminated.PropertyName,
+			strings.Join(discriminated.Values(), ", ")),
+	}
+}
+
+// RequestBodyTooLarge returns a ValidationError for a request body that is larger than the maximum number of
+// bytes that will be read for validation.
+func RequestBodyTooLarge(request *http.Request, maxBytes int64) *ValidationError {
+	return &ValidationError{
+		Code:              ErrorCodeBodyTooLarge,
+		ValidationType:    helpers.RequestBodyValidation,
+		ValidationSubType: helpers.BodySize,
+		Message: fmt.Sprintf("%s request body for '%s' is too large",
+			request.Method, request.URL.Path),
+		Reason:   fmt.Sprintf("The request body is larger than the maximum of %d bytes", maxBytes),
+		SpecLine: -1,
+		SpecCol:  -1,
+		HowToFix: fmt.Sprintf(HowToFixRequestBodyTooLarge, maxBytes),
+	}
+}
+
+// RequestBodyContentEncodingInvalid returns a ValidationError for a request body that cannot be decompressed using
+// its Content-Encoding, or that decompresses to more than the limit.
+func RequestBodyContentEncodingInvalid(request *http.Request, err error, maxBytes int64) *ValidationError {
+	return &ValidationError{
+		Code:              ErrorCodeBodyMalformed,
+		ValidationType:    helpers.RequestBodyValidation,
+		ValidationSubType: helpers.ContentEncoding,
+		Message: fmt.Sprintf("%s request body for '%s' cannot be decompressed",
+			request.Method, request.URL.Path),
+		Reason: fmt.Sprintf("The request body has a Content-Encoding of '%s', but %s",
+			request.Header.Get(helpers.ContentEncodingHeader), err.Error()),
+		SpecLine: -1,
+		SpecCol:  -1,
+		HowToFix: fmt.Sprintf(HowToFixContentEncoding, helpers.DecodedBodyLimit(maxBytes)),
+	}
+}
+
+// RequestBodyDuplicateKey returns a ValidationError for a key that is used more than once in the same object of a
+// JSON request body, the pointer is the JSON Pointer of the duplicate.
+func RequestBodyDuplicateKey(request *http.Request, key, pointer string) *ValidationError {
+	return &ValidationError{
+		Code:              ErrorCodeBodyMalformed,
+		ValidationType:    helpers.RequestBodyValidation,
+		ValidationSubType: helpers.DuplicateKey,
+		Message: fmt.Sprintf("%s request body for '%s' contains the duplicate key '%s'",
+			request.Method, request.URL.Path, key),
+		Reason: fmt.Sprintf("The key '%s' is used more than once in the same object, at '%s'. Only one of the "+
+			"values would be read", key, pointer),
+		SpecLine: -1,
+		SpecCol:  -1,
+		HowToFix: fmt.Sprintf(HowToFixDuplicateKey, key),
+		Context:  pointer,
+	}
+}
+
+// RequestBodyMissing returns a ValidationError for a request that has no body (and no Content-Type), when the
+// request body of the operation is required.
+func RequestBodyMissing(op *v3.Operation, request *http.Request) *ValidationError {
+	return &ValidationError{
+		Code:              ErrorCodeBodyMissing,
+		ValidationType:    helpers.RequestBodyValidation,
+		ValidationSubType: helpers.Missing,
+		Message: fmt.Sprintf("%s request body for '%s' is missing",
+			request.Method, request.URL.Path),
+		Reason:   "The request body is required by the specification, however the request does not contain a body",
+		SpecLine: op.RequestBody.GoLow().Required.ValueNode.Line,
+		SpecCol:  op.RequestBody.GoLow().Required.ValueNode.Column,
+		Context:  op,
+		HowToFix: HowToFixMissingRequestBody,
+	}
+}
+
+// RequestBodyEmpty returns a ValidationError for a request that declares a Content-Type, but sends an empty body,
+// when the request body of the operation is required.
+func RequestBodyEmpty(op *v3.Operation, request *http.Request) *ValidationError {
+	return &ValidationError{
+		Code:              ErrorCodeBodyMissing,
+		ValidationType:    helpers.RequestBodyValidation,
+		ValidationSubType: helpers.Empty,
+		Message: fmt.Sprintf("%s request body for '%s' is empty",
+			request.Method, request.URL.Path),
+		Reason: fmt.Sprintf("The request body is required by the specification, the request declares a "+
+			"content type of '%s', however the body is empty", request.Header.Get(helpers.ContentTypeHeader)),
+		SpecLine: op.RequestBody.GoLow().Required.ValueNode.Line,
+		SpecCol:  op.RequestBody.GoLow().Required.ValueNode.Column,
+		Context:  op,
+		HowToFix: HowToFixMissingRequestBody,
+	}
+}
+
+// RequestBodyNotExpected returns a ValidationError for a request that carries a body, when the operation does not
+// declare a request body.
+func RequestBodyNotExpected(request *http.Request) *ValidationError {
+	return &ValidationError{
+		Code:              ErrorCodeBodyNotExpected,
+		ValidationType:    helpers.RequestBodyValidation,
+		ValidationSubType: helpers.Unexpected,
+		Message: fmt.Sprintf("%s request for '%s' has a body, but the operation accepts no request body",
+			request.Method, request.URL.Path),
+		Reason:   "The request contains a body, however the operation does not define a request body in the specification",
+		SpecLine: -1,
+		SpecCol:  -1,
+		HowToFix: HowToFixUnexpectedRequestBody,
+	}
+}
+
+// WebhookNotFound returns a ValidationError for a request validated against a webhook that is not defined in the
+// 'webhooks' of the specification, or that does not define an operation for the method of the request.
+func WebhookNotFound(name string, request *http.Request) *ValidationError {
+	return &ValidationError{
+		Code:              ErrorCodeOperationNotFound,
+		ValidationType:    helpers.WebhookValidation,
+		ValidationSubType: helpers.Missing,
+		Message:           fmt.Sprintf("%s webhook '%s' not found", request.Method, name),
+		Reason: fmt.Sprintf("The %s request was validated against the webhook '%s', "+
+			"however that webhook, or the %s method for that webhook does not exist in the specification",
+			request.Method, name, request.Method),
+		SpecLine: -1,
+		SpecCol:  -1,
+		HowToFix: HowToFixWebhook,
+	}
+}
+
+// CallbackNotFound returns a ValidationError for a request validated against a callback that is not defined by an
+// operation, or that does not define an operation for the URL and method of the request.
+func CallbackNotFound(name string, request *http.Request) *ValidationError {
+	return &ValidationError{
+		Code:              ErrorCodeOperationNotFound,
+		ValidationType:    helpers.CallbackValidation,
+		ValidationSubType: helpers.Missing,
+		Message:           fmt.Sprintf("%s callback '%s' not found", request.Method, name),
+		Reason: fmt.Sprintf("The %s request to '%s' was validated against the callback '%s', "+
+			"however that callback, or the %s method for that callback does not exist in the specification",
+			request.Method, request.URL.String(), name, request.Method),
+		SpecLine: -1,
+		SpecCol:  -1,
+		HowToFix: HowToFixCallback,
+	}
+}
+
+// OperationNotFound returns a ValidationError for an operation that is not defined in the 'paths' of the
+// specification, using the templated path (e.g. /pet/{petId}) of the operation.
+func OperationNotFound(method, path string) *ValidationError {
+	return &ValidationError{
+		Code:              ErrorCodeOperationNotFound,
+		ValidationType:    helpers.ParameterValidationPath,
+		ValidationSubType: helpers.Missing,
+		Message:           fmt.Sprintf("%s operation for '%s' not found", method, path),
+		Reason: fmt.Sprintf("The path '%s', or the %s method for that path does not exist in the specification",
+			path, method),
+		SpecLine: -1,
+		SpecCol:  -1,
+		HowToFix: HowToFixPath,
+	}
+}
+
+// PathNotCovered returns a ValidationError for a request with a path that does not start with any of the base paths
+// validated by a MultiValidator, so there is no document to validate it against.
+func PathNotCovered(request *http.Request, basePaths []string) *ValidationError {
+	quoted := make([]string, len(basePaths))
+	for i, basePath := range basePaths {
+		quoted[i] = fmt.Sprintf("'%s'", basePath)
+	}
+	return &ValidationError{
+		Code:              ErrorCodePathNotFound,
+		ValidationType:    helpers.ParameterValidationPath,
+		ValidationSubType: helpers.Missing,
+		Message:           fmt.Sprintf("%s Path '%s' not found", request.Method, request.URL.Path),
+		Reason: fmt.Sprintf("The %s request contains a path of '%s', however no specification covers that path",
+			request.Method, request.URL.Path),
+		SpecLine: -1,
+		SpecCol:  -1,
+		HowToFix: fmt.Sprintf(HowToFixPathNotCovered, strings.Join(quoted, ", ")),
+	}
+}
+
+// OperationDeprecated returns a warning for a request to an operation that is marked as deprecated in the
+// specification. It does not fail validation.
+func OperationDeprecated(operation *v3.Operation, request *http.Request, path string) *ValidationError {
+	var specLine, specCol int
+	if low := operation.GoLow(); low != nil && low.Deprecated.KeyNode != nil {
+		specLine = low.Deprecated.KeyNode.Line
+		specCol = low.Deprecated.KeyNode.Column
+	}
+	return &ValidationError{
+		Code:              ErrorCodeOperationDeprecated,
+		ValidationType:    helpers.ParameterValidationPath,
+		ValidationSubType: helpers.Deprecated,
+		Severity:          helpers.SeverityWarning,
+		Message:           fmt.Sprintf("%s operation for '%s' is deprecated", request.Method, path),
+		Reason: fmt.Sprintf("The %s operation for '%s' is marked as deprecated in the specification, "+
+			"however it's used by the request to '%s'%s", request.Method, path, request.URL.Path,
+			replacedBy(operation.Extensions)),
+		SpecLine: specLine,
+		SpecCol:  specCol,
+		HowToFix: HowToFixDeprecatedOperation,
+	}
+}
+
+// OperationMethodMismatch returns a ValidationError for a request validated against an operation for a different
+// HTTP method, for example a POST request validated against a GET operation.
+func OperationMethodMismatch(method, path string, request *http.Request) *ValidationError {
+	return &ValidationError{
+		Code:              ErrorCodeMethodMismatch,
+		ValidationType:    helpers.ParameterValidationPath,
+		ValidationSubType: helpers.Missing,
+		Message: fmt.Sprintf("%s request cannot be validated against the %s operation for '%s'",
+			request.Method, method, path),
+		Reason: fmt.Sprintf("The %s request to '%s' was validated against the %s operation for '%s', "+
+			"the methods must match", request.Method, request.URL.Path, method, path),
+		SpecLine: -1,
+		SpecCol:  -1,
+		HowToFix: HowToFixPath,
+	}
+}
+
+// RequestBodySchemaUnresolved returns a ValidationError for a request body with a schema that cannot be built, for
+// example when it's an external reference that cannot be resolved. The body cannot be validated.
+func RequestBodySchemaUnresolved(request *http.Request, schema *base.SchemaProxy) *ValidationError {
+	specLine, specCol := schemaProxyPosition(schema)
+	return &ValidationError{
+		Code:              ErrorCodeDocument,
+		ValidationType:    helpers.RequestBodyValidation,
+		ValidationSubType: helpers.Reference,
+		Message: fmt.Sprintf("%s request body for '%s' cannot be validated, the schema cannot be resolved",
+			request.Method, request.URL.Path),
+		Reason:   schemaBuildReason(schema),
+		SpecLine: specLine,
+		SpecCol:  specCol,
+		HowToFix: HowToFixReference,
+		Context:  schema,
+	}
+}
+
+// RequestBodySchemaInvalid returns a ValidationError for a request body with a schema that cannot be compiled, as it
+// is not valid JSON Schema. The body cannot be validated.
+func RequestBodySchemaInvalid(request *http.Request, schema *base.Schema, err error) *ValidationError {
+	specLine, specCol := schemaPosition(schema)
+	return &ValidationError{
+		Code:              ErrorCodeDocument,
+		ValidationType:    helpers.RequestBodyValidation,
+		ValidationSubType: helpers.Schema,
+		Message: fmt.Sprintf("%s request body for '%s' cannot be validated, the schema cannot be compiled",
+			request.Method, request.URL.Path),
+		Reason:   fmt.Sprintf("The schema of the request body is not valid JSON Schema: %v", err),
+		SpecLine: specLine,
+		SpecCol:  specCol,
+		HowToFix: HowToFixSchemaCompile,
+		Context:  schema,
 	}
 }
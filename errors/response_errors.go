@@ -6,8 +6,10 @@ package errors
 import (
 	"fmt"
 	"github.com/pb33f/libopenapi-validator/helpers"
+	"github.com/pb33f/libopenapi/datamodel/high/base"
 	"github.com/pb33f/libopenapi/datamodel/high/v3"
 	"net/http"
+	"sort"
 	"strings"
 )
 
@@ -39,6 +41,7 @@ func ResponseContentTypeNotFound(op *v3.Operation,
 		contentMap = op.Responses.Default.Content
 	}
 	return &ValidationError{
+		Code:              ErrorCodeContentType,
 		ValidationType:    helpers.ResponseBodyValidation,
 		ValidationSubType: helpers.RequestBodyContentType,
 		Message: fmt.Sprintf("%s / %s operation response content type '%s' does not exist",
@@ -53,17 +56,259 @@ func ResponseContentTypeNotFound(op *v3.Operation,
 	}
 }
 
+// ResponseCodeNotFound returns a ValidationError for a response code that has no response defined: there is no
+// response for the exact code, none for its range (e.g. 4XX) and no default response. The responses that are
+// declared by the operation are listed, to guide the fix.
 func ResponseCodeNotFound(op *v3.Operation, request *http.Request, code int) *ValidationError {
+	codeRange := fmt.Sprintf("%dXX", code/100)
+	var declared []string
+	if op.Responses != nil {
+		for c := range op.Responses.Codes {
+			declared = append(declared, c)
+		}
+		sort.Strings(declared)
+		if op.Responses.Default != nil {
+			declared = append(declared, "default")
+		}
+	}
+	if len(declared) == 0 {
+		declared = append(declared, "none")
+	}
 	return &ValidationError{
+		Code:              ErrorCodeResponseCode,
 		ValidationType:    helpers.ResponseBodyValidation,
 		ValidationSubType: helpers.ResponseBodyResponseCode,
 		Message: fmt.Sprintf("%s operation request response code '%d' does not exist",
 			request.Method, code),
 		Reason: fmt.Sprintf("The reponse code '%d' of the %s request submitted has not "+
-			"been defined, it's an unknown type", code, request.Method),
+			"been defined, there is no '%d' response, no '%s' response and no default response. "+
+			"The operation declares: %s", code, request.Method, code, codeRange, strings.Join(declared, ", ")),
 		SpecLine: op.GoLow().Responses.KeyNode.Line,
 		SpecCol:  op.GoLow().Responses.KeyNode.Column,
 		Context:  op,
-		HowToFix: HowToFixInvalidResponseCode,
+		HowToFix: fmt.Sprintf(HowToFixInvalidResponseCode, strings.Join(declared, ", "), codeRange),
+	}
+}
+
+func ResponseHeaderMissing(header *v3.Header, name string, request *http.Request, code int) *ValidationError {
+	return &ValidationError{
+		Code:              ErrorCodeResponseHeaderMissing,
+		ValidationType:    helpers.ResponseBodyValidation,
+		ValidationSubType: helpers.Header,
+		Message: fmt.Sprintf("%s / %d operation response header '%s' is missing",
+			request.Method, code, name),
+		Reason: fmt.Sprintf("The response header '%s' is defined as being required, "+
+			"however it's missing from the response", name),
+		SpecLine: header.GoLow().Required.KeyNode.Line,
+		SpecCol:  header.GoLow().Required.KeyNode.Column,
+		Context:  header,
+		HowToFix: HowToFixMissingValue,
+	}
+}
+
+// ResponseCookieMissing returns a ValidationError for a cookie that is required by the schema of the Set-Cookie
+// header of a response, but that the response does not set.
+func ResponseCookieMissing(schema *base.Schema, name string, request *http.Request, code int) *ValidationError {
+	specLine, specCol := 1, 0
+	if low := schema.GoLow(); low != nil && low.Required.KeyNode != nil {
+		specLine, specCol = low.Required.KeyNode.Line, low.Required.KeyNode.Column
+	}
+	return &ValidationError{
+		Code:              ErrorCodeResponseHeaderMissing,
+		ValidationType:    helpers.ResponseBodyValidation,
+		ValidationSubType: helpers.Cookie,
+		Message: fmt.Sprintf("%s / %d operation response cookie '%s' is missing",
+			request.Method, code, name),
+		Reason: fmt.Sprintf("The response cookie '%s' is defined as being required, "+
+			"however the response does not set it", name),
+		SpecLine: specLine,
+		SpecCol:  specCol,
+		Context:  schema,
+		HowToFix: fmt.Sprintf(HowToFixMissingCookie, name),
+	}
+}
+
+// ResponseTrailerMissing returns a ValidationError for a required response header that the response announced
+// would be sent as a trailer (after the body), but was not.
+func ResponseTrailerMissing(header *v3.Header, name string, request *http.Request, code int) *ValidationError {
+	return &ValidationError{
+		Code:              ErrorCodeResponseHeaderMissing,
+		ValidationType:    helpers.ResponseBodyValidation,
+		ValidationSubType: helpers.Trailer,
+		Message: fmt.Sprintf("%s / %d operation response trailer '%s' is missing",
+			request.Method, code, name),
+		Reason: fmt.Sprintf("The response header '%s' is defined as being required, and the response announced "+
+			"it as a trailer, however the trailer is missing from the response", name),
+		SpecLine: header.GoLow().Required.KeyNode.Line,
+		SpecCol:  header.GoLow().Required.KeyNode.Column,
+		Context:  header,
+		HowToFix: HowToFixMissingTrailer,
+	}
+}
+
+func ResponseBodyInvalidXML(request *http.Request, response *http.Response, err error) *ValidationError {
+	return &ValidationError{
+		Code:              ErrorCodeBodyMalformed,
+		ValidationType:    helpers.ResponseBodyValidation,
+		ValidationSubType: helpers.XMLType,
+		Message: fmt.Sprintf("%d response body for '%s' is not valid XML",
+			response.StatusCode, request.URL.Path),
+		Reason:   fmt.Sprintf("The response body cannot be parsed as XML: %s", err.Error()),
+		SpecLine: 1,
+		SpecCol:  0,
+		HowToFix: HowToFixInvalidXML,
+	}
+}
+
+// ResponseBodyInvalidNDJSON returns a ValidationError for a line of a newline delimited JSON (NDJSON) response body
+// that cannot be parsed as JSON, the line number starts at 1.
+func ResponseBodyInvalidNDJSON(request *http.Request, response *http.Response, line int, err error) *ValidationError {
+	return &ValidationError{
+		Code:              ErrorCodeBodyMalformed,
+		ValidationType:    helpers.ResponseBodyValidation,
+		ValidationSubType: helpers.NDJSONType,
+		Message: fmt.Sprintf("%d response body for '%s' is not valid JSON (line %d)",
+			response.StatusCode, request.URL.Path, line),
+		Reason:   fmt.Sprintf("Line %d of the response body cannot be parsed as JSON: %s", line, err.Error()),
+		SpecLine: 1,
+		SpecCol:  0,
+		HowToFix: HowToFixInvalidJSON,
+	}
+}
+
+// ResponseBodyContentEncodingInvalid returns a ValidationError for a response body that cannot be decompressed
+// using its Content-Encoding, or that decompresses to more than the limit.
+func ResponseBodyContentEncodingInvalid(request *http.Request, response *http.Response,
+	err error, maxBytes int64) *ValidationError {
+	return &ValidationError{
+		Code:              ErrorCodeBodyMalformed,
+		ValidationType:    helpers.ResponseBodyValidation,
+		ValidationSubType: helpers.ContentEncoding,
+		Message: fmt.Sprintf("%d response body for '%s' cannot be decompressed",
+			response.StatusCode, request.URL.Path),
+		Reason: fmt.Sprintf("The response body has a Content-Encoding of '%s', but %s",
+			response.Header.Get(helpers.ContentEncodingHeader), err.Error()),
+		SpecLine: -1,
+		SpecCol:  -1,
+		HowToFix: fmt.Sprintf(HowToFixContentEncoding, helpers.DecodedBodyLimit(maxBytes)),
+	}
+}
+
+func ResponseBodyDiscriminatorMissing(request *http.Request, response *http.Response, schema *base.Schema,
+	discriminated *helpers.DiscriminatedSchemas) *ValidationError {
+	return &ValidationError{
+		Code:              ErrorCodeSchema,
+		ValidationType:    helpers.ResponseBodyValidation,
+		ValidationSubType: helpers.Discriminator,
+		Message: fmt.Sprintf("%d response body for '%s' is missing the discriminator property '%s'",
+			response.StatusCode, request.URL.Path, discriminated.PropertyName),
+		Reason: fmt.Sprintf("The property '%s' is used to select the schema the response body is validated "+
+			"against, however the property is missing", discriminated.PropertyName),
+		SpecLine: schema.GoLow().Discriminator.KeyNode.Line,
+		SpecCol:  schema.GoLow().Discriminator.KeyNode.Column,
+		Context:  schema,
+		HowToFix: fmt.Sprintf(HowToFixDiscriminator, discriminated.PropertyName,
+			strings.Join(discriminated.Values(), ", ")),
+	}
+}
+
+func ResponseBodyDiscriminatorInvalid(request *http.Request, response *http.Response, schema *base.Schema,
+	discriminated *helpers.DiscriminatedSchemas, value string) *ValidationError {
+	return &ValidationError{
+		Code:              ErrorCodeSchema,
+		ValidationType:    helpers.ResponseBodyValidation,
+		ValidationSubType: helpers.Discriminator,
+		Message: fmt.Sprintf("%d response body for '%s' has an unknown discriminator value '%s'",
+			response.StatusCode, request.URL.Path, value),
+		Reason: fmt.Sprintf("The discriminator property '%s' has the value '%s', which does not map to "+
+			"any of the schemas defined", discriminated.PropertyName, value),
+		SpecLine: schema.GoLow().Discriminator.KeyNode.Line,
+		SpecCol:  schema.GoLow().Discriminator.KeyNode.Column,
+		Context:  schema,
+		HowToFix: fmt.Sprintf(HowToFixDiscriminator, discriminated.PropertyName,
+			strings.Join(discriminated.Values(), ", ")),
+	}
+}
+
+// ResponseBodyWriteOnlyProperty returns a warning for a response body that contains properties marked as writeOnly,
+// each property is described by a schema validation failure. It does not fail validation.
+func ResponseBodyWriteOnlyProperty(request *http.Request, response *http.Response,
+	failures []*SchemaValidationFailure, renderedSchema []byte) *ValidationError {
+	properties := make([]string, 0, len(failures))
+	for _, failure := range failures {
+		properties = append(properties, failure.InstanceLocation)
+	}
+	return &ValidationError{
+		Code:              ErrorCodeSchema,
+		ValidationType:    helpers.ResponseBodyValidation,
+		ValidationSubType: helpers.Schema,
+		Severity:          helpers.SeverityWarning,
+		Message: fmt.Sprintf("%d response body for '%s' contains writeOnly properties",
+			response.StatusCode, request.URL.Path),
+		Reason: fmt.Sprintf("The properties at '%s' are marked as writeOnly in the specification, "+
+			"however they are returned in the response body", strings.Join(properties, "', '")),
+		SpecLine:               1,
+		SpecCol:                0,
+		SchemaValidationErrors: failures,
+		HowToFix:               HowToFixWriteOnlyProperty,
+		Context:                string(renderedSchema),
+	}
+}
+
+// ResponseContentTypeNotAcceptable returns a warning for a response with a content type that the Accept header of
+// the request does not allow. It does not fail validation.
+func ResponseContentTypeNotAcceptable(request *http.Request, response *http.Response) *ValidationError {
+	accept := strings.Join(request.Header.Values(helpers.AcceptHeader), ", ")
+	contentType := response.Header.Get(helpers.ContentTypeHeader)
+	return &ValidationError{
+		Code:              ErrorCodeNotAcceptable,
+		ValidationType:    helpers.ResponseBodyValidation,
+		ValidationSubType: helpers.RequestBodyContentType,
+		Severity:          helpers.SeverityWarning,
+		Message: fmt.Sprintf("%d response content type '%s' is not acceptable to the client",
+			response.StatusCode, contentType),
+		Reason: fmt.Sprintf("The %s request to '%s' only accepts '%s', however the response has a content "+
+			"type of '%s'", request.Method, request.URL.Path, accept, contentType),
+		SpecLine: 1,
+		SpecCol:  0,
+		HowToFix: fmt.Sprintf(HowToFixNotAcceptable, accept),
+	}
+}
+
+// ResponseBodySchemaUnresolved returns a ValidationError for a response body with a schema that cannot be built, for
+// example when it's an external reference that cannot be resolved. The body cannot be validated.
+func ResponseBodySchemaUnresolved(request *http.Request, response *http.Response,
+	schema *base.SchemaProxy) *ValidationError {
+	specLine, specCol := schemaProxyPosition(schema)
+	return &ValidationError{
+		Code:              ErrorCodeDocument,
+		ValidationType:    helpers.ResponseBodyValidation,
+		ValidationSubType: helpers.Reference,
+		Message: fmt.Sprintf("%d response body for '%s' cannot be validated, the schema cannot be resolved",
+			response.StatusCode, request.URL.Path),
+		Reason:   schemaBuildReason(schema),
+		SpecLine: specLine,
+		SpecCol:  specCol,
+		HowToFix: HowToFixReference,
+		Context:  schema,
+	}
+}
+
+// ResponseBodySchemaInvalid returns a ValidationError for a response body with a schema that cannot be compiled, as it
+// is not valid JSON Schema. The body cannot be validated.
+func ResponseBodySchemaInvalid(request *http.Request, response *http.Response,
+	schema *base.Schema, err error) *ValidationError {
+	specLine, specCol := schemaPosition(schema)
+	return &ValidationError{
+		Code:              ErrorCodeDocument,
+		ValidationType:    helpers.ResponseBodyValidation,
+		ValidationSubType: helpers.Schema,
+		Message: fmt.Sprintf("%d response body for '%s' cannot be validated, the schema cannot be compiled",
+			response.StatusCode, request.URL.Path),
+		Reason:   fmt.Sprintf("The schema of the response body is not valid JSON Schema: %v", err),
+		SpecLine: specLine,
+		SpecCol:  specCol,
+		HowToFix: HowToFixSchemaCompile,
+		Context:  schema,
 	}
 }
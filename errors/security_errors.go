@@ -0,0 +1,65 @@
+// Copyright 2023 Princess B33f Heavy Industries / Dave Shanley
+// SPDX-License-Identifier: MIT
+
+package errors
+
+import (
+	"fmt"
+	"github.com/pb33f/libopenapi-validator/helpers"
+	"github.com/pb33f/libopenapi/datamodel/high/base"
+	"net/http"
+	"strings"
+)
+
+// SecurityRequirementsNotMet returns a ValidationError for a request that does not carry the credentials required by
+// any of the security requirements of an operation. Each entry of missing describes what one of the security
+// requirements expected to find.
+func SecurityRequirementsNotMet(request *http.Request,
+	security []*base.SecurityRequirement,
+	missing []string) *ValidationError {
+
+	specLine, specCol := securityLocation(security)
+	return &ValidationError{
+		Code:           ErrorCodeSecurity,
+		ValidationType: helpers.SecurityValidation,
+		Message: fmt.Sprintf("%s request for '%s' is missing the credentials required by the security requirements",
+			request.Method, request.URL.Path),
+		Reason: fmt.Sprintf("None of the security requirements for the operation are met, "+
+			"expected %s", strings.Join(missing, ", or ")),
+		SpecLine: specLine,
+		SpecCol:  specCol,
+		HowToFix: HowToFixMissingCredentials,
+		Context:  security,
+	}
+}
+
+// SecurityCredentialsRejected returns a ValidationError for a request that carries credentials for the security
+// requirements of an operation, that were rejected by the verifier of a security scheme (see
+// config.WithSecurityVerifier), and does not meet any of the other requirements. Each entry of missing describes
+// what one of the security requirements expected to find.
+func SecurityCredentialsRejected(request *http.Request,
+	security []*base.SecurityRequirement,
+	missing []string) *ValidationError {
+
+	specLine, specCol := securityLocation(security)
+	return &ValidationError{
+		Code:           ErrorCodeSecurity,
+		ValidationType: helpers.SecurityValidation,
+		Message: fmt.Sprintf("%s request for '%s' has credentials that were rejected by the security requirements",
+			request.Method, request.URL.Path),
+		Reason: fmt.Sprintf("None of the security requirements for the operation are met, "+
+			"expected %s", strings.Join(missing, ", or ")),
+		SpecLine: specLine,
+		SpecCol:  specCol,
+		HowToFix: HowToFixRejectedCredentials,
+		Context:  security,
+	}
+}
+
+// securityLocation returns the line and column of the first security requirement, or zeros if it has no location.
+func securityLocation(security []*base.SecurityRequirement) (int, int) {
+	if len(security) > 0 && security[0].GoLow() != nil && security[0].GoLow().Requirements.ValueNode != nil {
+		return security[0].GoLow().Requirements.ValueNode.Line, security[0].GoLow().Requirements.ValueNode.Column
+	}
+	return 0, 0
+}
@@ -4,8 +4,14 @@
 package errors
 
 import (
+	"context"
+	"encoding/json"
 	"fmt"
+	"github.com/pb33f/libopenapi-validator/helpers"
+	"github.com/pb33f/libopenapi/datamodel/high/v3"
 	"github.com/santhosh-tekuri/jsonschema/v5"
+	"net/http"
+	"strings"
 )
 
 // SchemaValidationFailure is a wrapper around the jsonschema.ValidationError object, to provide a more
@@ -23,6 +29,10 @@ type SchemaValidationFailure struct {
 	// AbsoluteLocation is the absolute path to the validation failure as exposed by the jsonschema library.
 	AbsoluteLocation string `json:"absoluteLocation,omitempty" yaml:"absoluteLocation,omitempty"`
 
+	// InstanceLocation is a JSON Pointer (RFC 6901) to the value that failed validation, for example
+	// /category/name or /photoUrls/2. An empty InstanceLocation means the whole value failed.
+	InstanceLocation string `json:"instanceLocation,omitempty" yaml:"instanceLocation,omitempty"`
+
 	// Line is the line number where the violation occurred. This may a local line number
 	// if the validation is a schema (only schemas are validated locally, so the line number will be relative to
 	// the Context object held by the ValidationError object).
@@ -39,6 +49,10 @@ type SchemaValidationFailure struct {
 	// ReferenceObject is the object that was referenced in the validation failure.
 	ReferenceObject string `json:"referenceObject,omitempty" yaml:"referenceObject,omitempty"`
 
+	// SubschemaFailures holds the failures of each subschema when a oneOf or anyOf fails, keyed by the index of the
+	// subschema. It is only populated for oneOf and anyOf failures.
+	SubschemaFailures map[int][]*SchemaValidationFailure `json:"subschemaFailures,omitempty" yaml:"subschemaFailures,omitempty"`
+
 	// The original error object, which is a jsonschema.ValidationError object.
 	OriginalError *jsonschema.ValidationError `json:"-" yaml:"-"`
 }
@@ -48,6 +62,22 @@ func (s *SchemaValidationFailure) Error() string {
 	return fmt.Sprintf("Reason: %s, Location: %s", s.Reason, s.Location)
 }
 
+// MarshalJSON will serialize the failure using its json tags, subschemas without failures are omitted.
+func (s *SchemaValidationFailure) MarshalJSON() ([]byte, error) {
+	type schemaValidationFailure SchemaValidationFailure
+	failure := schemaValidationFailure(*s)
+	failure.SubschemaFailures = nil
+	for index, failures := range s.SubschemaFailures {
+		if failures = compactFailures(failures); failures != nil {
+			if failure.SubschemaFailures == nil {
+				failure.SubschemaFailures = make(map[int][]*SchemaValidationFailure)
+			}
+			failure.SubschemaFailures[index] = failures
+		}
+	}
+	return json.Marshal(failure)
+}
+
 // ValidationError is a struct that contains all the information about a validation error.
 type ValidationError struct {
 
@@ -63,6 +93,10 @@ type ValidationError struct {
 	// ValidationSubType is a string that describes the subtype of validation that failed.
 	ValidationSubType string `json:"validationSubType" yaml:"validationSubType"`
 
+	// Code is the category of the error (such as ErrorCodeQueryParamMissing or ErrorCodeSchema), use it to handle
+	// errors programmatically, rather than matching the Message.
+	Code ErrorCode `json:"code,omitempty" yaml:"code,omitempty"`
+
 	// SpecLine is the line number in the spec where the error occurred.
 	SpecLine int `json:"specLine" yaml:"specLine"`
 
@@ -76,33 +110,204 @@ type ValidationError struct {
 	// This is only populated whe the validation type is against a schema.
 	SchemaValidationErrors []*SchemaValidationFailure `json:"validationErrors,omitempty" yaml:"validationErrors,omitempty"`
 
+	// OperationId is the operationId of the operation the error was found in, it's empty when the operation does
+	// not declare one.
+	OperationId string `json:"operationId,omitempty" yaml:"operationId,omitempty"`
+
+	// Method is the HTTP method (e.g. GET) of the operation the error was found in.
+	Method string `json:"method,omitempty" yaml:"method,omitempty"`
+
+	// Path is the templated path (e.g. /pet/{petId}) of the operation the error was found in. For webhooks, it's
+	// the name of the webhook.
+	Path string `json:"path,omitempty" yaml:"path,omitempty"`
+
+	// Severity is either helpers.SeverityError or helpers.SeverityWarning, an empty Severity is an error. Warnings
+	// report deviations from the contract that do not fail validation.
+	Severity string `json:"severity,omitempty" yaml:"severity,omitempty"`
+
 	// Context is the object that the validation error occurred on. This is usually a pointer to a schema
 	// or a parameter object.
 	Context interface{} `json:"-" yaml:"-"`
 }
 
-// Error returns a string representation of the error
+// Error returns a single line made up of the message of the error, and the reason of the first schema failure (or
+// the reason of the error, if it has no schema failures).
 func (v *ValidationError) Error() string {
-	if v.SchemaValidationErrors != nil {
-		if v.SpecLine > 0 && v.SpecCol > 0 {
-			return fmt.Sprintf("Error: %s, Reason: %s, Validation Errors: %s, Line: %d, Column: %d",
-				v.Message, v.Reason, v.SchemaValidationErrors, v.SpecLine, v.SpecCol)
-		} else {
-			return fmt.Sprintf("Error: %s, Reason: %s, Validation Errors: %s",
-				v.Message, v.Reason, v.SchemaValidationErrors)
+	for _, failure := range v.SchemaValidationErrors {
+		if failure != nil && failure.Reason != "" {
+			return fmt.Sprintf("%s: %s", v.Message, failure.Reason)
+		}
+	}
+	if v.Reason == "" {
+		return v.Message
+	}
+	return fmt.Sprintf("%s: %s", v.Message, v.Reason)
+}
+
+// MultiError is a set of validation errors that implements the error interface, so the errors returned by a
+// validation can be handled like any other Go error. Each *ValidationError can be found using errors.As (or
+// errors.Is), the structured fields are left untouched.
+type MultiError []*ValidationError
+
+// NewMultiError returns the validation errors as a single error, or nil if there are no errors.
+func NewMultiError(validationErrors []*ValidationError) error {
+	var multi MultiError
+	for _, validationError := range validationErrors {
+		if validationError != nil {
+			multi = append(multi, validationError)
+		}
+	}
+	if len(multi) == 0 {
+		return nil
+	}
+	return multi
+}
+
+// Error returns a single line made up of the message of each error, and the first reason it failed.
+func (m MultiError) Error() string {
+	summaries := make([]string, len(m))
+	for i, validationError := range m {
+		summaries[i] = validationError.Error()
+	}
+	if len(m) == 1 {
+		return summaries[0]
+	}
+	return fmt.Sprintf("%d validation errors: %s", len(m), strings.Join(summaries, "; "))
+}
+
+// Unwrap returns each validation error, so they can be found using errors.As and errors.Is.
+func (m MultiError) Unwrap() []error {
+	unwrapped := make([]error, len(m))
+	for i, validationError := range m {
+		unwrapped[i] = validationError
+	}
+	return unwrapped
+}
+
+// MarshalJSON will serialize the error using its json tags, the field names are stable and can be relied on by
+// clients. The Context is never serialized, and validationErrors is omitted when there are no schema failures.
+func (v *ValidationError) MarshalJSON() ([]byte, error) {
+	type validationError ValidationError
+	validation := validationError(*v)
+	validation.SchemaValidationErrors = compactFailures(v.SchemaValidationErrors)
+	return json.Marshal(validation)
+}
+
+// compactFailures returns the failures without nil entries, or nil when there are none.
+func compactFailures(failures []*SchemaValidationFailure) []*SchemaValidationFailure {
+	var compacted []*SchemaValidationFailure
+	for _, failure := range failures {
+		if failure != nil {
+			compacted = append(compacted, failure)
+		}
+	}
+	return compacted
+}
+
+// SetOperation will record the operation each error was found in: the HTTP method of the request, the templated
+// path and the operationId (if the operation declares one). Errors that already have a method are left as they are.
+func SetOperation(validationErrors []*ValidationError, request *http.Request, pathItem *v3.PathItem, path string) {
+	if len(validationErrors) == 0 || request == nil || pathItem == nil {
+		return
+	}
+	var operationId string
+	if operation := helpers.ExtractOperation(request, pathItem); operation != nil {
+		operationId = operation.OperationId
+	}
+	for _, validationError := range validationErrors {
+		if validationError == nil || validationError.Method != "" {
+			continue
+		}
+		validationError.OperationId = operationId
+		validationError.Method = request.Method
+		validationError.Path = path
+	}
+}
+
+// Deduplicate returns the errors without duplicates, in the order they were found. Errors are duplicates when they
+// have the same message, reason and severity, and the same schema failures. Schema failures within an error are
+// duplicates when they have the same reason and instance location (the JSON pointer to the value that failed), for
+// example when the same constraint is declared by more than one schema in an allOf. The first of each is kept.
+func Deduplicate(validationErrors []*ValidationError) []*ValidationError {
+	if len(validationErrors) == 0 {
+		return validationErrors
+	}
+	seen := make(map[string]bool)
+	deduplicated := make([]*ValidationError, 0, len(validationErrors))
+	for _, validationError := range validationErrors {
+		if validationError == nil {
+			continue
+		}
+		validationError.SchemaValidationErrors = deduplicateFailures(validationError.SchemaValidationErrors)
+		key := []string{validationError.Message, validationError.Reason, validationError.Severity}
+		for _, failure := range validationError.SchemaValidationErrors {
+			key = append(key, failure.Reason, failure.InstanceLocation)
+		}
+		encoded, _ := json.Marshal(key)
+		if seen[string(encoded)] {
+			continue
 		}
-	} else {
-		if v.SpecLine > 0 && v.SpecCol > 0 {
-			return fmt.Sprintf("Error: %s, Reason: %s, Line: %d, Column: %d",
-				v.Message, v.Reason, v.SpecLine, v.SpecCol)
-		} else {
-			return fmt.Sprintf("Error: %s, Reason: %s",
-				v.Message, v.Reason)
+		seen[string(encoded)] = true
+		deduplicated = append(deduplicated, validationError)
+	}
+	return deduplicated
+}
+
+// deduplicateFailures returns the failures without duplicates (or nil entries), in the order they were found.
+func deduplicateFailures(failures []*SchemaValidationFailure) []*SchemaValidationFailure {
+	if len(failures) == 0 {
+		return failures
+	}
+	type failureKey struct{ reason, location string }
+	seen := make(map[failureKey]bool)
+	deduplicated := make([]*SchemaValidationFailure, 0, len(failures))
+	for _, failure := range failures {
+		if failure == nil || seen[failureKey{failure.Reason, failure.InstanceLocation}] {
+			continue
 		}
+		seen[failureKey{failure.Reason, failure.InstanceLocation}] = true
+		deduplicated = append(deduplicated, failure)
 	}
+	return deduplicated
+}
+
+// IsCancelledError returns true if the error has a ValidationType of "context" and a ValidationSubType of "cancelled".
+// A cancelled validation did not complete, so it says nothing about the validity of the request.
+func (v *ValidationError) IsCancelledError() bool {
+	return v.ValidationType == helpers.ContextValidation && v.ValidationSubType == helpers.Cancelled
+}
+
+// IsWarning returns true if the error has a Severity of "warning", warnings do not fail validation.
+func (v *ValidationError) IsWarning() bool {
+	return v.Severity == helpers.SeverityWarning
+}
+
+// HasErrors returns true if any of the validation errors is not a warning. Validation fails only when there are
+// errors, warnings are returned alongside them (or on their own, when validation passes).
+func HasErrors(validationErrors []*ValidationError) bool {
+	for _, validationError := range validationErrors {
+		if validationError != nil && !validationError.IsWarning() {
+			return true
+		}
+	}
+	return false
 }
 
 // IsPathMissingError returns true if the error has a ValidationType of "path" and a ValidationSubType of "missing"
 func (v *ValidationError) IsPathMissingError() bool {
 	return v.ValidationType == "path" && v.ValidationSubType == "missing"
 }
+
+// ValidationCancelled returns a ValidationError that signals the context used for validation was cancelled, or its
+// deadline was exceeded, before validation could complete. Use IsCancelledError to check for this error.
+func ValidationCancelled(ctx context.Context) *ValidationError {
+	return &ValidationError{
+		Code:              ErrorCodeCancelled,
+		ValidationType:    helpers.ContextValidation,
+		ValidationSubType: helpers.Cancelled,
+		Message:           "Validation was cancelled before it completed",
+		Reason:            fmt.Sprintf("The context used for validation is done: %v", ctx.Err()),
+		HowToFix:          HowToFixValidationCancelled,
+		Context:           ctx,
+	}
+}
@@ -0,0 +1,159 @@
+// Copyright 2023 Princess B33f Heavy Industries / Dave Shanley
+// SPDX-License-Identifier: MIT
+
+package har
+
+import (
+	"bytes"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	validator "github.com/pb33f/libopenapi-validator"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+)
+
+// Archive is a HAR (HTTP Archive) file, only the parts needed to re-create requests and responses are decoded.
+type Archive struct {
+	Log struct {
+		Entries []*Entry `json:"entries"`
+	} `json:"log"`
+}
+
+// Entry is a single request, and the response it received, recorded in an Archive.
+type Entry struct {
+	Request  Request  `json:"request"`
+	Response Response `json:"response"`
+}
+
+// Request is the recorded request of an Entry.
+type Request struct {
+	Method   string      `json:"method"`
+	URL      string      `json:"url"`
+	Headers  []NameValue `json:"headers"`
+	Cookies  []NameValue `json:"cookies"`
+	PostData *PostData   `json:"postData,omitempty"`
+}
+
+// PostData is the recorded body of a Request. Form posts may only record the params.
+type PostData struct {
+	MimeType string      `json:"mimeType"`
+	Text     string      `json:"text"`
+	Params   []NameValue `json:"params"`
+}
+
+// Response is the recorded response of an Entry. A status of 0 means no response was received.
+type Response struct {
+	Status  int         `json:"status"`
+	Headers []NameValue `json:"headers"`
+	Content Content     `json:"content"`
+}
+
+// Content is the recorded body of a Response, the text is base64 encoded if the encoding is 'base64'.
+type Content struct {
+	MimeType string `json:"mimeType"`
+	Text     string `json:"text"`
+	Encoding string `json:"encoding,omitempty"`
+}
+
+// NameValue is a header, cookie or form parameter.
+type NameValue struct {
+	Name  string `json:"name"`
+	Value string `json:"value"`
+}
+
+// ReadArchive will decode a HAR file.
+func ReadArchive(reader io.Reader) (*Archive, error) {
+	var archive Archive
+	if err := json.NewDecoder(reader).Decode(&archive); err != nil {
+		return nil, fmt.Errorf("unable to decode HAR file: %w", err)
+	}
+	return &archive, nil
+}
+
+// Exchange will re-create the request and response of the entry, so it can be validated. The response is nil if
+// no response was recorded.
+func (e *Entry) Exchange() (*validator.Exchange, error) {
+	request, err := e.Request.httpRequest()
+	if err != nil {
+		return nil, err
+	}
+	exchange := &validator.Exchange{Request: request}
+	if e.Response.Status > 0 {
+		exchange.Response, err = e.Response.httpResponse(request)
+		if err != nil {
+			return nil, err
+		}
+	}
+	return exchange, nil
+}
+
+func (r *Request) httpRequest() (*http.Request, error) {
+	var body []byte
+	if r.PostData != nil {
+		body = []byte(r.PostData.Text)
+		if len(body) == 0 && len(r.PostData.Params) > 0 {
+			form := url.Values{}
+			for _, param := range r.PostData.Params {
+				form.Add(param.Name, param.Value)
+			}
+			body = []byte(form.Encode())
+		}
+	}
+	request, err := http.NewRequest(r.Method, r.URL, bytes.NewReader(body))
+	if err != nil {
+		return nil, fmt.Errorf("unable to re-create the %s request for '%s': %w", r.Method, r.URL, err)
+	}
+	request.Header = recordedHeaders(r.Headers)
+	if r.PostData != nil && r.PostData.MimeType != "" && request.Header.Get("Content-Type") == "" {
+		request.Header.Set("Content-Type", r.PostData.MimeType)
+	}
+	if request.Header.Get("Cookie") == "" {
+		for _, cookie := range r.Cookies {
+			request.AddCookie(&http.Cookie{Name: cookie.Name, Value: cookie.Value})
+		}
+	}
+	return request, nil
+}
+
+func (r *Response) httpResponse(request *http.Request) (*http.Response, error) {
+	body := []byte(r.Content.Text)
+	if r.Content.Encoding == "base64" {
+		decoded, err := base64.StdEncoding.DecodeString(r.Content.Text)
+		if err != nil {
+			return nil, fmt.Errorf("unable to decode the response body for '%s': %w", request.URL, err)
+		}
+		body = decoded
+	}
+	header := recordedHeaders(r.Headers)
+	if r.Content.MimeType != "" && header.Get("Content-Type") == "" {
+		header.Set("Content-Type", r.Content.MimeType)
+	}
+	return &http.Response{
+		StatusCode:    r.Status,
+		Status:        fmt.Sprintf("%d %s", r.Status, http.StatusText(r.Status)),
+		Header:        header,
+		Body:          io.NopCloser(bytes.NewReader(body)),
+		ContentLength: int64(len(body)),
+		Request:       request,
+	}, nil
+}
+
+// recordedHeaders will re-create the headers of a request or response. Bodies are recorded after they have been
+// decoded, so the content encoding and length no longer apply and are dropped, as are HTTP/2 pseudo headers.
+func recordedHeaders(headers []NameValue) http.Header {
+	header := http.Header{}
+	for _, h := range headers {
+		if strings.HasPrefix(h.Name, ":") {
+			continue
+		}
+		switch http.CanonicalHeaderKey(h.Name) {
+		case "Content-Encoding", "Content-Length":
+			continue
+		}
+		header.Add(h.Name, h.Value)
+	}
+	return header
+}
@@ -0,0 +1,7 @@
+// Copyright 2023 Princess B33f Heavy Industries / Dave Shanley
+// SPDX-License-Identifier: MIT
+
+// Package har contains the logic for checking recorded traffic in a HAR (HTTP Archive) file against an OpenAPI 3+
+// document. Each entry of the archive is re-created as an *http.Request and *http.Response and validated, the
+// results are collected into a Report.
+package har
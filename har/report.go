@@ -0,0 +1,144 @@
+// Copyright 2023 Princess B33f Heavy Industries / Dave Shanley
+// SPDX-License-Identifier: MIT
+
+package har
+
+import (
+	"context"
+	validator "github.com/pb33f/libopenapi-validator"
+	"github.com/pb33f/libopenapi-validator/errors"
+	"io"
+)
+
+const (
+	// Valid is the result of an entry that conforms to the specification.
+	Valid = "valid"
+
+	// Invalid is the result of an entry that matched an operation, but violates the specification.
+	Invalid = "invalid"
+
+	// Unmatched is the result of an entry that does not match any path (or operation) in the specification.
+	Unmatched = "unmatched"
+
+	// Unreadable is the result of an entry that could not be re-created as a request and response.
+	Unreadable = "unreadable"
+)
+
+// Report is the result of checking every entry of a HAR file against the specification.
+type Report struct {
+	Total      int            `json:"total"`
+	Valid      int            `json:"valid"`
+	Invalid    int            `json:"invalid"`
+	Unmatched  int            `json:"unmatched"`
+	Unreadable int            `json:"unreadable"`
+	Entries    []*EntryResult `json:"entries"`
+}
+
+// EntryResult is the result of checking a single entry of a HAR file.
+type EntryResult struct {
+	// Index is the position of the entry in the HAR file, starting at 0.
+	Index int `json:"index"`
+
+	Method string `json:"method"`
+	URL    string `json:"url"`
+	Status int    `json:"status,omitempty"`
+
+	// Result is one of Valid, Invalid, Unmatched or Unreadable.
+	Result string `json:"result"`
+
+	// Path is the templated path (e.g. /pet/{petId}) the entry matched.
+	Path string `json:"path,omitempty"`
+
+	// OperationId is the operationId of the operation the entry matched, if it has one.
+	OperationId string `json:"operationId,omitempty"`
+
+	// Errors explain why the entry violates the specification (warnings are included).
+	Errors []*errors.ValidationError `json:"errors,omitempty"`
+
+	// ReadError is set when the entry could not be re-created as a request and response.
+	ReadError string `json:"readError,omitempty"`
+}
+
+// Violations returns the entries that are invalid or could not be read, unmatched entries are not violations.
+func (r *Report) Violations() []*EntryResult {
+	var violations []*EntryResult
+	for _, entry := range r.Entries {
+		if entry.Result == Invalid || entry.Result == Unreadable {
+			violations = append(violations, entry)
+		}
+	}
+	return violations
+}
+
+// Check will read a HAR file, and validate every entry against the specification, using ValidateExchanges. An
+// error is only returned if the HAR file cannot be decoded, entries that don't match a path in the specification
+// are reported as Unmatched.
+func Check(v validator.Validator, reader io.Reader) (*Report, error) {
+	return CheckWithContext(context.Background(), v, reader)
+}
+
+// CheckWithContext is the same as Check, but will stop validating when the context is done.
+func CheckWithContext(ctx context.Context, v validator.Validator, reader io.Reader) (*Report, error) {
+	archive, err := ReadArchive(reader)
+	if err != nil {
+		return nil, err
+	}
+	return CheckArchive(ctx, v, archive), nil
+}
+
+// CheckArchive will validate every entry of a decoded HAR file against the specification.
+func CheckArchive(ctx context.Context, v validator.Validator, archive *Archive) *Report {
+	report := &Report{Total: len(archive.Log.Entries), Entries: make([]*EntryResult, len(archive.Log.Entries))}
+
+	// re-create the exchanges, the entries that can be read are validated as a single batch.
+	var exchanges []*validator.Exchange
+	var indexes []int
+	for i, entry := range archive.Log.Entries {
+		report.Entries[i] = &EntryResult{
+			Index:  i,
+			Method: entry.Request.Method,
+			URL:    entry.Request.URL,
+			Status: entry.Response.Status,
+		}
+		exchange, readErr := entry.Exchange()
+		if readErr != nil {
+			report.Entries[i].Result = Unreadable
+			report.Entries[i].ReadError = readErr.Error()
+			report.Unreadable++
+			continue
+		}
+		exchanges = append(exchanges, exchange)
+		indexes = append(indexes, i)
+	}
+
+	for i, result := range v.ValidateExchangesWithContext(ctx, exchanges) {
+		entry := report.Entries[indexes[i]]
+		entry.Path = result.Path
+		entry.Errors = result.Errors
+		if result.Operation != nil {
+			entry.OperationId = result.Operation.OperationId
+		}
+		switch {
+		case result.Valid:
+			entry.Result = Valid
+			report.Valid++
+		case result.Path == "" && !cancelled(result.Errors):
+			entry.Result = Unmatched
+			report.Unmatched++
+		default:
+			entry.Result = Invalid
+			report.Invalid++
+		}
+	}
+	return report
+}
+
+// cancelled returns true if validation was stopped because the context was done.
+func cancelled(errs []*errors.ValidationError) bool {
+	for _, err := range errs {
+		if err.IsCancelledError() {
+			return true
+		}
+	}
+	return false
+}
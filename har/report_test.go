@@ -0,0 +1,92 @@
+// Copyright 2023 Princess B33f Heavy Industries / Dave Shanley
+// SPDX-License-Identifier: MIT
+
+package har
+
+import (
+	"github.com/pb33f/libopenapi"
+	validator "github.com/pb33f/libopenapi-validator"
+	"github.com/stretchr/testify/assert"
+	"strings"
+	"testing"
+)
+
+func TestCheck(t *testing.T) {
+
+	spec := `openapi: 3.1.0
+paths:
+  /burgers/{burgerId}:
+    get:
+      operationId: getBurger
+      parameters:
+        - name: burgerId
+          in: path
+          required: true
+          schema:
+            type: integer
+      responses:
+        '200':
+          content:
+            application/json:
+              schema:
+                type: object
+                required: [name]
+                properties:
+                  name:
+                    type: string`
+
+	archive := `{"log": {"version": "1.2", "entries": [
+  {"request": {"method": "GET", "url": "https://things.com/burgers/1", "headers": [{"name": ":authority", "value": "things.com"}]},
+   "response": {"status": 200, "headers": [{"name": "content-type", "value": "application/json"},
+     {"name": "content-encoding", "value": "gzip"}], "content": {"mimeType": "application/json", "text": "{\"name\": \"Big Mac\"}"}}},
+  {"request": {"method": "GET", "url": "https://things.com/burgers/2", "headers": []},
+   "response": {"status": 200, "headers": [], "content": {"mimeType": "application/json", "text": "eyJwYXR0aWVzIjogMn0=", "encoding": "base64"}}},
+  {"request": {"method": "GET", "url": "https://things.com/fries", "headers": []},
+   "response": {"status": 404, "headers": [], "content": {"mimeType": "text/plain", "text": "not found"}}},
+  {"request": {"method": "GET", "url": "https://things.com/burgers/3", "headers": []},
+   "response": {"status": 200, "headers": [], "content": {"mimeType": "application/json", "text": "!!!", "encoding": "base64"}}}
+]}}`
+
+	doc, _ := libopenapi.NewDocument([]byte(spec))
+	v, _ := validator.NewValidator(doc)
+
+	report, err := Check(v, strings.NewReader(archive))
+	assert.NoError(t, err)
+	assert.Equal(t, 4, report.Total)
+	assert.Equal(t, 1, report.Valid)
+	assert.Equal(t, 1, report.Invalid)
+	assert.Equal(t, 1, report.Unmatched)
+	assert.Equal(t, 1, report.Unreadable)
+	assert.Len(t, report.Entries, 4)
+
+	assert.Equal(t, Valid, report.Entries[0].Result)
+	assert.Equal(t, "/burgers/{burgerId}", report.Entries[0].Path)
+	assert.Equal(t, "getBurger", report.Entries[0].OperationId)
+	assert.Empty(t, report.Entries[0].Errors)
+
+	// the decoded response body is missing the name.
+	assert.Equal(t, Invalid, report.Entries[1].Result)
+	assert.Len(t, report.Entries[1].Errors, 1)
+
+	// entries that don't match a path are not errors.
+	assert.Equal(t, Unmatched, report.Entries[2].Result)
+	assert.Equal(t, "https://things.com/fries", report.Entries[2].URL)
+
+	assert.Equal(t, Unreadable, report.Entries[3].Result)
+	assert.NotEmpty(t, report.Entries[3].ReadError)
+
+	violations := report.Violations()
+	assert.Len(t, violations, 2)
+	assert.Equal(t, 1, violations[0].Index)
+	assert.Equal(t, 3, violations[1].Index)
+}
+
+func TestCheck_InvalidArchive(t *testing.T) {
+
+	doc, _ := libopenapi.NewDocument([]byte(`openapi: 3.1.0`))
+	v, _ := validator.NewValidator(doc)
+
+	report, err := Check(v, strings.NewReader("not a HAR file"))
+	assert.Nil(t, report)
+	assert.Error(t, err)
+}
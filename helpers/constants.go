@@ -14,6 +14,24 @@ const (
 	ResponseBodyValidation    = "response"
 	RequestBodyContentType    = "contentType"
 	ResponseBodyResponseCode  = "statusCode"
+	ContextValidation         = "context"
+	SecurityValidation        = "security"
+	WebhookValidation         = "webhook"
+	CallbackValidation        = "callback"
+	DocumentValidation        = "document"
+	ParameterStyle            = "style"
+	Example                   = "example"
+	Reference                 = "reference"
+	Missing                   = "missing"
+	BodySize                  = "size"
+	Unexpected                = "unexpected"
+	Empty                     = "empty"
+	DuplicateKey              = "duplicateKey"
+	Cancelled                 = "cancelled"
+	SeverityError             = "error"
+	SeverityWarning           = "warning"
+	Deprecated                = "deprecated"
+	ReplacedByExtension       = "x-replaced-by"
 	SpaceDelimited            = "spaceDelimited"
 	PipeDelimited             = "pipeDelimited"
 	DefaultDelimited          = "default"
@@ -33,15 +51,34 @@ const (
 	String                    = "string"
 	Array                     = "array"
 	Boolean                   = "boolean"
+	Null                      = "null"
 	DeepObject                = "deepObject"
+	Simple                    = "simple"
 	Header                    = "header"
 	Cookie                    = "cookie"
+	Trailer                   = "trailer"
 	Path                      = "path"
 	Form                      = "form"
 	Query                     = "query"
 	JSONContentType           = "application/json"
+	ProblemJSONContentType    = "application/problem+json"
 	JSONType                  = "json"
+	XMLContentType            = "application/xml"
+	FormURLEncodedContentType = "application/x-www-form-urlencoded"
+	MultipartFormContentType  = "multipart/form-data"
+	Binary                    = "binary"
+	Multipart                 = "multipart"
+	XMLType                   = "xml"
+	NDJSONType                = "ndjson"
+	Discriminator             = "discriminator"
 	ContentTypeHeader         = "Content-Type"
+	ContentEncodingHeader     = "Content-Encoding"
+	TrailerHeader             = "Trailer"
+	AcceptHeader              = "Accept"
+	SetCookieHeader           = "Set-Cookie"
+	ContentEncoding           = "contentEncoding"
+	Gzip                      = "gzip"
+	Deflate                   = "deflate"
 	Charset                   = "charset"
 	Boundary                  = "boundary"
 	Preferred                 = "preferred"
@@ -0,0 +1,106 @@
+// Copyright 2023 Princess B33f Heavy Industries / Dave Shanley
+// SPDX-License-Identifier: MIT
+
+package helpers
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/pb33f/libopenapi-validator/config"
+	"github.com/pb33f/libopenapi/datamodel/high/base"
+	"github.com/santhosh-tekuri/jsonschema/v5"
+)
+
+// DiscriminatedSchemas holds the compiled schemas of a oneOf (or anyOf) schema that uses a discriminator, keyed by
+// the discriminator value. The discriminator is used to select the single schema an object should be validated
+// against, instead of trying every schema.
+type DiscriminatedSchemas struct {
+	// PropertyName is the name of the property that holds the discriminator value.
+	PropertyName string
+
+	// Schemas are the compiled schemas, keyed by discriminator value.
+	Schemas map[string]*jsonschema.Schema
+}
+
+// NewDiscriminatedSchemas will compile each oneOf (or anyOf) schema of a schema that defines a discriminator. Values
+// are taken from the discriminator mapping, schemas that are not mapped use the name of the schema they reference
+// (the implicit mapping). nil is returned if the schema does not use a discriminator.
+func NewDiscriminatedSchemas(
+	name string,
+	schema *base.Schema,
+	jsonSchema []byte,
+	options *config.ValidationOptions) *DiscriminatedSchemas {
+
+	if schema == nil || schema.Discriminator == nil || schema.Discriminator.PropertyName == "" {
+		return nil
+	}
+	keyword, proxies := "oneOf", schema.OneOf
+	if len(proxies) == 0 {
+		keyword, proxies = "anyOf", schema.AnyOf
+	}
+	if len(proxies) == 0 {
+		return nil
+	}
+
+	compiler := jsonschema.NewCompiler()
+	ConfigureFormats(compiler, options)
+	ConfigureContent(compiler)
+	fName := fmt.Sprintf("%s.json", name)
+	if err := compiler.AddResource(fName, strings.NewReader(string(jsonSchema))); err != nil {
+		return nil
+	}
+
+	ds := &DiscriminatedSchemas{
+		PropertyName: schema.Discriminator.PropertyName,
+		Schemas:      make(map[string]*jsonschema.Schema),
+	}
+	for i, proxy := range proxies {
+		ref := proxy.GetReference()
+		if ref == "" {
+			continue // inline schemas can't be selected by a discriminator.
+		}
+		compiled, err := compiler.Compile(fmt.Sprintf("%s#/%s/%d", fName, keyword, i))
+		if err != nil {
+			continue
+		}
+		mapped := false
+		for value, target := range schema.Discriminator.Mapping {
+			if ref == target || strings.HasSuffix(ref, "/"+target) {
+				ds.Schemas[value] = compiled
+				mapped = true
+			}
+		}
+		if !mapped {
+			ds.Schemas[ref[strings.LastIndex(ref, "/")+1:]] = compiled
+		}
+	}
+	return ds
+}
+
+// Select will return the schema an object should be validated against, using the value of the discriminator
+// property. The value of the discriminator is returned, and if the property is missing, present is false.
+// If the value is not known, the schema is nil.
+func (d *DiscriminatedSchemas) Select(obj any) (schema *jsonschema.Schema, value string, present bool) {
+	m, ok := obj.(map[string]any)
+	if !ok {
+		return nil, "", false
+	}
+	raw, ok := m[d.PropertyName]
+	if !ok || raw == nil {
+		return nil, "", false
+	}
+	value = fmt.Sprint(raw)
+	return d.Schemas[value], value, true
+}
+
+// Values will return the known discriminator values, sorted.
+func (d *DiscriminatedSchemas) Values() []string {
+	values := make([]string, 0, len(d.Schemas))
+	for v := range d.Schemas {
+		values = append(values, v)
+	}
+	sort.Strings(values)
+	return values
+}
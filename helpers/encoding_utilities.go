@@ -0,0 +1,69 @@
+// Copyright 2023 Princess B33f Heavy Industries / Dave Shanley
+// SPDX-License-Identifier: MIT
+
+package helpers
+
+import (
+	"bytes"
+	"compress/flate"
+	"compress/gzip"
+	"compress/zlib"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+)
+
+// DefaultMaxDecodedBodyBytes is the largest body (in bytes) a compressed body can be decompressed to, when no
+// MaxBodyBytes has been set. It stops a small compressed body from exhausting memory once decompressed.
+const DefaultMaxDecodedBodyBytes int64 = 32 << 20
+
+// DecodeContentEncoding will decompress a body sent with a Content-Encoding of gzip or deflate, so it can be parsed
+// and validated. Encodings are listed in the order they were applied, so they are removed in reverse. A body with
+// no encoding (or an encoding that is not supported) is returned as it is. An error is returned if the body is not
+// correctly compressed, or if it decompresses to more than maxBytes (DefaultMaxDecodedBodyBytes if maxBytes is 0).
+func DecodeContentEncoding(contentEncoding string, body []byte, maxBytes int64) ([]byte, error) {
+	if contentEncoding == "" || len(body) == 0 {
+		return body, nil
+	}
+	maxBytes = DecodedBodyLimit(maxBytes)
+	encodings := strings.Split(contentEncoding, Comma)
+	for i := len(encodings) - 1; i >= 0; i-- {
+		var reader io.Reader
+		var err error
+		encoding := strings.ToLower(strings.TrimSpace(encodings[i]))
+		switch encoding {
+		case Gzip, "x-gzip":
+			reader, err = gzip.NewReader(bytes.NewReader(body))
+		case Deflate:
+			// deflate should be zlib wrapped, but some clients send the raw deflate stream.
+			reader, err = zlib.NewReader(bytes.NewReader(body))
+			if errors.Is(err, zlib.ErrHeader) {
+				reader, err = flate.NewReader(bytes.NewReader(body)), nil
+			}
+		default:
+			continue
+		}
+		if err != nil {
+			return nil, fmt.Errorf("the body is not valid %s: %w", encoding, err)
+		}
+		decoded, err := io.ReadAll(io.LimitReader(reader, maxBytes+1))
+		if err != nil {
+			return nil, fmt.Errorf("the body is not valid %s: %w", encoding, err)
+		}
+		if int64(len(decoded)) > maxBytes {
+			return nil, fmt.Errorf("the body decompresses to more than %d bytes", maxBytes)
+		}
+		body = decoded
+	}
+	return body, nil
+}
+
+// DecodedBodyLimit returns the largest body a compressed body can be decompressed to, for a MaxBodyBytes option.
+// When maxBodyBytes is 0 (no limit), DefaultMaxDecodedBodyBytes is used.
+func DecodedBodyLimit(maxBodyBytes int64) int64 {
+	if maxBodyBytes <= 0 {
+		return DefaultMaxDecodedBodyBytes
+	}
+	return maxBodyBytes
+}
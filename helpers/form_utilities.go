@@ -0,0 +1,136 @@
+// Copyright 2023 Princess B33f Heavy Industries / Dave Shanley
+// SPDX-License-Identifier: MIT
+
+package helpers
+
+import (
+	"encoding/json"
+	"strings"
+
+	"github.com/pb33f/libopenapi/datamodel/high/base"
+	"github.com/pb33f/libopenapi/datamodel/high/v3"
+)
+
+// DecodeFormValues will decode form fields (from an application/x-www-form-urlencoded or multipart/form-data body)
+// into a map that can be validated against the schema of the request body. The encoding rules of the media type are
+// applied to each field:
+//
+//   - arrays are sent as repeated keys when exploded (the default), otherwise the values are delimited by the style
+//     (form uses commas, spaceDelimited uses spaces and pipeDelimited uses pipes).
+//   - objects are sent as 'key[property]=value' pairs when using deepObject, as a JSON document when the encoding
+//     content type is application/json, otherwise as comma separated property/value pairs.
+//   - primitives are converted into the type declared by the property schema.
+//
+// Fields that are not described by the schema are added as strings (or arrays of strings when repeated), so that
+// additionalProperties can be checked.
+func DecodeFormValues(values map[string][]string, schema *base.Schema, encoding map[string]*v3.Encoding) map[string]any {
+	decoded := make(map[string]any)
+	props := make(map[string]*base.Schema)
+	if schema != nil {
+		for k, v := range schema.Properties {
+			props[k] = v.Schema()
+		}
+	}
+
+	// collect deepObject fields (address[city]=London) by the name of the property.
+	deepObjects := make(map[string]map[string]any)
+	for key, vals := range values {
+		open := strings.IndexRune(key, '[')
+		if open > 0 && strings.HasSuffix(key, "]") && len(vals) > 0 {
+			name := key[:open]
+			if enc, ok := encoding[name]; ok && enc.Style == DeepObject {
+				if deepObjects[name] == nil {
+					deepObjects[name] = make(map[string]any)
+				}
+				prop := key[open+1 : len(key)-1]
+				var propSchema *base.Schema
+				if props[name] != nil && props[name].Properties[prop] != nil {
+					propSchema = props[name].Properties[prop].Schema()
+				}
+				deepObjects[name][prop] = coerceForSchema(vals[0], propSchema)
+			}
+		}
+	}
+
+	for key, vals := range values {
+		if len(vals) == 0 {
+			continue
+		}
+		open := strings.IndexRune(key, '[')
+		if open > 0 && deepObjects[key[:open]] != nil {
+			continue
+		}
+
+		propSchema := props[key]
+		enc := encoding[key]
+		switch {
+		case propSchema != nil && len(propSchema.Type) > 0 && propSchema.Type[0] == Array:
+			var itemSchema *base.Schema
+			if propSchema.Items != nil && propSchema.Items.IsA() {
+				itemSchema = propSchema.Items.A.Schema()
+			}
+			var items []any
+			for _, v := range explodeFormArray(vals, enc) {
+				items = append(items, coerceForSchema(v, itemSchema))
+			}
+			decoded[key] = items
+
+		case propSchema != nil && len(propSchema.Type) > 0 && propSchema.Type[0] == Object:
+			if enc != nil && strings.Contains(strings.ToLower(enc.ContentType), JSONType) {
+				var obj any
+				if err := json.Unmarshal([]byte(vals[0]), &obj); err == nil {
+					decoded[key] = obj
+				} else {
+					decoded[key] = vals[0]
+				}
+				continue
+			}
+			decoded[key] = ConstructMapFromCSV(vals[0])
+
+		case len(vals) > 1 && propSchema == nil:
+			var items []any
+			for _, v := range vals {
+				items = append(items, v)
+			}
+			decoded[key] = items
+
+		default:
+			decoded[key] = coerceForSchema(vals[0], propSchema)
+		}
+	}
+	for k, v := range deepObjects {
+		decoded[k] = v
+	}
+	return decoded
+}
+
+// explodeFormArray will split the values of an array field based on the encoding rules of the field.
+func explodeFormArray(values []string, enc *v3.Encoding) []string {
+	exploded := true
+	style := Form
+	if enc != nil {
+		if enc.Explode != nil {
+			exploded = *enc.Explode
+		}
+		if enc.Style != "" {
+			style = enc.Style
+		}
+	}
+	delimiter := Comma
+	switch style {
+	case SpaceDelimited:
+		delimiter = Space
+		exploded = false
+	case PipeDelimited:
+		delimiter = Pipe
+		exploded = false
+	}
+	if exploded {
+		return values
+	}
+	var split []string
+	for _, v := range values {
+		split = append(split, strings.Split(v, delimiter)...)
+	}
+	return split
+}
@@ -0,0 +1,119 @@
+// Copyright 2023 Princess B33f Heavy Industries / Dave Shanley
+// SPDX-License-Identifier: MIT
+
+package helpers
+
+import (
+	"bytes"
+	"encoding/json"
+	"io"
+	"strconv"
+	"strings"
+)
+
+var (
+	jsonPointerEscaper   = strings.NewReplacer("~", "~0", "/", "~1")
+	jsonPointerUnescaper = strings.NewReplacer("~1", "/", "~0", "~")
+)
+
+// UnmarshalJSON will decode a JSON document, numbers are decoded as a json.Number rather than a float64. Numbers are
+// validated as they were sent, so an integer that a float64 cannot represent (such as 9007199254740993) keeps its
+// precision. The errors returned are the same as json.Unmarshal.
+func UnmarshalJSON(data []byte) (any, error) {
+	decoder := json.NewDecoder(bytes.NewReader(data))
+	decoder.UseNumber()
+	var decoded any
+	if err := decoder.Decode(&decoded); err == nil {
+		if _, err = decoder.Token(); err == io.EOF {
+			return decoded, nil
+		}
+	}
+	// the document is not valid, json.Unmarshal explains why.
+	var discarded any
+	return nil, json.Unmarshal(data, &discarded)
+}
+
+// ValueAtPointer will return the value of a decoded JSON document found at a JSON Pointer (RFC 6901), such as
+// /burgers/0/name. The second return value is false if there is no value at the pointer.
+func ValueAtPointer(document any, pointer string) (any, bool) {
+	if pointer == "" {
+		return document, true
+	}
+	for _, token := range strings.Split(strings.TrimPrefix(pointer, Slash), Slash) {
+		token = jsonPointerUnescaper.Replace(token)
+		switch node := document.(type) {
+		case map[string]any:
+			value, ok := node[token]
+			if !ok {
+				return nil, false
+			}
+			document = value
+		case []any:
+			i, err := strconv.Atoi(token)
+			if err != nil || i < 0 || i >= len(node) {
+				return nil, false
+			}
+			document = node[i]
+		default:
+			return nil, false
+		}
+	}
+	return document, true
+}
+
+// DuplicatedKey is a key that is used more than once in the same object of a JSON document, the Pointer is the
+// JSON Pointer of the duplicate.
+type DuplicatedKey struct {
+	Key     string
+	Pointer string
+}
+
+// FindDuplicateKeys will stream through a JSON document and return every object key that has already been used in
+// the same object, in the order they appear. encoding/json keeps the last value of a duplicate key, so duplicates
+// are otherwise lost. An error is returned if the document is not valid JSON.
+func FindDuplicateKeys(body []byte) ([]DuplicatedKey, error) {
+	decoder := json.NewDecoder(bytes.NewReader(body))
+	decoder.UseNumber()
+	var duplicates []DuplicatedKey
+	if err := findDuplicateKeys(decoder, "", &duplicates); err != nil {
+		return nil, err
+	}
+	return duplicates, nil
+}
+
+func findDuplicateKeys(decoder *json.Decoder, pointer string, duplicates *[]DuplicatedKey) error {
+	token, err := decoder.Token()
+	if err != nil {
+		return err
+	}
+	switch token {
+	case json.Delim('{'):
+		seen := make(map[string]bool)
+		for decoder.More() {
+			key, err := decoder.Token()
+			if err != nil {
+				return err
+			}
+			name, _ := key.(string)
+			keyPointer := pointer + Slash + jsonPointerEscaper.Replace(name)
+			if seen[name] {
+				*duplicates = append(*duplicates, DuplicatedKey{Key: name, Pointer: keyPointer})
+			}
+			seen[name] = true
+			if err = findDuplicateKeys(decoder, keyPointer, duplicates); err != nil {
+				return err
+			}
+		}
+		_, err = decoder.Token()
+		return err
+	case json.Delim('['):
+		for i := 0; decoder.More(); i++ {
+			if err = findDuplicateKeys(decoder, pointer+Slash+strconv.Itoa(i), duplicates); err != nil {
+				return err
+			}
+		}
+		_, err = decoder.Token()
+		return err
+	}
+	return nil
+}
@@ -4,8 +4,12 @@
 package helpers
 
 import (
+	"fmt"
 	"github.com/pb33f/libopenapi/datamodel/high/v3"
 	"net/http"
+	"regexp"
+	"slices"
+	"strconv"
 	"strings"
 )
 
@@ -57,3 +61,167 @@ func ExtractContentType(contentType string) (string, string, string) {
 	}
 	return contentType, charset, boundary
 }
+
+// FindResponseByCode will locate the response for a status code. An exact match (e.g. '204') is preferred,
+// followed by a range match (e.g. '2XX'). The key of the matched response is returned along with the response,
+// nil and an empty key are returned if neither match. The 'default' response is not considered.
+func FindResponseByCode(responses *v3.Responses, code int) (*v3.Response, string) {
+	if responses == nil {
+		return nil, ""
+	}
+	codeStr := strconv.Itoa(code)
+	if r, ok := responses.Codes[codeStr]; ok {
+		return r, codeStr
+	}
+	for k, r := range responses.Codes {
+		if len(k) == 3 && k[0] == codeStr[0] && strings.EqualFold(k[1:], "XX") {
+			return r, k
+		}
+	}
+	return nil, ""
+}
+
+// FindMediaType will locate the media type definition in a content map that matches the supplied media type
+// (which should not include any parameters, see ExtractContentType). The most specific match wins:
+//
+//  1. an exact match (media types are case-insensitive)
+//  2. a structured suffix wildcard ('application/*+json' matches 'application/problem+json')
+//  3. the structured suffix itself ('application/json' matches 'application/problem+json')
+//  4. a subtype wildcard ('application/*' matches 'application/json')
+//  5. a full wildcard ('*/*')
+//
+// The key of the matched media type is returned along with the definition. If nothing matches, false is returned.
+func FindMediaType(content map[string]*v3.MediaType, mediaType string) (*v3.MediaType, string, bool) {
+	if mediaType == "" {
+		return nil, "", false
+	}
+	mediaType = strings.ToLower(mediaType)
+	mainType, subType, _ := strings.Cut(mediaType, Slash)
+
+	var candidates []string
+	candidates = append(candidates, mediaType)
+	if i := strings.LastIndex(subType, "+"); i > -1 {
+		suffix := subType[i+1:]
+		candidates = append(candidates,
+			fmt.Sprintf("%s/*+%s", mainType, suffix),
+			fmt.Sprintf("%s/%s", mainType, suffix))
+	}
+	candidates = append(candidates, fmt.Sprintf("%s/*", mainType), "*/*")
+
+	for _, candidate := range candidates {
+		for k, mt := range content {
+			if strings.EqualFold(strings.TrimSpace(k), candidate) {
+				return mt, k, true
+			}
+		}
+	}
+	return nil, "", false
+}
+
+// AcceptsMediaType will return true if the media type (e.g. application/json) is acceptable according to the
+// values of an Accept header. The most specific media range that matches (application/json, then application/*,
+// then */*) decides, a media range with a quality of 0 (e.g. 'text/html;q=0') is not acceptable. Every media type is
+// acceptable when there is no Accept header.
+func AcceptsMediaType(accept []string, mediaType string) bool {
+	mediaType = strings.ToLower(strings.TrimSpace(mediaType))
+	mainType, _, _ := strings.Cut(mediaType, Slash)
+	matched, specificity, acceptable := false, -1, false
+	for _, value := range accept {
+		for _, mediaRange := range strings.Split(value, Comma) {
+			params := strings.Split(mediaRange, SemiColon)
+			rangeType := strings.ToLower(strings.TrimSpace(params[0]))
+			if rangeType == "" {
+				continue
+			}
+			matched = true
+			var rangeSpecificity int
+			switch rangeType {
+			case mediaType:
+				rangeSpecificity = 2
+			case mainType + "/*":
+				rangeSpecificity = 1
+			case "*/*":
+				rangeSpecificity = 0
+			default:
+				continue
+			}
+			if rangeSpecificity <= specificity {
+				continue
+			}
+			specificity, acceptable = rangeSpecificity, true
+			for _, param := range params[1:] {
+				if k, v, ok := strings.Cut(param, Equals); ok && strings.TrimSpace(strings.ToLower(k)) == "q" {
+					if q, err := strconv.ParseFloat(strings.TrimSpace(v), 64); err == nil && q == 0 {
+						acceptable = false
+					}
+				}
+			}
+		}
+	}
+	return !matched || acceptable
+}
+
+// ndjsonMediaTypes are the media types used for newline delimited JSON, one JSON value per line.
+var ndjsonMediaTypes = []string{"application/x-ndjson", "application/ndjson", "application/jsonl",
+	"application/x-jsonlines", "application/jsonlines"}
+
+// IsNDJSONContentType will return true if the content type is newline delimited JSON (NDJSON, or JSON Lines), where
+// each line of the body is a JSON value of its own.
+func IsNDJSONContentType(contentType string) bool {
+	mediaType, _, _ := ExtractContentType(strings.ToLower(contentType))
+	return slices.Contains(ndjsonMediaTypes, mediaType)
+}
+
+// IsStructuredContentType will return true if the content type is JSON, XML or a form (URL encoded or multipart),
+// bodies of any other content type are treated as raw strings.
+func IsStructuredContentType(contentType string) bool {
+	contentType = strings.ToLower(contentType)
+	return strings.Contains(contentType, JSONType) ||
+		strings.Contains(contentType, XMLType) ||
+		strings.Contains(contentType, FormURLEncodedContentType) ||
+		strings.Contains(contentType, MultipartFormContentType)
+}
+
+// MediaTypeMatches will return true if the media type matches one of the declared media types. The declared media
+// types are a comma separated list (as used by the encoding object), and can contain wildcards (image/*, */*).
+func MediaTypeMatches(mediaType, declared string) bool {
+	mediaType = strings.ToLower(strings.TrimSpace(mediaType))
+	mainType, _, _ := strings.Cut(mediaType, Slash)
+	for _, d := range strings.Split(declared, Comma) {
+		d, _, _ = ExtractContentType(strings.ToLower(strings.TrimSpace(d)))
+		if d == mediaType || d == "*/*" || d == mainType+"/*" {
+			return true
+		}
+	}
+	return false
+}
+
+// FindCallbackPathItem will locate the PathItem of a callback that matches a (resolved) callback URL. The keys of a
+// callback are runtime expressions (e.g. '{$request.body#/callbackUrl}/data'), the expressions are treated as
+// wildcards, so the URL is matched against the rest of the key. When more than one key matches, the key with the
+// most literal characters is used. If nothing matches, nil is returned.
+func FindCallbackPathItem(callback *v3.Callback, callbackURL string) *v3.PathItem {
+	var found *v3.PathItem
+	best := -1
+	for expression, pathItem := range callback.Expression {
+		var pattern strings.Builder
+		literal := 0
+		rest := expression
+		for {
+			start := strings.Index(rest, "{")
+			end := strings.Index(rest, "}")
+			if start < 0 || end < start {
+				pattern.WriteString(regexp.QuoteMeta(rest))
+				literal += len(rest)
+				break
+			}
+			pattern.WriteString(regexp.QuoteMeta(rest[:start]) + ".*")
+			literal += start
+			rest = rest[end+1:]
+		}
+		if matched, _ := regexp.MatchString("^"+pattern.String()+"$", callbackURL); matched && literal > best {
+			found, best = pathItem, literal
+		}
+	}
+	return found
+}
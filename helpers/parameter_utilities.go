@@ -5,8 +5,12 @@ package helpers
 
 import (
 	"fmt"
+	"github.com/pb33f/libopenapi/datamodel/high/base"
 	v3 "github.com/pb33f/libopenapi/datamodel/high/v3"
 	"net/http"
+	"net/url"
+	"regexp"
+	"slices"
 	"strconv"
 	"strings"
 )
@@ -18,47 +22,124 @@ type QueryParam struct {
 	Key      string
 	Values   []string
 	Property string
+
+	// RawValues are the values as they were sent, before they were decoded (if known), in the same order as Values.
+	RawValues []string
 }
 
-// ExtractParamsForOperation will extract the parameters for the operation based on the request method.
-// Both the path level params and the method level params will be returned.
-func ExtractParamsForOperation(request *http.Request, item *v3.PathItem) []*v3.Parameter {
-	params := item.Parameters
-	switch request.Method {
-	case http.MethodGet:
-		if item.Get != nil {
-			params = append(params, item.Get.Parameters...)
+// ExtractRawQueryValues will split a raw query string into its values, keyed by the decoded key. The values are
+// kept as they were sent (percent-encoded), so it's possible to see which characters were not encoded. Values that
+// cannot be decoded are skipped (see ExtractMalformedQueryValues), the same as url.ParseQuery, so the values are
+// in the same order as the values of url.URL.Query.
+func ExtractRawQueryValues(rawQuery string) map[string][]string {
+	values := make(map[string][]string)
+	for _, pair := range strings.Split(rawQuery, "&") {
+		if pair == "" || strings.Contains(pair, ";") {
+			continue
+		}
+		key, value, _ := strings.Cut(pair, Equals)
+		if _, err := url.QueryUnescape(value); err != nil {
+			continue
 		}
-	case http.MethodPost:
-		if item.Post != nil {
-			params = append(params, item.Post.Parameters...)
+		if decoded, err := url.QueryUnescape(key); err == nil {
+			values[decoded] = append(values[decoded], value)
 		}
-	case http.MethodPut:
-		if item.Put != nil {
-			params = append(params, item.Put.Parameters...)
+	}
+	return values
+}
+
+// ExtractMalformedQueryValues will return the values of a raw query string that have a malformed percent-encoded
+// sequence (such as %zz, or a % at the end), keyed by the decoded key. url.URL.Query silently drops these values.
+func ExtractMalformedQueryValues(rawQuery string) map[string][]string {
+	values := make(map[string][]string)
+	for _, pair := range strings.Split(rawQuery, "&") {
+		if pair == "" || strings.Contains(pair, ";") {
+			continue
+		}
+		key, value, _ := strings.Cut(pair, Equals)
+		if _, err := url.QueryUnescape(value); err == nil {
+			continue
+		}
+		if decoded, err := url.QueryUnescape(key); err == nil {
+			values[decoded] = append(values[decoded], value)
+		}
+	}
+	return values
+}
+
+var pathTemplateRegex = regexp.MustCompile(`\{([^{}]+)}`)
+
+// ComparePathTemplate will compare the {name} tokens of a templated path (e.g. /pet/{petId}) with the path parameters
+// declared for it. The names of tokens that have no matching parameter are returned as undeclared, and parameters
+// that have no matching token are returned as unused. Names are case-sensitive, so {petID} does not match petId.
+func ComparePathTemplate(path string, params []*v3.Parameter) (undeclared []string, unused []*v3.Parameter) {
+	tokens := make(map[string]bool)
+	var names []string
+	for _, match := range pathTemplateRegex.FindAllStringSubmatch(path, -1) {
+		// strip label (.) and matrix (;) prefixes, and the explode (*) suffix.
+		name := strings.TrimSuffix(strings.TrimLeft(match[1], Period+SemiColon), Asterisk)
+		if !tokens[name] {
+			tokens[name] = true
+			names = append(names, name)
 		}
-	case http.MethodDelete:
-		if item.Delete != nil {
-			params = append(params, item.Delete.Parameters...)
+	}
+	declared := make(map[string]bool)
+	for _, param := range params {
+		if param.In != Path {
+			continue
 		}
-	case http.MethodOptions:
-		if item.Options != nil {
-			params = append(params, item.Options.Parameters...)
+		declared[param.Name] = true
+		if !tokens[param.Name] {
+			unused = append(unused, param)
 		}
-	case http.MethodHead:
-		if item.Head != nil {
-			params = append(params, item.Head.Parameters...)
+	}
+	for _, name := range names {
+		if !declared[name] {
+			undeclared = append(undeclared, name)
 		}
-	case http.MethodPatch:
-		if item.Patch != nil {
-			params = append(params, item.Patch.Parameters...)
+	}
+	return undeclared, unused
+}
+
+// ExtractParamsForOperation will extract the parameters for the operation based on the request method.
+// Both the path level params and the method level params will be returned, merged using MergeParameters.
+func ExtractParamsForOperation(request *http.Request, item *v3.PathItem) []*v3.Parameter {
+	var operationParams []*v3.Parameter
+	if operation := ExtractOperation(request, item); operation != nil {
+		operationParams = operation.Parameters
+	}
+	return MergeParameters(item.Parameters, operationParams)
+}
+
+// MergeParameters will merge the parameters of a path item with the parameters of one of its operations. A
+// parameter is identified by its name and location, an operation parameter overrides a path item parameter with
+// the same name and location (header names are not case-sensitive). Each parameter appears once, and a new slice
+// is always returned, so the parameters of the path item are never modified.
+func MergeParameters(pathParams, operationParams []*v3.Parameter) []*v3.Parameter {
+	params := make([]*v3.Parameter, 0, len(pathParams)+len(operationParams))
+	for _, param := range pathParams {
+		overridden := false
+		for _, override := range operationParams {
+			if sameParameter(param, override) {
+				overridden = true
+				break
+			}
 		}
-	case http.MethodTrace:
-		if item.Trace != nil {
-			params = append(params, item.Trace.Parameters...)
+		if !overridden {
+			params = append(params, param)
 		}
 	}
-	return params
+	return append(params, operationParams...)
+}
+
+func sameParameter(a, b *v3.Parameter) bool {
+	if a.In != b.In {
+		return false
+	}
+	if a.In == Header {
+		return strings.EqualFold(a.Name, b.Name)
+	}
+	return a.Name == b.Name
 }
 
 func cast(v string) any {
@@ -78,18 +159,121 @@ func cast(v string) any {
 	return v
 }
 
+// MatchEnum will check a raw parameter value against the values of an enum, and return the enum value that
+// matched (as a string). When caseInsensitive is true, string enum values are matched without regard to case,
+// so the canonical value is returned rather than the value that was sent.
+func MatchEnum(value string, enum []any, caseInsensitive bool) (string, bool) {
+	value = strings.TrimSpace(value)
+	for _, enumVal := range enum {
+		if value == fmt.Sprint(enumVal) {
+			return value, true
+		}
+	}
+	if caseInsensitive {
+		for _, enumVal := range enum {
+			if s, ok := enumVal.(string); ok && strings.EqualFold(value, s) {
+				return s, true
+			}
+		}
+	}
+	return "", false
+}
+
+// IsPrimitiveType will return true if every type supplied is a primitive (string, integer, number, boolean or null).
+func IsPrimitiveType(types []string) bool {
+	if len(types) == 0 {
+		return false
+	}
+	for _, t := range types {
+		switch t {
+		case String, Integer, Number, Boolean, Null:
+		default:
+			return false
+		}
+	}
+	return true
+}
+
+// SchemaTypes returns the types declared by the schema. An OpenAPI 3.0 schema marked as 'nullable' also has 'null'
+// as a type, so it is treated the same way as an OpenAPI 3.1 schema that lists 'null' in its types.
+func SchemaTypes(schema *base.Schema) []string {
+	if schema == nil {
+		return nil
+	}
+	if schema.Nullable == nil || !*schema.Nullable || len(schema.Type) == 0 || slices.Contains(schema.Type, Null) {
+		return schema.Type
+	}
+	return append(slices.Clone(schema.Type), Null)
+}
+
+// CoerceValue will convert a raw string value (from a query string, header etc.) into the first of the supplied
+// primitive types that it can represent. Types are tried in the order they are declared, so ["integer", "null"]
+// will convert '30' into an integer, and an empty value into nil. The second return value will be false if the
+// value cannot be converted into any of the types.
+func CoerceValue(value string, types []string) (any, bool) {
+	for _, t := range types {
+		switch t {
+		case Integer:
+			if i, err := strconv.ParseInt(value, 10, 64); err == nil {
+				return i, true
+			}
+		case Number:
+			if f, err := strconv.ParseFloat(value, 64); err == nil {
+				return f, true
+			}
+		case Boolean:
+			if value == "true" || value == "false" {
+				return value == "true", true
+			}
+		case Null:
+			if value == "" || value == Null {
+				return nil, true
+			}
+		case String:
+			return value, true
+		}
+	}
+	return nil, false
+}
+
+// coerceForSchema will convert a raw string value into the primitive type declared by the schema. If the value
+// cannot be converted (or the schema is not a primitive), the original string is returned so the schema can
+// report the problem.
+func coerceForSchema(value string, schema *base.Schema) any {
+	if schema != nil && IsPrimitiveType(schema.Type) {
+		if v, ok := CoerceValue(value, SchemaTypes(schema)); ok {
+			return v
+		}
+	}
+	return value
+}
+
 // ConstructParamMapFromDeepObjectEncoding will construct a map from the query parameters that are encoded as
 // deep objects. It's kind of a crazy way to do things, but hey, each to their own.
+//
+// Nested properties (filter[dimensions][w]=10) are stored with a Property of 'dimensions][w', each level is
+// re-constructed as a nested map.
 func ConstructParamMapFromDeepObjectEncoding(values []*QueryParam) map[string]interface{} {
 	// deepObject encoding is a technique used to encode objects into query parameters. Kinda nuts.
 	decoded := make(map[string]interface{})
 	for _, v := range values {
 		if decoded[v.Key] == nil {
-			props := make(map[string]interface{})
-			props[v.Property] = cast(v.Values[0])
-			decoded[v.Key] = props
-		} else {
-			decoded[v.Key].(map[string]interface{})[v.Property] = cast(v.Values[0])
+			decoded[v.Key] = make(map[string]interface{})
+		}
+		props := decoded[v.Key].(map[string]interface{})
+		segments := strings.Split(v.Property, "][")
+		for i, segment := range segments {
+			if i == len(segments)-1 {
+				props[segment] = cast(v.Values[0])
+				break
+			}
+			// a scalar and an object can't share the same name, the object wins.
+			next, ok := props[segment].(map[string]interface{})
+			if !ok {
+				next = make(map[string]interface{})
+				props[segment] = next
+			}
+			props = next
 		}
 	}
 	return decoded
@@ -201,6 +385,56 @@ func ConstructKVFromMatrixCSV(values string) map[string]interface{} {
 	return props
 }
 
+// ExtractMatrixParams will break a matrix style path segment (for example ';color=blue,black;size=large') into
+// a slice of QueryParam objects, one for each key found in the segment. Values are split on commas before they are
+// percent-decoded, so encoded separators (%3B, %3D, %2C) remain part of the value. A key without a value (';color')
+// will hold a single empty value. If the segment is not matrix encoded, or cannot be decoded, false is returned.
+func ExtractMatrixParams(segment string) ([]*QueryParam, bool) {
+	if !strings.HasPrefix(segment, SemiColon) {
+		return nil, false
+	}
+	var params []*QueryParam
+	for _, pair := range strings.Split(segment[1:], SemiColon) {
+		if pair == "" {
+			continue
+		}
+		rawKey, rawValue, _ := strings.Cut(pair, Equals)
+		key, err := url.PathUnescape(rawKey)
+		if err != nil || key == "" {
+			return nil, false
+		}
+		var values []string
+		for _, v := range strings.Split(rawValue, Comma) {
+			decoded, dErr := url.PathUnescape(v)
+			if dErr != nil {
+				return nil, false
+			}
+			values = append(values, decoded)
+		}
+		params = append(params, &QueryParam{Key: key, Values: values})
+	}
+	return params, true
+}
+
+// ConstructParamMapFromMatrixParams will construct a map from exploded matrix parameters, where each
+// key is a property name (';id=1234;vegetarian=false').
+func ConstructParamMapFromMatrixParams(values []*QueryParam) map[string]interface{} {
+	decoded := make(map[string]interface{})
+	for _, v := range values {
+		decoded[v.Key] = cast(strings.Join(v.Values, Comma))
+	}
+	return decoded
+}
+
+// ConstructMapFromValues will construct a map from an already exploded slice of alternating keys and values.
+func ConstructMapFromValues(values []string) map[string]interface{} {
+	decoded := make(map[string]interface{})
+	for i := 0; i+1 < len(values); i += 2 {
+		decoded[values[i]] = cast(values[i+1])
+	}
+	return decoded
+}
+
 // ConstructParamMapFromFormEncodingArray will construct a map from the query parameters that are encoded as
 // form encoded values.
 func ConstructParamMapFromFormEncodingArray(values []*QueryParam) map[string]interface{} {
@@ -252,3 +486,87 @@ func CollapseCSVIntoSpaceDelimitedStyle(key string, values []string) string {
 func CollapseCSVIntoPipeDelimitedStyle(key string, values []string) string {
 	return fmt.Sprintf("%s=%s", key, strings.Join(values, Pipe))
 }
+
+// DecodeHeaderValue will decode a header value using the simple style, which is the only style allowed for
+// headers. Values that cannot be coerced into the schema type are left as strings, so the schema can report them.
+func DecodeHeaderValue(value string, header *v3.Header, sch *base.Schema) any {
+	return DecodeSimpleValue(value, header.Explode, sch)
+}
+
+// DecodeSimpleValue will decode a value using the simple style. Arrays are comma separated (a,b,c) whether they
+// are exploded or not. Objects are comma separated keys and values (role,admin,scope,all), or key=value pairs
+// (role=admin,scope=all) when exploded. Items and properties are coerced into the type declared by their schema.
+// Items that cannot be coerced are left as strings, properties are converted into the type they look like, so the
+// schema can report them.
+func DecodeSimpleValue(value string, explode bool, sch *base.Schema) any {
+	switch {
+	case len(sch.Type) > 0 && sch.Type[0] == Array:
+		var itemSchema *base.Schema
+		if sch.Items != nil && sch.Items.IsA() {
+			itemSchema = sch.Items.A.Schema()
+		}
+		var items []any
+		for _, item := range strings.Split(value, Comma) {
+			items = append(items, coerceForSchema(strings.TrimSpace(item), itemSchema))
+		}
+		return items
+	case len(sch.Type) > 0 && sch.Type[0] == Object:
+		obj := make(map[string]any)
+		add := func(name, v string) {
+			if prop, ok := sch.Properties[name]; ok && prop != nil {
+				if propSchema := prop.Schema(); propSchema != nil && IsPrimitiveType(propSchema.Type) {
+					if coerced, ok := CoerceValue(v, SchemaTypes(propSchema)); ok {
+						obj[name] = coerced
+						return
+					}
+				}
+			}
+			obj[name] = cast(v)
+		}
+		segments := strings.Split(value, Comma)
+		for i := range segments {
+			segment := strings.TrimSpace(segments[i])
+			if explode {
+				if kv := strings.SplitN(segment, Equals, 2); len(kv) == 2 {
+					add(kv[0], kv[1])
+				}
+			} else if i%2 == 0 && i+1 < len(segments) {
+				add(segment, strings.TrimSpace(segments[i+1]))
+			}
+		}
+		return obj
+	}
+	return coerceForSchema(value, sch)
+}
+
+// NormalizePath will collapse duplicate slashes in a path and remove a trailing slash, so /pet//123/ becomes
+// /pet/123. The root path (/) is left as it is.
+func NormalizePath(path string) string {
+	if !strings.Contains(path, "//") && (len(path) < 2 || !strings.HasSuffix(path, Slash)) {
+		return path
+	}
+	segments := strings.Split(path, Slash)
+	normalized := make([]string, 0, len(segments))
+	for i, segment := range segments {
+		if segment != "" || i == 0 {
+			normalized = append(normalized, segment)
+		}
+	}
+	if len(normalized) == 1 && normalized[0] == "" {
+		return Slash
+	}
+	return strings.Join(normalized, Slash)
+}
+
+// SplitEscapedPath will split an escaped path (see url.URL.EscapedPath) into its segments, and decode each segment
+// on its own. An encoded slash (%2F) is part of the value of a segment, rather than a separator, so /pet/a%2Fb has
+// the segments 'pet' and 'a/b'. Segments that cannot be decoded are left as they are.
+func SplitEscapedPath(escapedPath string) []string {
+	segments := strings.Split(escapedPath, Slash)
+	for i, segment := range segments {
+		if decoded, err := url.PathUnescape(segment); err == nil {
+			segments[i] = decoded
+		}
+	}
+	return segments
+}
@@ -0,0 +1,183 @@
+// Copyright 2023 Princess B33f Heavy Industries / Dave Shanley
+// SPDX-License-Identifier: MIT
+
+package helpers
+
+import (
+	"encoding/base64"
+	"fmt"
+	"strings"
+	"sync"
+
+	"github.com/pb33f/libopenapi-validator/config"
+	"github.com/pb33f/libopenapi/datamodel/high/base"
+	lowbase "github.com/pb33f/libopenapi/datamodel/low/base"
+	"github.com/pb33f/libopenapi/utils"
+	"github.com/santhosh-tekuri/jsonschema/v5"
+)
+
+// NewCompiledSchema will compile a rendered JSON schema into a jsonschema.Schema that can be used to validate
+// values. Compiling a schema is expensive, so the result should be cached and re-used, a compiled schema
+// is safe for concurrent use. The options control how the schema is compiled, nil uses the defaults.
+func NewCompiledSchema(name string, jsonSchema []byte, options *config.ValidationOptions) (*jsonschema.Schema, error) {
+	compiler := jsonschema.NewCompiler()
+	ConfigureFormats(compiler, options)
+	ConfigureContent(compiler)
+	fName := fmt.Sprintf("%s.json", name)
+	if err := compiler.AddResource(fName, strings.NewReader(string(jsonSchema))); err != nil {
+		return nil, err
+	}
+	return compiler.Compile(fName)
+}
+
+// annotationFormats replaces every built-in format with a checker that always passes, so formats are only
+// annotations.
+var annotationFormats = func() map[string]func(any) bool {
+	formats := make(map[string]func(any) bool, len(jsonschema.Formats))
+	for name := range jsonschema.Formats {
+		formats[name] = func(any) bool { return true }
+	}
+	return formats
+}()
+
+// ConfigureFormats will set up how a compiler treats 'format'. When format assertions are enabled by the options,
+// string formats (date-time, email, uuid etc.) are validated. Otherwise, formats are treated as annotations, which
+// is the default for OpenAPI 3.1. The compiler would otherwise assert formats for any schema without a '$schema'.
+// Custom formats supplied by the options are always validated.
+func ConfigureFormats(compiler *jsonschema.Compiler, options *config.ValidationOptions) {
+	compiler.AssertFormat = true
+	if options == nil || (!options.FormatAssertions && len(options.Formats) == 0) {
+		compiler.Formats = annotationFormats
+		return
+	}
+	formats := make(map[string]func(any) bool)
+	if !options.FormatAssertions {
+		for name, fn := range annotationFormats {
+			formats[name] = fn
+		}
+	}
+	for name, fn := range options.Formats {
+		formats[name] = customFormat(fn)
+	}
+	compiler.Formats = formats
+}
+
+// contentDecoders are the content encodings that can be decoded, in addition to base64.
+var contentDecoders = map[string]func(string) ([]byte, error){
+	"base64url": decodeBase64URL,
+}
+
+// ConfigureContent will enable the contentEncoding, contentMediaType and contentSchema keywords (OpenAPI 3.1). A
+// string is decoded using its contentEncoding ('base64' or 'base64url'), the decoded content must be of the
+// contentMediaType, and JSON content is validated against the contentSchema. The compiler would otherwise treat
+// these keywords as annotations.
+func ConfigureContent(compiler *jsonschema.Compiler) {
+	compiler.AssertContent = true
+	for name, decoder := range contentDecoders {
+		compiler.Decoders[name] = decoder
+	}
+}
+
+// decodeBase64URL will decode URL safe base64, with or without padding (a JWT has no padding).
+func decodeBase64URL(s string) ([]byte, error) {
+	return base64.RawURLEncoding.DecodeString(strings.TrimRight(s, "="))
+}
+
+// customFormat will adapt a custom string format into a jsonschema format, anything that isn't a string passes.
+func customFormat(fn func(string) bool) func(any) bool {
+	return func(v any) bool {
+		s, ok := v.(string)
+		if !ok {
+			return true
+		}
+		return fn(s)
+	}
+}
+
+// SchemaCacheKey will return a key that identifies a schema, so it can be compiled once and cached. References are
+// keyed by their $ref location (so every use of a component shares the same compiled schema), inline schemas are
+// keyed by their node in the document.
+func SchemaCacheKey(schema *lowbase.SchemaProxy) any {
+	if schema.IsSchemaReference() {
+		return schema.GetSchemaReference()
+	}
+	return schema.GetValueNode()
+}
+
+// SchemaCache holds compiled schemas keyed by SchemaCacheKey, so each schema is rendered and compiled once, using
+// the options the cache was created with. It's used for the schemas of parameters and headers, that are validated
+// against values rather than bodies. A SchemaCache is safe for concurrent use, a nil SchemaCache compiles every
+// schema using the default options, without caching it.
+type SchemaCache struct {
+	options *config.ValidationOptions
+	schemas sync.Map // schema key (SchemaCacheKey) -> *compiledSchema
+}
+
+// compiledSchema is a cached schema, or the reason it cannot be compiled.
+type compiledSchema struct {
+	schema *jsonschema.Schema
+	err    error
+}
+
+// NewSchemaCache will create an empty SchemaCache, schemas are compiled using the options.
+func NewSchemaCache(options *config.ValidationOptions) *SchemaCache {
+	return &SchemaCache{options: options}
+}
+
+// Compile will return the compiled schema, rendering (and converting any OpenAPI 3.0 keywords) and compiling it the
+// first time it's asked for. A schema that cannot be compiled is cached too, the error is returned every time.
+func (c *SchemaCache) Compile(name string, schema *base.Schema) (*jsonschema.Schema, error) {
+	if c == nil {
+		return compileValueSchema(name, schema, nil)
+	}
+	key := valueSchemaKey(schema)
+	if cacheHit, ok := c.schemas.Load(key); ok {
+		return cacheHit.(*compiledSchema).schema, cacheHit.(*compiledSchema).err
+	}
+	compiled := &compiledSchema{}
+	compiled.schema, compiled.err = compileValueSchema(name, schema, c.options)
+
+	// if another request compiled the same schema in the meantime, use that one.
+	cached, _ := c.schemas.LoadOrStore(key, compiled)
+	return cached.(*compiledSchema).schema, cached.(*compiledSchema).err
+}
+
+// compileValueSchema will render a schema as JSON, convert it into JSON Schema and compile it.
+func compileValueSchema(name string, schema *base.Schema, options *config.ValidationOptions) (*jsonschema.Schema, error) {
+	renderedSchema, _ := RenderSchema(schema)
+	jsonSchema, _ := utils.ConvertYAMLtoJSON(renderedSchema)
+	jsonSchema = ConvertExclusiveBounds(ConvertNullable(jsonSchema))
+	return NewCompiledSchema(name, jsonSchema, options)
+}
+
+// valueSchemaKey will return the key of a schema (see SchemaCacheKey), schemas that were not built from a document
+// are keyed by themselves.
+func valueSchemaKey(schema *base.Schema) any {
+	if schema.ParentProxy != nil && schema.ParentProxy.GoLow() != nil {
+		return SchemaCacheKey(schema.ParentProxy.GoLow())
+	}
+	return schema
+}
+
+// renderLock is shared by everything that renders schemas, libopenapi builds schemas lazily while rendering and
+// mutates state as it goes, so schemas cannot be rendered concurrently.
+var renderLock = &sync.Mutex{}
+
+// RenderSchema will render a schema as YAML, it's safe to call from multiple goroutines.
+func RenderSchema(schema *base.Schema) ([]byte, error) {
+	renderLock.Lock()
+	defer renderLock.Unlock()
+	return schema.Render()
+}
+
+// RenderSchemaInline will render a schema as YAML with all references inlined, it's safe to call from
+// multiple goroutines. Circular references cannot be inlined, they are rendered as references to definitions
+// under '$defs' instead, and keywords libopenapi does not model are kept (see renderSchemaNodes).
+func RenderSchemaInline(schema *base.Schema) ([]byte, error) {
+	renderLock.Lock()
+	defer renderLock.Unlock()
+	if rendered, ok := renderSchemaNodes(schema); ok {
+		return rendered, nil
+	}
+	return schema.RenderInline()
+}
@@ -0,0 +1,187 @@
+// Copyright 2023 Princess B33f Heavy Industries / Dave Shanley
+// SPDX-License-Identifier: MIT
+
+package helpers
+
+import (
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/pb33f/libopenapi/datamodel/high/base"
+	"github.com/pb33f/libopenapi/index"
+	"gopkg.in/yaml.v3"
+)
+
+const definitions = "$defs"
+
+// definitionName matches the characters that cannot be used in the name of a definition.
+var definitionName = regexp.MustCompile(`[^A-Za-z0-9_.-]`)
+
+// unmodelledKeywords are JSON Schema keywords that libopenapi does not model, so they are lost when a schema is
+// rendered by libopenapi.
+var unmodelledKeywords = map[string]bool{
+	"contentEncoding":  true,
+	"contentMediaType": true,
+	"contentSchema":    true,
+}
+
+// nodeRenderer renders a schema from the nodes of the document, with every reference inlined, so every keyword is
+// kept. A reference that points back to a schema that is still being rendered (a circular reference) cannot be
+// inlined, so it's rendered as a reference to a definition under '$defs' instead. The definition is rendered once
+// and the compiled subschema is re-used for every level of recursion, so the rendered schema is finite.
+type nodeRenderer struct {
+	rendering   map[string]bool       // references that are currently being rendered, keyed by full definition.
+	names       map[string]string     // definition names, keyed by full definition.
+	definitions map[string]*yaml.Node // rendered definitions, keyed by definition name.
+	pending     []*index.Reference    // circular references that still need a definition rendered.
+	taken       map[string]bool
+	unmodelled  bool // true if the schema uses a keyword that libopenapi does not model.
+}
+
+// renderSchemaNodes will render a schema as YAML with all references inlined, if the schema contains circular
+// references or uses keywords that libopenapi does not model. Otherwise (or if the schema cannot be walked), false
+// is returned and the schema should be rendered by libopenapi instead.
+func renderSchemaNodes(schema *base.Schema) ([]byte, bool) {
+	low := schema.GoLow()
+	if low == nil || low.ParentProxy == nil || low.Index == nil || low.ParentProxy.GetValueNode() == nil {
+		return nil, false
+	}
+	r := &nodeRenderer{
+		rendering:   make(map[string]bool),
+		names:       make(map[string]string),
+		definitions: make(map[string]*yaml.Node),
+		taken:       make(map[string]bool),
+	}
+	root := r.render(low.ParentProxy.GetValueNode(), low.Index)
+	if (len(r.pending) == 0 && !r.unmodelled) || root == nil || root.Kind != yaml.MappingNode {
+		return nil, false
+	}
+
+	// render every definition that is referenced, each one may be circular itself and add more definitions.
+	for len(r.pending) > 0 {
+		ref := r.pending[0]
+		r.pending = r.pending[1:]
+		name := r.names[ref.FullDefinition]
+		if _, done := r.definitions[name]; done {
+			continue
+		}
+		r.rendering[ref.FullDefinition] = true
+		r.definitions[name] = r.render(ref.Node, ref.Index)
+		delete(r.rendering, ref.FullDefinition)
+	}
+
+	names := make([]string, 0, len(r.definitions))
+	for name := range r.definitions {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	defs := &yaml.Node{Kind: yaml.MappingNode, Tag: "!!map"}
+	for _, name := range names {
+		defs.Content = append(defs.Content, scalarNode(name), r.definitions[name])
+	}
+	if len(defs.Content) > 0 {
+		root.Content = append(root.Content, scalarNode(definitions), defs)
+	}
+	rendered, err := yaml.Marshal(root)
+	if err != nil {
+		return nil, false
+	}
+	return rendered, true
+}
+
+// render will return a copy of the node, with every reference replaced by the schema it refers to.
+func (r *nodeRenderer) render(node *yaml.Node, idx *index.SpecIndex) *yaml.Node {
+	if node == nil {
+		return nil
+	}
+	switch node.Kind {
+	case yaml.DocumentNode, yaml.AliasNode:
+		if len(node.Content) > 0 {
+			return r.render(node.Content[0], idx)
+		}
+		if node.Alias != nil {
+			return r.render(node.Alias, idx)
+		}
+		return nil
+	case yaml.MappingNode:
+		if ref := referenceValue(node); ref != "" && idx != nil {
+			return r.renderReference(node, ref, idx)
+		}
+		for i := 0; i < len(node.Content); i += 2 {
+			r.unmodelled = r.unmodelled || unmodelledKeywords[node.Content[i].Value]
+		}
+		fallthrough
+	case yaml.SequenceNode:
+		rendered := *node
+		rendered.Content = make([]*yaml.Node, len(node.Content))
+		for i, n := range node.Content {
+			rendered.Content[i] = r.render(n, idx)
+		}
+		return &rendered
+	default:
+		rendered := *node
+		return &rendered
+	}
+}
+
+// renderReference will inline the schema a reference points to, unless that schema is already being rendered, then
+// the reference is replaced with a reference to its definition.
+func (r *nodeRenderer) renderReference(node *yaml.Node, ref string, idx *index.SpecIndex) *yaml.Node {
+	found, foundIdx := idx.SearchIndexForReference(ref)
+	if found == nil || found.Node == nil {
+		rendered := *node
+		return &rendered
+	}
+	if foundIdx == nil {
+		foundIdx = idx
+	}
+	key := found.FullDefinition
+	if key == "" {
+		key = ref
+	}
+	if r.rendering[key] {
+		return &yaml.Node{Kind: yaml.MappingNode, Tag: "!!map", Content: []*yaml.Node{
+			scalarNode("$ref"), scalarNode(fmt.Sprintf("#/%s/%s", definitions, r.definitionName(key, found.Node, foundIdx))),
+		}}
+	}
+	r.rendering[key] = true
+	defer delete(r.rendering, key)
+	return r.render(found.Node, foundIdx)
+}
+
+// definitionName will return the name used for the definition of a circular reference, it's derived from the
+// last segment of the reference and is unique within the rendered schema.
+func (r *nodeRenderer) definitionName(key string, node *yaml.Node, idx *index.SpecIndex) string {
+	if name, ok := r.names[key]; ok {
+		return name
+	}
+	segments := strings.Split(key, "/")
+	prefix := definitionName.ReplaceAllString(segments[len(segments)-1], "_")
+	if prefix == "" {
+		prefix = "schema"
+	}
+	name := prefix
+	for i := 2; r.taken[name]; i++ {
+		name = fmt.Sprintf("%s_%d", prefix, i)
+	}
+	r.taken[name] = true
+	r.names[key] = name
+	r.pending = append(r.pending, &index.Reference{FullDefinition: key, Node: node, Index: idx})
+	return name
+}
+
+// referenceValue returns the value of the $ref held by a mapping node, or an empty string if it does not hold one.
+func referenceValue(node *yaml.Node) string {
+	for i := 0; i+1 < len(node.Content); i += 2 {
+		if node.Content[i].Value == "$ref" && node.Content[i+1].Kind == yaml.ScalarNode {
+			return node.Content[i+1].Value
+		}
+	}
+	return ""
+}
+
+func scalarNode(value string) *yaml.Node {
+	return &yaml.Node{Kind: yaml.ScalarNode, Tag: "!!str", Value: value}
+}
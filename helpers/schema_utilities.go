@@ -0,0 +1,209 @@
+// Copyright 2023 Princess B33f Heavy Industries / Dave Shanley
+// SPDX-License-Identifier: MIT
+
+package helpers
+
+import (
+	"bytes"
+	"encoding/json"
+	"github.com/pb33f/libopenapi/datamodel/high/base"
+)
+
+const (
+	readOnly  = "readOnly"
+	writeOnly = "writeOnly"
+	nullable  = "nullable"
+)
+
+// PrepareRequestSchema will adjust a rendered JSON schema for validating request bodies. Properties marked as
+// readOnly are set by the server, so clients are not required to send them, they are removed from 'required'.
+// Nullable and boolean exclusive bounds (OpenAPI 3.0) are converted into JSON Schema.
+func PrepareRequestSchema(jsonSchema []byte) []byte {
+	return adjustSchemaAccess(ConvertExclusiveBounds(ConvertNullable(jsonSchema)), readOnly, false)
+}
+
+// PrepareResponseSchema will adjust a rendered JSON schema for validating response bodies. Properties marked as
+// writeOnly are never returned by the server, so they are removed from 'required'. If rejectWriteOnly is true,
+// writeOnly properties are not allowed to appear in a response at all. Nullable and boolean exclusive bounds
+// (OpenAPI 3.0) are converted into JSON Schema.
+func PrepareResponseSchema(jsonSchema []byte, rejectWriteOnly bool) []byte {
+	return adjustSchemaAccess(ConvertExclusiveBounds(ConvertNullable(jsonSchema)), writeOnly, rejectWriteOnly)
+}
+
+// adjustSchemaAccess will remove properties marked with the access keyword (readOnly or writeOnly) from 'required',
+// and optionally replace the property schema with 'false' so the property is rejected.
+func adjustSchemaAccess(jsonSchema []byte, keyword string, reject bool) []byte {
+	if !bytes.Contains(jsonSchema, []byte(keyword)) {
+		return jsonSchema
+	}
+	var decoded any
+	if err := json.Unmarshal(jsonSchema, &decoded); err != nil {
+		return jsonSchema
+	}
+	walkSchemaAccess(decoded, keyword, reject)
+	adjusted, err := json.Marshal(decoded)
+	if err != nil {
+		return jsonSchema
+	}
+	return adjusted
+}
+
+func walkSchemaAccess(node any, keyword string, reject bool) {
+	switch n := node.(type) {
+	case []any:
+		for _, item := range n {
+			walkSchemaAccess(item, keyword, reject)
+		}
+	case map[string]any:
+		for _, v := range n {
+			walkSchemaAccess(v, keyword, reject)
+		}
+		props, ok := n["properties"].(map[string]any)
+		if !ok {
+			return
+		}
+		marked := make(map[string]bool)
+		for name, prop := range props {
+			if p, isMap := prop.(map[string]any); isMap && p[keyword] == true {
+				marked[name] = true
+				if reject {
+					props[name] = false
+				}
+			}
+		}
+		if required, isArray := n["required"].([]any); isArray && len(marked) > 0 {
+			filtered := make([]any, 0, len(required))
+			for _, r := range required {
+				if name, isString := r.(string); !isString || !marked[name] {
+					filtered = append(filtered, r)
+				}
+			}
+			n["required"] = filtered
+		}
+	}
+}
+
+// schemaMapKeywords are the keywords that hold a map of schemas (keyed by name), rather than a schema.
+var schemaMapKeywords = map[string]bool{
+	"properties":        true,
+	"patternProperties": true,
+	"dependentSchemas":  true,
+	"definitions":       true,
+	"$defs":             true,
+}
+
+// ConvertNullable will translate the OpenAPI 3.0 'nullable' keyword of a rendered JSON schema into JSON Schema, which
+// has no 'nullable'. A schema with 'nullable: true' has 'null' added to its type (and its enum, if it has one), a
+// schema without a type becomes an anyOf that also allows null. Without this, null is rejected by a nullable schema.
+func ConvertNullable(jsonSchema []byte) []byte {
+	return convertSchemas(jsonSchema, nullable, convertNullable)
+}
+
+// ConvertExclusiveBounds will translate the OpenAPI 3.0 boolean form of 'exclusiveMinimum' and 'exclusiveMaximum'
+// into the numeric form used by JSON Schema (and OpenAPI 3.1). 'minimum: 5' with 'exclusiveMinimum: true' becomes
+// 'exclusiveMinimum: 5', and 'exclusiveMinimum: false' is removed (leaving 'minimum: 5'). Without this, schemas
+// using the boolean form cannot be compiled. A boolean bound without a minimum (or maximum) is not converted.
+func ConvertExclusiveBounds(jsonSchema []byte) []byte {
+	return convertSchemas(jsonSchema, "exclusiveM", convertExclusiveBounds)
+}
+
+// convertSchemas will decode a rendered JSON schema and convert it (and every schema nested inside it), if it
+// contains the keyword. The schema is returned untouched if it cannot be decoded.
+func convertSchemas(jsonSchema []byte, keyword string, convert func(map[string]any) any) []byte {
+	if !bytes.Contains(jsonSchema, []byte(keyword)) {
+		return jsonSchema
+	}
+	var decoded any
+	if err := json.Unmarshal(jsonSchema, &decoded); err != nil {
+		return jsonSchema
+	}
+	decoded = walkSchemas(decoded, convert)
+	converted, err := json.Marshal(decoded)
+	if err != nil {
+		return jsonSchema
+	}
+	return converted
+}
+
+// walkSchemas will convert the schema, and every schema nested inside it, returning the converted schema.
+func walkSchemas(node any, convert func(map[string]any) any) any {
+	switch n := node.(type) {
+	case []any:
+		for i := range n {
+			n[i] = walkSchemas(n[i], convert)
+		}
+		return n
+	case map[string]any:
+		for k, v := range n {
+			if schemas, ok := v.(map[string]any); ok && schemaMapKeywords[k] {
+				for name := range schemas {
+					schemas[name] = walkSchemas(schemas[name], convert)
+				}
+				continue
+			}
+			n[k] = walkSchemas(v, convert)
+		}
+		return convert(n)
+	}
+	return node
+}
+
+func convertNullable(n map[string]any) any {
+	isNullable, ok := n[nullable].(bool)
+	if !ok {
+		return n
+	}
+	delete(n, nullable)
+	if !isNullable {
+		return n
+	}
+	if enum, isArray := n["enum"].([]any); isArray {
+		n["enum"] = append(enum, nil)
+	}
+	switch t := n["type"].(type) {
+	case string:
+		n["type"] = []any{t, Null}
+	case []any:
+		for _, existing := range t {
+			if existing == Null {
+				return n
+			}
+		}
+		n["type"] = append(t, Null)
+	default:
+		return map[string]any{"anyOf": []any{map[string]any{"type": Null}, n}}
+	}
+	return n
+}
+
+func convertExclusiveBounds(n map[string]any) any {
+	for exclusive, bound := range map[string]string{"exclusiveMinimum": "minimum", "exclusiveMaximum": "maximum"} {
+		isExclusive, ok := n[exclusive].(bool)
+		limit, hasLimit := n[bound]
+		// a numeric bound in a 3.0 document is read as false, without a minimum (or maximum) to apply it to, it
+		// is left in place so the schema cannot be compiled, rather than silently dropping the bound.
+		if !ok || !hasLimit {
+			continue
+		}
+		delete(n, exclusive)
+		if isExclusive {
+			n[exclusive] = limit
+			delete(n, bound)
+		}
+	}
+	return n
+}
+
+// IsStringSchema will return true if the schema is a string schema, bodies that are not structured (such as
+// text/plain) can only be validated against string schemas.
+func IsStringSchema(schema *base.Schema) bool {
+	if schema == nil {
+		return false
+	}
+	for _, t := range schema.Type {
+		if t == String {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,239 @@
+// Copyright 2023 Princess B33f Heavy Industries / Dave Shanley
+// SPDX-License-Identifier: MIT
+
+package helpers
+
+import (
+	"bytes"
+	"encoding/xml"
+	"errors"
+	"io"
+	"strings"
+
+	"github.com/pb33f/libopenapi/datamodel/high/base"
+)
+
+// xmlElement is a generic representation of a parsed XML element.
+type xmlElement struct {
+	name     string
+	attrs    map[string]string
+	children []*xmlElement
+	text     string
+}
+
+// DecodeXML will decode an XML document into a structure that can be validated against a JSON Schema. The schema
+// is used to guide the conversion, so the 'xml' object hints (name, attribute and wrapped) on the schema and its
+// properties are honored, and values are converted into the types declared by the schema. Elements that are not
+// described by the schema are still decoded (as strings, or maps), so they can be reported by the schema.
+//
+// An error is returned if the XML cannot be parsed.
+func DecodeXML(body []byte, schema *base.Schema) (any, error) {
+	root, err := parseXML(body)
+	if err != nil {
+		return nil, err
+	}
+	return convertXMLElement(root, schema), nil
+}
+
+// parseXML will parse an XML document into a tree of xmlElement, returning the root element.
+func parseXML(body []byte) (*xmlElement, error) {
+	decoder := xml.NewDecoder(bytes.NewReader(body))
+	var stack []*xmlElement
+	var root *xmlElement
+	for {
+		tok, err := decoder.Token()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, err
+		}
+		switch t := tok.(type) {
+		case xml.StartElement:
+			if root != nil && len(stack) == 0 {
+				return nil, errors.New("XML document contains more than one root element")
+			}
+			el := &xmlElement{name: t.Name.Local, attrs: make(map[string]string)}
+			for _, a := range t.Attr {
+				if a.Name.Space == "xmlns" || a.Name.Local == "xmlns" {
+					continue
+				}
+				el.attrs[a.Name.Local] = a.Value
+			}
+			if len(stack) > 0 {
+				parent := stack[len(stack)-1]
+				parent.children = append(parent.children, el)
+			} else {
+				root = el
+			}
+			stack = append(stack, el)
+		case xml.EndElement:
+			stack = stack[:len(stack)-1]
+		case xml.CharData:
+			if len(stack) > 0 {
+				stack[len(stack)-1].text += string(t)
+			}
+		}
+	}
+	if root == nil {
+		return nil, errors.New("XML document does not contain a root element")
+	}
+	return root, nil
+}
+
+// xmlName will return the XML name for a property, the schema xml name overrides the property name.
+func xmlName(name string, schema *base.Schema) string {
+	if schema != nil && schema.XML != nil && schema.XML.Name != "" {
+		return schema.XML.Name
+	}
+	return name
+}
+
+// xmlProperties will collect the properties of a schema, including those of any allOf schemas.
+func xmlProperties(schema *base.Schema) map[string]*base.Schema {
+	props := make(map[string]*base.Schema)
+	if schema == nil {
+		return props
+	}
+	for k, v := range schema.Properties {
+		props[k] = v.Schema()
+	}
+	for _, a := range schema.AllOf {
+		for k, v := range xmlProperties(a.Schema()) {
+			props[k] = v
+		}
+	}
+	return props
+}
+
+// xmlItemsSchema will return the items schema for an array schema, or nil if there isn't one.
+func xmlItemsSchema(schema *base.Schema) *base.Schema {
+	if schema != nil && schema.Items != nil && schema.Items.IsA() {
+		return schema.Items.A.Schema()
+	}
+	return nil
+}
+
+func isXMLType(schema *base.Schema, t string) bool {
+	if schema == nil {
+		return false
+	}
+	for _, st := range schema.Type {
+		if st == t {
+			return true
+		}
+	}
+	return false
+}
+
+// convertXMLElement will convert an element into a value, guided by the schema.
+func convertXMLElement(el *xmlElement, schema *base.Schema) any {
+	props := xmlProperties(schema)
+	switch {
+	case isXMLType(schema, Array):
+		// the element is the wrapper, each child is an item.
+		items := make([]any, 0, len(el.children))
+		itemSchema := xmlItemsSchema(schema)
+		for _, child := range el.children {
+			items = append(items, convertXMLElement(child, itemSchema))
+		}
+		return items
+
+	case isXMLType(schema, Object) || len(props) > 0:
+		return convertXMLObject(el, props)
+
+	case schema != nil && len(schema.Type) > 0:
+		return coerceForSchema(strings.TrimSpace(el.text), schema)
+	}
+
+	// no schema to guide the conversion, elements with children (or attributes) become maps.
+	if len(el.children) == 0 && len(el.attrs) == 0 {
+		return strings.TrimSpace(el.text)
+	}
+	return convertXMLObject(el, props)
+}
+
+// convertXMLObject will convert an element into a map, properties are located using their xml hints, any
+// children or attributes not described by a property are added using their own names.
+func convertXMLObject(el *xmlElement, props map[string]*base.Schema) map[string]any {
+	obj := make(map[string]any)
+	consumed := make(map[*xmlElement]bool)
+	consumedAttrs := make(map[string]bool)
+
+	for name, propSchema := range props {
+		n := xmlName(name, propSchema)
+
+		// attributes
+		if propSchema != nil && propSchema.XML != nil && propSchema.XML.Attribute {
+			if v, ok := el.attrs[n]; ok {
+				obj[name] = coerceForSchema(v, propSchema)
+				consumedAttrs[n] = true
+			}
+			continue
+		}
+
+		// arrays are either wrapped in an element, or are repeated elements (the default).
+		if isXMLType(propSchema, Array) {
+			itemSchema := xmlItemsSchema(propSchema)
+			if propSchema.XML != nil && propSchema.XML.Wrapped {
+				for _, child := range el.children {
+					if child.name == n && !consumed[child] {
+						consumed[child] = true
+						obj[name] = convertXMLElement(child, propSchema)
+						break
+					}
+				}
+				continue
+			}
+			itemName := n
+			if itemSchema != nil && itemSchema.XML != nil && itemSchema.XML.Name != "" {
+				itemName = itemSchema.XML.Name
+			}
+			var items []any
+			for _, child := range el.children {
+				if child.name == itemName && !consumed[child] {
+					consumed[child] = true
+					items = append(items, convertXMLElement(child, itemSchema))
+				}
+			}
+			if items != nil {
+				obj[name] = items
+			}
+			continue
+		}
+
+		for _, child := range el.children {
+			if child.name == n && !consumed[child] {
+				consumed[child] = true
+				obj[name] = convertXMLElement(child, propSchema)
+				break
+			}
+		}
+	}
+
+	// anything left over is not described by the schema, add it so additionalProperties can be checked.
+	for k, v := range el.attrs {
+		if !consumedAttrs[k] {
+			if _, exists := obj[k]; !exists {
+				obj[k] = v
+			}
+		}
+	}
+	for _, child := range el.children {
+		if consumed[child] {
+			continue
+		}
+		value := convertXMLElement(child, nil)
+		if existing, exists := obj[child.name]; exists {
+			// repeated elements become an array.
+			if arr, ok := existing.([]any); ok {
+				obj[child.name] = append(arr, value)
+			} else {
+				obj[child.name] = []any{existing, value}
+			}
+			continue
+		}
+		obj[child.name] = value
+	}
+	return obj
+}
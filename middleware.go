@@ -0,0 +1,250 @@
+// Copyright 2023 Princess B33f Heavy Industries / Dave Shanley
+// SPDX-License-Identifier: MIT
+
+package validator
+
+import (
+	"bytes"
+	"github.com/pb33f/libopenapi-validator/errors"
+	"github.com/pb33f/libopenapi-validator/helpers"
+	"io"
+	"net/http"
+)
+
+// Middleware will create net/http middleware that validates every request using the Validator before it reaches
+// the next handler. If validation fails, onError is called (and the next handler is not), otherwise the request
+// is passed through. The request body is buffered and restored, so the next handler can still read it. When the
+// Validator was created with config.WithMaxBodyBytes, no more than that is buffered, and a larger body is rejected
+// (using onError) with a single errors.RequestBodyTooLarge error, before it's read in full.
+//
+// If onError is nil, failed requests are answered with a 400 (Bad Request) and the validation errors as an
+// RFC 7807 problem details object (see errors.ToProblemJSON).
+func Middleware(v Validator,
+	onError func(http.ResponseWriter, *http.Request, []*errors.ValidationError)) func(http.Handler) http.Handler {
+
+	if onError == nil {
+		onError = writeProblemJSON
+	}
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+
+			// buffer the body, so it can be read by validation, and again by the next handler.
+			rewind, err := BufferRequestBodyWithLimit(r, maxBodyBytes(v))
+			if err != nil {
+				if tooLarge, ok := err.(*errors.ValidationError); ok {
+					onError(w, r, []*errors.ValidationError{tooLarge})
+					return
+				}
+				http.Error(w, err.Error(), http.StatusBadRequest)
+				return
+			}
+			if valid, validationErrors := v.ValidateHttpRequestWithContext(r.Context(), r); !valid {
+				onError(w, r, validationErrors)
+				return
+			}
+			rewind()
+			next.ServeHTTP(w, r)
+		})
+	}
+}
+
+// ResponseMiddleware will create net/http middleware that validates the request, and the response written by the
+// next handler, after the handler returns. Violations are passed to onViolation (for logging), the response is never
+// changed or blocked, and is streamed to the client as it's written.
+//
+// The response body is captured so it can be validated, maxBodySize caps the number of bytes captured (0 or less
+// captures everything). If a response body is larger than maxBodySize, only the request is validated. When the
+// Validator was created with config.WithMaxBodyBytes, a request body larger than that is not buffered or validated,
+// a single errors.RequestBodyTooLarge error is passed to onViolation, and the request is still served.
+func ResponseMiddleware(v Validator,
+	onViolation func(*http.Request, []*errors.ValidationError),
+	maxBodySize int) func(http.Handler) http.Handler {
+
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+
+			// buffer the body, so it can be read by the next handler, and again by validation.
+			rewind, err := BufferRequestBodyWithLimit(r, maxBodyBytes(v))
+			if err != nil {
+				tooLarge, ok := err.(*errors.ValidationError)
+				if !ok {
+					http.Error(w, err.Error(), http.StatusBadRequest)
+					return
+				}
+				if onViolation != nil {
+					onViolation(r, []*errors.ValidationError{tooLarge})
+				}
+				next.ServeHTTP(w, r)
+				return
+			}
+			capture := NewResponseCapture(w, maxBodySize)
+			next.ServeHTTP(capture, r)
+
+			rewind()
+			if valid, validationErrors := ValidateCapturedResponse(v, r, capture); !valid && onViolation != nil {
+				onViolation(r, validationErrors)
+			}
+		})
+	}
+}
+
+// BufferRequestBody will read the body of the request into memory, and replace it with a copy, so it can be read
+// more than once. The returned rewind function resets the body back to the start, call it before each read.
+//
+// Together with ResponseCapture and ValidateCapturedResponse, it's the building block for adapters to web
+// frameworks that wrap net/http, (gin, echo, chi etc.) for example, a gin middleware looks like:
+//
+//	func(c *gin.Context) {
+//		rewind, _ := validator.BufferRequestBody(c.Request)
+//		if valid, errs := v.ValidateHttpRequest(c.Request); !valid {
+//			c.AbortWithStatusJSON(http.StatusBadRequest, errors.NewProblemDetails(errs, http.StatusBadRequest))
+//			return
+//		}
+//		rewind()
+//		c.Next()
+//	}
+//
+// The whole body is read, use BufferRequestBodyWithLimit to cap the size of the body that is buffered.
+func BufferRequestBody(request *http.Request) (rewind func(), err error) {
+	return BufferRequestBodyWithLimit(request, 0)
+}
+
+// BufferRequestBodyWithLimit is the same as BufferRequestBody, but no more than maxBytes of the body are read
+// (0 or less reads everything). If the body is larger, an errors.RequestBodyTooLarge error (a *ValidationError) is
+// returned, and the body of the request is left so it can still be read in full, from the start.
+func BufferRequestBodyWithLimit(request *http.Request, maxBytes int64) (rewind func(), err error) {
+	if request.Body == nil || request.Body == http.NoBody {
+		return func() {}, nil
+	}
+	if maxBytes > 0 && request.ContentLength > maxBytes {
+		return func() {}, errors.RequestBodyTooLarge(request, maxBytes)
+	}
+	reader := io.Reader(request.Body)
+	if maxBytes > 0 {
+		reader = io.LimitReader(request.Body, maxBytes+1)
+	}
+	body, err := io.ReadAll(reader)
+	if err != nil {
+		_ = request.Body.Close()
+		return func() {}, err
+	}
+	if maxBytes > 0 && int64(len(body)) > maxBytes {
+		// put back what was read, so the body can still be passed on.
+		request.Body = struct {
+			io.Reader
+			io.Closer
+		}{io.MultiReader(bytes.NewReader(body), request.Body), request.Body}
+		return func() {}, errors.RequestBodyTooLarge(request, maxBytes)
+	}
+	_ = request.Body.Close()
+	rewind = func() {
+		request.Body = io.NopCloser(bytes.NewReader(body))
+	}
+	rewind()
+	return rewind, nil
+}
+
+// ResponseCapture is an http.ResponseWriter that passes everything through to the wrapped http.ResponseWriter,
+// while keeping a copy of the status code, headers and (up to maxBodySize of) the body, so the response can be
+// validated once it's been written.
+type ResponseCapture struct {
+	http.ResponseWriter
+	maxBodySize int
+	status      int
+	header      http.Header
+	body        bytes.Buffer
+	truncated   bool
+}
+
+// NewResponseCapture will wrap an http.ResponseWriter in a ResponseCapture. maxBodySize caps the number of bytes
+// of the body that are kept, 0 or less keeps everything.
+func NewResponseCapture(w http.ResponseWriter, maxBodySize int) *ResponseCapture {
+	return &ResponseCapture{ResponseWriter: w, maxBodySize: maxBodySize}
+}
+
+func (c *ResponseCapture) WriteHeader(code int) {
+	if c.status == 0 {
+		c.status = code
+		c.header = c.ResponseWriter.Header().Clone()
+	}
+	c.ResponseWriter.WriteHeader(code)
+}
+
+func (c *ResponseCapture) Write(b []byte) (int, error) {
+	if c.status == 0 {
+		c.WriteHeader(http.StatusOK)
+	}
+	if !c.truncated {
+		if c.maxBodySize > 0 && c.body.Len()+len(b) > c.maxBodySize {
+			c.truncated = true
+			c.body.Reset()
+		} else {
+			c.body.Write(b)
+		}
+	}
+	return c.ResponseWriter.Write(b)
+}
+
+// Flush will flush the wrapped http.ResponseWriter, if it supports flushing, so streaming responses keep working.
+func (c *ResponseCapture) Flush() {
+	if flusher, ok := c.ResponseWriter.(http.Flusher); ok {
+		flusher.Flush()
+	}
+}
+
+// Unwrap returns the wrapped http.ResponseWriter, for use by http.ResponseController.
+func (c *ResponseCapture) Unwrap() http.ResponseWriter {
+	return c.ResponseWriter
+}
+
+// Truncated returns true if the body written was larger than maxBodySize, and was not kept.
+func (c *ResponseCapture) Truncated() bool {
+	return c.truncated
+}
+
+// Response will create an *http.Response from what was captured, for the request supplied.
+func (c *ResponseCapture) Response(request *http.Request) *http.Response {
+	status, header := c.status, c.header
+	if status == 0 {
+		status, header = http.StatusOK, c.ResponseWriter.Header().Clone()
+	}
+	return &http.Response{
+		StatusCode: status,
+		Header:     header,
+		Body:       io.NopCloser(bytes.NewReader(c.body.Bytes())),
+		Request:    request,
+	}
+}
+
+// ValidateCapturedResponse will validate the request and the response held by a ResponseCapture. If the captured
+// body was truncated, only the request is validated.
+func ValidateCapturedResponse(v Validator,
+	request *http.Request,
+	capture *ResponseCapture) (bool, []*errors.ValidationError) {
+
+	if capture.Truncated() {
+		return v.ValidateHttpRequest(request)
+	}
+	return v.ValidateHttpRequestResponse(request, capture.Response(request))
+}
+
+// maxBodyBytes returns the largest request body the Validator will read (set using config.WithMaxBodyBytes), 0 when
+// there is no limit, or the Validator was not created by this package.
+func maxBodyBytes(v Validator) int64 {
+	if val, ok := v.(*validator); ok {
+		return val.options.MaxBodyBytes
+	}
+	return 0
+}
+
+// writeProblemJSON will write validation errors as an RFC 7807 problem details object, with a 400 status code.
+func writeProblemJSON(w http.ResponseWriter, _ *http.Request, validationErrors []*errors.ValidationError) {
+	problemJSON, err := errors.ToProblemJSONWithStatus(validationErrors, http.StatusBadRequest)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	w.Header().Set(helpers.ContentTypeHeader, helpers.ProblemJSONContentType)
+	w.WriteHeader(http.StatusBadRequest)
+	_, _ = w.Write(problemJSON)
+}
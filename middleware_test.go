@@ -0,0 +1,309 @@
+// Copyright 2023 Princess B33f Heavy Industries / Dave Shanley
+// SPDX-License-Identifier: MIT
+
+package validator
+
+import (
+	"bytes"
+	"encoding/json"
+	"github.com/pb33f/libopenapi"
+	"github.com/pb33f/libopenapi-validator/config"
+	"github.com/pb33f/libopenapi-validator/errors"
+	"github.com/pb33f/libopenapi-validator/helpers"
+	"github.com/stretchr/testify/assert"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+var middlewareSpec = `openapi: 3.1.0
+paths:
+  /burgers/createBurger:
+    post:
+      requestBody:
+        content:
+          application/json:
+            schema:
+              type: object
+              required: [name]
+              properties:
+                name:
+                  type: string`
+
+func TestMiddleware(t *testing.T) {
+
+	doc, _ := libopenapi.NewDocument([]byte(middlewareSpec))
+	v, _ := NewValidator(doc)
+
+	var received string
+	handler := Middleware(v, nil)(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		received = string(body)
+		w.WriteHeader(http.StatusCreated)
+	}))
+
+	// the handler can still read the body after validation.
+	request := httptest.NewRequest(http.MethodPost, "https://things.com/burgers/createBurger",
+		bytes.NewBufferString(`{"name": "Big Mac"}`))
+	request.Header.Set(helpers.ContentTypeHeader, helpers.JSONContentType)
+	res := httptest.NewRecorder()
+	handler.ServeHTTP(res, request)
+
+	assert.Equal(t, http.StatusCreated, res.Code)
+	assert.Equal(t, `{"name": "Big Mac"}`, received)
+
+	// invalid requests never reach the handler, problem details are returned by default.
+	received = ""
+	request = httptest.NewRequest(http.MethodPost, "https://things.com/burgers/createBurger",
+		bytes.NewBufferString(`{"name": 1}`))
+	request.Header.Set(helpers.ContentTypeHeader, helpers.JSONContentType)
+	res = httptest.NewRecorder()
+	handler.ServeHTTP(res, request)
+
+	assert.Equal(t, http.StatusBadRequest, res.Code)
+	assert.Equal(t, helpers.ProblemJSONContentType, res.Header().Get(helpers.ContentTypeHeader))
+	assert.Equal(t, "", received)
+
+	var problem errors.ProblemDetails
+	_ = json.Unmarshal(res.Body.Bytes(), &problem)
+	assert.Len(t, problem.Errors, 1)
+	assert.Equal(t, "/name", problem.Errors[0].Pointer)
+}
+
+func TestMiddleware_OnError(t *testing.T) {
+
+	doc, _ := libopenapi.NewDocument([]byte(middlewareSpec))
+	v, _ := NewValidator(doc)
+
+	var reported []*errors.ValidationError
+	onError := func(w http.ResponseWriter, r *http.Request, validationErrors []*errors.ValidationError) {
+		reported = validationErrors
+		w.WriteHeader(http.StatusUnprocessableEntity)
+	}
+	handler := Middleware(v, onError)(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		t.Fatal("the handler should not be called")
+	}))
+
+	request := httptest.NewRequest(http.MethodGet, "https://things.com/not/here", nil)
+	res := httptest.NewRecorder()
+	handler.ServeHTTP(res, request)
+
+	assert.Equal(t, http.StatusUnprocessableEntity, res.Code)
+	assert.Len(t, reported, 1)
+	assert.True(t, reported[0].IsPathMissingError())
+}
+
+var responseMiddlewareSpec = `openapi: 3.1.0
+paths:
+  /burgers/{burgerId}:
+    get:
+      parameters:
+        - name: burgerId
+          in: path
+          required: true
+          schema:
+            type: string
+      responses:
+        "200":
+          content:
+            application/json:
+              schema:
+                type: object
+                required: [name]
+                properties:
+                  name:
+                    type: string`
+
+func TestResponseMiddleware(t *testing.T) {
+
+	doc, _ := libopenapi.NewDocument([]byte(responseMiddlewareSpec))
+	v, _ := NewValidator(doc)
+
+	var violations []*errors.ValidationError
+	onViolation := func(r *http.Request, errs []*errors.ValidationError) {
+		violations = errs
+	}
+
+	handler := ResponseMiddleware(v, onViolation, 0)(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set(helpers.ContentTypeHeader, helpers.JSONContentType)
+		_, _ = w.Write([]byte(`{"name": `))
+		w.(http.Flusher).Flush()
+		_, _ = w.Write([]byte(`"Big Mac"}`))
+	}))
+
+	request := httptest.NewRequest(http.MethodGet, "https://things.com/burgers/big-mac", nil)
+	res := httptest.NewRecorder()
+	handler.ServeHTTP(res, request)
+
+	// the streamed response reaches the client untouched.
+	assert.Equal(t, http.StatusOK, res.Code)
+	assert.True(t, res.Flushed)
+	assert.Equal(t, `{"name": "Big Mac"}`, res.Body.String())
+	assert.Nil(t, violations)
+
+	// an invalid response is reported, but still delivered.
+	handler = ResponseMiddleware(v, onViolation, 0)(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set(helpers.ContentTypeHeader, helpers.JSONContentType)
+		w.WriteHeader(http.StatusOK)
+		_, _ = w.Write([]byte(`{"name": 1}`))
+	}))
+
+	res = httptest.NewRecorder()
+	handler.ServeHTTP(res, request)
+
+	assert.Equal(t, `{"name": 1}`, res.Body.String())
+	assert.Len(t, violations, 1)
+	assert.Equal(t, "200 response body for '/burgers/big-mac' failed to validate schema", violations[0].Message)
+}
+
+func TestResponseMiddleware_MaxBodySize(t *testing.T) {
+
+	doc, _ := libopenapi.NewDocument([]byte(responseMiddlewareSpec))
+	v, _ := NewValidator(doc)
+
+	var violations []*errors.ValidationError
+	handler := ResponseMiddleware(v, func(r *http.Request, errs []*errors.ValidationError) {
+		violations = errs
+	}, 5)(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set(helpers.ContentTypeHeader, helpers.JSONContentType)
+		_, _ = w.Write([]byte(`{"name": 1}`))
+	}))
+
+	// the body is larger than the cap, so the response body is not validated.
+	request := httptest.NewRequest(http.MethodGet, "https://things.com/burgers/big-mac", nil)
+	res := httptest.NewRecorder()
+	handler.ServeHTTP(res, request)
+
+	assert.Equal(t, `{"name": 1}`, res.Body.String())
+	assert.Nil(t, violations)
+}
+
+func TestValidateCapturedResponse(t *testing.T) {
+
+	doc, _ := libopenapi.NewDocument([]byte(responseMiddlewareSpec))
+	v, _ := NewValidator(doc)
+
+	// an adapter for a framework wraps its writer, runs the handler, then validates what was captured.
+	request := httptest.NewRequest(http.MethodGet, "https://things.com/burgers/big-mac", nil)
+	rewind, err := BufferRequestBody(request)
+	assert.NoError(t, err)
+
+	res := httptest.NewRecorder()
+	capture := NewResponseCapture(res, 0)
+	capture.Header().Set(helpers.ContentTypeHeader, helpers.JSONContentType)
+	capture.WriteHeader(http.StatusOK)
+	_, _ = capture.Write([]byte(`{"name": "Big Mac"}`))
+
+	rewind()
+	valid, errs := ValidateCapturedResponse(v, request, capture)
+	assert.True(t, valid)
+	assert.Len(t, errs, 0)
+
+	response := capture.Response(request)
+	assert.Equal(t, http.StatusOK, response.StatusCode)
+	assert.Equal(t, helpers.JSONContentType, response.Header.Get(helpers.ContentTypeHeader))
+	assert.False(t, capture.Truncated())
+}
+
+func TestBufferRequestBody(t *testing.T) {
+
+	request := httptest.NewRequest(http.MethodPost, "https://things.com/burgers/createBurger",
+		bytes.NewBufferString(`{"name": "Big Mac"}`))
+	rewind, err := BufferRequestBody(request)
+	assert.NoError(t, err)
+
+	first, _ := io.ReadAll(request.Body)
+	rewind()
+	second, _ := io.ReadAll(request.Body)
+	assert.Equal(t, `{"name": "Big Mac"}`, string(first))
+	assert.Equal(t, string(first), string(second))
+}
+
+func TestMiddleware_MaxBodyBytes(t *testing.T) {
+
+	doc, _ := libopenapi.NewDocument([]byte(middlewareSpec))
+	v, _ := NewValidator(doc, config.WithMaxBodyBytes(10))
+
+	var reported []*errors.ValidationError
+	onError := func(w http.ResponseWriter, r *http.Request, validationErrors []*errors.ValidationError) {
+		reported = validationErrors
+		w.WriteHeader(http.StatusRequestEntityTooLarge)
+	}
+	handler := Middleware(v, onError)(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		t.Fatal("the handler should not be called")
+	}))
+
+	// the length of a streamed body is unknown, it's rejected once more than the limit has been read.
+	request := httptest.NewRequest(http.MethodPost, "https://things.com/burgers/createBurger",
+		bytes.NewBufferString(`{"name": "Big Mac"}`))
+	request.Header.Set(helpers.ContentTypeHeader, helpers.JSONContentType)
+	request.ContentLength = -1
+	res := httptest.NewRecorder()
+	handler.ServeHTTP(res, request)
+
+	assert.Equal(t, http.StatusRequestEntityTooLarge, res.Code)
+	assert.Len(t, reported, 1)
+	assert.Equal(t, errors.ErrorCodeBodyTooLarge, reported[0].Code)
+	assert.Equal(t, "POST request body for '/burgers/createBurger' is too large", reported[0].Message)
+}
+
+func TestResponseMiddleware_MaxBodyBytes(t *testing.T) {
+
+	doc, _ := libopenapi.NewDocument([]byte(middlewareSpec))
+	v, _ := NewValidator(doc, config.WithMaxBodyBytes(10))
+
+	var violations []*errors.ValidationError
+	var received string
+	handler := ResponseMiddleware(v, func(r *http.Request, errs []*errors.ValidationError) {
+		violations = errs
+	}, 0)(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		received = string(body)
+		w.WriteHeader(http.StatusCreated)
+	}))
+
+	// the body is too large to be validated, it's reported, but the handler still receives all of it.
+	request := httptest.NewRequest(http.MethodPost, "https://things.com/burgers/createBurger",
+		bytes.NewBufferString(`{"name": "Big Mac"}`))
+	request.Header.Set(helpers.ContentTypeHeader, helpers.JSONContentType)
+	request.ContentLength = -1
+	res := httptest.NewRecorder()
+	handler.ServeHTTP(res, request)
+
+	assert.Equal(t, http.StatusCreated, res.Code)
+	assert.Equal(t, `{"name": "Big Mac"}`, received)
+	assert.Len(t, violations, 1)
+	assert.Equal(t, errors.ErrorCodeBodyTooLarge, violations[0].Code)
+}
+
+func TestBufferRequestBodyWithLimit(t *testing.T) {
+
+	request := httptest.NewRequest(http.MethodPost, "https://things.com/burgers/createBurger",
+		bytes.NewBufferString(`{"name": "Big Mac"}`))
+	rewind, err := BufferRequestBodyWithLimit(request, 100)
+	assert.NoError(t, err)
+
+	first, _ := io.ReadAll(request.Body)
+	rewind()
+	second, _ := io.ReadAll(request.Body)
+	assert.Equal(t, `{"name": "Big Mac"}`, string(first))
+	assert.Equal(t, string(first), string(second))
+
+	// a larger body is rejected using its content length, before it's read.
+	request = httptest.NewRequest(http.MethodPost, "https://things.com/burgers/createBurger",
+		bytes.NewBufferString(`{"name": "Big Mac"}`))
+	_, err = BufferRequestBodyWithLimit(request, 10)
+	assert.Error(t, err)
+	assert.Equal(t, errors.ErrorCodeBodyTooLarge, err.(*errors.ValidationError).Code)
+
+	// without a content length, the body is read up to the limit, and then restored.
+	request = httptest.NewRequest(http.MethodPost, "https://things.com/burgers/createBurger",
+		bytes.NewBufferString(`{"name": "Big Mac"}`))
+	request.ContentLength = -1
+	_, err = BufferRequestBodyWithLimit(request, 10)
+	assert.Error(t, err)
+
+	body, _ := io.ReadAll(request.Body)
+	assert.Equal(t, `{"name": "Big Mac"}`, string(body))
+}
@@ -0,0 +1,94 @@
+// Copyright 2023 Princess B33f Heavy Industries / Dave Shanley
+// SPDX-License-Identifier: MIT
+
+package validator
+
+import (
+	"context"
+	"github.com/pb33f/libopenapi-validator/errors"
+	"net/http"
+	"sort"
+	"strings"
+)
+
+// MultiValidator validates requests against one of several documents, for example a gateway in front of several
+// services that each have their own specification. Each Validator is registered with the base path it covers (e.g.
+// /orders), and a request is validated by the Validator with the longest base path that the path of the request
+// starts with. Base paths match whole segments, so /orders covers /orders and /orders/1, but not /orders-archive.
+//
+// The request is not changed before it is validated, the document of each Validator has to describe the paths as
+// they are sent (the base path can be the url of a server in the document). Each Validator keeps its own cache of
+// compiled schemas. A MultiValidator is safe for concurrent use.
+type MultiValidator struct {
+	basePaths  []string
+	validators map[string]Validator
+}
+
+// NewMultiValidator will create a MultiValidator from validators keyed by the base path they cover. A base path
+// of '/' (or an empty base path) covers every request that no other base path covers.
+func NewMultiValidator(validators map[string]Validator) *MultiValidator {
+	m := &MultiValidator{validators: make(map[string]Validator, len(validators))}
+	for basePath, v := range validators {
+		basePath = "/" + strings.Trim(basePath, "/")
+		m.validators[basePath] = v
+		m.basePaths = append(m.basePaths, basePath)
+	}
+	// the longest base path is the most specific, so it's checked first.
+	sort.Slice(m.basePaths, func(i, j int) bool {
+		if len(m.basePaths[i]) != len(m.basePaths[j]) {
+			return len(m.basePaths[i]) > len(m.basePaths[j])
+		}
+		return m.basePaths[i] < m.basePaths[j]
+	})
+	return m
+}
+
+// Validator returns the Validator that covers the path of the request, and the base path it was registered with.
+// If no Validator covers the path, nil is returned.
+func (m *MultiValidator) Validator(request *http.Request) (Validator, string) {
+	for _, basePath := range m.basePaths {
+		if basePath == "/" || request.URL.Path == basePath || strings.HasPrefix(request.URL.Path, basePath+"/") {
+			return m.validators[basePath], basePath
+		}
+	}
+	return nil, ""
+}
+
+// ValidateHttpRequest will validate an *http.Request object using the Validator that covers the path of the request.
+// If no Validator covers the path, false is returned with a single error (see ValidationError.IsPathMissingError).
+func (m *MultiValidator) ValidateHttpRequest(request *http.Request) (bool, []*errors.ValidationError) {
+	return m.ValidateHttpRequestWithContext(context.Background(), request)
+}
+
+// ValidateHttpRequestWithContext is the same as ValidateHttpRequest, but will stop validating when the context
+// is done.
+func (m *MultiValidator) ValidateHttpRequestWithContext(ctx context.Context,
+	request *http.Request) (bool, []*errors.ValidationError) {
+	v, _ := m.Validator(request)
+	if v == nil {
+		return false, []*errors.ValidationError{errors.PathNotCovered(request, m.basePaths)}
+	}
+	return v.ValidateHttpRequestWithContext(ctx, request)
+}
+
+// ValidateHttpResponse will validate an *http.Response object using the Validator that covers the path of the
+// request that was sent for it.
+func (m *MultiValidator) ValidateHttpResponse(request *http.Request,
+	response *http.Response) (bool, []*errors.ValidationError) {
+	v, _ := m.Validator(request)
+	if v == nil {
+		return false, []*errors.ValidationError{errors.PathNotCovered(request, m.basePaths)}
+	}
+	return v.ValidateHttpResponse(request, response)
+}
+
+// ValidateHttpRequestResponse will validate both the *http.Request and *http.Response objects using the Validator
+// that covers the path of the request.
+func (m *MultiValidator) ValidateHttpRequestResponse(request *http.Request,
+	response *http.Response) (bool, []*errors.ValidationError) {
+	v, _ := m.Validator(request)
+	if v == nil {
+		return false, []*errors.ValidationError{errors.PathNotCovered(request, m.basePaths)}
+	}
+	return v.ValidateHttpRequestResponse(request, response)
+}
@@ -0,0 +1,94 @@
+// Copyright 2023 Princess B33f Heavy Industries / Dave Shanley
+// SPDX-License-Identifier: MIT
+
+package validator
+
+import (
+	"github.com/pb33f/libopenapi"
+	"github.com/pb33f/libopenapi-validator/errors"
+	"github.com/stretchr/testify/assert"
+	"net/http"
+	"testing"
+)
+
+func TestMultiValidator(t *testing.T) {
+
+	orders := `openapi: 3.1.0
+servers:
+  - url: https://api.things.com/orders
+paths:
+  /{orderId}:
+    get:
+      parameters:
+        - name: orderId
+          in: path
+          required: true
+          schema:
+            type: integer`
+
+	payments := `openapi: 3.1.0
+paths:
+  /payments/{paymentId}:
+    get:
+      parameters:
+        - name: paymentId
+          in: path
+          required: true
+          schema:
+            type: integer`
+
+	ordersDoc, _ := libopenapi.NewDocument([]byte(orders))
+	ordersValidator, _ := NewValidator(ordersDoc)
+	paymentsDoc, _ := libopenapi.NewDocument([]byte(payments))
+	paymentsValidator, _ := NewValidator(paymentsDoc)
+
+	m := NewMultiValidator(map[string]Validator{
+		"/orders":    ordersValidator,
+		"/payments/": paymentsValidator,
+	})
+
+	validate := func(path string) (bool, []*errors.ValidationError) {
+		request, _ := http.NewRequest(http.MethodGet, "https://api.things.com"+path, nil)
+		return m.ValidateHttpRequest(request)
+	}
+
+	// each request is validated against the document that covers it.
+	valid, errs := validate("/orders/1")
+	assert.True(t, valid)
+	assert.Len(t, errs, 0)
+
+	valid, errs = validate("/payments/2")
+	assert.True(t, valid)
+	assert.Len(t, errs, 0)
+
+	valid, errs = validate("/payments/two")
+	assert.False(t, valid)
+	if assert.Len(t, errs, 1) {
+		assert.Equal(t, errors.ErrorCodeParamType, errs[0].Code)
+	}
+
+	request, _ := http.NewRequest(http.MethodGet, "https://api.things.com/orders/1", nil)
+	v, basePath := m.Validator(request)
+	assert.Equal(t, ordersValidator, v)
+	assert.Equal(t, "/orders", basePath)
+
+	// base paths match whole segments, and no document covers the path.
+	for _, path := range []string{"/orders-archive/1", "/refunds/1"} {
+		valid, errs = validate(path)
+		assert.False(t, valid)
+		if assert.Len(t, errs, 1) {
+			assert.True(t, errs[0].IsPathMissingError())
+			assert.Equal(t, errors.ErrorCodePathNotFound, errs[0].Code)
+			assert.Equal(t, "Check the path is correct, it must start with one of the base paths: "+
+				"'/payments', '/orders'", errs[0].HowToFix)
+		}
+	}
+
+	// a root validator covers everything else.
+	m = NewMultiValidator(map[string]Validator{"/orders": ordersValidator, "/": paymentsValidator})
+	valid, errs = validate("/refunds/1")
+	assert.False(t, valid)
+	if assert.Len(t, errs, 1) {
+		assert.Equal(t, "GET Path '/refunds/1' not found", errs[0].Message)
+	}
+}
@@ -0,0 +1,109 @@
+// Copyright 2023 Princess B33f Heavy Industries / Dave Shanley
+// SPDX-License-Identifier: MIT
+
+package validator
+
+import (
+	"context"
+	"github.com/pb33f/libopenapi-validator/errors"
+	"github.com/pb33f/libopenapi-validator/helpers"
+	"github.com/pb33f/libopenapi/datamodel/high/v3"
+	"net/http"
+	"strings"
+)
+
+// OperationValidator validates requests and responses against a single operation, located up front using its
+// method and templated path, so there is no path lookup for each request. Use it for services that have already
+// matched the route (for example, using a router). An OperationValidator is safe for concurrent use.
+type OperationValidator interface {
+
+	// ValidateRequest will validate an *http.Request object against the operation. The path, query, cookie and
+	// header parameters, security requirements and request body are validated. The method of the request must
+	// match the method of the operation.
+	ValidateRequest(request *http.Request) (bool, []*errors.ValidationError)
+
+	// ValidateRequestWithContext is the same as ValidateRequest, but will stop validating when the context is done.
+	ValidateRequestWithContext(ctx context.Context, request *http.Request) (bool, []*errors.ValidationError)
+
+	// ValidateResponse will validate an *http.Response object against the responses of the operation. The request
+	// is used to check the method matches the operation.
+	ValidateResponse(request *http.Request, response *http.Response) (bool, []*errors.ValidationError)
+
+	// GetOperation returns the operation requests and responses are validated against.
+	GetOperation() *v3.Operation
+}
+
+type operationValidator struct {
+	validator *validator
+	method    string
+	pathValue string
+	pathItem  *v3.PathItem
+	operation *v3.Operation
+}
+
+// OperationValidator will locate an operation using its method (e.g. GET) and templated path as it's defined in the
+// specification (e.g. /pet/{petId}), and return an OperationValidator for it. The schemas of the operation's
+// parameters, request body and responses are compiled up front. An error is returned if the operation does not exist.
+func (v *validator) OperationValidator(method, templatedPath string) (OperationValidator, error) {
+	method = strings.ToUpper(method)
+	pathItem, operation := v.findOperation(method, templatedPath)
+	if operation == nil {
+		return nil, errors.OperationNotFound(method, templatedPath)
+	}
+
+	v.paramValidator.WarmOperationSchemaCache(pathItem, operation)
+	v.requestValidator.WarmOperationSchemaCache(operation)
+	v.responseValidator.WarmOperationSchemaCache(operation)
+
+	return &operationValidator{
+		validator: v,
+		method:    method,
+		pathValue: templatedPath,
+		pathItem:  pathItem,
+		operation: operation,
+	}, nil
+}
+
+// findOperation will locate the PathItem for a templated path, and the operation of the PathItem for the method
+// (which must be upper case). Nil is returned for the operation if either does not exist.
+func (v *validator) findOperation(method, templatedPath string) (*v3.PathItem, *v3.Operation) {
+	if v.v3Model.Paths == nil || v.v3Model.Paths.PathItems[templatedPath] == nil {
+		return nil, nil
+	}
+	pathItem := v.v3Model.Paths.PathItems[templatedPath]
+	return pathItem, helpers.ExtractOperation(&http.Request{Method: method}, pathItem)
+}
+
+func (o *operationValidator) GetOperation() *v3.Operation {
+	return o.operation
+}
+
+func (o *operationValidator) ValidateRequest(request *http.Request) (bool, []*errors.ValidationError) {
+	return o.ValidateRequestWithContext(context.Background(), request)
+}
+
+func (o *operationValidator) ValidateRequestWithContext(
+	ctx context.Context,
+	request *http.Request) (bool, []*errors.ValidationError) {
+
+	if ctx.Err() != nil {
+		return false, []*errors.ValidationError{errors.ValidationCancelled(ctx)}
+	}
+	if request.Method != o.method {
+		return false, []*errors.ValidationError{errors.OperationMethodMismatch(o.method, o.pathValue, request)}
+	}
+	return o.validator.validateHttpRequest(ctx, request, o.pathItem, o.pathValue)
+}
+
+func (o *operationValidator) ValidateResponse(
+	request *http.Request,
+	response *http.Response) (bool, []*errors.ValidationError) {
+
+	if request.Method != o.method {
+		return false, []*errors.ValidationError{errors.OperationMethodMismatch(o.method, o.pathValue, request)}
+	}
+	_, responseErrors := o.validator.responseValidator.ValidateResponseBodyWithPathItem(request, response,
+		o.pathItem, o.pathValue)
+	responseErrors = o.validator.prepareErrors(responseErrors)
+	return !errors.HasErrors(responseErrors), responseErrors
+}
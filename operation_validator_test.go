@@ -0,0 +1,179 @@
+// Copyright 2023 Princess B33f Heavy Industries / Dave Shanley
+// SPDX-License-Identifier: MIT
+
+package validator
+
+import (
+	"bytes"
+	"github.com/pb33f/libopenapi"
+	"github.com/pb33f/libopenapi-validator/helpers"
+	"github.com/stretchr/testify/assert"
+	"io"
+	"net/http"
+	"sync"
+	"testing"
+)
+
+var operationSpec = `openapi: 3.1.0
+paths:
+  /burgers/{burgerId}:
+    put:
+      parameters:
+        - name: burgerId
+          in: path
+          required: true
+          schema:
+            type: integer
+      requestBody:
+        content:
+          application/json:
+            schema:
+              type: object
+              required: [name]
+              properties:
+                name:
+                  type: string
+      responses:
+        "200":
+          content:
+            application/json:
+              schema:
+                type: object
+                required: [name]
+                properties:
+                  name:
+                    type: string`
+
+func TestValidator_OperationValidator(t *testing.T) {
+
+	doc, _ := libopenapi.NewDocument([]byte(operationSpec))
+	v, _ := NewValidator(doc)
+
+	op, err := v.OperationValidator("put", "/burgers/{burgerId}")
+	assert.NoError(t, err)
+	assert.NotNil(t, op.GetOperation().RequestBody)
+
+	request, _ := http.NewRequest(http.MethodPut, "https://things.com/burgers/1234",
+		bytes.NewBufferString(`{"name": "Big Mac"}`))
+	request.Header.Set(helpers.ContentTypeHeader, helpers.JSONContentType)
+
+	valid, errs := op.ValidateRequest(request)
+	assert.True(t, valid)
+	assert.Len(t, errs, 0)
+
+	// parameters are still validated against the templated path.
+	request, _ = http.NewRequest(http.MethodPut, "https://things.com/burgers/big-mac",
+		bytes.NewBufferString(`{"name": "Big Mac"}`))
+	request.Header.Set(helpers.ContentTypeHeader, helpers.JSONContentType)
+
+	valid, errs = op.ValidateRequest(request)
+	assert.False(t, valid)
+	assert.Len(t, errs, 1)
+	assert.Equal(t, "Path parameter 'burgerId' is not a valid integer", errs[0].Message)
+
+	response := &http.Response{
+		StatusCode: http.StatusOK,
+		Header:     http.Header{helpers.ContentTypeHeader: {helpers.JSONContentType}},
+		Body:       io.NopCloser(bytes.NewBufferString(`{"name": 1}`)),
+	}
+	valid, errs = op.ValidateResponse(request, response)
+	assert.False(t, valid)
+	assert.Len(t, errs, 1)
+	assert.Equal(t, "200 response body for '/burgers/big-mac' failed to validate schema", errs[0].Message)
+}
+
+func TestValidator_OperationValidator_NotFound(t *testing.T) {
+
+	doc, _ := libopenapi.NewDocument([]byte(operationSpec))
+	v, _ := NewValidator(doc)
+
+	op, err := v.OperationValidator(http.MethodGet, "/burgers/{burgerId}")
+	assert.Nil(t, op)
+	assert.EqualError(t, err, "GET operation for '/burgers/{burgerId}' not found: The path "+
+		"'/burgers/{burgerId}', or the GET method for that path does not exist in the specification")
+
+	_, err = v.OperationValidator(http.MethodPut, "/burgers")
+	assert.Error(t, err)
+}
+
+func TestValidator_OperationValidator_MethodMismatch(t *testing.T) {
+
+	doc, _ := libopenapi.NewDocument([]byte(operationSpec))
+	v, _ := NewValidator(doc)
+
+	op, _ := v.OperationValidator(http.MethodPut, "/burgers/{burgerId}")
+
+	request, _ := http.NewRequest(http.MethodPost, "https://things.com/burgers/1234", nil)
+	valid, errs := op.ValidateRequest(request)
+	assert.False(t, valid)
+	assert.Len(t, errs, 1)
+	assert.Equal(t, "POST request cannot be validated against the PUT operation for '/burgers/{burgerId}'",
+		errs[0].Message)
+}
+
+func TestValidator_OperationValidator_Concurrent(t *testing.T) {
+
+	doc, _ := libopenapi.NewDocument([]byte(operationSpec))
+	v, _ := NewValidator(doc)
+
+	op, _ := v.OperationValidator(http.MethodPut, "/burgers/{burgerId}")
+
+	var wg sync.WaitGroup
+	for i := 0; i < 20; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			body := `{"name": "Big Mac"}`
+			if i%2 == 0 {
+				body = `{"name": 1}`
+			}
+			request, _ := http.NewRequest(http.MethodPut, "https://things.com/burgers/1234",
+				bytes.NewBufferString(body))
+			request.Header.Set(helpers.ContentTypeHeader, helpers.JSONContentType)
+			valid, _ := op.ValidateRequest(request)
+			assert.Equal(t, i%2 != 0, valid)
+		}(i)
+	}
+	wg.Wait()
+}
+
+func TestNewValidator_ValidateHttpRequestAgainst(t *testing.T) {
+
+	doc, _ := libopenapi.NewDocument([]byte(operationSpec))
+	v, _ := NewValidator(doc)
+
+	request, _ := http.NewRequest(http.MethodPut, "https://things.com/burgers/1234",
+		bytes.NewBufferString(`{"name": "Big Mac"}`))
+	request.Header.Set(helpers.ContentTypeHeader, helpers.JSONContentType)
+
+	valid, errs := v.ValidateHttpRequestAgainst(request, "put", "/burgers/{burgerId}")
+	assert.True(t, valid)
+	assert.Len(t, errs, 0)
+
+	// the body and parameters are validated against the operation that was named.
+	request, _ = http.NewRequest(http.MethodPut, "https://things.com/burgers/big-mac",
+		bytes.NewBufferString(`{"name": 1}`))
+	request.Header.Set(helpers.ContentTypeHeader, helpers.JSONContentType)
+
+	valid, errs = v.ValidateHttpRequestAgainst(request, http.MethodPut, "/burgers/{burgerId}")
+	assert.False(t, valid)
+	assert.Len(t, errs, 2)
+
+	// the template must exist, and the method of the request must match.
+	valid, errs = v.ValidateHttpRequestAgainst(request, http.MethodPut, "/burgers")
+	assert.False(t, valid)
+	assert.Len(t, errs, 1)
+	assert.Equal(t, "PUT operation for '/burgers' not found", errs[0].Message)
+
+	valid, errs = v.ValidateHttpRequestAgainst(request, http.MethodGet, "/burgers/{burgerId}")
+	assert.False(t, valid)
+	assert.Len(t, errs, 1)
+	assert.Equal(t, "GET operation for '/burgers/{burgerId}' not found", errs[0].Message)
+
+	request, _ = http.NewRequest(http.MethodPost, "https://things.com/burgers/1234", nil)
+	valid, errs = v.ValidateHttpRequestAgainst(request, http.MethodPut, "/burgers/{burgerId}")
+	assert.False(t, valid)
+	assert.Len(t, errs, 1)
+	assert.Equal(t, "POST request cannot be validated against the PUT operation for '/burgers/{burgerId}'",
+		errs[0].Message)
+}
@@ -4,10 +4,10 @@
 package parameters
 
 import (
+	"context"
 	"fmt"
 	"github.com/pb33f/libopenapi-validator/errors"
 	"github.com/pb33f/libopenapi-validator/helpers"
-	"github.com/pb33f/libopenapi-validator/paths"
 	"github.com/pb33f/libopenapi/datamodel/high/base"
 	v3 "github.com/pb33f/libopenapi/datamodel/high/v3"
 	"net/http"
@@ -16,113 +16,211 @@ import (
 )
 
 func (v *paramValidator) ValidateCookieParams(request *http.Request) (bool, []*errors.ValidationError) {
+	return v.ValidateCookieParamsWithContext(context.Background(), request)
+}
 
-	// find path
-	var pathItem *v3.PathItem
-	var errs []*errors.ValidationError
-	if v.pathItem == nil {
-		pathItem, errs, _ = paths.FindPath(request, v.document)
-		if pathItem == nil || errs != nil {
-			v.errors = errs
-			return false, errs
-		}
-	} else {
-		pathItem = v.pathItem
+func (v *paramValidator) ValidateCookieParamsWithContext(
+	ctx context.Context,
+	request *http.Request) (bool, []*errors.ValidationError) {
+
+	pathItem, pathValue, errs := v.findPath(request)
+	if pathItem == nil {
+		return false, errs
 	}
+	return v.ValidateCookieParamsWithPathItem(ctx, request, pathItem, pathValue)
+}
+
+func (v *paramValidator) ValidateCookieParamsWithPathItem(
+	ctx context.Context,
+	request *http.Request,
+	pathItem *v3.PathItem,
+	pathValue string) (valid bool, validationErrors []*errors.ValidationError) {
+
+	// errors are labelled with the operation they were found in.
+	defer func() { errors.SetOperation(validationErrors, request, pathItem, pathValue) }()
 
 	// extract params for the operation
 	var params = helpers.ExtractParamsForOperation(request, pathItem)
-	var validationErrors []*errors.ValidationError
+	cookies, malformed := requestCookies(request)
 	for _, p := range params {
+		if ctx.Err() != nil {
+			return false, []*errors.ValidationError{errors.ValidationCancelled(ctx)}
+		}
 		if p.In == helpers.Cookie {
-			for _, cookie := range request.Cookies() {
+
+			var sch *base.Schema
+			if p.Schema != nil {
+				sch = p.Schema.Schema()
+			}
+
+			// collect every cookie that matches the parameter name, the same name can appear multiple
+			// times, which is how exploded arrays are sent.
+			var matched []*http.Cookie
+			for _, cookie := range cookies {
 				if cookie.Name == p.Name { // cookies are case-sensitive, an exact match is required
+					matched = append(matched, cookie)
+				}
+			}
+			if len(matched) > 0 && p.Deprecated && v.options.DeprecationWarnings {
+				validationErrors = append(validationErrors, errors.ParameterDeprecated(p))
+			}
 
-					var sch *base.Schema
-					if p.Schema != nil {
-						sch = p.Schema.Schema()
+			// exploded objects send each property as its own cookie.
+			if sch != nil && p.IsExploded() && len(sch.Type) > 0 && sch.Type[0] == helpers.Object {
+				props := make(map[string][]*helpers.QueryParam)
+				for _, cookie := range cookies {
+					if _, ok := sch.Properties[cookie.Name]; ok {
+						props[cookie.Name] = append(props[cookie.Name],
+							&helpers.QueryParam{Key: cookie.Name, Values: []string{cookie.Value}})
 					}
-					pType := sch.Type
+				}
+				if len(props) == 0 {
+					if p.Required {
+						validationErrors = append(validationErrors, errors.CookieParameterMissing(p))
+					}
+					continue
+				}
+				validationErrors = append(validationErrors,
+					ValidateParameterSchema(sch, helpers.ConstructParamMapFromQueryParamInput(props), "",
+						"Cookie parameter",
+						"The cookie parameter",
+						p.Name,
+						helpers.ParameterValidation,
+						helpers.ParameterValidationCookie,
+						v.schemas)...)
+				continue
+			}
 
-					for _, ty := range pType {
-						switch ty {
-						case helpers.Integer, helpers.Number:
-							if _, err := strconv.ParseFloat(cookie.Value, 64); err != nil {
-								validationErrors = append(validationErrors,
-									errors.InvalidCookieParamNumber(p, strings.ToLower(cookie.Value), sch))
-								break
-							}
-							// check if enum is in range
-							if sch.Enum != nil {
-								matchFound := false
-								for _, enumVal := range sch.Enum {
-									if strings.TrimSpace(cookie.Value) == fmt.Sprint(enumVal) {
-										matchFound = true
-										break
-									}
-								}
-								if !matchFound {
-									validationErrors = append(validationErrors,
-										errors.IncorrectCookieParamEnum(p, strings.ToLower(cookie.Value), sch))
+			if len(matched) == 0 {
+				if value, ok := malformed[p.Name]; ok {
+					validationErrors = append(validationErrors, errors.CookieParameterMalformed(p, value))
+					continue
+				}
+				if p.Required {
+					validationErrors = append(validationErrors, errors.CookieParameterMissing(p))
+				}
+				continue
+			}
+
+			// exploded arrays are sent as repeated cookies, collapse them into a single CSV value.
+			if sch != nil && p.IsExploded() && len(sch.Type) > 0 && sch.Type[0] == helpers.Array && len(matched) > 1 {
+				var values []string
+				for _, cookie := range matched {
+					values = append(values, cookie.Value)
+				}
+				matched = []*http.Cookie{{Name: p.Name, Value: strings.Join(values, helpers.Comma)}}
+			}
+
+			for _, cookie := range matched {
+
+				// a set of primitive types (such as [integer, null]) is converted into the first type that fits.
+				if hasPrimitiveTypes(sch) {
+					validationErrors = append(validationErrors, v.validatePrimitiveTypes(sch, p, cookie.Value,
+						helpers.ParameterValidationCookie)...)
+					continue
+				}
+				pType := sch.Type
+
+				for _, ty := range pType {
+					switch ty {
+					case helpers.Integer, helpers.Number:
+						if _, err := strconv.ParseFloat(cookie.Value, 64); err != nil {
+							validationErrors = append(validationErrors,
+								errors.InvalidCookieParamNumber(p, strings.ToLower(cookie.Value), sch))
+							break
+						}
+						// check if enum is in range
+						if sch.Enum != nil {
+							matchFound := false
+							for _, enumVal := range sch.Enum {
+								if strings.TrimSpace(cookie.Value) == fmt.Sprint(enumVal) {
+									matchFound = true
+									break
 								}
 							}
-						case helpers.Boolean:
-							if _, err := strconv.ParseBool(cookie.Value); err != nil {
+							if !matchFound {
 								validationErrors = append(validationErrors,
-									errors.IncorrectCookieParamBool(p, strings.ToLower(cookie.Value), sch))
-							}
-						case helpers.Object:
-							if !p.IsExploded() {
-								encodedObj := helpers.ConstructMapFromCSV(cookie.Value)
-
-								// if a schema was extracted
-								if sch != nil {
-									validationErrors = append(validationErrors,
-										ValidateParameterSchema(sch, encodedObj, "",
-											"Cookie parameter",
-											"The cookie parameter",
-											p.Name,
-											helpers.ParameterValidation,
-											helpers.ParameterValidationQuery)...)
-								}
-							}
-						case helpers.Array:
-
-							if !p.IsExploded() {
-								// well we're already in an array, so we need to check the items schema
-								// to ensure this array items matches the type
-								// only check if items is a schema, not a boolean
-								if sch.Items.IsA() {
-									validationErrors = append(validationErrors,
-										ValidateCookieArray(sch, p, cookie.Value)...)
-								}
+									errors.IncorrectCookieParamEnum(p, strings.ToLower(cookie.Value), sch))
 							}
+						}
+					case helpers.Boolean:
+						if _, err := strconv.ParseBool(cookie.Value); err != nil {
+							validationErrors = append(validationErrors,
+								errors.IncorrectCookieParamBool(p, strings.ToLower(cookie.Value), sch))
+						}
+					case helpers.Object:
+						encodedObj := helpers.ConstructMapFromCSV(cookie.Value)
 
-						case helpers.String:
-
-							// check if the schema has an enum, and if so, match the value against one of
-							// the defined enum values.
-							if sch.Enum != nil {
-								matchFound := false
-								for _, enumVal := range sch.Enum {
-									if strings.TrimSpace(cookie.Value) == fmt.Sprint(enumVal) {
-										matchFound = true
-										break
-									}
-								}
-								if !matchFound {
-									validationErrors = append(validationErrors,
-										errors.IncorrectCookieParamEnum(p, strings.ToLower(cookie.Value), sch))
-								}
+						// if a schema was extracted
+						if sch != nil {
+							validationErrors = append(validationErrors,
+								ValidateParameterSchema(sch, encodedObj, "",
+									"Cookie parameter",
+									"The cookie parameter",
+									p.Name,
+									helpers.ParameterValidation,
+									helpers.ParameterValidationCookie,
+									v.schemas)...)
+						}
+					case helpers.Array:
+
+						// well we're already in an array, so we need to check the items schema
+						// to ensure this array items matches the type
+						// only check if items is a schema, not a boolean
+						if sch.Items.IsA() {
+							validationErrors = append(validationErrors,
+								ValidateCookieArray(sch, p, cookie.Value)...)
+						}
+
+					case helpers.String:
+
+						// check if the schema has an enum, and if so, match the value against one of
+						// the defined enum values.
+						if sch.Enum != nil {
+							if _, ok := helpers.MatchEnum(cookie.Value, sch.Enum,
+								v.options.CaseInsensitiveEnums); !ok {
+								validationErrors = append(validationErrors,
+									errors.IncorrectCookieParamEnum(p, strings.ToLower(cookie.Value), sch))
+								break
 							}
 						}
+						// a custom format (config.WithCustomFormat) is checked against the schema.
+						if v.usesCustomFormat(sch) {
+							validationErrors = append(validationErrors, v.validatePrimitiveTypes(sch, p, cookie.Value,
+								helpers.ParameterValidationCookie)...)
+						}
 					}
 				}
 			}
 		}
 	}
-	if len(validationErrors) > 0 {
+	if errors.HasErrors(validationErrors) {
 		return false, validationErrors
 	}
-	return true, nil
+	return true, validationErrors
+}
+
+// requestCookies will read the cookies sent in every Cookie header of the request, using the same rules as
+// http.Request.Cookies. Attributes prefixed with '$' (such as $Version and $Path, from RFC 2109) are not cookies,
+// so they are ignored. Cookies with a value that cannot be parsed are returned separately, keyed by name, so they
+// can be reported as malformed, rather than missing.
+func requestCookies(request *http.Request) ([]*http.Cookie, map[string]string) {
+	var cookies []*http.Cookie
+	malformed := make(map[string]string)
+	for _, line := range request.Header.Values("Cookie") {
+		for _, pair := range strings.Split(line, ";") {
+			pair = strings.TrimSpace(pair)
+			name, value, _ := strings.Cut(pair, "=")
+			if pair == "" || strings.HasPrefix(name, "$") {
+				continue
+			}
+			parsed := (&http.Request{Header: http.Header{"Cookie": {pair}}}).Cookies()
+			if len(parsed) == 0 {
+				malformed[name] = value
+				continue
+			}
+			cookies = append(cookies, parsed...)
+		}
+	}
+	return cookies, malformed
 }
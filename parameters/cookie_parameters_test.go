@@ -5,9 +5,12 @@ package parameters
 
 import (
 	"github.com/pb33f/libopenapi"
+	"github.com/pb33f/libopenapi-validator/config"
+	"github.com/pb33f/libopenapi-validator/helpers"
 	"github.com/pb33f/libopenapi-validator/paths"
 	"github.com/stretchr/testify/assert"
 	"net/http"
+	"strings"
 	"testing"
 )
 
@@ -552,3 +555,406 @@ paths:
 	assert.Len(t, errors, 1)
 	assert.Equal(t, "Instead of '2500', use one of the allowed values: '1, 2, 99'", errors[0].HowToFix)
 }
+
+func TestNewValidator_CookieParamMissing(t *testing.T) {
+
+	spec := `openapi: 3.1.0
+paths:
+  /burgers/beef:
+    get:
+      parameters:
+        - name: PattyPreference
+          in: cookie
+          required: true
+          schema:
+            type: integer`
+
+	doc, _ := libopenapi.NewDocument([]byte(spec))
+	m, _ := doc.BuildV3Model()
+	v := NewParameterValidator(&m.Model)
+
+	request, _ := http.NewRequest(http.MethodGet, "https://things.com/burgers/beef", nil)
+	request.AddCookie(&http.Cookie{Name: "BunPreference", Value: "brioche"})
+
+	valid, errors := v.ValidateCookieParams(request)
+
+	assert.False(t, valid)
+	assert.Len(t, errors, 1)
+	assert.Equal(t, "Cookie parameter 'PattyPreference' is missing", errors[0].Message)
+	assert.Equal(t, helpers.ParameterValidationCookie, errors[0].ValidationSubType)
+}
+
+func TestNewValidator_CookieParamMissingNotRequired(t *testing.T) {
+
+	spec := `openapi: 3.1.0
+paths:
+  /burgers/beef:
+    get:
+      parameters:
+        - name: PattyPreference
+          in: cookie
+          schema:
+            type: integer`
+
+	doc, _ := libopenapi.NewDocument([]byte(spec))
+	m, _ := doc.BuildV3Model()
+	v := NewParameterValidator(&m.Model)
+
+	request, _ := http.NewRequest(http.MethodGet, "https://things.com/burgers/beef", nil)
+
+	valid, errors := v.ValidateCookieParams(request)
+
+	assert.True(t, valid)
+	assert.Len(t, errors, 0)
+}
+
+func TestNewValidator_CookieParamArrayExplodedRepeatedCookies(t *testing.T) {
+
+	spec := `openapi: 3.1.0
+paths:
+  /burgers/beef:
+    get:
+      parameters:
+        - name: PattyPreference
+          in: cookie
+          required: true
+          explode: true
+          schema:
+            type: array
+            items:
+              type: integer`
+
+	doc, _ := libopenapi.NewDocument([]byte(spec))
+	m, _ := doc.BuildV3Model()
+	v := NewParameterValidator(&m.Model)
+
+	request, _ := http.NewRequest(http.MethodGet, "https://things.com/burgers/beef", nil)
+	request.AddCookie(&http.Cookie{Name: "PattyPreference", Value: "1"})
+	request.AddCookie(&http.Cookie{Name: "PattyPreference", Value: "2"})
+
+	valid, errors := v.ValidateCookieParams(request)
+
+	assert.True(t, valid)
+	assert.Len(t, errors, 0)
+}
+
+func TestNewValidator_CookieParamArrayExplodedRepeatedCookiesInvalid(t *testing.T) {
+
+	spec := `openapi: 3.1.0
+paths:
+  /burgers/beef:
+    get:
+      parameters:
+        - name: PattyPreference
+          in: cookie
+          required: true
+          explode: true
+          schema:
+            type: array
+            items:
+              type: integer`
+
+	doc, _ := libopenapi.NewDocument([]byte(spec))
+	m, _ := doc.BuildV3Model()
+	v := NewParameterValidator(&m.Model)
+
+	request, _ := http.NewRequest(http.MethodGet, "https://things.com/burgers/beef", nil)
+	request.AddCookie(&http.Cookie{Name: "PattyPreference", Value: "1"})
+	request.AddCookie(&http.Cookie{Name: "PattyPreference", Value: "two"})
+
+	valid, errors := v.ValidateCookieParams(request)
+
+	assert.False(t, valid)
+	assert.Len(t, errors, 1)
+}
+
+func TestNewValidator_CookieParamRepeatedCookiesInvalid(t *testing.T) {
+
+	spec := `openapi: 3.1.0
+paths:
+  /burgers/beef:
+    get:
+      parameters:
+        - name: PattyPreference
+          in: cookie
+          required: true
+          schema:
+            type: integer`
+
+	doc, _ := libopenapi.NewDocument([]byte(spec))
+	m, _ := doc.BuildV3Model()
+	v := NewParameterValidator(&m.Model)
+
+	request, _ := http.NewRequest(http.MethodGet, "https://things.com/burgers/beef", nil)
+	request.AddCookie(&http.Cookie{Name: "PattyPreference", Value: "1"})
+	request.AddCookie(&http.Cookie{Name: "PattyPreference", Value: "two"})
+
+	valid, errors := v.ValidateCookieParams(request)
+
+	assert.False(t, valid)
+	assert.Len(t, errors, 1)
+}
+
+func TestNewValidator_CookieParamObjectExplodedValid(t *testing.T) {
+
+	spec := `openapi: 3.1.0
+paths:
+  /burgers/beef:
+    get:
+      parameters:
+        - name: PattyPreference
+          in: cookie
+          required: true
+          explode: true
+          schema:
+            type: object
+            properties:
+              pink:
+                type: boolean
+              number:
+                type: integer
+            required: [pink, number]`
+
+	doc, _ := libopenapi.NewDocument([]byte(spec))
+	m, _ := doc.BuildV3Model()
+	v := NewParameterValidator(&m.Model)
+
+	request, _ := http.NewRequest(http.MethodGet, "https://things.com/burgers/beef", nil)
+	request.AddCookie(&http.Cookie{Name: "pink", Value: "true"})
+	request.AddCookie(&http.Cookie{Name: "number", Value: "22"})
+
+	valid, errors := v.ValidateCookieParams(request)
+
+	assert.True(t, valid)
+	assert.Len(t, errors, 0)
+}
+
+func TestNewValidator_CookieParamObjectExplodedInvalid(t *testing.T) {
+
+	spec := `openapi: 3.1.0
+paths:
+  /burgers/beef:
+    get:
+      parameters:
+        - name: PattyPreference
+          in: cookie
+          required: true
+          explode: true
+          schema:
+            type: object
+            properties:
+              pink:
+                type: boolean
+              number:
+                type: integer
+            required: [pink, number]`
+
+	doc, _ := libopenapi.NewDocument([]byte(spec))
+	m, _ := doc.BuildV3Model()
+	v := NewParameterValidator(&m.Model)
+
+	request, _ := http.NewRequest(http.MethodGet, "https://things.com/burgers/beef", nil)
+	request.AddCookie(&http.Cookie{Name: "pink", Value: "true"})
+	request.AddCookie(&http.Cookie{Name: "number", Value: "twenty-two"})
+
+	valid, errors := v.ValidateCookieParams(request)
+
+	assert.False(t, valid)
+	assert.Len(t, errors, 1)
+	assert.Equal(t, helpers.ParameterValidationCookie, errors[0].ValidationSubType)
+}
+
+func TestNewValidator_CookieParamMultipleCookieHeaders(t *testing.T) {
+
+	spec := `openapi: 3.1.0
+paths:
+  /burgers/beef:
+    get:
+      parameters:
+        - name: PattyPreference
+          in: cookie
+          required: true
+          schema:
+            type: integer
+        - name: Sauce
+          in: cookie
+          required: true
+          schema:
+            type: string
+            enum: [ketchup, mayo]`
+
+	doc, _ := libopenapi.NewDocument([]byte(spec))
+	m, _ := doc.BuildV3Model()
+	v := NewParameterValidator(&m.Model)
+
+	// the cookies are split across two headers, and the RFC 2109 attributes are not parameters.
+	request, _ := http.NewRequest(http.MethodGet, "https://things.com/burgers/beef", nil)
+	request.Header.Add("Cookie", `$Version=1; PattyPreference=2; $Path=/burgers`)
+	request.Header.Add("Cookie", "Sauce=mayo")
+
+	valid, errors := v.ValidateCookieParams(request)
+
+	assert.True(t, valid)
+	assert.Len(t, errors, 0)
+}
+
+func TestNewValidator_CookieParamMalformed(t *testing.T) {
+
+	spec := `openapi: 3.1.0
+paths:
+  /burgers/beef:
+    get:
+      parameters:
+        - name: PattyPreference
+          in: cookie
+          required: true
+          schema:
+            type: integer
+        - name: Sauce
+          in: cookie
+          required: true
+          schema:
+            type: string`
+
+	doc, _ := libopenapi.NewDocument([]byte(spec))
+	m, _ := doc.BuildV3Model()
+	v := NewParameterValidator(&m.Model)
+
+	request, _ := http.NewRequest(http.MethodGet, "https://things.com/burgers/beef", nil)
+	request.Header.Add("Cookie", "Sauce=ket\\chup")
+
+	valid, errors := v.ValidateCookieParams(request)
+
+	assert.False(t, valid)
+	assert.Len(t, errors, 2)
+
+	messages := make([]string, 0, len(errors))
+	for _, e := range errors {
+		messages = append(messages, e.Message)
+	}
+	assert.ElementsMatch(t, []string{
+		"Cookie parameter 'PattyPreference' is missing",
+		"Cookie parameter 'Sauce' is malformed",
+	}, messages)
+}
+
+func TestNewValidator_CookieParamNullable(t *testing.T) {
+
+	spec := `openapi: 3.1.0
+paths:
+  /burgers/beef:
+    get:
+      parameters:
+        - name: PattyCount
+          in: cookie
+          required: true
+          schema:
+            type: [integer, "null"]`
+
+	doc, _ := libopenapi.NewDocument([]byte(spec))
+	m, _ := doc.BuildV3Model()
+	v := NewParameterValidator(&m.Model)
+
+	for _, count := range []string{"2", "null", ""} {
+		request, _ := http.NewRequest(http.MethodGet, "https://things.com/burgers/beef", nil)
+		request.Header.Set("Cookie", "PattyCount="+count)
+
+		valid, errors := v.ValidateCookieParams(request)
+		assert.True(t, valid, count)
+		assert.Len(t, errors, 0)
+	}
+
+	request, _ := http.NewRequest(http.MethodGet, "https://things.com/burgers/beef", nil)
+	request.Header.Set("Cookie", "PattyCount=two")
+
+	valid, errors := v.ValidateCookieParams(request)
+	assert.False(t, valid)
+	assert.Len(t, errors, 1)
+	assert.Equal(t, "Cookie parameter 'PattyCount' is not a valid 'integer, null'", errors[0].Message)
+}
+
+func TestNewValidator_CookieParamEmptyValue(t *testing.T) {
+
+	spec := `openapi: 3.1.0
+paths:
+  /burgers/beef:
+    get:
+      parameters:
+        - name: anything
+          in: cookie
+          schema: {}
+        - name: size
+          in: cookie
+          schema:
+            type: integer`
+
+	doc, _ := libopenapi.NewDocument([]byte(spec))
+	m, _ := doc.BuildV3Model()
+	v := NewParameterValidator(&m.Model)
+
+	// optional cookies that are not sent are valid.
+	request, _ := http.NewRequest(http.MethodGet, "https://things.com/burgers/beef", nil)
+
+	valid, errors := v.ValidateCookieParams(request)
+	assert.True(t, valid)
+	assert.Len(t, errors, 0)
+
+	// an empty schema accepts an empty value.
+	request, _ = http.NewRequest(http.MethodGet, "https://things.com/burgers/beef", nil)
+	request.Header.Set("Cookie", "anything=")
+
+	valid, errors = v.ValidateCookieParams(request)
+	assert.True(t, valid)
+	assert.Len(t, errors, 0)
+
+	// an empty value is validated against the schema, it is not treated as absent.
+	request, _ = http.NewRequest(http.MethodGet, "https://things.com/burgers/beef", nil)
+	request.Header.Set("Cookie", "size=")
+
+	valid, errors = v.ValidateCookieParams(request)
+	assert.False(t, valid)
+	assert.Len(t, errors, 1)
+	assert.Equal(t, "Cookie parameter 'size' is not a valid number", errors[0].Message)
+}
+
+func TestNewValidator_CookieParamCustomFormat(t *testing.T) {
+
+	spec := `openapi: 3.1.0
+paths:
+  /burgers/beef:
+    get:
+      parameters:
+        - name: sku
+          in: cookie
+          schema:
+            type: string
+            format: sku
+        - name: session
+          in: cookie
+          schema:
+            type: string
+            pattern: ^[0-9]+$`
+
+	doc, _ := libopenapi.NewDocument([]byte(spec))
+	m, _ := doc.BuildV3Model()
+	v := NewParameterValidator(&m.Model, config.WithCustomFormat("sku", func(s string) bool {
+		return strings.HasPrefix(s, "SKU-")
+	}))
+
+	// only a custom format is checked, the pattern of other string cookies is not.
+	request, _ := http.NewRequest(http.MethodGet, "https://things.com/burgers/beef", nil)
+	request.Header.Set("Cookie", "sku=SKU-123; session=abc")
+
+	valid, errors := v.ValidateCookieParams(request)
+	assert.True(t, valid)
+	assert.Len(t, errors, 0)
+
+	request, _ = http.NewRequest(http.MethodGet, "https://things.com/burgers/beef", nil)
+	request.Header.Set("Cookie", "sku=123; session=abc")
+
+	valid, errors = v.ValidateCookieParams(request)
+	assert.False(t, valid)
+	assert.Len(t, errors, 1)
+	assert.Equal(t, "Cookie parameter 'sku' failed to validate", errors[0].Message)
+	assert.Equal(t, "'123' is not valid 'sku'", errors[0].SchemaValidationErrors[0].Reason)
+}
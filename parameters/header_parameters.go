@@ -4,46 +4,78 @@
 package parameters
 
 import (
+	"context"
 	"fmt"
 	"github.com/pb33f/libopenapi-validator/errors"
 	"github.com/pb33f/libopenapi-validator/helpers"
-	"github.com/pb33f/libopenapi-validator/paths"
 	"github.com/pb33f/libopenapi/datamodel/high/base"
 	"github.com/pb33f/libopenapi/datamodel/high/v3"
 	"net/http"
+	"slices"
 	"strconv"
 	"strings"
 )
 
 func (v *paramValidator) ValidateHeaderParams(request *http.Request) (bool, []*errors.ValidationError) {
+	return v.ValidateHeaderParamsWithContext(context.Background(), request)
+}
 
-	// find path
-	var pathItem *v3.PathItem
-	var errs []*errors.ValidationError
-	if v.pathItem == nil {
-		pathItem, errs, _ = paths.FindPath(request, v.document)
-		if pathItem == nil || errs != nil {
-			v.errors = errs
-			return false, errs
-		}
-	} else {
-		pathItem = v.pathItem
+func (v *paramValidator) ValidateHeaderParamsWithContext(
+	ctx context.Context,
+	request *http.Request) (bool, []*errors.ValidationError) {
+
+	pathItem, pathValue, errs := v.findPath(request)
+	if pathItem == nil {
+		return false, errs
 	}
+	return v.ValidateHeaderParamsWithPathItem(ctx, request, pathItem, pathValue)
+}
+
+func (v *paramValidator) ValidateHeaderParamsWithPathItem(
+	ctx context.Context,
+	request *http.Request,
+	pathItem *v3.PathItem,
+	pathValue string) (valid bool, validationErrors []*errors.ValidationError) {
+
+	// errors are labelled with the operation they were found in.
+	defer func() { errors.SetOperation(validationErrors, request, pathItem, pathValue) }()
 
 	// extract params for the operation
 	var params = helpers.ExtractParamsForOperation(request, pathItem)
 
-	var validationErrors []*errors.ValidationError
 	var seenHeaders = make(map[string]bool)
 	for _, p := range params {
+		if ctx.Err() != nil {
+			return false, []*errors.ValidationError{errors.ValidationCancelled(ctx)}
+		}
 		if p.In == helpers.Header {
 
 			seenHeaders[strings.ToLower(p.Name)] = true
-			if param := request.Header.Get(p.Name); param != "" {
+			var sch *base.Schema
+			if p.Schema != nil {
+				sch = p.Schema.Schema()
+			}
+			values := headerValues(request.Header, p.Name)
+			var param string
+			if len(values) > 0 {
+				param = values[0]
+			}
+			sent := len(values) > 0
 
-				var sch *base.Schema
-				if p.Schema != nil {
-					sch = p.Schema.Schema()
+			// a header sent without a value is null, when null is one of the types of the parameter.
+			if param == "" && sent && hasPrimitiveTypes(sch) && slices.Contains(helpers.SchemaTypes(sch), helpers.Null) {
+				continue
+			}
+			if sent {
+				if p.Deprecated && v.options.DeprecationWarnings {
+					validationErrors = append(validationErrors, errors.ParameterDeprecated(p))
+				}
+
+				// a set of primitive types (such as [integer, null]) is converted into the first type that fits.
+				if hasPrimitiveTypes(sch) {
+					validationErrors = append(validationErrors, v.validatePrimitiveTypes(sch, p, param,
+						helpers.ParameterValidationHeader)...)
+					continue
 				}
 				pType := sch.Type
 
@@ -78,16 +110,9 @@ func (v *paramValidator) ValidateHeaderParams(request *http.Request) (bool, []*e
 
 					case helpers.Object:
 
-						// check if the header is default encoded or not
-						var encodedObj map[string]interface{}
-						// we have found our header, check the explode type.
-						if p.IsDefaultHeaderEncoding() {
-							encodedObj = helpers.ConstructMapFromCSV(param)
-						} else {
-							if p.IsExploded() { // only option is to be exploded for KV extraction.
-								encodedObj = helpers.ConstructKVFromCSV(param)
-							}
-						}
+						// a header can be sent more than once, the values are combined.
+						encodedObj, _ := helpers.DecodeSimpleValue(
+							strings.Join(values, helpers.Comma), p.IsExploded(), sch).(map[string]any)
 
 						if len(encodedObj) == 0 {
 							validationErrors = append(validationErrors,
@@ -95,25 +120,36 @@ func (v *paramValidator) ValidateHeaderParams(request *http.Request) (bool, []*e
 							break
 						}
 
-						// if a schema was extracted
-						if sch != nil {
-							validationErrors = append(validationErrors,
-								ValidateParameterSchema(sch,
-									encodedObj,
+						validationErrors = append(validationErrors,
+							ValidateParameterSchema(sch,
+								encodedObj,
+								"",
+								"Header parameter",
+								"The header parameter",
+								p.Name,
+								helpers.ParameterValidation,
+								helpers.ParameterValidationHeader,
+								v.schemas)...)
+
+					case helpers.Array:
+						// simple style arrays are comma separated, whether they are exploded or not. each item is
+						// checked first, so the offending item can be reported, then the array is checked against
+						// the rest of the schema (minItems, uniqueItems, pattern etc.).
+						if sch.Items != nil && sch.Items.IsA() {
+							joined := strings.Join(values, helpers.Comma)
+							arrayErrors := ValidateHeaderArray(sch, p, joined)
+							if len(arrayErrors) == 0 {
+								arrayErrors = ValidateParameterSchema(sch,
+									helpers.DecodeSimpleValue(joined, p.IsExploded(), sch),
 									"",
 									"Header parameter",
 									"The header parameter",
 									p.Name,
 									helpers.ParameterValidation,
-									helpers.ParameterValidationQuery)...)
-						}
-
-					case helpers.Array:
-						if !p.IsExploded() { // only unexploded arrays are supported for cookie params
-							if sch.Items.IsA() {
-								validationErrors = append(validationErrors,
-									ValidateHeaderArray(sch, p, param)...)
+									helpers.ParameterValidationHeader,
+									v.schemas)
 							}
+							validationErrors = append(validationErrors, arrayErrors...)
 						}
 
 					case helpers.String:
@@ -121,18 +157,17 @@ func (v *paramValidator) ValidateHeaderParams(request *http.Request) (bool, []*e
 						// check if the schema has an enum, and if so, match the value against one of
 						// the defined enum values.
 						if sch.Enum != nil {
-							matchFound := false
-							for _, enumVal := range sch.Enum {
-								if strings.TrimSpace(param) == fmt.Sprint(enumVal) {
-									matchFound = true
-									break
-								}
-							}
-							if !matchFound {
+							if _, ok := helpers.MatchEnum(param, sch.Enum, v.options.CaseInsensitiveEnums); !ok {
 								validationErrors = append(validationErrors,
 									errors.IncorrectHeaderParamEnum(p, strings.ToLower(param), sch))
+								break
 							}
 						}
+						// a custom format (config.WithCustomFormat) is checked against the schema.
+						if v.usesCustomFormat(sch) {
+							validationErrors = append(validationErrors, v.validatePrimitiveTypes(sch, p, param,
+								helpers.ParameterValidationHeader)...)
+						}
 					}
 				}
 			} else {
@@ -143,8 +178,22 @@ func (v *paramValidator) ValidateHeaderParams(request *http.Request) (bool, []*e
 		}
 	}
 
-	if len(validationErrors) > 0 {
+	if errors.HasErrors(validationErrors) {
 		return false, validationErrors
 	}
-	return true, nil
+	return true, validationErrors
+}
+
+// headerValues returns the values of a header, the name of the header is matched without regard to case, so headers
+// that were added to the request without being canonicalized (e.g. request.Header["x-api-version"]) are found.
+func headerValues(header http.Header, name string) []string {
+	if values := header.Values(name); len(values) > 0 {
+		return values
+	}
+	for key, values := range header {
+		if strings.EqualFold(key, name) {
+			return values
+		}
+	}
+	return nil
 }
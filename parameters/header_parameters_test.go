@@ -38,6 +38,53 @@ paths:
 	assert.Equal(t, "Header parameter 'bash' is missing", errors[0].Message)
 }
 
+func TestNewValidator_HeaderParamRequired(t *testing.T) {
+
+	spec := `openapi: 3.1.0
+paths:
+  /burgers:
+    get:
+      parameters:
+        - name: X-Api-Version
+          in: header
+          required: true
+          schema:
+            type: string
+        - name: X-Request-Id
+          in: header
+          schema:
+            type: string
+`
+
+	doc, _ := libopenapi.NewDocument([]byte(spec))
+	m, _ := doc.BuildV3Model()
+	v := NewParameterValidator(&m.Model)
+
+	// the required header is omitted, the optional one is not reported.
+	request, _ := http.NewRequest(http.MethodGet, "https://things.com/burgers", nil)
+	valid, errors := v.ValidateHeaderParams(request)
+
+	assert.False(t, valid)
+	if assert.Len(t, errors, 1) {
+		assert.Equal(t, "Header parameter 'X-Api-Version' is missing", errors[0].Message)
+		assert.Equal(t, "Send the 'X-Api-Version' header with the request, it's required by the operation",
+			errors[0].HowToFix)
+	}
+
+	// header names are matched without regard to case.
+	request, _ = http.NewRequest(http.MethodGet, "https://things.com/burgers", nil)
+	request.Header.Set("x-api-version", "2")
+	valid, errors = v.ValidateHeaderParams(request)
+	assert.True(t, valid)
+	assert.Len(t, errors, 0)
+
+	request, _ = http.NewRequest(http.MethodGet, "https://things.com/burgers", nil)
+	request.Header["x-api-version"] = []string{"2"}
+	valid, errors = v.ValidateHeaderParams(request)
+	assert.True(t, valid)
+	assert.Len(t, errors, 0)
+}
+
 func TestNewValidator_HeaderPathMissing(t *testing.T) {
 
 	spec := `openapi: 3.1.0
@@ -627,3 +674,192 @@ paths:
 	assert.Equal(t, "Instead of '1200', "+
 		"use one of the allowed values: '1, 2, 99'", errors[0].HowToFix)
 }
+
+func TestNewValidator_HeaderParamSimpleArrayAndObject(t *testing.T) {
+
+	spec := `openapi: 3.1.0
+paths:
+  /vending/drinks:
+    get:
+      parameters:
+        - name: X-Tags
+          in: header
+          schema:
+            type: array
+            maxItems: 3
+            items:
+              type: string
+              enum: [a, b, c]
+        - name: X-Sizes
+          in: header
+          schema:
+            type: array
+            items:
+              type: integer
+        - name: X-Meta
+          in: header
+          explode: true
+          schema:
+            type: object
+            properties:
+              role:
+                type: string
+              scope:
+                type: string
+                enum: [all, some]
+              level:
+                type: integer`
+
+	doc, _ := libopenapi.NewDocument([]byte(spec))
+	m, _ := doc.BuildV3Model()
+	v := NewParameterValidator(&m.Model)
+
+	request, _ := http.NewRequest(http.MethodGet, "https://things.com/vending/drinks", nil)
+	request.Header.Set("X-Tags", "a, b,c")
+	request.Header.Set("X-Sizes", "1,2")
+	request.Header.Add("X-Sizes", "3")
+	request.Header.Set("X-Meta", "role=admin,scope=all,level=2")
+
+	valid, errors := v.ValidateHeaderParams(request)
+	assert.True(t, valid)
+	assert.Len(t, errors, 0)
+
+	// the offending item is reported.
+	request, _ = http.NewRequest(http.MethodGet, "https://things.com/vending/drinks", nil)
+	request.Header.Set("X-Tags", "a,z")
+	request.Header.Set("X-Sizes", "1,two")
+
+	valid, errors = v.ValidateHeaderParams(request)
+	assert.False(t, valid)
+	assert.Len(t, errors, 2)
+	assert.Equal(t, "Header parameter 'X-Tags' failed to validate", errors[0].Message)
+	assert.Equal(t, "/1", errors[0].SchemaValidationErrors[0].InstanceLocation)
+	assert.Contains(t, errors[1].Reason, "'two'")
+
+	// the array is checked against the rest of the schema.
+	request, _ = http.NewRequest(http.MethodGet, "https://things.com/vending/drinks", nil)
+	request.Header.Set("X-Tags", "a,b,c,a")
+
+	valid, errors = v.ValidateHeaderParams(request)
+	assert.False(t, valid)
+	assert.Len(t, errors, 1)
+	assert.Equal(t, "maximum 3 items required, but found 4 items", errors[0].SchemaValidationErrors[0].Reason)
+
+	// object properties are validated against their schemas.
+	request, _ = http.NewRequest(http.MethodGet, "https://things.com/vending/drinks", nil)
+	request.Header.Set("X-Meta", "role=admin,scope=none")
+
+	valid, errors = v.ValidateHeaderParams(request)
+	assert.False(t, valid)
+	assert.Len(t, errors, 1)
+	assert.Equal(t, "Header parameter 'X-Meta' failed to validate", errors[0].Message)
+	assert.Equal(t, "/scope", errors[0].SchemaValidationErrors[0].InstanceLocation)
+}
+
+func TestNewValidator_HeaderParamNullable(t *testing.T) {
+
+	spec := `openapi: 3.1.0
+paths:
+  /vending/drinks:
+    get:
+      parameters:
+        - name: X-Size
+          in: header
+          required: true
+          schema:
+            type: [integer, "null"]
+            minimum: 1
+        - name: X-Sizes
+          in: header
+          schema:
+            type: array
+            items:
+              type: [integer, "null"]`
+
+	doc, _ := libopenapi.NewDocument([]byte(spec))
+	m, _ := doc.BuildV3Model()
+	v := NewParameterValidator(&m.Model)
+
+	// values are converted into the first type that fits.
+	for _, size := range []string{"2", "null", ""} {
+		request, _ := http.NewRequest(http.MethodGet, "https://things.com/vending/drinks", nil)
+		request.Header.Set("X-Size", size)
+		request.Header.Set("X-Sizes", "1,null,3")
+
+		valid, errors := v.ValidateHeaderParams(request)
+		assert.True(t, valid, size)
+		assert.Len(t, errors, 0)
+	}
+
+	// a value that fits none of the types is a type error.
+	request, _ := http.NewRequest(http.MethodGet, "https://things.com/vending/drinks", nil)
+	request.Header.Set("X-Size", "large")
+
+	valid, errors := v.ValidateHeaderParams(request)
+	assert.False(t, valid)
+	assert.Len(t, errors, 1)
+	assert.Equal(t, "Header parameter 'X-Size' is not a valid 'integer, null'", errors[0].Message)
+	assert.Equal(t, "The header parameter 'X-Size' is defined as being one of 'integer, null', "+
+		"however the value 'large' cannot be converted into any of those types", errors[0].Reason)
+
+	// the converted value is checked against the rest of the schema.
+	request, _ = http.NewRequest(http.MethodGet, "https://things.com/vending/drinks", nil)
+	request.Header.Set("X-Size", "0")
+
+	valid, errors = v.ValidateHeaderParams(request)
+	assert.False(t, valid)
+	assert.Len(t, errors, 1)
+	assert.Equal(t, "Header parameter 'X-Size' failed to validate", errors[0].Message)
+
+	// a required header that is not sent at all is still missing.
+	request, _ = http.NewRequest(http.MethodGet, "https://things.com/vending/drinks", nil)
+
+	valid, errors = v.ValidateHeaderParams(request)
+	assert.False(t, valid)
+	assert.Len(t, errors, 1)
+	assert.Equal(t, "Header parameter 'X-Size' is missing", errors[0].Message)
+}
+
+func TestNewValidator_HeaderParamEmptyValue(t *testing.T) {
+
+	spec := `openapi: 3.1.0
+paths:
+  /vending/drinks:
+    get:
+      parameters:
+        - name: X-Anything
+          in: header
+          schema: {}
+        - name: X-Size
+          in: header
+          schema:
+            type: integer`
+
+	doc, _ := libopenapi.NewDocument([]byte(spec))
+	m, _ := doc.BuildV3Model()
+	v := NewParameterValidator(&m.Model)
+
+	// optional headers that are not sent are valid.
+	request, _ := http.NewRequest(http.MethodGet, "https://things.com/vending/drinks", nil)
+
+	valid, errors := v.ValidateHeaderParams(request)
+	assert.True(t, valid)
+	assert.Len(t, errors, 0)
+
+	// an empty schema accepts an empty value.
+	request, _ = http.NewRequest(http.MethodGet, "https://things.com/vending/drinks", nil)
+	request.Header.Set("X-Anything", "")
+
+	valid, errors = v.ValidateHeaderParams(request)
+	assert.True(t, valid)
+	assert.Len(t, errors, 0)
+
+	// an empty value is validated against the schema, it is not treated as absent.
+	request, _ = http.NewRequest(http.MethodGet, "https://things.com/vending/drinks", nil)
+	request.Header.Set("X-Size", "")
+
+	valid, errors = v.ValidateHeaderParams(request)
+	assert.False(t, valid)
+	assert.Len(t, errors, 1)
+	assert.Equal(t, "Header parameter 'X-Size' is not a valid number", errors[0].Message)
+}
@@ -4,7 +4,12 @@
 package parameters
 
 import (
+	"context"
+	"github.com/pb33f/libopenapi-validator/config"
 	"github.com/pb33f/libopenapi-validator/errors"
+	"github.com/pb33f/libopenapi-validator/helpers"
+	"github.com/pb33f/libopenapi-validator/paths"
+	"github.com/pb33f/libopenapi/datamodel/high/base"
 	v3 "github.com/pb33f/libopenapi/datamodel/high/v3"
 	"net/http"
 )
@@ -13,16 +18,26 @@ import (
 // There are 4 types of parameters: query, header, cookie and path.
 //
 //	ValidateQueryParams will validate the query parameters for the request
+//	ValidateQueryParamValue will validate a single query parameter value
 //	ValidateHeaderParams will validate the header parameters for the request
 //	ValidateCookieParams will validate the cookie parameters for the request
 //	ValidatePathParams will validate the path parameters for the request
 //
-// Each method accepts an *http.Request and returns true if validation passed,
-// false if validation failed and a slice of ValidationError pointers.
+// Each request level method accepts an *http.Request and returns true if validation passed,
+// false if validation failed and a slice of ValidationError pointers. Each request level method also has a
+// WithContext variant, if the context is done before validation completes, validation stops and a single
+// cancellation error is returned (see ValidationError.IsCancelledError).
+//
+// Query, header and cookie parameters that are absent are only reported when they are required. A parameter that
+// is present with an empty value (such as ?name= or an empty header) is not treated as absent, the empty value is
+// validated against the schema of the parameter, so an empty schema ({}) accepts it, and a schema of type integer
+// does not.
 type ParameterValidator interface {
 
 	// SetPathItem will set the pathItem for the ParameterValidator, all validations will be performed against this pathItem
-	// otherwise if not set, each validation will perform a lookup for the pathItem based on the *http.Request
+	// otherwise if not set, each validation will perform a lookup for the pathItem based on the *http.Request.
+	// The pathItem is stored on the validator, so SetPathItem is not safe to use while the validator is shared
+	// between goroutines, use the WithPathItem methods instead.
 	SetPathItem(path *v3.PathItem, pathValue string)
 
 	// ValidateQueryParams accepts an *http.Request and validates the query parameters against the OpenAPI specification.
@@ -30,17 +45,73 @@ type ParameterValidator interface {
 	// will be matched and validated against what has been supplied in the http.Request query string.
 	ValidateQueryParams(request *http.Request) (bool, []*errors.ValidationError)
 
+	// ValidateQueryParamsWithContext is the same as ValidateQueryParams, but will stop validating the query parameters
+	// when the context is done.
+	ValidateQueryParamsWithContext(ctx context.Context, request *http.Request) (bool, []*errors.ValidationError)
+
+	// ValidateQueryParamsWithPathItem is the same as ValidateQueryParamsWithContext, but uses the supplied pathItem and
+	// pathValue (as returned by paths.FindPath) instead of looking them up, or using the pathItem set by SetPathItem.
+	// The request is never changed, so it can run alongside other validations of the request. Defaults are validated,
+	// but are only added to the request by ApplyQueryDefaults.
+	ValidateQueryParamsWithPathItem(ctx context.Context, request *http.Request, pathItem *v3.PathItem,
+		pathValue string) (bool, []*errors.ValidationError)
+
+	// ApplyQueryDefaults will add the schema default of each optional query parameter that is missing from the
+	// request to its query, when config.WithApplyDefaults is used. ValidateQueryParams (and WithContext) call it once
+	// the query has been validated. It changes the request, so call it after every validation of the request is done.
+	ApplyQueryDefaults(request *http.Request, pathItem *v3.PathItem)
+
+	// ValidateQueryParamValue validates a single (already decoded) query parameter value against the parameter
+	// definition, without the need for an *http.Request. The same style, coercion and schema rules used by
+	// ValidateQueryParams are applied.
+	ValidateQueryParamValue(param *v3.Parameter, rawValue string) (bool, []*errors.ValidationError)
+
 	// ValidateHeaderParams validates the header parameters contained within *http.Request. It returns a boolean
 	// stating true if validation passed (false for failed), and a slice of errors if validation failed.
 	ValidateHeaderParams(request *http.Request) (bool, []*errors.ValidationError)
 
+	// ValidateHeaderParamsWithContext is the same as ValidateHeaderParams, but will stop validating the header parameters
+	// when the context is done.
+	ValidateHeaderParamsWithContext(ctx context.Context, request *http.Request) (bool, []*errors.ValidationError)
+
+	// ValidateHeaderParamsWithPathItem is the same as ValidateHeaderParamsWithContext, but uses the supplied pathItem and
+	// pathValue (as returned by paths.FindPath) instead of looking them up, or using the pathItem set by SetPathItem.
+	ValidateHeaderParamsWithPathItem(ctx context.Context, request *http.Request, pathItem *v3.PathItem,
+		pathValue string) (bool, []*errors.ValidationError)
+
 	// ValidateCookieParams validates the cookie parameters contained within *http.Request.
 	// It returns a boolean stating true if validation passed (false for failed), and a slice of errors if validation failed.
 	ValidateCookieParams(request *http.Request) (bool, []*errors.ValidationError)
 
+	// ValidateCookieParamsWithContext is the same as ValidateCookieParams, but will stop validating the cookie parameters
+	// when the context is done.
+	ValidateCookieParamsWithContext(ctx context.Context, request *http.Request) (bool, []*errors.ValidationError)
+
+	// ValidateCookieParamsWithPathItem is the same as ValidateCookieParamsWithContext, but uses the supplied pathItem and
+	// pathValue (as returned by paths.FindPath) instead of looking them up, or using the pathItem set by SetPathItem.
+	ValidateCookieParamsWithPathItem(ctx context.Context, request *http.Request, pathItem *v3.PathItem,
+		pathValue string) (bool, []*errors.ValidationError)
+
 	// ValidatePathParams validates the path parameters contained within *http.Request. It returns a boolean stating true
 	// if validation passed (false for failed), and a slice of errors if validation failed.
 	ValidatePathParams(request *http.Request) (bool, []*errors.ValidationError)
+
+	// ValidatePathParamsWithContext is the same as ValidatePathParams, but will stop validating the path parameters
+	// when the context is done.
+	ValidatePathParamsWithContext(ctx context.Context, request *http.Request) (bool, []*errors.ValidationError)
+
+	// ValidatePathParamsWithPathItem is the same as ValidatePathParamsWithContext, but uses the supplied pathItem and
+	// pathValue (as returned by paths.FindPath) instead of looking them up, or using the pathItem set by SetPathItem.
+	ValidatePathParamsWithPathItem(ctx context.Context, request *http.Request, pathItem *v3.PathItem,
+		pathValue string) (bool, []*errors.ValidationError)
+
+	// WarmSchemaCache will render and compile the schemas of all parameters in the document up front. Schemas are
+	// otherwise compiled (once) the first time they are used.
+	WarmSchemaCache()
+
+	// WarmOperationSchemaCache is the same as WarmSchemaCache, but only compiles the schemas of the parameters of a
+	// single operation, and of the path item it belongs to.
+	WarmOperationSchemaCache(pathItem *v3.PathItem, operation *v3.Operation)
 }
 
 func (v *paramValidator) SetPathItem(path *v3.PathItem, pathValue string) {
@@ -48,14 +119,72 @@ func (v *paramValidator) SetPathItem(path *v3.PathItem, pathValue string) {
 	v.pathValue = pathValue
 }
 
-// NewParameterValidator will create a new ParameterValidator from an OpenAPI 3+ document
-func NewParameterValidator(document *v3.Document) ParameterValidator {
-	return &paramValidator{document: document}
+// NewParameterValidator will create a new ParameterValidator from an OpenAPI 3+ document, any options
+// supplied will change the default validation behavior.
+func NewParameterValidator(document *v3.Document, opts ...config.Option) ParameterValidator {
+	options := config.NewValidationOptions(opts...)
+	return &paramValidator{document: document, options: options, schemas: helpers.NewSchemaCache(options)}
+}
+
+// WarmSchemaCache will render and compile the schema of every parameter in the document, so the first request
+// for each operation does not pay the cost.
+func (v *paramValidator) WarmSchemaCache() {
+	if v.document == nil || v.document.Paths == nil {
+		return
+	}
+	for _, pathItem := range v.document.Paths.PathItems {
+		for _, operation := range pathItem.GetOperations() {
+			v.WarmOperationSchemaCache(pathItem, operation)
+		}
+	}
+}
+
+func (v *paramValidator) WarmOperationSchemaCache(pathItem *v3.PathItem, operation *v3.Operation) {
+	var params []*v3.Parameter
+	if pathItem != nil {
+		params = append(params, pathItem.Parameters...)
+	}
+	if operation != nil {
+		params = append(params, operation.Parameters...)
+	}
+	for _, param := range params {
+		var sch *base.Schema
+		if param.Schema != nil {
+			sch = param.Schema.Schema()
+		}
+		for _, mediaType := range param.Content {
+			if mediaType.Schema != nil {
+				sch = mediaType.Schema.Schema()
+			}
+		}
+		if sch == nil {
+			continue
+		}
+		_, _ = v.schemas.Compile(param.Name, sch)
+
+		// the items of an array are validated on their own, as well as the array.
+		if sch.Items != nil && sch.Items.IsA() && sch.Items.A.Schema() != nil {
+			_, _ = v.schemas.Compile(param.Name, sch.Items.A.Schema())
+		}
+	}
+}
+
+// findPath will return the pathItem set using SetPathItem, otherwise the pathItem is located using the request.
+func (v *paramValidator) findPath(request *http.Request) (*v3.PathItem, string, []*errors.ValidationError) {
+	if v.pathItem != nil {
+		return v.pathItem, v.pathValue, nil
+	}
+	pathItem, errs, pathValue := paths.FindPathWithOptions(request, v.document, v.options)
+	if errs != nil {
+		return nil, "", errs
+	}
+	return pathItem, pathValue, nil
 }
 
 type paramValidator struct {
 	document  *v3.Document
+	options   *config.ValidationOptions
+	schemas   *helpers.SchemaCache
 	pathItem  *v3.PathItem
 	pathValue string
-	errors    []*errors.ValidationError
 }
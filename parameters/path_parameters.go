@@ -4,10 +4,9 @@
 package parameters
 
 import (
-	"fmt"
+	"context"
 	"github.com/pb33f/libopenapi-validator/errors"
 	"github.com/pb33f/libopenapi-validator/helpers"
-	"github.com/pb33f/libopenapi-validator/paths"
 	"github.com/pb33f/libopenapi/datamodel/high/v3"
 	"net/http"
 	"strconv"
@@ -15,31 +14,66 @@ import (
 )
 
 func (v *paramValidator) ValidatePathParams(request *http.Request) (bool, []*errors.ValidationError) {
+	return v.ValidatePathParamsWithContext(context.Background(), request)
+}
 
-	// find path
-	var pathItem *v3.PathItem
-	var errs []*errors.ValidationError
-	var foundPath string
-	if v.pathItem == nil && v.pathValue == "" {
-		pathItem, errs, foundPath = paths.FindPath(request, v.document)
-		if pathItem == nil || errs != nil {
-			v.errors = errs
-			return false, errs
-		}
-	} else {
-		pathItem = v.pathItem
-		foundPath = v.pathValue
+func (v *paramValidator) ValidatePathParamsWithContext(
+	ctx context.Context,
+	request *http.Request) (bool, []*errors.ValidationError) {
+
+	pathItem, pathValue, errs := v.findPath(request)
+	if pathItem == nil {
+		return false, errs
 	}
+	return v.ValidatePathParamsWithPathItem(ctx, request, pathItem, pathValue)
+}
+
+func (v *paramValidator) ValidatePathParamsWithPathItem(
+	ctx context.Context,
+	request *http.Request,
+	pathItem *v3.PathItem,
+	pathValue string) (valid bool, validationErrors []*errors.ValidationError) {
+
+	// errors are labelled with the operation they were found in.
+	defer func() { errors.SetOperation(validationErrors, request, pathItem, pathValue) }()
 
 	// extract params for the operation
 	var params = helpers.ExtractParamsForOperation(request, pathItem)
-	var validationErrors []*errors.ValidationError
+
+	// every path parameter must match a {name} in the path, and every {name} must be declared.
+	if pathValue != "" {
+		undeclared, unused := helpers.ComparePathTemplate(pathValue, params)
+		for _, name := range undeclared {
+			validationErrors = append(validationErrors, errors.PathTemplateParameterNotDeclared(name, pathValue))
+		}
+		for _, p := range unused {
+			validationErrors = append(validationErrors, errors.PathParameterNotInTemplate(p, pathValue))
+		}
+	}
+
 	for _, p := range params {
+		if ctx.Err() != nil {
+			return false, []*errors.ValidationError{errors.ValidationCancelled(ctx)}
+		}
 		if p.In == helpers.Path {
 
-			// split the path into segments
-			submittedSegments := strings.Split(request.URL.Path, helpers.Slash)
-			pathSegments := strings.Split(foundPath, helpers.Slash)
+			// split the path into segments, each segment is decoded on its own and matrix values are decoded
+			// from the escaped path, so that encoded separators are not confused with real ones.
+			escapedPath := request.URL.EscapedPath()
+			if !v.options.StrictPathMatching {
+				escapedPath = helpers.NormalizePath(escapedPath)
+			}
+			submittedSegments := helpers.SplitEscapedPath(escapedPath)
+			escapedSegments := strings.Split(escapedPath, helpers.Slash)
+			pathSegments := strings.Split(pathValue, helpers.Slash)
+
+			// the request path can be prefixed with the base path of a server, skip those segments.
+			if extra := len(submittedSegments) - len(pathSegments); extra > 0 {
+				submittedSegments = submittedSegments[extra:]
+				if len(escapedSegments) == len(pathSegments)+extra {
+					escapedSegments = escapedSegments[extra:]
+				}
+			}
 
 			//var paramTemplate string
 			for x := range pathSegments {
@@ -75,67 +109,99 @@ func (v *paramValidator) ValidatePathParams(request *http.Request) (bool, []*err
 						continue
 					}
 
-					// extract the parameter value from the path.
+					// extract the parameter value from the path, path parameters are always required.
 					paramValue := submittedSegments[x]
+					if paramValue == "" {
+						validationErrors = append(validationErrors, errors.PathParameterMissing(p))
+						continue
+					}
 
 					// extract the schema from the parameter
 					sch := p.Schema.Schema()
 
-					// check enum (if present)
-					enumCheck := func(paramValue string) {
-						matchFound := false
-						for _, enumVal := range sch.Enum {
-							if strings.TrimSpace(paramValue) == fmt.Sprint(enumVal) {
-								matchFound = true
-								break
+					// the style defined on the parameter is the source of truth for matrix encoding, the
+					// template prefix (;) is optional.
+					var matrixParams []*helpers.QueryParam
+					var matrixValues []string
+					if p.Style == helpers.MatrixStyle {
+						isMatrix = true
+						isSimple = false
+						rawSegment := paramValue
+						if len(escapedSegments) == len(submittedSegments) {
+							rawSegment = escapedSegments[x]
+						}
+						var ok bool
+						if matrixParams, ok = helpers.ExtractMatrixParams(rawSegment); ok {
+							for _, mp := range matrixParams {
+								if mp.Key == p.Name {
+									matrixValues = append(matrixValues, mp.Values...)
+								}
 							}
 						}
-						if !matchFound {
+
+						// exploded objects are keyed by property name, everything else is keyed by the param name.
+						explodedObject := p.IsExploded() && len(sch.Type) > 0 && sch.Type[0] == helpers.Object
+						if !ok || (!explodedObject && len(matrixValues) == 0) {
+							validationErrors = append(validationErrors,
+								errors.IncorrectPathParamMatrixSegment(p, rawSegment))
+							continue
+						}
+						paramValue = strings.Join(matrixValues, helpers.Comma)
+					}
+
+					// label values are prefixed with a period, strip it off once and validate what remains.
+					// a lone period means the value is missing entirely.
+					if p.Style == helpers.LabelStyle {
+						isLabel = true
+						isSimple = false
+						paramValue = strings.TrimPrefix(paramValue, helpers.Period)
+						if paramValue == "" {
+							validationErrors = append(validationErrors,
+								errors.IncorrectPathParamLabelSegment(p, submittedSegments[x]))
+							continue
+						}
+					}
+
+					// check enum (if present)
+					enumCheck := func(paramValue string) {
+						if _, ok := helpers.MatchEnum(paramValue, sch.Enum, v.options.CaseInsensitiveEnums); !ok {
 							validationErrors = append(validationErrors,
 								errors.IncorrectPathParamEnum(p, strings.ToLower(paramValue), sch))
 						}
 					}
 
+					// a set of primitive types (such as [integer, null]) is converted into the first type that fits.
+					if hasPrimitiveTypes(sch) {
+						validationErrors = append(validationErrors, v.validatePrimitiveTypes(sch, p, paramValue,
+							helpers.ParameterValidationPath)...)
+						continue
+					}
+
 					// for each type, check the value.
 					for typ := range sch.Type {
 
 						switch sch.Type[typ] {
 						case helpers.String:
 
-							// TODO: label and matrix style validation
-
 							// check if the param is within the enum
 							if sch.Enum != nil {
-								enumCheck(paramValue)
-							}
-
-						case helpers.Integer, helpers.Number:
-							// simple use case is already handled in find param.
-							if isLabel && p.Style == helpers.LabelStyle {
-								if _, err := strconv.ParseFloat(paramValue[1:], 64); err != nil {
-									validationErrors = append(validationErrors,
-										errors.IncorrectPathParamNumber(p, paramValue[1:], sch))
-									break
-								}
-								// check if the param is within the enum
-								if sch.Enum != nil {
-									enumCheck(paramValue[1:])
+								enumErrors := len(validationErrors)
+								if enumCheck(paramValue); len(validationErrors) > enumErrors {
 									break
 								}
 							}
-							if isMatrix && p.Style == helpers.MatrixStyle {
-								// strip off the colon and the parameter name
-								paramValue = strings.Replace(paramValue[1:], fmt.Sprintf("%s=", p.Name), "", 1)
-								if _, err := strconv.ParseFloat(paramValue, 64); err != nil {
-									validationErrors = append(validationErrors,
-										errors.IncorrectPathParamNumber(p, paramValue[1:], sch))
-									break
-								}
-								// check if the param is within the enum
-								if sch.Enum != nil {
-									enumCheck(paramValue)
-									break
-								}
+							// a custom format (config.WithCustomFormat) is checked against the schema.
+							if v.usesCustomFormat(sch) {
+								validationErrors = append(validationErrors, v.validatePrimitiveTypes(sch, p, paramValue,
+									helpers.ParameterValidationPath)...)
+							}
+
+						case helpers.Integer:
+							// path values are always strings, an integer must convert without losing anything.
+							if _, err := strconv.ParseInt(paramValue, 10, 64); err != nil {
+								validationErrors = append(validationErrors,
+									errors.IncorrectPathParamInteger(p, paramValue, sch))
+								break
 							}
 							// check if the param is within the enum
 							if sch.Enum != nil {
@@ -143,22 +209,20 @@ func (v *paramValidator) ValidatePathParams(request *http.Request) (bool, []*err
 								break
 							}
 
-						case helpers.Boolean:
-							if isLabel && p.Style == helpers.LabelStyle {
-								if _, err := strconv.ParseFloat(paramValue[1:], 64); err != nil {
-									validationErrors = append(validationErrors,
-										errors.IncorrectPathParamBool(p, paramValue[1:], sch))
-								}
+						case helpers.Number:
+							if _, err := strconv.ParseFloat(paramValue, 64); err != nil {
+								validationErrors = append(validationErrors,
+									errors.IncorrectPathParamNumber(p, paramValue, sch))
+								break
 							}
-							if isSimple {
-								if _, err := strconv.ParseBool(paramValue); err != nil {
-									validationErrors = append(validationErrors,
-										errors.IncorrectPathParamBool(p, paramValue, sch))
-								}
+							// check if the param is within the enum
+							if sch.Enum != nil {
+								enumCheck(paramValue)
+								break
 							}
-							if isMatrix && p.Style == helpers.MatrixStyle {
-								// strip off the colon and the parameter name
-								paramValue = strings.Replace(paramValue[1:], fmt.Sprintf("%s=", p.Name), "", 1)
+
+						case helpers.Boolean:
+							if isSimple || isLabel || isMatrix {
 								if _, err := strconv.ParseBool(paramValue); err != nil {
 									validationErrors = append(validationErrors,
 										errors.IncorrectPathParamBool(p, paramValue, sch))
@@ -173,17 +237,15 @@ func (v *paramValidator) ValidatePathParams(request *http.Request) (bool, []*err
 								switch p.Style {
 								case helpers.LabelStyle:
 									if !p.IsExploded() {
-										encodedObject = helpers.ConstructMapFromCSV(paramValue[1:])
+										encodedObject = helpers.ConstructMapFromCSV(paramValue)
 									} else {
 										encodedObject = helpers.ConstructKVFromLabelEncoding(paramValue)
 									}
 								case helpers.MatrixStyle:
 									if !p.IsExploded() {
-										paramValue = strings.Replace(paramValue[1:], fmt.Sprintf("%s=", p.Name), "", 1)
-										encodedObject = helpers.ConstructMapFromCSV(paramValue)
+										encodedObject = helpers.ConstructMapFromValues(matrixValues)
 									} else {
-										paramValue = strings.Replace(paramValue[1:], fmt.Sprintf("%s=", p.Name), "", 1)
-										encodedObject = helpers.ConstructKVFromMatrixCSV(paramValue)
+										encodedObject = helpers.ConstructParamMapFromMatrixParams(matrixParams)
 									}
 								default:
 									if p.IsExploded() {
@@ -201,7 +263,8 @@ func (v *paramValidator) ValidatePathParams(request *http.Request) (bool, []*err
 										"The path parameter",
 										p.Name,
 										helpers.ParameterValidation,
-										helpers.ParameterValidationPath)...)
+										helpers.ParameterValidationPath,
+										v.schemas)...)
 							}
 
 						case helpers.Array:
@@ -217,26 +280,41 @@ func (v *paramValidator) ValidatePathParams(request *http.Request) (bool, []*err
 									}
 									if isLabel {
 										if !p.IsExploded() {
-											arrayValues = strings.Split(paramValue[1:], helpers.Comma)
+											arrayValues = strings.Split(paramValue, helpers.Comma)
 										} else {
-											arrayValues = strings.Split(paramValue[1:], helpers.Period)
+											arrayValues = strings.Split(paramValue, helpers.Period)
 										}
 									}
 									if isMatrix {
-										if !p.IsExploded() {
-											paramValue = strings.Replace(paramValue[1:], fmt.Sprintf("%s=", p.Name), "", 1)
-											arrayValues = strings.Split(paramValue, helpers.Comma)
-										} else {
-											paramValue = strings.ReplaceAll(paramValue[1:], fmt.Sprintf("%s=", p.Name), "")
-											arrayValues = strings.Split(paramValue, helpers.SemiColon)
+										// exploded (;burger=1;burger=2) and non-exploded (;burger=1,2) values
+										// have both already been collected.
+										arrayValues = matrixValues
+									}
+									// check each item is within the items enum (if present)
+									arrayEnumCheck := func(idx int, item string) {
+										if _, ok := helpers.MatchEnum(item, iSch.Enum,
+											v.options.CaseInsensitiveEnums); ok {
+											return
 										}
+										validationErrors = append(validationErrors,
+											errors.IncorrectPathParamArrayEnum(p, item, idx, sch, iSch))
 									}
 									switch iSch.Type[n] {
+									case helpers.String:
+										if iSch.Enum != nil {
+											for pv := range arrayValues {
+												arrayEnumCheck(pv, arrayValues[pv])
+											}
+										}
 									case helpers.Integer, helpers.Number:
 										for pv := range arrayValues {
 											if _, err := strconv.ParseFloat(arrayValues[pv], 64); err != nil {
 												validationErrors = append(validationErrors,
 													errors.IncorrectPathParamArrayNumber(p, arrayValues[pv], sch, iSch))
+												continue
+											}
+											if iSch.Enum != nil {
+												arrayEnumCheck(pv, arrayValues[pv])
 											}
 										}
 									case helpers.Boolean:
@@ -4,10 +4,13 @@
 package parameters
 
 import (
+	"context"
 	"github.com/pb33f/libopenapi"
+	"github.com/pb33f/libopenapi-validator/config"
 	"github.com/pb33f/libopenapi-validator/paths"
 	"github.com/stretchr/testify/assert"
 	"net/http"
+	"strings"
 	"testing"
 )
 
@@ -280,7 +283,7 @@ paths:
 
 	assert.False(t, valid)
 	assert.Len(t, errors, 1)
-	assert.Equal(t, "GET Path '/burgers/hello/locate' not found", errors[0].Message)
+	assert.Equal(t, "Path parameter 'burgerId' is not a valid integer", errors[0].Message)
 }
 
 func TestNewValidator_SimpleEncodedPath_InvalidBoolean(t *testing.T) {
@@ -335,7 +338,7 @@ paths:
 
 	assert.False(t, valid)
 	assert.Len(t, errors, 1)
-	assert.Equal(t, "Path parameter 'burgerId' is not a valid number", errors[0].Message)
+	assert.Equal(t, "Path parameter 'burgerId' is not a valid integer", errors[0].Message)
 }
 
 func TestNewValidator_LabelEncodedPath_InvalidBoolean(t *testing.T) {
@@ -681,7 +684,7 @@ paths:
 
 	assert.False(t, valid)
 	assert.Len(t, errors, 1)
-	assert.Equal(t, "Path parameter 'burgerId' is not a valid number", errors[0].Message)
+	assert.Equal(t, "Path parameter 'burgerId' is not a valid integer", errors[0].Message)
 }
 
 func TestNewValidator_MatrixEncodedPath_ValidPrimitiveBoolean(t *testing.T) {
@@ -1252,3 +1255,621 @@ paths:
 	assert.Equal(t, "Path parameter 'burgerId' does not match allowed values", errors[0].Message)
 	assert.Equal(t, "Instead of '22334', use one of the allowed values: '1, 2, 99, 100'", errors[0].HowToFix)
 }
+
+func TestNewValidator_PathParamMatrixStyleNoTemplatePrefix(t *testing.T) {
+
+	spec := `openapi: 3.1.0
+paths:
+  /burgers/{burgerId}/locate:
+    parameters:
+      - name: burgerId
+        in: path
+        style: matrix
+        schema:
+          type: integer
+    get:
+      operationId: locateBurgers`
+
+	doc, _ := libopenapi.NewDocument([]byte(spec))
+
+	m, _ := doc.BuildV3Model()
+
+	v := NewParameterValidator(&m.Model)
+
+	request, _ := http.NewRequest(http.MethodGet, "https://things.com/burgers/;burgerId=5/locate", nil)
+	valid, errors := v.ValidatePathParams(request)
+
+	assert.True(t, valid)
+	assert.Len(t, errors, 0)
+}
+
+func TestNewValidator_PathParamMatrixEmptyValue(t *testing.T) {
+
+	spec := `openapi: 3.1.0
+paths:
+  /burgers/{;color}/locate:
+    parameters:
+      - name: color
+        in: path
+        style: matrix
+        schema:
+          type: string
+    get:
+      operationId: locateBurgers`
+
+	doc, _ := libopenapi.NewDocument([]byte(spec))
+
+	m, _ := doc.BuildV3Model()
+
+	v := NewParameterValidator(&m.Model)
+
+	request, _ := http.NewRequest(http.MethodGet, "https://things.com/burgers/;color/locate", nil)
+	valid, errors := v.ValidatePathParams(request)
+
+	assert.True(t, valid)
+	assert.Len(t, errors, 0)
+}
+
+func TestNewValidator_PathParamMatrixEmptyValue_InvalidNumber(t *testing.T) {
+
+	spec := `openapi: 3.1.0
+paths:
+  /burgers/{;burgerId}/locate:
+    parameters:
+      - name: burgerId
+        in: path
+        style: matrix
+        schema:
+          type: integer
+    get:
+      operationId: locateBurgers`
+
+	doc, _ := libopenapi.NewDocument([]byte(spec))
+
+	m, _ := doc.BuildV3Model()
+
+	v := NewParameterValidator(&m.Model)
+
+	request, _ := http.NewRequest(http.MethodGet, "https://things.com/burgers/;burgerId/locate", nil)
+	valid, errors := v.ValidatePathParams(request)
+
+	assert.False(t, valid)
+	assert.Len(t, errors, 1)
+	assert.Equal(t, "Path parameter 'burgerId' is not a valid integer", errors[0].Message)
+}
+
+func TestNewValidator_PathParamMatrixEncodedSeparator(t *testing.T) {
+
+	spec := `openapi: 3.1.0
+paths:
+  /burgers/{;burger}/locate:
+    parameters:
+      - name: burger
+        in: path
+        style: matrix
+        schema:
+          type: string
+          enum: [big;mac, whopper]
+    get:
+      operationId: locateBurgers`
+
+	doc, _ := libopenapi.NewDocument([]byte(spec))
+
+	m, _ := doc.BuildV3Model()
+
+	v := NewParameterValidator(&m.Model)
+
+	request, _ := http.NewRequest(http.MethodGet, "https://things.com/burgers/;burger=big%3Bmac/locate", nil)
+	valid, errors := v.ValidatePathParams(request)
+
+	assert.True(t, valid)
+	assert.Len(t, errors, 0)
+}
+
+func TestNewValidator_PathParamMatrixExplodedArray_RepeatedKeys_Invalid(t *testing.T) {
+
+	spec := `openapi: 3.1.0
+paths:
+  /burgers/{;burger*}/locate:
+    parameters:
+      - name: burger
+        in: path
+        style: matrix
+        explode: true
+        schema:
+          type: array
+          items:
+            type: boolean
+    get:
+      operationId: locateBurgers`
+
+	doc, _ := libopenapi.NewDocument([]byte(spec))
+
+	m, _ := doc.BuildV3Model()
+
+	v := NewParameterValidator(&m.Model)
+
+	request, _ := http.NewRequest(http.MethodGet,
+		"https://things.com/burgers/;burger=true;burger=nope;burger=false;burger=1/locate", nil)
+	valid, errors := v.ValidatePathParams(request)
+
+	assert.False(t, valid)
+	assert.Len(t, errors, 2)
+	assert.Equal(t, "Path array parameter 'burger' is not a valid boolean", errors[0].Message)
+}
+
+func TestNewValidator_PathParamMatrixWrongKey(t *testing.T) {
+
+	spec := `openapi: 3.1.0
+paths:
+  /burgers/{;burgerId}/locate:
+    parameters:
+      - name: burgerId
+        in: path
+        style: matrix
+        schema:
+          type: integer
+    get:
+      operationId: locateBurgers`
+
+	doc, _ := libopenapi.NewDocument([]byte(spec))
+
+	m, _ := doc.BuildV3Model()
+
+	v := NewParameterValidator(&m.Model)
+
+	request, _ := http.NewRequest(http.MethodGet, "https://things.com/burgers/;burger=5/locate", nil)
+	valid, errors := v.ValidatePathParams(request)
+
+	assert.False(t, valid)
+	assert.Len(t, errors, 1)
+	assert.Equal(t, "Path parameter 'burgerId' matrix segment ';burger=5' is not valid", errors[0].Message)
+	assert.Equal(t, "Matrix style path parameters must start with a semicolon, followed by "+
+		"the parameter name and value. For example: ';burgerId=value'", errors[0].HowToFix)
+}
+
+func TestNewValidator_PathParamLabelStyleNoTemplatePrefix(t *testing.T) {
+
+	spec := `openapi: 3.1.0
+paths:
+  /users/{userId}:
+    parameters:
+      - name: userId
+        in: path
+        style: label
+        schema:
+          type: integer
+    get:
+      operationId: getUser`
+
+	doc, _ := libopenapi.NewDocument([]byte(spec))
+
+	m, _ := doc.BuildV3Model()
+
+	v := NewParameterValidator(&m.Model)
+
+	request, _ := http.NewRequest(http.MethodGet, "https://things.com/users/.5", nil)
+	valid, errors := v.ValidatePathParams(request)
+
+	assert.True(t, valid)
+	assert.Len(t, errors, 0)
+}
+
+func TestNewValidator_PathParamLabelBoolean(t *testing.T) {
+
+	spec := `openapi: 3.1.0
+paths:
+  /users/{.active}:
+    parameters:
+      - name: active
+        in: path
+        style: label
+        schema:
+          type: boolean
+    get:
+      operationId: getUser`
+
+	doc, _ := libopenapi.NewDocument([]byte(spec))
+
+	m, _ := doc.BuildV3Model()
+
+	v := NewParameterValidator(&m.Model)
+
+	request, _ := http.NewRequest(http.MethodGet, "https://things.com/users/.true", nil)
+	valid, errors := v.ValidatePathParams(request)
+
+	assert.True(t, valid)
+	assert.Len(t, errors, 0)
+
+	request, _ = http.NewRequest(http.MethodGet, "https://things.com/users/.nope", nil)
+	valid, errors = v.ValidatePathParams(request)
+
+	assert.False(t, valid)
+	assert.Len(t, errors, 1)
+	assert.Equal(t, "Path parameter 'active' is not a valid boolean", errors[0].Message)
+}
+
+func TestNewValidator_PathParamLabelArray(t *testing.T) {
+
+	spec := `openapi: 3.1.0
+paths:
+  /users/{.ids}:
+    parameters:
+      - name: ids
+        in: path
+        style: label
+        schema:
+          type: array
+          items:
+            type: integer
+    get:
+      operationId: getUsers`
+
+	doc, _ := libopenapi.NewDocument([]byte(spec))
+
+	m, _ := doc.BuildV3Model()
+
+	v := NewParameterValidator(&m.Model)
+
+	request, _ := http.NewRequest(http.MethodGet, "https://things.com/users/.3,4,5", nil)
+	valid, errors := v.ValidatePathParams(request)
+
+	assert.True(t, valid)
+	assert.Len(t, errors, 0)
+}
+
+func TestNewValidator_PathParamLabelExplodedArray_InvalidEnum(t *testing.T) {
+
+	spec := `openapi: 3.1.0
+paths:
+  /users/{.ids*}:
+    parameters:
+      - name: ids
+        in: path
+        style: label
+        explode: true
+        schema:
+          type: array
+          items:
+            type: integer
+            enum: [3, 4, 5]
+    get:
+      operationId: getUsers`
+
+	doc, _ := libopenapi.NewDocument([]byte(spec))
+
+	m, _ := doc.BuildV3Model()
+
+	v := NewParameterValidator(&m.Model)
+
+	request, _ := http.NewRequest(http.MethodGet, "https://things.com/users/.3.4.5", nil)
+	valid, errors := v.ValidatePathParams(request)
+
+	assert.True(t, valid)
+	assert.Len(t, errors, 0)
+
+	request, _ = http.NewRequest(http.MethodGet, "https://things.com/users/.3.9.5", nil)
+	valid, errors = v.ValidatePathParams(request)
+
+	assert.False(t, valid)
+	assert.Len(t, errors, 1)
+	assert.Equal(t, "Path array parameter 'ids' does not match allowed values", errors[0].Message)
+	assert.Equal(t, "The path array parameter 'ids' has pre-defined values set via an enum. "+
+		"The value '9' at index 1 is not one of those values.", errors[0].Reason)
+	assert.Equal(t, "Instead of '9', use one of the allowed values: '3, 4, 5'", errors[0].HowToFix)
+}
+
+func TestNewValidator_PathParamLabelMissingValue(t *testing.T) {
+
+	spec := `openapi: 3.1.0
+paths:
+  /users/{.ids}:
+    parameters:
+      - name: ids
+        in: path
+        style: label
+        schema:
+          type: array
+          items:
+            type: string
+    get:
+      operationId: getUsers`
+
+	doc, _ := libopenapi.NewDocument([]byte(spec))
+
+	m, _ := doc.BuildV3Model()
+
+	v := NewParameterValidator(&m.Model)
+
+	request, _ := http.NewRequest(http.MethodGet, "https://things.com/users/.", nil)
+	valid, errors := v.ValidatePathParams(request)
+
+	assert.False(t, valid)
+	assert.Len(t, errors, 1)
+	assert.Equal(t, "Path parameter 'ids' label segment '.' is not valid", errors[0].Message)
+}
+
+func TestNewValidator_PathParamWithServerBasePath(t *testing.T) {
+
+	spec := `openapi: 3.1.0
+servers:
+  - url: https://{region}.api.com/v2
+    variables:
+      region:
+        default: eu
+paths:
+  /pet/{kind}:
+    get:
+      parameters:
+        - name: kind
+          in: path
+          required: true
+          schema:
+            type: string
+            enum: [dog, cat]
+`
+
+	doc, _ := libopenapi.NewDocument([]byte(spec))
+	m, _ := doc.BuildV3Model()
+	v := NewParameterValidator(&m.Model)
+
+	request, _ := http.NewRequest(http.MethodGet, "https://eu.api.com/v2/pet/dog", nil)
+	valid, errors := v.ValidatePathParams(request)
+	assert.True(t, valid)
+	assert.Len(t, errors, 0)
+
+	request, _ = http.NewRequest(http.MethodGet, "https://eu.api.com/v2/pet/fish", nil)
+	valid, errors = v.ValidatePathParams(request)
+	assert.False(t, valid)
+	assert.Len(t, errors, 1)
+	assert.Equal(t, "Path parameter 'kind' does not match allowed values", errors[0].Message)
+}
+
+func TestNewValidator_PathParamNormalizedPath(t *testing.T) {
+
+	spec := `openapi: 3.1.0
+paths:
+  /pet/{kind}:
+    get:
+      parameters:
+        - name: kind
+          in: path
+          required: true
+          schema:
+            type: string
+            enum: [dog, cat]
+`
+
+	doc, _ := libopenapi.NewDocument([]byte(spec))
+	m, _ := doc.BuildV3Model()
+	v := NewParameterValidator(&m.Model)
+
+	request, _ := http.NewRequest(http.MethodGet, "https://things.com//pet//dog/", nil)
+	valid, errors := v.ValidatePathParams(request)
+	assert.True(t, valid)
+	assert.Len(t, errors, 0)
+}
+
+func TestNewValidator_PathParamTemplateMismatch(t *testing.T) {
+
+	spec := `openapi: 3.1.0
+paths:
+  /pet/{petID}:
+    get:
+      parameters:
+        - name: petId
+          in: path
+          required: true
+          schema:
+            type: string
+`
+
+	doc, _ := libopenapi.NewDocument([]byte(spec))
+	m, _ := doc.BuildV3Model()
+	v := NewParameterValidator(&m.Model)
+
+	request, _ := http.NewRequest(http.MethodGet, "https://things.com/pet/rover", nil)
+	valid, errors := v.ValidatePathParams(request)
+	assert.False(t, valid)
+	assert.Len(t, errors, 2)
+	assert.Equal(t, "Path parameter 'petID' of the path '/pet/{petID}' is not defined", errors[0].Message)
+	assert.Equal(t, "Path parameter 'petId' is not part of the path '/pet/{petID}'", errors[1].Message)
+	assert.Equal(t, 6, errors[1].SpecLine)
+}
+
+func TestNewValidator_PathParamEmptyValue(t *testing.T) {
+
+	spec := `openapi: 3.1.0
+paths:
+  /pet/{kind}/toys:
+    get:
+      parameters:
+        - name: kind
+          in: path
+          required: true
+          schema:
+            type: string
+`
+
+	doc, _ := libopenapi.NewDocument([]byte(spec))
+	m, _ := doc.BuildV3Model()
+	v := NewParameterValidator(&m.Model, config.WithStrictPathMatching())
+
+	request, _ := http.NewRequest(http.MethodGet, "https://things.com/pet//toys", nil)
+	valid, errors := v.ValidatePathParamsWithPathItem(context.Background(), request,
+		m.Model.Paths.PathItems["/pet/{kind}/toys"], "/pet/{kind}/toys")
+	assert.False(t, valid)
+	assert.Len(t, errors, 1)
+	assert.Equal(t, "Path parameter 'kind' is missing", errors[0].Message)
+}
+
+func TestNewValidator_PathParamNullable(t *testing.T) {
+
+	spec := `openapi: 3.1.0
+paths:
+  /burgers/{burgerId}/locate:
+    get:
+      parameters:
+        - name: burgerId
+          in: path
+          required: true
+          schema:
+            type: [integer, "null"]`
+
+	doc, _ := libopenapi.NewDocument([]byte(spec))
+	m, _ := doc.BuildV3Model()
+	v := NewParameterValidator(&m.Model)
+
+	for _, id := range []string{"1234", "null"} {
+		request, _ := http.NewRequest(http.MethodGet, "https://things.com/burgers/"+id+"/locate", nil)
+
+		valid, errors := v.ValidatePathParams(request)
+		assert.True(t, valid, id)
+		assert.Len(t, errors, 0)
+	}
+
+	// the path is matched, so the value can be validated against the path item.
+	request, _ := http.NewRequest(http.MethodGet, "https://things.com/burgers/big-mac/locate", nil)
+	valid, errors := v.ValidatePathParamsWithPathItem(context.Background(), request,
+		m.Model.Paths.PathItems["/burgers/{burgerId}/locate"], "/burgers/{burgerId}/locate")
+	assert.False(t, valid)
+	assert.Len(t, errors, 1)
+	assert.Equal(t, "Path parameter 'burgerId' is not a valid 'integer, null'", errors[0].Message)
+}
+
+func TestNewValidator_PathParamIntegerCoercion(t *testing.T) {
+
+	spec := `openapi: 3.1.0
+paths:
+  /pet/{petId}:
+    get:
+      parameters:
+        - name: petId
+          in: path
+          required: true
+          schema:
+            type: integer
+  /pet/{petId}/adopted/{adopted}:
+    get:
+      parameters:
+        - name: petId
+          in: path
+          required: true
+          schema:
+            type: integer
+        - name: adopted
+          in: path
+          required: true
+          schema:
+            type: boolean`
+
+	doc, _ := libopenapi.NewDocument([]byte(spec))
+	m, _ := doc.BuildV3Model()
+	v := NewParameterValidator(&m.Model)
+
+	for _, path := range []string{"/pet/12", "/pet/12/adopted/true"} {
+		request, _ := http.NewRequest(http.MethodGet, "https://things.com"+path, nil)
+
+		valid, errors := v.ValidatePathParams(request)
+		assert.True(t, valid, path)
+		assert.Len(t, errors, 0)
+	}
+
+	// a value that is not an integer is reported against the parameter, the path is still found.
+	for _, petId := range []string{"abc", "1.5"} {
+		request, _ := http.NewRequest(http.MethodGet, "https://things.com/pet/"+petId, nil)
+
+		valid, errors := v.ValidatePathParams(request)
+		assert.False(t, valid, petId)
+		assert.Len(t, errors, 1)
+		assert.Equal(t, "Path parameter 'petId' is not a valid integer", errors[0].Message)
+		assert.Equal(t, "The path parameter 'petId' is defined as being an integer, "+
+			"however the value '"+petId+"' is not a valid integer", errors[0].Reason)
+	}
+
+	request, _ := http.NewRequest(http.MethodGet, "https://things.com/pet/12/adopted/maybe", nil)
+
+	valid, errors := v.ValidatePathParams(request)
+	assert.False(t, valid)
+	assert.Len(t, errors, 1)
+	assert.Equal(t, "Path parameter 'adopted' is not a valid boolean", errors[0].Message)
+}
+
+func TestNewValidator_PathParamPercentEncoded(t *testing.T) {
+
+	spec := `openapi: 3.1.0
+paths:
+  /pet/{name}:
+    get:
+      parameters:
+        - name: name
+          in: path
+          required: true
+          schema:
+            type: string
+            enum: [foo bar, cats/dogs]`
+
+	doc, _ := libopenapi.NewDocument([]byte(spec))
+	m, _ := doc.BuildV3Model()
+	v := NewParameterValidator(&m.Model)
+
+	// the decoded value is checked against the enum, an encoded slash is part of the value.
+	for _, name := range []string{"foo%20bar", "cats%2Fdogs", "cats%2fdogs"} {
+		request, _ := http.NewRequest(http.MethodGet, "https://things.com/pet/"+name, nil)
+
+		valid, errors := v.ValidatePathParams(request)
+		assert.True(t, valid, name)
+		assert.Len(t, errors, 0)
+	}
+
+	request, _ := http.NewRequest(http.MethodGet, "https://things.com/pet/foo%2Bbar", nil)
+
+	valid, errors := v.ValidatePathParams(request)
+	assert.False(t, valid)
+	assert.Len(t, errors, 1)
+	assert.Equal(t, "The path parameter 'name' has pre-defined values setvia an enum. "+
+		"The value 'foo+bar' is not one of those values.", errors[0].Reason)
+}
+
+func TestNewValidator_PathParamCustomFormat(t *testing.T) {
+
+	spec := `openapi: 3.1.0
+paths:
+  /burgers/{sku}/{burgerId}:
+    get:
+      parameters:
+        - name: sku
+          in: path
+          required: true
+          schema:
+            type: string
+            format: sku
+        - name: burgerId
+          in: path
+          required: true
+          schema:
+            type: string
+            format: uuid
+            pattern: ^[a-f0-9-]{36}$`
+
+	doc, _ := libopenapi.NewDocument([]byte(spec))
+	m, _ := doc.BuildV3Model()
+	v := NewParameterValidator(&m.Model, config.WithCustomFormat("sku", func(s string) bool {
+		return strings.HasPrefix(s, "SKU-")
+	}))
+
+	// only a custom format is checked, the pattern and built-in format of other string params are not.
+	request, _ := http.NewRequest(http.MethodGet, "https://things.com/burgers/SKU-123/not-a-uuid", nil)
+
+	valid, errors := v.ValidatePathParams(request)
+	assert.True(t, valid)
+	assert.Len(t, errors, 0)
+
+	request, _ = http.NewRequest(http.MethodGet, "https://things.com/burgers/big-mac/not-a-uuid", nil)
+
+	valid, errors = v.ValidatePathParams(request)
+	assert.False(t, valid)
+	assert.Len(t, errors, 1)
+	assert.Equal(t, "Path parameter 'sku' failed to validate", errors[0].Message)
+	assert.Equal(t, "'big-mac' is not valid 'sku'", errors[0].SchemaValidationErrors[0].Reason)
+}
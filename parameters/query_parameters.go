@@ -4,214 +4,124 @@
 package parameters
 
 import (
-	"encoding/json"
+	"context"
 	"fmt"
 	"github.com/pb33f/libopenapi-validator/errors"
 	"github.com/pb33f/libopenapi-validator/helpers"
-	"github.com/pb33f/libopenapi-validator/paths"
 	"github.com/pb33f/libopenapi/datamodel/high/base"
 	"github.com/pb33f/libopenapi/datamodel/high/v3"
 	"net/http"
+	"net/url"
 	"regexp"
+	"slices"
+	"sort"
 	"strconv"
 	"strings"
 )
 
 func (v *paramValidator) ValidateQueryParams(request *http.Request) (bool, []*errors.ValidationError) {
+	return v.ValidateQueryParamsWithContext(context.Background(), request)
+}
+
+func (v *paramValidator) ValidateQueryParamsWithContext(
+	ctx context.Context,
+	request *http.Request) (bool, []*errors.ValidationError) {
 
-	// find path
-	var pathItem *v3.PathItem
-	var errs []*errors.ValidationError
-	if v.pathItem == nil {
-		pathItem, errs, _ = paths.FindPath(request, v.document)
-		if pathItem == nil || errs != nil {
-			v.errors = errs
-			return false, errs
+	pathItem, pathValue, errs := v.findPath(request)
+	if pathItem == nil {
+		return false, errs
+	}
+	valid, validationErrors := v.ValidateQueryParamsWithPathItem(ctx, request, pathItem, pathValue)
+	if ctx.Err() == nil {
+		v.ApplyQueryDefaults(request, pathItem)
+	}
+	return valid, validationErrors
+}
+
+// ApplyQueryDefaults will add the schema default of every optional query parameter that is missing from the request
+// to the query of the request, when defaults are applied (see config.WithApplyDefaults). The request is changed, so
+// it must not be called while the request is still being validated.
+func (v *paramValidator) ApplyQueryDefaults(request *http.Request, pathItem *v3.PathItem) {
+	if !v.options.ApplyDefaults || pathItem == nil {
+		return
+	}
+	sent := make(map[string]bool)
+	for qKey := range request.URL.Query() {
+		// properties of a deepObject (filter[a]) are sent for the param they belong to.
+		if strings.IndexRune(qKey, '[') > 0 && strings.LastIndex(qKey, "]") > strings.IndexRune(qKey, '[') {
+			qKey = qKey[:strings.IndexRune(qKey, '[')]
 		}
-	} else {
-		pathItem = v.pathItem
+		sent[qKey] = true
 	}
+	malformedValues := helpers.ExtractMalformedQueryValues(request.URL.RawQuery)
+	for _, param := range helpers.ExtractParamsForOperation(request, pathItem) {
+		if param.In != helpers.Query || param.Required || sent[param.Name] || len(malformedValues[param.Name]) > 0 {
+			continue
+		}
+		if values := defaultQueryValues(param); values != nil {
+			addQueryValues(request, param.Name, values)
+		}
+	}
+}
+
+func (v *paramValidator) ValidateQueryParamsWithPathItem(
+	ctx context.Context,
+	request *http.Request,
+	pathItem *v3.PathItem,
+	pathValue string) (valid bool, validationErrors []*errors.ValidationError) {
+
+	// errors are labelled with the operation they were found in.
+	defer func() { errors.SetOperation(validationErrors, request, pathItem, pathValue) }()
 
 	// extract params for the operation
 	var params = helpers.ExtractParamsForOperation(request, pathItem)
 	queryParams := make(map[string][]*helpers.QueryParam)
-	var validationErrors []*errors.ValidationError
 
+	rawValues := helpers.ExtractRawQueryValues(request.URL.RawQuery)
+	malformedValues := helpers.ExtractMalformedQueryValues(request.URL.RawQuery)
 	for qKey, qVal := range request.URL.Query() {
-		// check if the param is encoded as a property / deepObject
-		if strings.IndexRune(qKey, '[') > 0 && strings.IndexRune(qKey, ']') > 0 {
+		// check if the param is encoded as a property / deepObject, nested properties (filter[a][b]) keep
+		// everything between the outer brackets, so they can be re-constructed later.
+		if strings.IndexRune(qKey, '[') > 0 && strings.LastIndex(qKey, "]") > strings.IndexRune(qKey, '[') {
 			stripped := qKey[:strings.IndexRune(qKey, '[')]
-			value := qKey[strings.IndexRune(qKey, '[')+1 : strings.IndexRune(qKey, ']')]
+			value := qKey[strings.IndexRune(qKey, '[')+1 : strings.LastIndex(qKey, "]")]
 			queryParams[stripped] = append(queryParams[stripped], &helpers.QueryParam{
-				Key:      stripped,
-				Values:   qVal,
-				Property: value,
+				Key:       stripped,
+				Values:    qVal,
+				Property:  value,
+				RawValues: rawValues[qKey],
 			})
 		} else {
 			queryParams[qKey] = append(queryParams[qKey], &helpers.QueryParam{
-				Key:    qKey,
-				Values: qVal,
+				Key:       qKey,
+				Values:    qVal,
+				RawValues: rawValues[qKey],
 			})
 		}
 	}
 
-	// look through the params for the query key
-doneLooking:
+	// look through the params for the query key, every param is checked so all violations are reported.
 	for p := range params {
+		if ctx.Err() != nil {
+			return false, []*errors.ValidationError{errors.ValidationCancelled(ctx)}
+		}
 		if params[p].In == helpers.Query {
 
-			contentWrapped := false
-			var contentType string
+			// values that cannot be percent-decoded can't be validated, the parameter was still sent.
+			for _, malformed := range malformedValues[params[p].Name] {
+				validationErrors = append(validationErrors, errors.IncorrectQueryParamEncoding(params[p], malformed))
+			}
+
 			// check if this param is found as a set of query strings
 			if jk, ok := queryParams[params[p].Name]; ok {
-			skipValues:
-				for _, fp := range jk {
-					// let's check styles first.
-					validationErrors = append(validationErrors, ValidateQueryParamStyle(params[p], jk)...)
-
-					// there is a match, is the type correct
-					// this context is extracted from the 3.1 spec to explain what is going on here:
-					// For more complex scenarios, the content property can define the media type and schema of the
-					// parameter. A parameter MUST contain either a schema property, or a content property, but not both.
-					// The map MUST only contain one entry. (for content)
-					var sch *base.Schema
-					if params[p].Schema != nil {
-						sch = params[p].Schema.Schema()
-					} else {
-						// ok, no schema, check for a content type
-						if params[p].Content != nil {
-							for k, ct := range params[p].Content {
-								sch = ct.Schema.Schema()
-								contentWrapped = true
-								contentType = k
-								break
-							}
-						}
-					}
-					pType := sch.Type
-
-					// for each param, check each type
-					for _, ef := range fp.Values {
-
-						// check allowReserved values. If this is set to true, then we can allow the
-						// following characters
-						//  :/?#[]@!$&'()*+,;=
-						// to be present as they are, without being URLEncoded.
-						if !params[p].AllowReserved {
-							rx := `[:\/\?#\[\]\@!\$&'\(\)\*\+,;=]`
-							regexp.MustCompile(rx)
-							if regexp.MustCompile(rx).MatchString(ef) && params[p].IsExploded() {
-								validationErrors = append(validationErrors,
-									errors.IncorrectReservedValues(params[p], ef, sch))
-							}
-						}
-						for _, ty := range pType {
-							switch ty {
-
-							case helpers.String:
-
-								// check if the param is within an enum
-								if sch.Enum != nil {
-									matchFound := false
-									for _, enumVal := range sch.Enum {
-										if strings.TrimSpace(ef) == fmt.Sprint(enumVal) {
-											matchFound = true
-											break
-										}
-									}
-									if !matchFound {
-										validationErrors = append(validationErrors,
-											errors.IncorrectQueryParamEnum(params[p], ef, sch))
-									}
-								}
-
-							case helpers.Integer, helpers.Number:
-								if _, err := strconv.ParseFloat(ef, 64); err != nil {
-									validationErrors = append(validationErrors,
-										errors.InvalidQueryParamNumber(params[p], ef, sch))
-									break
-								}
-								// check if the param is within an enum
-								if sch.Enum != nil {
-									matchFound := false
-									for _, enumVal := range sch.Enum {
-										if strings.TrimSpace(ef) == fmt.Sprint(enumVal) {
-											matchFound = true
-											break
-										}
-									}
-									if !matchFound {
-										validationErrors = append(validationErrors,
-											errors.IncorrectQueryParamEnum(params[p], ef, sch))
-									}
-								}
-
-							case helpers.Boolean:
-								if _, err := strconv.ParseBool(ef); err != nil {
-									validationErrors = append(validationErrors,
-										errors.IncorrectQueryParamBool(params[p], ef, sch))
-								}
-							case helpers.Object:
-
-								// check what style of encoding was used and then construct a map[string]interface{}
-								// and pass that in as encoded JSON.
-								var encodedObj map[string]interface{}
-
-								switch params[p].Style {
-								case helpers.DeepObject:
-									encodedObj = helpers.ConstructParamMapFromDeepObjectEncoding(jk)
-								case helpers.PipeDelimited:
-									encodedObj = helpers.ConstructParamMapFromPipeEncoding(jk)
-								case helpers.SpaceDelimited:
-									encodedObj = helpers.ConstructParamMapFromSpaceEncoding(jk)
-								default:
-									// form encoding is default.
-									if contentWrapped {
-										switch contentType {
-										case helpers.JSONContentType:
-											// we need to unmarshal the JSON into a map[string]interface{}
-											encodedParams := make(map[string]interface{})
-											encodedObj = make(map[string]interface{})
-											if err := json.Unmarshal([]byte(ef), &encodedParams); err != nil {
-												validationErrors = append(validationErrors,
-													errors.IncorrectParamEncodingJSON(params[p], ef, sch))
-												break skipValues
-											}
-											encodedObj[params[p].Name] = encodedParams
-										}
-									} else {
-										encodedObj = helpers.ConstructParamMapFromFormEncodingArray(jk)
-									}
-								}
-
-								numErrors := len(validationErrors)
-								validationErrors = append(validationErrors,
-									ValidateParameterSchema(sch, encodedObj[params[p].Name].(map[string]interface{}),
-										ef,
-										"Query parameter",
-										"The query parameter",
-										params[p].Name,
-										helpers.ParameterValidation,
-										helpers.ParameterValidationQuery)...)
-								if len(validationErrors) > numErrors {
-									// we've already added an error for this, so we can skip the rest of the values
-									break skipValues
-								}
-
-							case helpers.Array:
-								// well we're already in an array, so we need to check the items schema
-								// to ensure this array items matches the type
-								// only check if items is a schema, not a boolean
-								if sch.Items.IsA() {
-									validationErrors = append(validationErrors,
-										ValidateQueryArray(sch, params[p], ef, contentWrapped)...)
-								}
-							}
-						}
-					}
+				if params[p].Deprecated && v.options.DeprecationWarnings {
+					validationErrors = append(validationErrors, errors.ParameterDeprecated(params[p]))
 				}
-
-			} else {
+				if params[p].AllowReserved {
+					jk = decodeReservedValues(jk)
+				}
+				validationErrors = append(validationErrors, v.validateQueryParam(params[p], jk)...)
+			} else if len(malformedValues[params[p].Name]) == 0 {
 				// if the param is not in the requests, so let's check if this param is an
 				// object, and if we should use default encoding and explode values.
 				if params[p].Schema != nil {
@@ -229,8 +139,18 @@ doneLooking:
 								"The query parameter (which is an array)",
 								params[p].Name,
 								helpers.ParameterValidation,
-								helpers.ParameterValidationQuery)...)
-						break doneLooking
+								helpers.ParameterValidationQuery,
+								v.schemas)...)
+						continue
+					}
+				}
+				// the default of an optional param is validated as if it was sent, it's added to the request by
+				// ApplyQueryDefaults, once every validation of the request has finished.
+				if !params[p].Required && v.options.ApplyDefaults {
+					if values := defaultQueryValues(params[p]); values != nil {
+						validationErrors = append(validationErrors, v.validateQueryParam(params[p],
+							[]*helpers.QueryParam{{Key: params[p].Name, Values: values}})...)
+						continue
 					}
 				}
 				// if there is no match, check if the param is required or not.
@@ -241,9 +161,339 @@ doneLooking:
 		}
 	}
 
-	v.errors = validationErrors
+	if v.options.RejectUndeclaredQueryParams {
+		validationErrors = append(validationErrors, undeclaredQueryParams(request, params, queryParams)...)
+	}
+
+	if errors.HasErrors(validationErrors) {
+		return false, validationErrors
+	}
+	return true, validationErrors
+}
+
+// undeclaredQueryParams will return an error for every query parameter of the request that is not declared. The
+// properties of an object parameter that uses form encoding are sent as parameters of their own, so they are
+// declared too. An object parameter of this kind without any properties accepts any parameter.
+func undeclaredQueryParams(request *http.Request,
+	params []*v3.Parameter,
+	queryParams map[string][]*helpers.QueryParam) []*errors.ValidationError {
+
+	declared := make(map[string]bool)
+	for _, param := range params {
+		if param.In != helpers.Query {
+			continue
+		}
+		declared[param.Name] = true
+		if param.Schema == nil || !param.IsDefaultFormEncoding() {
+			continue
+		}
+		if sch := param.Schema.Schema(); sch != nil && slices.Contains(sch.Type, helpers.Object) {
+			if len(sch.Properties) == 0 {
+				return nil
+			}
+			for name := range sch.Properties {
+				declared[name] = true
+			}
+		}
+	}
+
+	var names []string
+	for name := range queryParams {
+		if !declared[name] {
+			names = append(names, name)
+		}
+	}
+	sort.Strings(names)
+
+	var validationErrors []*errors.ValidationError
+	for _, name := range names {
+		validationErrors = append(validationErrors, errors.QueryParameterNotDeclared(name, request))
+	}
+	return validationErrors
+}
+
+func (v *paramValidator) ValidateQueryParamValue(param *v3.Parameter, rawValue string) (bool, []*errors.ValidationError) {
+	validationErrors := v.validateQueryParam(param, []*helpers.QueryParam{{Key: param.Name, Values: []string{rawValue}}})
 	if len(validationErrors) > 0 {
 		return false, validationErrors
 	}
 	return true, nil
 }
+
+// defaultQueryValues will return the default of a query parameter, encoded as it would be sent in a query string.
+// nil is returned if there is no default, or the default is an object.
+func defaultQueryValues(param *v3.Parameter) []string {
+	if param.Schema == nil {
+		return nil
+	}
+	sch := param.Schema.Schema()
+	if sch == nil || sch.Default == nil {
+		return nil
+	}
+	switch def := sch.Default.(type) {
+	case map[string]any:
+		return nil
+	case []any:
+		values := make([]string, 0, len(def))
+		for _, item := range def {
+			values = append(values, fmt.Sprint(item))
+		}
+		if param.IsExploded() {
+			return values
+		}
+		delimiter := helpers.Comma
+		switch param.Style {
+		case helpers.SpaceDelimited:
+			delimiter = helpers.Space
+		case helpers.PipeDelimited:
+			delimiter = helpers.Pipe
+		}
+		return []string{strings.Join(values, delimiter)}
+	default:
+		return []string{fmt.Sprint(def)}
+	}
+}
+
+// addQueryValues will append values to the query string of the request, without re-encoding the existing query.
+func addQueryValues(request *http.Request, name string, values []string) {
+	for _, value := range values {
+		if request.URL.RawQuery != "" {
+			request.URL.RawQuery += "&"
+		}
+		request.URL.RawQuery += url.QueryEscape(name) + helpers.Equals + url.QueryEscape(value)
+	}
+}
+
+// decodeReservedValues will decode the values of a parameter that allows reserved characters from the values as
+// they were sent. Reserved characters are sent as they are, so a '+' is a plus rather than an encoded space.
+func decodeReservedValues(jk []*helpers.QueryParam) []*helpers.QueryParam {
+	decoded := make([]*helpers.QueryParam, 0, len(jk))
+	for _, fp := range jk {
+		if len(fp.RawValues) != len(fp.Values) {
+			decoded = append(decoded, fp)
+			continue
+		}
+		values := make([]string, len(fp.Values))
+		for i, raw := range fp.RawValues {
+			values[i] = fp.Values[i]
+			if value, err := url.PathUnescape(raw); err == nil {
+				values[i] = value
+			}
+		}
+		decoded = append(decoded, &helpers.QueryParam{
+			Key: fp.Key, Values: values, Property: fp.Property, RawValues: fp.RawValues})
+	}
+	return decoded
+}
+
+var reservedCharactersRegex = regexp.MustCompile(`[:\/\?#\[\]\@!\$&'\(\)\*\+,;=]`)
+
+// validateQueryParam will validate all the values supplied for a single query parameter. The values are
+// keyed by the parameter name (and property, if a deepObject was used).
+func (v *paramValidator) validateQueryParam(param *v3.Parameter, jk []*helpers.QueryParam) []*errors.ValidationError {
+	var validationErrors []*errors.ValidationError
+	contentWrapped := false
+	var contentType string
+	for _, fp := range jk {
+		// let's check styles first.
+		validationErrors = append(validationErrors, ValidateQueryParamStyle(param, jk)...)
+
+		// there is a match, is the type correct
+		// this context is extracted from the 3.1 spec to explain what is going on here:
+		// For more complex scenarios, the content property can define the media type and schema of the
+		// parameter. A parameter MUST contain either a schema property, or a content property, but not both.
+		// The map MUST only contain one entry. (for content)
+		var sch *base.Schema
+		if param.Schema != nil {
+			sch = param.Schema.Schema()
+		} else {
+			// ok, no schema, check for a content type
+			if param.Content != nil {
+				for k, ct := range param.Content {
+					if ct.Schema != nil {
+						sch = ct.Schema.Schema()
+					}
+					contentWrapped = true
+					contentType = k
+					break
+				}
+			}
+		}
+		var pType []string
+		if sch != nil {
+			pType = sch.Type
+		}
+
+		// for each param, check each type
+		var contentValues []string
+		for i, ef := range fp.Values {
+
+			// an empty value (?debug or ?debug=) passes when the parameter allows empty values, otherwise it's
+			// checked against the schema like any other value.
+			if ef == "" && param.AllowEmptyValue {
+				continue
+			}
+
+			// check allowReserved values. If this is set to true, then we can allow the
+			// following characters
+			//  :/?#[]@!$&'()*+,;=
+			// to be present as they are, without being URLEncoded. Values are checked as they were sent, so
+			// reserved characters that were encoded are not reported.
+			if !param.AllowReserved {
+				sent := ef
+				if i < len(fp.RawValues) {
+					sent = fp.RawValues[i]
+				}
+				if reservedCharactersRegex.MatchString(sent) && param.IsExploded() {
+					validationErrors = append(validationErrors,
+						errors.IncorrectReservedValues(param, ef, sch))
+				}
+			}
+			// a value serialized using a media type is parsed using it, rather than the style of the parameter.
+			if contentWrapped {
+				contentValues = append(contentValues, ef)
+				continue
+			}
+
+			// primitive values always arrive as strings, so they need to be coerced into the declared
+			// type(s) before they can be validated against the schema.
+			if helpers.IsPrimitiveType(pType) {
+				validationErrors = append(validationErrors,
+					v.validateQueryPrimitive(sch, param, ef)...)
+				continue
+			}
+			for _, ty := range pType {
+				switch ty {
+
+				case helpers.String:
+
+					// check if the param is within an enum
+					if sch.Enum != nil {
+						if _, ok := helpers.MatchEnum(ef, sch.Enum, v.options.CaseInsensitiveEnums); !ok {
+							validationErrors = append(validationErrors,
+								errors.IncorrectQueryParamEnum(param, ef, sch))
+						}
+					}
+
+				case helpers.Integer, helpers.Number:
+					if _, err := strconv.ParseFloat(ef, 64); err != nil {
+						validationErrors = append(validationErrors,
+							errors.InvalidQueryParamNumber(param, ef, sch))
+						break
+					}
+					// check if the param is within an enum
+					if sch.Enum != nil {
+						matchFound := false
+						for _, enumVal := range sch.Enum {
+							if strings.TrimSpace(ef) == fmt.Sprint(enumVal) {
+								matchFound = true
+								break
+							}
+						}
+						if !matchFound {
+							validationErrors = append(validationErrors,
+								errors.IncorrectQueryParamEnum(param, ef, sch))
+						}
+					}
+
+				case helpers.Boolean:
+					if _, err := strconv.ParseBool(ef); err != nil {
+						validationErrors = append(validationErrors,
+							errors.IncorrectQueryParamBool(param, ef, sch))
+					}
+				case helpers.Object:
+
+					// check what style of encoding was used and then construct a map[string]interface{}
+					// and pass that in as encoded JSON.
+					var encodedObj map[string]interface{}
+
+					switch param.Style {
+					case helpers.DeepObject:
+						encodedObj = helpers.ConstructParamMapFromDeepObjectEncoding(jk)
+					case helpers.PipeDelimited:
+						encodedObj = helpers.ConstructParamMapFromPipeEncoding(jk)
+					case helpers.SpaceDelimited:
+						encodedObj = helpers.ConstructParamMapFromSpaceEncoding(jk)
+					default:
+						// form encoding is default.
+						encodedObj = helpers.ConstructParamMapFromFormEncodingArray(jk)
+					}
+
+					numErrors := len(validationErrors)
+					validationErrors = append(validationErrors,
+						ValidateParameterSchema(sch, encodedObj[param.Name].(map[string]interface{}),
+							ef,
+							"Query parameter",
+							"The query parameter",
+							param.Name,
+							helpers.ParameterValidation,
+							helpers.ParameterValidationQuery,
+							v.schemas)...)
+					if len(validationErrors) > numErrors {
+						// we've already added an error for this, so we can skip the rest of the values
+						return validationErrors
+					}
+
+				case helpers.Array:
+					// well we're already in an array, so we need to check the items schema
+					// to ensure this array items matches the type
+					// only check if items is a schema, not a boolean
+					if sch.Items.IsA() {
+						validationErrors = append(validationErrors,
+							v.validateQueryArray(sch, param, ef, false)...)
+					}
+				}
+			}
+		}
+		if len(contentValues) > 0 {
+			validationErrors = append(validationErrors,
+				v.validateQueryContent(param, contentType, sch, contentValues)...)
+		}
+	}
+
+	return validationErrors
+}
+
+// validateQueryContent will validate a query parameter that declares how it's serialized using 'content' (a media
+// type), rather than a schema and a style. JSON values are parsed, and a value that is not valid JSON is reported
+// as such, before the parsed value is validated against the schema of the media type. The values of any other media
+// type are validated as a string. An array parameter can be sent more than once, each value is an item of the array.
+func (v *paramValidator) validateQueryContent(param *v3.Parameter,
+	contentType string,
+	sch *base.Schema,
+	values []string) []*errors.ValidationError {
+
+	decoded := make([]any, len(values))
+	for i, value := range values {
+		decoded[i] = value
+		if strings.Contains(strings.ToLower(contentType), helpers.JSONType) {
+			var err error
+			if decoded[i], err = helpers.UnmarshalJSON([]byte(value)); err != nil {
+				return []*errors.ValidationError{errors.IncorrectParamEncodingJSON(param, value, sch)}
+			}
+		}
+	}
+	if sch == nil {
+		return nil
+	}
+
+	validate := func(value any, raw string) []*errors.ValidationError {
+		return ValidateParameterSchema(sch,
+			value,
+			raw,
+			"Query parameter",
+			"The query parameter",
+			param.Name,
+			helpers.ParameterValidation,
+			helpers.ParameterValidationQuery,
+			v.schemas)
+	}
+	if len(values) > 1 && slices.Contains(sch.Type, helpers.Array) {
+		return validate(decoded, strings.Join(values, helpers.Comma))
+	}
+	var validationErrors []*errors.ValidationError
+	for i := range values {
+		validationErrors = append(validationErrors, validate(decoded[i], values[i])...)
+	}
+	return validationErrors
+}
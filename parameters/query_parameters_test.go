@@ -4,10 +4,15 @@
 package parameters
 
 import (
+	"context"
 	"github.com/pb33f/libopenapi"
+	"github.com/pb33f/libopenapi-validator/config"
+	"github.com/pb33f/libopenapi-validator/errors"
 	"github.com/pb33f/libopenapi-validator/paths"
 	"github.com/stretchr/testify/assert"
 	"net/http"
+	"net/url"
+	"strings"
 	"testing"
 )
 
@@ -2414,10 +2419,1275 @@ paths:
 	}
 
 	errs := ValidateParameterSchema(s, rawObject, "cake", "burger", "lemons",
-		"pizza", "rice", "herbs")
+		"pizza", "rice", "herbs",
+		nil)
 
 	assert.Len(t, errs, 1)
 	assert.Equal(t, "lemons 'pizza' is defined as an object, "+
 		"however it failed to be decoded as an object", errs[0].Reason)
 
 }
+
+func TestNewValidator_QueryParamSpaceDelimitedArray_EncodedSpaces(t *testing.T) {
+
+	spec := `openapi: 3.1.0
+paths:
+  /a/fishy/on/a/dishy:
+    get:
+      parameters:
+        - name: ids
+          in: query
+          style: spaceDelimited
+          schema:
+            type: array
+            items:
+              type: integer
+      operationId: locateFishy`
+
+	doc, _ := libopenapi.NewDocument([]byte(spec))
+
+	m, _ := doc.BuildV3Model()
+
+	v := NewParameterValidator(&m.Model)
+
+	request, _ := http.NewRequest(http.MethodGet, "https://things.com/a/fishy/on/a/dishy?ids=1%202%203", nil)
+	valid, errors := v.ValidateQueryParams(request)
+	assert.True(t, valid)
+	assert.Len(t, errors, 0)
+
+	request, _ = http.NewRequest(http.MethodGet, "https://things.com/a/fishy/on/a/dishy?ids=1%20two%203", nil)
+	valid, errors = v.ValidateQueryParams(request)
+	assert.False(t, valid)
+	assert.Len(t, errors, 1)
+	assert.Equal(t, "Query array parameter 'ids' is not a valid number", errors[0].Message)
+}
+
+func TestNewValidator_QueryParamPipeDelimitedArray_CommaDelimited(t *testing.T) {
+
+	spec := `openapi: 3.1.0
+paths:
+  /a/fishy/on/a/dishy:
+    get:
+      parameters:
+        - name: ids
+          in: query
+          style: pipeDelimited
+          schema:
+            type: array
+            items:
+              type: integer
+      operationId: locateFishy`
+
+	doc, _ := libopenapi.NewDocument([]byte(spec))
+
+	m, _ := doc.BuildV3Model()
+
+	v := NewParameterValidator(&m.Model)
+
+	request, _ := http.NewRequest(http.MethodGet, "https://things.com/a/fishy/on/a/dishy?ids=1,2,3", nil)
+	valid, errors := v.ValidateQueryParams(request)
+	assert.False(t, valid)
+	assert.Len(t, errors, 1)
+	assert.Equal(t, "Query array parameter 'ids' delimited incorrectly", errors[0].Message)
+	assert.Equal(t, "The query parameter (which is an array) 'ids' has 'pipeDelimited' style defined, "+
+		"however the value '1,2,3' is delimited using commas", errors[0].Reason)
+	assert.Equal(t, "When using 'explode' with pipe delimited parameters, they should be separated by pipes '|'. "+
+		"For example: 'ids=1|2|3'", errors[0].HowToFix)
+}
+
+func TestNewValidator_QueryParamSpaceDelimitedArray_CommaDelimited(t *testing.T) {
+
+	spec := `openapi: 3.1.0
+paths:
+  /a/fishy/on/a/dishy:
+    get:
+      parameters:
+        - name: ids
+          in: query
+          style: spaceDelimited
+          schema:
+            type: array
+            items:
+              type: number
+      operationId: locateFishy`
+
+	doc, _ := libopenapi.NewDocument([]byte(spec))
+
+	m, _ := doc.BuildV3Model()
+
+	v := NewParameterValidator(&m.Model)
+
+	request, _ := http.NewRequest(http.MethodGet, "https://things.com/a/fishy/on/a/dishy?ids=1.2,2,3", nil)
+	valid, errors := v.ValidateQueryParams(request)
+	assert.False(t, valid)
+	assert.Len(t, errors, 1)
+	assert.Equal(t, "Query array parameter 'ids' delimited incorrectly", errors[0].Message)
+	assert.Equal(t, "When using 'explode' with space delimited parameters, they should be separated by spaces. "+
+		"For example: 'ids=1.2%202%203'", errors[0].HowToFix)
+}
+
+func TestNewValidator_QueryParamDeepObject_MissingRequiredProperty(t *testing.T) {
+
+	spec := `openapi: 3.1.0
+paths:
+  /things:
+    get:
+      parameters:
+        - name: filter
+          in: query
+          style: deepObject
+          explode: true
+          schema:
+            type: object
+            required: [color, size]
+            properties:
+              color:
+                type: string
+              size:
+                type: string
+      operationId: filterThings`
+
+	doc, _ := libopenapi.NewDocument([]byte(spec))
+
+	m, _ := doc.BuildV3Model()
+
+	v := NewParameterValidator(&m.Model)
+
+	request, _ := http.NewRequest(http.MethodGet, "https://things.com/things?filter[color]=red&filter[size]=large", nil)
+	valid, errors := v.ValidateQueryParams(request)
+	assert.True(t, valid)
+	assert.Len(t, errors, 0)
+
+	request, _ = http.NewRequest(http.MethodGet, "https://things.com/things?filter[color]=red", nil)
+	valid, errors = v.ValidateQueryParams(request)
+	assert.False(t, valid)
+	assert.Len(t, errors, 1)
+	assert.Equal(t, "Query parameter 'filter' failed to validate", errors[0].Message)
+	assert.Equal(t, "missing properties: 'size'", errors[0].SchemaValidationErrors[0].Reason)
+}
+
+func TestNewValidator_QueryParamDeepObject_NestedProperties(t *testing.T) {
+
+	spec := `openapi: 3.1.0
+paths:
+  /things:
+    get:
+      parameters:
+        - name: filter
+          in: query
+          style: deepObject
+          explode: true
+          schema:
+            type: object
+            properties:
+              color:
+                type: string
+              dimensions:
+                type: object
+                properties:
+                  w:
+                    type: integer
+                  h:
+                    type: integer
+      operationId: filterThings`
+
+	doc, _ := libopenapi.NewDocument([]byte(spec))
+
+	m, _ := doc.BuildV3Model()
+
+	v := NewParameterValidator(&m.Model)
+
+	request, _ := http.NewRequest(http.MethodGet,
+		"https://things.com/things?filter[color]=red&filter[dimensions][w]=10&filter[dimensions][h]=20", nil)
+	valid, errors := v.ValidateQueryParams(request)
+	assert.True(t, valid)
+	assert.Len(t, errors, 0)
+
+	request, _ = http.NewRequest(http.MethodGet,
+		"https://things.com/things?filter[color]=red&filter[dimensions][w]=wide", nil)
+	valid, errors = v.ValidateQueryParams(request)
+	assert.False(t, valid)
+	assert.Len(t, errors, 1)
+	assert.Equal(t, "expected integer, but got string", errors[0].SchemaValidationErrors[0].Reason)
+	assert.Equal(t, "/properties/dimensions/properties/w/type", errors[0].SchemaValidationErrors[0].Location)
+}
+
+func TestNewValidator_QueryParamCoerceInteger(t *testing.T) {
+
+	spec := `openapi: 3.1.0
+paths:
+  /a/fishy/on/a/dishy:
+    get:
+      parameters:
+        - name: age
+          in: query
+          required: true
+          schema:
+            type: integer
+            minimum: 18
+      operationId: locateFishy
+`
+
+	doc, _ := libopenapi.NewDocument([]byte(spec))
+	m, _ := doc.BuildV3Model()
+	v := NewParameterValidator(&m.Model)
+
+	request, _ := http.NewRequest(http.MethodGet, "https://things.com/a/fishy/on/a/dishy?age=30", nil)
+	valid, errors := v.ValidateQueryParams(request)
+	assert.True(t, valid)
+	assert.Len(t, errors, 0)
+
+	request, _ = http.NewRequest(http.MethodGet, "https://things.com/a/fishy/on/a/dishy?age=12", nil)
+	valid, errors = v.ValidateQueryParams(request)
+	assert.False(t, valid)
+	assert.Len(t, errors, 1)
+	assert.Equal(t, "Query parameter 'age' failed to validate", errors[0].Message)
+	assert.Equal(t, "value 12 is less than minimum 18", errors[0].SchemaValidationErrors[0].Reason)
+}
+
+func TestNewValidator_QueryParamCoerceIntegerInvalid(t *testing.T) {
+
+	spec := `openapi: 3.1.0
+paths:
+  /a/fishy/on/a/dishy:
+    get:
+      parameters:
+        - name: age
+          in: query
+          required: true
+          schema:
+            type: integer
+      operationId: locateFishy
+`
+
+	doc, _ := libopenapi.NewDocument([]byte(spec))
+	m, _ := doc.BuildV3Model()
+	v := NewParameterValidator(&m.Model)
+
+	request, _ := http.NewRequest(http.MethodGet, "https://things.com/a/fishy/on/a/dishy?age=abc", nil)
+	valid, errors := v.ValidateQueryParams(request)
+	assert.False(t, valid)
+	assert.Len(t, errors, 1)
+	assert.Equal(t, "Query parameter 'age' is not a valid number", errors[0].Message)
+
+	request, _ = http.NewRequest(http.MethodGet, "https://things.com/a/fishy/on/a/dishy?age=30.5", nil)
+	valid, errors = v.ValidateQueryParams(request)
+	assert.False(t, valid)
+	assert.Len(t, errors, 1)
+	assert.Equal(t, "Query parameter 'age' is not a valid integer", errors[0].Message)
+	assert.Equal(t, "Convert the value '30.5' into an integer (a whole number, without a decimal point)",
+		errors[0].HowToFix)
+}
+
+func TestNewValidator_QueryParamCoerceNullable(t *testing.T) {
+
+	spec := `openapi: 3.1.0
+paths:
+  /a/fishy/on/a/dishy:
+    get:
+      parameters:
+        - name: age
+          in: query
+          required: true
+          schema:
+            type: [integer, "null"]
+      operationId: locateFishy
+`
+
+	doc, _ := libopenapi.NewDocument([]byte(spec))
+	m, _ := doc.BuildV3Model()
+	v := NewParameterValidator(&m.Model)
+
+	request, _ := http.NewRequest(http.MethodGet, "https://things.com/a/fishy/on/a/dishy?age=30", nil)
+	valid, errors := v.ValidateQueryParams(request)
+	assert.True(t, valid)
+	assert.Len(t, errors, 0)
+
+	request, _ = http.NewRequest(http.MethodGet, "https://things.com/a/fishy/on/a/dishy?age=", nil)
+	valid, errors = v.ValidateQueryParams(request)
+	assert.True(t, valid)
+	assert.Len(t, errors, 0)
+
+	request, _ = http.NewRequest(http.MethodGet, "https://things.com/a/fishy/on/a/dishy?age=abc", nil)
+	valid, errors = v.ValidateQueryParams(request)
+	assert.False(t, valid)
+	assert.Len(t, errors, 1)
+	assert.Equal(t, "Query parameter 'age' is not a valid number", errors[0].Message)
+}
+
+func TestNewValidator_QueryParamCoerceMultipleTypes(t *testing.T) {
+
+	spec := `openapi: 3.1.0
+paths:
+  /a/fishy/on/a/dishy:
+    get:
+      parameters:
+        - name: age
+          in: query
+          required: true
+          schema:
+            type: [integer, boolean]
+      operationId: locateFishy
+`
+
+	doc, _ := libopenapi.NewDocument([]byte(spec))
+	m, _ := doc.BuildV3Model()
+	v := NewParameterValidator(&m.Model)
+
+	request, _ := http.NewRequest(http.MethodGet, "https://things.com/a/fishy/on/a/dishy?age=true", nil)
+	valid, errors := v.ValidateQueryParams(request)
+	assert.True(t, valid)
+	assert.Len(t, errors, 0)
+
+	request, _ = http.NewRequest(http.MethodGet, "https://things.com/a/fishy/on/a/dishy?age=abc", nil)
+	valid, errors = v.ValidateQueryParams(request)
+	assert.False(t, valid)
+	assert.Len(t, errors, 1)
+	assert.Equal(t, "Query parameter 'age' is not a valid 'integer, boolean'", errors[0].Message)
+	assert.Equal(t, "Convert the value 'abc' into one of the following types: 'integer, boolean'",
+		errors[0].HowToFix)
+}
+
+func TestNewValidator_QueryParamValue(t *testing.T) {
+
+	spec := `openapi: 3.1.0
+paths:
+  /a/fishy/on/a/dishy:
+    get:
+      parameters:
+        - name: age
+          in: query
+          required: true
+          schema:
+            type: integer
+            minimum: 18
+        - name: fishy
+          in: query
+          schema:
+            type: array
+            items:
+              type: number
+      operationId: locateFishy
+`
+
+	doc, _ := libopenapi.NewDocument([]byte(spec))
+	m, _ := doc.BuildV3Model()
+	v := NewParameterValidator(&m.Model)
+
+	params := m.Model.Paths.PathItems["/a/fishy/on/a/dishy"].Get.Parameters
+
+	valid, errors := v.ValidateQueryParamValue(params[0], "30")
+	assert.True(t, valid)
+	assert.Len(t, errors, 0)
+
+	valid, errors = v.ValidateQueryParamValue(params[0], "12")
+	assert.False(t, valid)
+	assert.Len(t, errors, 1)
+	assert.Equal(t, "Query parameter 'age' failed to validate", errors[0].Message)
+
+	valid, errors = v.ValidateQueryParamValue(params[0], "abc")
+	assert.False(t, valid)
+	assert.Len(t, errors, 1)
+	assert.Equal(t, "Query parameter 'age' is not a valid number", errors[0].Message)
+
+	valid, errors = v.ValidateQueryParamValue(params[1], "1.2,cod")
+	assert.False(t, valid)
+	assert.Len(t, errors, 1)
+	assert.Equal(t, "Query array parameter 'fishy' is not a valid number", errors[0].Message)
+}
+
+func TestNewValidator_QueryParamApplyDefaults(t *testing.T) {
+
+	spec := `openapi: 3.1.0
+paths:
+  /a/fishy/on/a/dishy:
+    get:
+      parameters:
+        - name: fishy
+          in: query
+          required: true
+          schema:
+            type: string
+            default: cod
+        - name: limit
+          in: query
+          schema:
+            type: integer
+            maximum: 100
+            default: 20
+        - name: sides
+          in: query
+          explode: false
+          schema:
+            type: array
+            items:
+              type: string
+            default: [chips, peas]
+      operationId: locateFishy
+`
+
+	doc, _ := libopenapi.NewDocument([]byte(spec))
+
+	m, _ := doc.BuildV3Model()
+
+	// defaults are not applied unless asked for.
+	v := NewParameterValidator(&m.Model)
+	request, _ := http.NewRequest(http.MethodGet, "https://things.com/a/fishy/on/a/dishy?fishy=haddock", nil)
+	valid, errors := v.ValidateQueryParams(request)
+	assert.True(t, valid)
+	assert.Len(t, errors, 0)
+	assert.Equal(t, "fishy=haddock", request.URL.RawQuery)
+
+	v = NewParameterValidator(&m.Model, config.WithApplyDefaults())
+	request, _ = http.NewRequest(http.MethodGet, "https://things.com/a/fishy/on/a/dishy?fishy=haddock", nil)
+	valid, errors = v.ValidateQueryParams(request)
+	assert.True(t, valid)
+	assert.Len(t, errors, 0)
+	assert.Equal(t, "20", request.URL.Query().Get("limit"))
+	assert.Equal(t, "chips,peas", request.URL.Query().Get("sides"))
+	assert.Equal(t, "haddock", request.URL.Query().Get("fishy"))
+
+	// validating with a path item never changes the request, the defaults are added by ApplyQueryDefaults.
+	request, _ = http.NewRequest(http.MethodGet, "https://things.com/a/fishy/on/a/dishy?fishy=haddock", nil)
+	pathItem := m.Model.Paths.PathItems["/a/fishy/on/a/dishy"]
+	valid, _ = v.ValidateQueryParamsWithPathItem(context.Background(), request, pathItem, "/a/fishy/on/a/dishy")
+	assert.True(t, valid)
+	assert.Equal(t, "fishy=haddock", request.URL.RawQuery)
+
+	v.ApplyQueryDefaults(request, pathItem)
+	assert.Equal(t, "20", request.URL.Query().Get("limit"))
+	assert.Equal(t, "chips,peas", request.URL.Query().Get("sides"))
+
+	// sent values are never replaced.
+	request, _ = http.NewRequest(http.MethodGet, "https://things.com/a/fishy/on/a/dishy?fishy=haddock&limit=5", nil)
+	valid, _ = v.ValidateQueryParams(request)
+	assert.True(t, valid)
+	assert.Equal(t, []string{"5"}, request.URL.Query()["limit"])
+
+	// required params are still missing, even with a default.
+	request, _ = http.NewRequest(http.MethodGet, "https://things.com/a/fishy/on/a/dishy", nil)
+	valid, errors = v.ValidateQueryParams(request)
+	assert.False(t, valid)
+	assert.Len(t, errors, 1)
+	assert.Equal(t, "Query parameter 'fishy' is missing", errors[0].Message)
+}
+
+func TestNewValidator_QueryParamApplyDefaults_InvalidDefault(t *testing.T) {
+
+	spec := `openapi: 3.1.0
+paths:
+  /a/fishy/on/a/dishy:
+    get:
+      parameters:
+        - name: limit
+          in: query
+          schema:
+            type: integer
+            maximum: 100
+            default: 200
+      operationId: locateFishy
+`
+
+	doc, _ := libopenapi.NewDocument([]byte(spec))
+
+	m, _ := doc.BuildV3Model()
+
+	v := NewParameterValidator(&m.Model, config.WithApplyDefaults())
+	request, _ := http.NewRequest(http.MethodGet, "https://things.com/a/fishy/on/a/dishy", nil)
+	valid, errors := v.ValidateQueryParams(request)
+	assert.False(t, valid)
+	assert.Len(t, errors, 1)
+}
+
+func TestNewValidator_QueryParamMultipleErrors(t *testing.T) {
+
+	spec := `openapi: 3.1.0
+paths:
+  /a/fishy/on/a/dishy:
+    get:
+      parameters:
+        - name: fishy
+          in: query
+          required: true
+          schema:
+            type: string
+        - name: limit
+          in: query
+          schema:
+            type: integer
+        - name: fresh
+          in: query
+          schema:
+            type: boolean
+        - name: species
+          in: query
+          schema:
+            type: string
+            enum: [cod, haddock]
+      operationId: locateFishy
+`
+
+	doc, _ := libopenapi.NewDocument([]byte(spec))
+
+	m, _ := doc.BuildV3Model()
+
+	v := NewParameterValidator(&m.Model)
+	request, _ := http.NewRequest(http.MethodGet,
+		"https://things.com/a/fishy/on/a/dishy?limit=lots&fresh=maybe&species=plaice", nil)
+	valid, errors := v.ValidateQueryParams(request)
+	assert.False(t, valid)
+	assert.Len(t, errors, 4)
+
+	// every violation can be fixed on its own.
+	messages := make(map[string]string)
+	for _, e := range errors {
+		assert.NotEmpty(t, e.HowToFix)
+		messages[e.Message] = e.HowToFix
+	}
+	assert.Contains(t, messages, "Query parameter 'fishy' is missing")
+	assert.Contains(t, messages, "Query parameter 'limit' is not a valid number")
+	assert.Contains(t, messages, "Query parameter 'fresh' is not a valid boolean")
+	assert.Contains(t, messages, "Query parameter 'species' does not match allowed values")
+}
+
+func TestNewValidator_QueryParamMultipleErrors_Object(t *testing.T) {
+
+	spec := `openapi: 3.1.0
+paths:
+  /a/fishy/on/a/dishy:
+    get:
+      parameters:
+        - name: fishy
+          in: query
+          schema:
+            type: object
+            properties:
+              size:
+                type: integer
+        - name: limit
+          in: query
+          schema:
+            type: integer
+      operationId: locateFishy
+`
+
+	doc, _ := libopenapi.NewDocument([]byte(spec))
+
+	m, _ := doc.BuildV3Model()
+
+	v := NewParameterValidator(&m.Model)
+	request, _ := http.NewRequest(http.MethodGet,
+		"https://things.com/a/fishy/on/a/dishy?size=large&limit=lots", nil)
+	valid, errors := v.ValidateQueryParams(request)
+	assert.False(t, valid)
+
+	// the object parameter does not stop the other parameters being checked.
+	assert.Len(t, errors, 2)
+}
+
+func TestNewValidator_QueryParamAllowEmptyValue(t *testing.T) {
+
+	spec := `openapi: 3.1.0
+paths:
+  /a/fishy/on/a/dishy:
+    get:
+      parameters:
+        - name: debug
+          in: query
+          allowEmptyValue: true
+          schema:
+            type: boolean
+        - name: fresh
+          in: query
+          schema:
+            type: boolean
+      operationId: locateFishy
+`
+
+	doc, _ := libopenapi.NewDocument([]byte(spec))
+	m, _ := doc.BuildV3Model()
+	v := NewParameterValidator(&m.Model)
+
+	request, _ := http.NewRequest(http.MethodGet, "https://things.com/a/fishy/on/a/dishy?debug", nil)
+	valid, errors := v.ValidateQueryParams(request)
+	assert.True(t, valid)
+	assert.Len(t, errors, 0)
+
+	request, _ = http.NewRequest(http.MethodGet, "https://things.com/a/fishy/on/a/dishy?debug=&fresh=true", nil)
+	valid, errors = v.ValidateQueryParams(request)
+	assert.True(t, valid)
+	assert.Len(t, errors, 0)
+
+	// a value that is sent is still checked.
+	request, _ = http.NewRequest(http.MethodGet, "https://things.com/a/fishy/on/a/dishy?debug=maybe", nil)
+	valid, errors = v.ValidateQueryParams(request)
+	assert.False(t, valid)
+	assert.Len(t, errors, 1)
+
+	// empty values are not allowed for fresh.
+	request, _ = http.NewRequest(http.MethodGet, "https://things.com/a/fishy/on/a/dishy?fresh", nil)
+	valid, errors = v.ValidateQueryParams(request)
+	assert.False(t, valid)
+	assert.Len(t, errors, 1)
+	assert.Equal(t, "Query parameter 'fresh' is not a valid boolean", errors[0].Message)
+}
+
+func TestNewValidator_QueryParamAllowReserved(t *testing.T) {
+
+	spec := `openapi: 3.1.0
+paths:
+  /a/fishy/on/a/dishy:
+    get:
+      parameters:
+        - name: fishy
+          in: query
+          allowReserved: true
+          explode: true
+          schema:
+            type: string
+        - name: dishy
+          in: query
+          explode: true
+          schema:
+            type: string
+      operationId: locateFishy
+`
+
+	doc, _ := libopenapi.NewDocument([]byte(spec))
+	m, _ := doc.BuildV3Model()
+	v := NewParameterValidator(&m.Model)
+
+	// reserved characters are allowed.
+	request, _ := http.NewRequest(http.MethodGet, "https://things.com/a/fishy/on/a/dishy?fishy=cod/haddock", nil)
+	valid, errors := v.ValidateQueryParams(request)
+	assert.True(t, valid)
+	assert.Len(t, errors, 0)
+
+	// reserved characters are not allowed.
+	request, _ = http.NewRequest(http.MethodGet, "https://things.com/a/fishy/on/a/dishy?dishy=cod/haddock", nil)
+	valid, errors = v.ValidateQueryParams(request)
+	assert.False(t, valid)
+	assert.Len(t, errors, 1)
+	assert.Equal(t, "Query parameter 'dishy' value contains reserved values", errors[0].Message)
+
+	// reserved characters that are encoded are fine.
+	request, _ = http.NewRequest(http.MethodGet, "https://things.com/a/fishy/on/a/dishy?dishy=cod%2Fhaddock", nil)
+	valid, errors = v.ValidateQueryParams(request)
+	assert.True(t, valid)
+	assert.Len(t, errors, 0)
+}
+
+func TestNewValidator_QueryParamCaseInsensitiveEnums(t *testing.T) {
+
+	spec := `openapi: 3.1.0
+paths:
+  /pets:
+    get:
+      parameters:
+        - name: status
+          in: query
+          schema:
+            type: string
+            enum: [available, sold]
+        - name: tags
+          in: query
+          schema:
+            type: array
+            items:
+              type: string
+              enum: [fluffy, grumpy]
+      operationId: findPets
+`
+
+	doc, _ := libopenapi.NewDocument([]byte(spec))
+	m, _ := doc.BuildV3Model()
+
+	// enums are case-sensitive by default.
+	v := NewParameterValidator(&m.Model)
+	request, _ := http.NewRequest(http.MethodGet, "https://things.com/pets?status=Sold", nil)
+	valid, errors := v.ValidateQueryParams(request)
+	assert.False(t, valid)
+	assert.Len(t, errors, 1)
+	assert.Equal(t, "Query parameter 'status' does not match allowed values", errors[0].Message)
+	assert.Contains(t, errors[0].HowToFix, "available, sold")
+
+	v = NewParameterValidator(&m.Model, config.WithCaseInsensitiveEnums())
+	request, _ = http.NewRequest(http.MethodGet, "https://things.com/pets?status=Sold&tags=Fluffy,GRUMPY", nil)
+	valid, errors = v.ValidateQueryParams(request)
+	assert.True(t, valid)
+	assert.Len(t, errors, 0)
+
+	// values that are not in the enum still fail, listing the canonical values.
+	request, _ = http.NewRequest(http.MethodGet, "https://things.com/pets?status=Lost", nil)
+	valid, errors = v.ValidateQueryParams(request)
+	assert.False(t, valid)
+	assert.Len(t, errors, 1)
+	assert.Contains(t, errors[0].HowToFix, "available, sold")
+}
+
+func TestNewValidator_QueryParamRejectUndeclared(t *testing.T) {
+
+	spec := `openapi: 3.1.0
+paths:
+  /a/fishy/on/a/dishy:
+    parameters:
+      - name: fishy
+        in: query
+        schema:
+          type: string
+    get:
+      parameters:
+        - name: dishy
+          in: query
+          schema:
+            type: string
+        - name: coords
+          in: query
+          schema:
+            type: object
+            properties:
+              lat:
+                type: number
+              long:
+                type: number
+      operationId: locateFishy
+`
+
+	doc, _ := libopenapi.NewDocument([]byte(spec))
+	m, _ := doc.BuildV3Model()
+
+	// undeclared parameters are ignored by default.
+	v := NewParameterValidator(&m.Model)
+	request, _ := http.NewRequest(http.MethodGet, "https://things.com/a/fishy/on/a/dishy?foo=bar", nil)
+	valid, errors := v.ValidateQueryParams(request)
+	assert.True(t, valid)
+	assert.Len(t, errors, 0)
+
+	// parameters of the path item and the operation (and the properties of a form object) are declared.
+	v = NewParameterValidator(&m.Model, config.WithRejectUndeclaredQueryParams())
+	request, _ = http.NewRequest(http.MethodGet,
+		"https://things.com/a/fishy/on/a/dishy?fishy=cod&dishy=plate&lat=1.5&long=2", nil)
+	valid, errors = v.ValidateQueryParams(request)
+	assert.True(t, valid)
+	assert.Len(t, errors, 0)
+
+	request, _ = http.NewRequest(http.MethodGet, "https://things.com/a/fishy/on/a/dishy?fishy=cod&foo=bar&chips=yes", nil)
+	valid, errors = v.ValidateQueryParams(request)
+	assert.False(t, valid)
+	assert.Len(t, errors, 2)
+	assert.Equal(t, "Query parameter 'chips' is not declared", errors[0].Message)
+	assert.Equal(t, "Query parameter 'foo' is not declared", errors[1].Message)
+}
+
+func TestNewValidator_QueryParamOperationOverridesPathItem(t *testing.T) {
+
+	spec := `openapi: 3.1.0
+paths:
+  /burgers:
+    parameters:
+      - name: limit
+        in: query
+        schema:
+          type: integer
+          maximum: 10
+    get:
+      parameters:
+        - name: limit
+          in: query
+          schema:
+            type: string
+            enum: [all, none]
+      operationId: listBurgers
+    delete:
+      operationId: deleteBurgers
+`
+
+	doc, _ := libopenapi.NewDocument([]byte(spec))
+	m, _ := doc.BuildV3Model()
+	v := NewParameterValidator(&m.Model)
+
+	// the operation parameter replaces the path item parameter.
+	request, _ := http.NewRequest(http.MethodGet, "https://things.com/burgers?limit=all", nil)
+	valid, errors := v.ValidateQueryParams(request)
+	assert.True(t, valid)
+	assert.Len(t, errors, 0)
+
+	// and is validated once.
+	request, _ = http.NewRequest(http.MethodGet, "https://things.com/burgers?limit=5", nil)
+	valid, errors = v.ValidateQueryParams(request)
+	assert.False(t, valid)
+	assert.Len(t, errors, 1)
+	assert.Equal(t, "Query parameter 'limit' does not match allowed values", errors[0].Message)
+
+	// operations that don't override the parameter use the path item parameter.
+	request, _ = http.NewRequest(http.MethodDelete, "https://things.com/burgers?limit=5", nil)
+	valid, errors = v.ValidateQueryParams(request)
+	assert.True(t, valid)
+	assert.Len(t, errors, 0)
+
+	request, _ = http.NewRequest(http.MethodDelete, "https://things.com/burgers?limit=all", nil)
+	valid, errors = v.ValidateQueryParams(request)
+	assert.False(t, valid)
+	assert.Len(t, errors, 1)
+}
+
+func TestNewValidator_QueryParamDeprecated(t *testing.T) {
+
+	spec := `openapi: 3.1.0
+paths:
+  /a/fishy/on/a/dishy:
+    get:
+      parameters:
+        - name: fishy
+          in: query
+          deprecated: true
+          schema:
+            type: integer
+        - name: dishy
+          in: query
+          schema:
+            type: string
+      operationId: locateFishy
+`
+
+	doc, _ := libopenapi.NewDocument([]byte(spec))
+	m, _ := doc.BuildV3Model()
+
+	// deprecated parameters are validated like any other by default.
+	v := NewParameterValidator(&m.Model)
+	request, _ := http.NewRequest(http.MethodGet, "https://things.com/a/fishy/on/a/dishy?fishy=1", nil)
+	valid, errors := v.ValidateQueryParams(request)
+	assert.True(t, valid)
+	assert.Len(t, errors, 0)
+
+	// a deprecated parameter that is sent is a warning, the request is still valid.
+	v = NewParameterValidator(&m.Model, config.WithDeprecationWarnings())
+	valid, errors = v.ValidateQueryParams(request)
+	assert.True(t, valid)
+	assert.Len(t, errors, 1)
+	assert.True(t, errors[0].IsWarning())
+	assert.Equal(t, "Query parameter 'fishy' is deprecated", errors[0].Message)
+	assert.Equal(t, "locateFishy", errors[0].OperationId)
+
+	// not sending it is fine.
+	request, _ = http.NewRequest(http.MethodGet, "https://things.com/a/fishy/on/a/dishy?dishy=plate", nil)
+	valid, errors = v.ValidateQueryParams(request)
+	assert.True(t, valid)
+	assert.Len(t, errors, 0)
+
+	// an invalid value is still an error.
+	request, _ = http.NewRequest(http.MethodGet, "https://things.com/a/fishy/on/a/dishy?fishy=cod", nil)
+	valid, errors = v.ValidateQueryParams(request)
+	assert.False(t, valid)
+	assert.Len(t, errors, 2)
+	assert.True(t, errors[0].IsWarning())
+	assert.False(t, errors[1].IsWarning())
+}
+
+func TestNewValidator_QueryParamArrayNullableItems(t *testing.T) {
+
+	spec := `openapi: 3.1.0
+paths:
+  /a/fishy/on/a/dishy:
+    get:
+      parameters:
+        - name: ages
+          in: query
+          schema:
+            type: array
+            items:
+              type: [integer, "null"]
+      operationId: locateFishy
+`
+
+	doc, _ := libopenapi.NewDocument([]byte(spec))
+	m, _ := doc.BuildV3Model()
+	v := NewParameterValidator(&m.Model)
+
+	request, _ := http.NewRequest(http.MethodGet, "https://things.com/a/fishy/on/a/dishy?ages=1,null,3", nil)
+	valid, errors := v.ValidateQueryParams(request)
+	assert.True(t, valid)
+	assert.Len(t, errors, 0)
+
+	request, _ = http.NewRequest(http.MethodGet, "https://things.com/a/fishy/on/a/dishy?ages=1,old", nil)
+	valid, errors = v.ValidateQueryParams(request)
+	assert.False(t, valid)
+	assert.Len(t, errors, 1)
+	assert.Equal(t, "Query array parameter 'ages' is not a valid number", errors[0].Message)
+}
+
+func TestNewValidator_QueryParamContent(t *testing.T) {
+
+	spec := `openapi: 3.1.0
+paths:
+  /burgers:
+    get:
+      parameters:
+        - name: filter
+          in: query
+          content:
+            application/json:
+              schema:
+                type: object
+                required: [name]
+                properties:
+                  name:
+                    type: string
+                  patties:
+                    type: integer
+        - name: ids
+          in: query
+          content:
+            application/json:
+              schema:
+                type: array
+                items:
+                  type: integer
+        - name: size
+          in: query
+          content:
+            application/json:
+              schema:
+                oneOf:
+                  - type: integer
+                  - type: object
+      operationId: locateBurgers
+`
+
+	doc, _ := libopenapi.NewDocument([]byte(spec))
+	m, _ := doc.BuildV3Model()
+	v := NewParameterValidator(&m.Model)
+
+	validate := func(query string) (bool, []*errors.ValidationError) {
+		request, _ := http.NewRequest(http.MethodGet, "https://things.com/burgers?"+query, nil)
+		return v.ValidateQueryParams(request)
+	}
+
+	// objects, arrays and values without a type are parsed as JSON.
+	valid, errs := validate("filter=" + url.QueryEscape(`{"name": "Big Mac", "patties": 2}`) +
+		"&ids=" + url.QueryEscape(`[1, 2]`) + "&size=3")
+	assert.True(t, valid)
+	assert.Len(t, errs, 0)
+
+	// the parsed value is validated against the schema of the media type.
+	valid, errs = validate("ids=" + url.QueryEscape(`[1, "two"]`))
+	assert.False(t, valid)
+	assert.Len(t, errs, 1)
+	assert.Equal(t, "Query parameter 'ids' failed to validate", errs[0].Message)
+	assert.Equal(t, "expected integer, but got string", errs[0].SchemaValidationErrors[0].Reason)
+	assert.Equal(t, "/1", errs[0].SchemaValidationErrors[0].InstanceLocation)
+
+	valid, errs = validate("size=" + url.QueryEscape(`"large"`))
+	assert.False(t, valid)
+	assert.Len(t, errs, 1)
+	assert.Equal(t, "Query parameter 'size' failed to validate", errs[0].Message)
+
+	// a value that cannot be parsed is not a schema error.
+	valid, errs = validate("ids=" + url.QueryEscape(`[1,`))
+	assert.False(t, valid)
+	assert.Len(t, errs, 1)
+	assert.Equal(t, "Query parameter 'ids' is not valid JSON", errs[0].Message)
+	assert.Equal(t, "The query parameter 'ids' is defined as being JSON ('application/json'), "+
+		"however the value '[1,' is not valid JSON", errs[0].Reason)
+	assert.Empty(t, errs[0].SchemaValidationErrors)
+}
+
+func TestNewValidator_QueryParamEmptyValue(t *testing.T) {
+
+	spec := `openapi: 3.1.0
+paths:
+  /a/fishy/on/a/dishy:
+    get:
+      parameters:
+        - name: anything
+          in: query
+          schema: {}
+        - name: size
+          in: query
+          schema:
+            type: integer`
+
+	doc, _ := libopenapi.NewDocument([]byte(spec))
+	m, _ := doc.BuildV3Model()
+	v := NewParameterValidator(&m.Model)
+
+	// optional parameters that are not sent are valid, an empty schema accepts an empty value.
+	for _, query := range []string{"", "?anything=", "?anything"} {
+		request, _ := http.NewRequest(http.MethodGet, "https://things.com/a/fishy/on/a/dishy"+query, nil)
+
+		valid, errors := v.ValidateQueryParams(request)
+		assert.True(t, valid, query)
+		assert.Len(t, errors, 0)
+	}
+
+	// an empty value is validated against the schema, it is not treated as absent.
+	for _, query := range []string{"?size=", "?size"} {
+		request, _ := http.NewRequest(http.MethodGet, "https://things.com/a/fishy/on/a/dishy"+query, nil)
+
+		valid, errors := v.ValidateQueryParams(request)
+		assert.False(t, valid, query)
+		assert.Len(t, errors, 1)
+		assert.Equal(t, "Query parameter 'size' is not a valid number", errors[0].Message)
+	}
+}
+
+func TestNewValidator_QueryParamPercentEncoded(t *testing.T) {
+
+	spec := `openapi: 3.1.0
+paths:
+  /a/fishy/on/a/dishy:
+    get:
+      parameters:
+        - name: fishy
+          in: query
+          schema:
+            type: string
+            enum: [a+b, cod & chips]
+        - name: dishy
+          in: query
+          allowReserved: true
+          schema:
+            type: string
+            enum: [a+b, plate/bowl]`
+
+	doc, _ := libopenapi.NewDocument([]byte(spec))
+	m, _ := doc.BuildV3Model()
+	v := NewParameterValidator(&m.Model)
+
+	// the decoded value is checked against the enum.
+	for _, query := range []string{"fishy=a%2Bb", "fishy=cod+%26+chips", "fishy=cod%20%26%20chips",
+		"dishy=a+b", "dishy=a%2Bb", "dishy=plate/bowl"} {
+		request, _ := http.NewRequest(http.MethodGet, "https://things.com/a/fishy/on/a/dishy?"+query, nil)
+
+		valid, errors := v.ValidateQueryParams(request)
+		assert.True(t, valid, query)
+		assert.Len(t, errors, 0)
+	}
+
+	// without allowReserved, a '+' is an encoded space.
+	request, _ := http.NewRequest(http.MethodGet, "https://things.com/a/fishy/on/a/dishy?fishy=a+b", nil)
+
+	valid, errors := v.ValidateQueryParams(request)
+	assert.False(t, valid)
+	assert.Len(t, errors, 1)
+	assert.Equal(t, "Query parameter 'fishy' does not match allowed values", errors[0].Message)
+
+	// a malformed percent-encoded sequence is reported, rather than the value being ignored.
+	request, _ = http.NewRequest(http.MethodGet, "https://things.com/a/fishy/on/a/dishy", nil)
+	request.URL.RawQuery = "fishy=a%zzb&dishy=a%2"
+
+	valid, errors = v.ValidateQueryParams(request)
+	assert.False(t, valid)
+	assert.Len(t, errors, 2)
+	for _, e := range errors {
+		assert.Contains(t, e.Message, "is not correctly percent-encoded")
+	}
+	assert.Equal(t, "The query parameter 'fishy' has the value 'a%zzb', which contains a malformed "+
+		"percent-encoded sequence, so it cannot be decoded", errors[0].Reason)
+}
+
+func TestNewValidator_QueryParamSchemaCannotCompile(t *testing.T) {
+
+	// a boolean bound without a minimum cannot be converted into JSON Schema, so the schema cannot be compiled.
+	spec := `openapi: 3.0.3
+paths:
+  /burgers:
+    get:
+      parameters:
+        - name: n
+          in: query
+          schema:
+            type: integer
+            exclusiveMinimum: true
+`
+
+	doc, _ := libopenapi.NewDocument([]byte(spec))
+
+	m, _ := doc.BuildV3Model()
+
+	v := NewParameterValidator(&m.Model)
+
+	request, _ := http.NewRequest(http.MethodGet, "https://things.com/burgers?n=5", nil)
+
+	valid, errs := v.ValidateQueryParams(request)
+	assert.False(t, valid)
+	assert.Len(t, errs, 1)
+	assert.Equal(t, errors.ErrorCodeDocument, errs[0].Code)
+	assert.Equal(t, "Query parameter 'n' cannot be validated, the schema cannot be compiled", errs[0].Message)
+	assert.Equal(t, errors.HowToFixSchemaCompile, errs[0].HowToFix)
+}
+
+func TestNewValidator_QueryParamSchemaReasonType(t *testing.T) {
+
+	spec := `openapi: 3.1.0
+paths:
+  /burgers:
+    get:
+      parameters:
+        - name: amount
+          in: query
+          schema:
+            type: integer
+            minimum: 10
+        - name: sauce
+          in: query
+          schema:
+            type: [integer, string]
+            maxLength: 2
+`
+
+	doc, _ := libopenapi.NewDocument([]byte(spec))
+
+	m, _ := doc.BuildV3Model()
+
+	v := NewParameterValidator(&m.Model)
+
+	request, _ := http.NewRequest(http.MethodGet, "https://things.com/burgers?amount=5", nil)
+
+	valid, errs := v.ValidateQueryParams(request)
+	assert.False(t, valid)
+	assert.Len(t, errs, 1)
+	assert.Equal(t, "The query parameter 'amount' is defined as an integer, "+
+		"however it failed to pass a schema validation", errs[0].Reason)
+
+	request, _ = http.NewRequest(http.MethodGet, "https://things.com/burgers?sauce=ketchup", nil)
+
+	valid, errs = v.ValidateQueryParams(request)
+	assert.False(t, valid)
+	assert.Len(t, errs, 1)
+	assert.Equal(t, "The query parameter 'sauce' is defined by a schema, "+
+		"however it failed to pass a schema validation", errs[0].Reason)
+}
+
+func TestNewValidator_QueryParamFormatAssertions(t *testing.T) {
+
+	spec := `openapi: 3.1.0
+paths:
+  /burgers:
+    get:
+      parameters:
+        - name: id
+          in: query
+          schema:
+            type: string
+            format: uuid
+`
+
+	doc, _ := libopenapi.NewDocument([]byte(spec))
+
+	m, _ := doc.BuildV3Model()
+
+	request, _ := http.NewRequest(http.MethodGet, "https://things.com/burgers?id=notauuid", nil)
+
+	// formats are annotations by default.
+	valid, errs := NewParameterValidator(&m.Model).ValidateQueryParams(request)
+	assert.True(t, valid)
+	assert.Len(t, errs, 0)
+
+	valid, errs = NewParameterValidator(&m.Model, config.WithFormatAssertions()).ValidateQueryParams(request)
+	assert.False(t, valid)
+	assert.Len(t, errs, 1)
+	assert.Equal(t, "Query parameter 'id' failed to validate", errs[0].Message)
+}
+
+func TestNewValidator_QueryParamCustomFormat(t *testing.T) {
+
+	spec := `openapi: 3.1.0
+paths:
+  /burgers:
+    get:
+      parameters:
+        - name: sku
+          in: query
+          schema:
+            type: string
+            format: sku
+        - name: X-Sku
+          in: header
+          schema:
+            type: string
+            format: sku
+`
+
+	doc, _ := libopenapi.NewDocument([]byte(spec))
+
+	m, _ := doc.BuildV3Model()
+
+	v := NewParameterValidator(&m.Model, config.WithCustomFormat("sku", func(s string) bool {
+		return strings.HasPrefix(s, "SKU-")
+	}))
+
+	request, _ := http.NewRequest(http.MethodGet, "https://things.com/burgers?sku=SKU-123", nil)
+	request.Header.Set("X-Sku", "SKU-456")
+
+	valid, errs := v.ValidateQueryParams(request)
+	assert.True(t, valid)
+	assert.Len(t, errs, 0)
+	valid, errs = v.ValidateHeaderParams(request)
+	assert.True(t, valid)
+	assert.Len(t, errs, 0)
+
+	request, _ = http.NewRequest(http.MethodGet, "https://things.com/burgers?sku=123", nil)
+	request.Header.Set("X-Sku", "456")
+
+	valid, errs = v.ValidateQueryParams(request)
+	assert.False(t, valid)
+	assert.Len(t, errs, 1)
+	assert.Equal(t, "'123' is not valid 'sku'", errs[0].SchemaValidationErrors[0].Reason)
+	valid, errs = v.ValidateHeaderParams(request)
+	assert.False(t, valid)
+	assert.Len(t, errs, 1)
+}
+
+func TestNewValidator_ParamSchemaCache(t *testing.T) {
+
+	spec := `openapi: 3.1.0
+paths:
+  /burgers/{id}:
+    parameters:
+      - name: id
+        in: path
+        required: true
+        schema:
+          type: string
+          pattern: ^[a-z]+$
+    get:
+      parameters:
+        - name: amount
+          in: query
+          schema:
+            type: integer
+            minimum: 1
+`
+
+	doc, _ := libopenapi.NewDocument([]byte(spec))
+
+	m, _ := doc.BuildV3Model()
+
+	v := NewParameterValidator(&m.Model)
+	v.WarmSchemaCache()
+
+	pathItem := m.Model.Paths.PathItems["/burgers/{id}"]
+	schemas := v.(*paramValidator).schemas
+	idSchema := pathItem.Parameters[0].Schema.Schema()
+	amountSchema := pathItem.Get.Parameters[0].Schema.Schema()
+	compiledId, err := schemas.Compile("id", idSchema)
+	assert.NoError(t, err)
+	compiledAmount, err := schemas.Compile("amount", amountSchema)
+	assert.NoError(t, err)
+
+	// the schemas compiled up front are re-used by every request.
+	for i := 0; i < 3; i++ {
+		request, _ := http.NewRequest(http.MethodGet, "https://things.com/burgers/big?amount=0", nil)
+		valid, errs := v.ValidatePathParams(request)
+		assert.True(t, valid)
+		assert.Len(t, errs, 0)
+		valid, errs = v.ValidateQueryParams(request)
+		assert.False(t, valid)
+		assert.Len(t, errs, 1)
+	}
+	compiled, _ := schemas.Compile("id", idSchema)
+	assert.Same(t, compiledId, compiled)
+	compiled, _ = schemas.Compile("amount", amountSchema)
+	assert.Same(t, compiledAmount, compiled)
+}
@@ -7,8 +7,9 @@ import (
 	"encoding/json"
 	"fmt"
 	"github.com/pb33f/libopenapi-validator/errors"
+	"github.com/pb33f/libopenapi-validator/helpers"
+	"github.com/pb33f/libopenapi-validator/schema_validation"
 	"github.com/pb33f/libopenapi/datamodel/high/base"
-	"github.com/pb33f/libopenapi/utils"
 	"github.com/santhosh-tekuri/jsonschema/v5"
 	"net/url"
 	"reflect"
@@ -26,6 +27,8 @@ import (
 //	name: the name of the parameter
 //	validationType: the type of validation being performed
 //	subValType: the type of sub-validation being performed
+//	schemas: the cache the compiled schema is kept in, schemas are compiled using the options of the cache (such as
+//	format assertions). When nil, the schema is compiled using the default options, and is not cached
 func ValidateParameterSchema(
 	schema *base.Schema,
 	rawObject any,
@@ -34,13 +37,18 @@ func ValidateParameterSchema(
 	reasonEntity,
 	name,
 	validationType,
-	subValType string) []*errors.ValidationError {
+	subValType string,
+	schemas *helpers.SchemaCache) []*errors.ValidationError {
 
 	var validationErrors []*errors.ValidationError
 
-	// 1. build a JSON render of the schema.
-	renderedSchema, _ := schema.Render()
-	jsonSchema, _ := utils.ConvertYAMLtoJSON(renderedSchema)
+	// 1. render and compile the schema, or use the schema compiled for a previous value.
+	jsch, err := schemas.Compile(name, schema)
+	if err != nil {
+		// the schema is not valid, so nothing can be validated against it.
+		return []*errors.ValidationError{errors.ParameterSchemaInvalid(schema,
+			entity, reasonEntity, name, validationType, subValType, err)}
+	}
 
 	// 2. decode the object into a json blob.
 	var decodedObj interface{}
@@ -58,18 +66,17 @@ func ValidateParameterSchema(
 			} else {
 				rawIsMap = true
 			}
+		default:
+			// primitives (already coerced into the correct type) are validated as they are.
+			decodedObj = rawObject
+			validEncoding = true
 		}
 	} else {
 		decodedString, _ := url.QueryUnescape(rawBlob)
 		_ = json.Unmarshal([]byte(decodedString), &decodedObj)
 		validEncoding = true
 	}
-	// 3. create a new json schema compiler and add the schema to it
-	compiler := jsonschema.NewCompiler()
-	_ = compiler.AddResource(fmt.Sprintf("%s.json", name), strings.NewReader(string(jsonSchema)))
-	jsch, _ := compiler.Compile(fmt.Sprintf("%s.json", name))
-
-	// 4. validate the object against the schema
+	// 3. validate the object against the schema
 	var scErrs error
 	if validEncoding {
 		scErrs = jsch.Validate(decodedObj)
@@ -86,20 +93,43 @@ func ValidateParameterSchema(
 				continue // ignore this error, it's not useful
 			}
 			schemaValidationErrors = append(schemaValidationErrors, &errors.SchemaValidationFailure{
-				Reason:        er.Error,
-				Location:      er.KeywordLocation,
-				OriginalError: jk,
+				Reason:           er.Error,
+				Location:         er.KeywordLocation,
+				DeepLocation:     er.KeywordLocation,
+				AbsoluteLocation: er.AbsoluteKeywordLocation,
+				InstanceLocation: er.InstanceLocation,
+				OriginalError:    jk,
 			})
 		}
+
+		// name each property that is not allowed, the index of each tuple item that failed and every duplicate item,
+		// describe numeric limits that were crossed, explain which branch of an if/then/else was applied, and why each
+		// subschema of a oneOf or anyOf failed.
+		schemaValidationErrors = schema_validation.SplitAdditionalPropertyFailures(schemaValidationErrors)
+		schema_validation.DescribeNumericFailures(schemaValidationErrors)
+		schema_validation.DescribeArrayFailures(schemaValidationErrors, decodedObj)
+		schema_validation.DescribeTupleFailures(schemaValidationErrors)
+		schema_validation.DescribeConditionalFailures(schemaValidationErrors)
+		schema_validation.GroupSubschemaFailures(schemaValidationErrors)
+
+		// a schema without a type (for example, a oneOf) has no type to locate.
+		line := 1
+		col := 0
+		if schema.GoLow().Type.KeyNode != nil {
+			line = schema.GoLow().Type.KeyNode.Line
+			col = schema.GoLow().Type.KeyNode.Column
+		}
+
 		// add the error to the list
 		validationErrors = append(validationErrors, &errors.ValidationError{
 			ValidationType:    validationType,
 			ValidationSubType: subValType,
+			Code:              errors.ErrorCodeParamSchema,
 			Message:           fmt.Sprintf("%s '%s' failed to validate", entity, name),
-			Reason: fmt.Sprintf("%s '%s' is defined as an object, "+
-				"however it failed to pass a schema validation", reasonEntity, name),
-			SpecLine:               schema.GoLow().Type.KeyNode.Line,
-			SpecCol:                schema.GoLow().Type.KeyNode.Column,
+			Reason: fmt.Sprintf("%s '%s' is defined %s, "+
+				"however it failed to pass a schema validation", reasonEntity, name, describeSchemaType(schema)),
+			SpecLine:               line,
+			SpecCol:                col,
 			SchemaValidationErrors: schemaValidationErrors,
 			HowToFix:               errors.HowToFixInvalidSchema,
 		})
@@ -113,6 +143,7 @@ func ValidateParameterSchema(
 				validationErrors = append(validationErrors, &errors.ValidationError{
 					ValidationType:    validationType,
 					ValidationSubType: subValType,
+					Code:              errors.ErrorCodeParamEncoding,
 					Message:           fmt.Sprintf("%s '%s' cannot be decoded", entity, name),
 					Reason: fmt.Sprintf("%s '%s' is defined as an object, "+
 						"however it failed to be decoded as an object", reasonEntity, name),
@@ -125,3 +156,22 @@ func ValidateParameterSchema(
 	}
 	return validationErrors
 }
+
+// describeSchemaType describes the type of a schema for a reason (e.g. 'as an integer'), 'null' is ignored. A schema
+// without a single type (such as a oneOf, or [string, integer]) is described as 'by a schema'.
+func describeSchemaType(schema *base.Schema) string {
+	var types []string
+	for _, t := range helpers.SchemaTypes(schema) {
+		if t != helpers.Null {
+			types = append(types, t)
+		}
+	}
+	if len(types) != 1 {
+		return "by a schema"
+	}
+	switch types[0] {
+	case helpers.Integer, helpers.Object, helpers.Array:
+		return "as an " + types[0]
+	}
+	return "as a " + types[0]
+}
@@ -4,11 +4,12 @@
 package parameters
 
 import (
-	"fmt"
+	"github.com/pb33f/libopenapi-validator/config"
 	"github.com/pb33f/libopenapi-validator/errors"
 	"github.com/pb33f/libopenapi-validator/helpers"
 	"github.com/pb33f/libopenapi/datamodel/high/base"
 	"github.com/pb33f/libopenapi/datamodel/high/v3"
+	"slices"
 	"strconv"
 	"strings"
 )
@@ -25,6 +26,12 @@ func ValidateCookieArray(
 
 	// now check each item in the array
 	for _, item := range items {
+		// items with a set of primitive types (such as [integer, null]) only fail if they match none of them.
+		if hasPrimitiveTypes(itemsSchema) && itemsSchema.Enum == nil {
+			if _, ok := helpers.CoerceValue(item, helpers.SchemaTypes(itemsSchema)); ok {
+				continue
+			}
+		}
 		// for each type defined in the item's schema, check the item
 		for _, itemType := range itemsSchema.Type {
 			switch itemType {
@@ -65,6 +72,13 @@ func ValidateHeaderArray(
 
 	// now check each item in the array
 	for _, item := range items {
+		item = strings.TrimSpace(item)
+		// items with a set of primitive types (such as [integer, null]) only fail if they match none of them.
+		if hasPrimitiveTypes(itemsSchema) && itemsSchema.Enum == nil {
+			if _, ok := helpers.CoerceValue(item, helpers.SchemaTypes(itemsSchema)); ok {
+				continue
+			}
+		}
 		// for each type defined in the item's schema, check the item
 		for _, itemType := range itemsSchema.Type {
 			switch itemType {
@@ -96,10 +110,31 @@ func ValidateHeaderArray(
 // ValidateQueryArray will validate a query parameter that is an array
 func ValidateQueryArray(
 	sch *base.Schema, param *v3.Parameter, ef string, contentWrapped bool) []*errors.ValidationError {
+	return (&paramValidator{options: config.NewValidationOptions()}).validateQueryArray(sch, param, ef, contentWrapped)
+}
+
+func (v *paramValidator) validateQueryArray(sch *base.Schema,
+	param *v3.Parameter,
+	ef string,
+	contentWrapped bool) []*errors.ValidationError {
 
 	var validationErrors []*errors.ValidationError
 	itemsSchema := sch.Items.A.Schema()
 
+	// a comma delimited value sent to a pipe or space delimited parameter is a style mismatch, not a type problem.
+	// string items may legitimately contain a comma, so only check items that cannot.
+	switch param.Style {
+	case helpers.PipeDelimited, helpers.SpaceDelimited:
+		delimiter := helpers.Pipe
+		if param.Style == helpers.SpaceDelimited {
+			delimiter = helpers.Space
+		}
+		if !contentWrapped && !slices.Contains(itemsSchema.Type, helpers.String) &&
+			strings.Contains(ef, helpers.Comma) && !strings.Contains(ef, delimiter) {
+			return []*errors.ValidationError{errors.IncorrectQueryParamArrayDelimiter(param, ef)}
+		}
+	}
+
 	// check for an exploded bit on the schema.
 	// if it's exploded, then we need to check each item in the array
 	// if it's not exploded, then we need to check the whole array as a string
@@ -123,19 +158,12 @@ func ValidateQueryArray(
 	}
 
 	// check if the param is within an enum
-	checkEnum := func(enumCheck, item string) {
+	checkEnum := func(item string) {
 		// check if the array param is within an enum
 		if sch.Items.IsA() {
 			itemsSch := sch.Items.A.Schema()
 			if itemsSch.Enum != nil {
-				matchFound := false
-				for _, enumVal := range itemsSch.Enum {
-					if strings.TrimSpace(enumCheck) == fmt.Sprint(enumVal) {
-						matchFound = true
-						break
-					}
-				}
-				if !matchFound {
+				if _, ok := helpers.MatchEnum(item, itemsSch.Enum, v.options.CaseInsensitiveEnums); !ok {
 					validationErrors = append(validationErrors,
 						errors.IncorrectQueryParamEnumArray(param, item, sch))
 				}
@@ -145,6 +173,12 @@ func ValidateQueryArray(
 
 	// now check each item in the array
 	for _, item := range items {
+		// items with a set of primitive types (such as [integer, null]) only fail if they match none of them.
+		if hasPrimitiveTypes(itemsSchema) && itemsSchema.Enum == nil {
+			if _, ok := helpers.CoerceValue(item, helpers.SchemaTypes(itemsSchema)); ok {
+				continue
+			}
+		}
 		// for each type defined in the item's schema, check the item
 		for _, itemType := range itemsSchema.Type {
 			switch itemType {
@@ -155,7 +189,7 @@ func ValidateQueryArray(
 					break
 				}
 				// will it blend?
-				checkEnum(ef, item)
+				checkEnum(item)
 
 			case helpers.Boolean:
 				if _, err := strconv.ParseBool(item); err != nil {
@@ -171,18 +205,132 @@ func ValidateQueryArray(
 						"The query parameter (which is an array)",
 						param.Name,
 						helpers.ParameterValidation,
-						helpers.ParameterValidationQuery)...)
+						helpers.ParameterValidationQuery,
+						v.schemas)...)
 
 			case helpers.String:
 
 				// will it float?
-				checkEnum(ef, item)
+				checkEnum(item)
 			}
 		}
 	}
 	return validationErrors
 }
 
+// ValidateQueryPrimitive will validate a query parameter that is a primitive (or a set of primitives, such as
+// [integer, null]). The value is coerced into the declared type before being checked against the schema.
+func ValidateQueryPrimitive(
+	sch *base.Schema, param *v3.Parameter, ef string) []*errors.ValidationError {
+	return (&paramValidator{options: config.NewValidationOptions()}).validateQueryPrimitive(sch, param, ef)
+}
+
+func (v *paramValidator) validateQueryPrimitive(sch *base.Schema,
+	param *v3.Parameter,
+	ef string) []*errors.ValidationError {
+
+	coerced, ok := helpers.CoerceValue(ef, helpers.SchemaTypes(sch))
+	if !ok {
+		var nonNull []string
+		for _, t := range sch.Type {
+			if t != helpers.Null {
+				nonNull = append(nonNull, t)
+			}
+		}
+		if len(nonNull) == 1 {
+			switch nonNull[0] {
+			case helpers.Integer:
+				if _, err := strconv.ParseFloat(ef, 64); err != nil {
+					return []*errors.ValidationError{errors.InvalidQueryParamNumber(param, ef, sch)}
+				}
+				return []*errors.ValidationError{errors.InvalidQueryParamInteger(param, ef, sch)}
+			case helpers.Number:
+				return []*errors.ValidationError{errors.InvalidQueryParamNumber(param, ef, sch)}
+			case helpers.Boolean:
+				return []*errors.ValidationError{errors.IncorrectQueryParamBool(param, ef, sch)}
+			}
+		}
+		return []*errors.ValidationError{errors.InvalidQueryParamType(param, ef, sch)}
+	}
+
+	// check if the param is within an enum
+	if sch.Enum != nil {
+		enumVal, ok := helpers.MatchEnum(ef, sch.Enum, v.options.CaseInsensitiveEnums)
+		if !ok {
+			return []*errors.ValidationError{errors.IncorrectQueryParamEnum(param, ef, sch)}
+		}
+		// a value matched without regard to case is validated as the canonical enum value.
+		if _, isString := coerced.(string); isString {
+			coerced = enumVal
+		}
+	}
+
+	// the value is the right type, now check it against the rest of the schema (minimum, pattern etc.)
+	return ValidateParameterSchema(sch,
+		coerced,
+		ef,
+		"Query parameter",
+		"The query parameter",
+		param.Name,
+		helpers.ParameterValidation,
+		helpers.ParameterValidationQuery,
+		v.schemas)
+}
+
+// primitiveTypeErrors are used to report a header, cookie or path value that cannot be converted into any of the
+// primitive types declared by the schema of the parameter, keyed by where the parameter is found.
+var primitiveTypeErrors = map[string]func(*v3.Parameter, string, *base.Schema) *errors.ValidationError{
+	helpers.ParameterValidationHeader: errors.InvalidHeaderParamType,
+	helpers.ParameterValidationCookie: errors.InvalidCookieParamType,
+	helpers.ParameterValidationPath:   errors.InvalidPathParamType,
+}
+
+// hasPrimitiveTypes returns true if the schema declares a set of primitive types (such as [integer, null]), rather
+// than a single type. Values of these parameters are validated using validatePrimitiveTypes.
+func hasPrimitiveTypes(sch *base.Schema) bool {
+	types := helpers.SchemaTypes(sch)
+	return len(types) > 1 && helpers.IsPrimitiveType(types)
+}
+
+// usesCustomFormat returns true if the schema of a string parameter declares a format that was registered using
+// config.WithCustomFormat. Other string header, cookie and path parameters are only checked against their enum.
+func (v *paramValidator) usesCustomFormat(sch *base.Schema) bool {
+	return sch.Format != "" && v.options.Formats[sch.Format] != nil
+}
+
+// validatePrimitiveTypes will validate a header, cookie or path parameter that declares a set of primitive types
+// (such as [integer, null]), or a string that uses a custom format. The value is coerced into the first of the types
+// it can represent, so an empty value (or 'null') is accepted when 'null' is one of the types. A type error is only
+// reported when the value cannot be converted into any of the types, otherwise the coerced value is checked against
+// the rest of the schema.
+func (v *paramValidator) validatePrimitiveTypes(sch *base.Schema,
+	param *v3.Parameter,
+	value, in string) []*errors.ValidationError {
+
+	coerced, ok := helpers.CoerceValue(value, helpers.SchemaTypes(sch))
+	if !ok {
+		return []*errors.ValidationError{primitiveTypeErrors[in](param, value, sch)}
+	}
+
+	// a value matched without regard to case is validated as the canonical enum value.
+	if _, isString := coerced.(string); isString && sch.Enum != nil {
+		if enumVal, matched := helpers.MatchEnum(value, sch.Enum, v.options.CaseInsensitiveEnums); matched {
+			coerced = enumVal
+		}
+	}
+
+	entity := strings.ToUpper(in[:1]) + in[1:] + " parameter"
+	return ValidateParameterSchema(sch,
+		coerced,
+		value,
+		entity,
+		"The "+in+" parameter",
+		param.Name,
+		helpers.ParameterValidation,
+		in,
+		v.schemas)
+}
+
 // ValidateQueryParamStyle will validate a query parameter by style
 func ValidateQueryParamStyle(param *v3.Parameter, as []*helpers.QueryParam) []*errors.ValidationError {
 
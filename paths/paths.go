@@ -5,12 +5,16 @@ package paths
 
 import (
 	"fmt"
+	"github.com/pb33f/libopenapi-validator/config"
 	"github.com/pb33f/libopenapi-validator/errors"
 	"github.com/pb33f/libopenapi-validator/helpers"
 	"github.com/pb33f/libopenapi/datamodel/high/v3"
 	"net/http"
 	"net/url"
 	"path/filepath"
+	"regexp"
+	"slices"
+	"sort"
 	"strconv"
 	"strings"
 )
@@ -18,25 +22,44 @@ import (
 // FindPath will find the path in the document that matches the request path. If a successful match was found, then
 // the first return value will be a pointer to the PathItem. The second return value will contain any validation errors
 // that were picked up when locating the path. Number/Integer validation is performed in any path parameters in the request.
+// A path that only fails to match because an integer or number path parameter is not a number is still matched (and
+// no errors are returned), so the parameter validator can report the parameter, instead of the path not being found.
 // The third return value will be the path that was found in the document, as it pertains to the contract, so all path
 // parameters will not have been replaced with their values from the request - allowing model lookups.
+//
+// By default, request paths are normalized before they are matched: duplicate slashes are collapsed (/pet//123 is
+// /pet/123) and a trailing slash is ignored (/pet/ matches /pet). Request paths are always matched decoded, so
+// percent-encoded characters match their decoded form in the specification. Each segment is decoded on its own, so an
+// encoded slash (%2F) is part of the value of a path parameter, rather than a separator. Use config.WithStrictPathMatching
+// (and FindPathWithOptions) to match request paths exactly as they are sent.
 func FindPath(request *http.Request, document *v3.Document) (*v3.PathItem, []*errors.ValidationError, string) {
+	return FindPathWithOptions(request, document, nil)
+}
+
+// FindPathWithOptions is the same as FindPath, but the supplied options (which may be nil) change how the path
+// is matched. The base path of the servers defined in the document is stripped from the request path before
+// matching, unless SkipBasePathStripping is set. The request path is normalized, unless StrictPathMatching is set.
+func FindPathWithOptions(request *http.Request,
+	document *v3.Document,
+	options *config.ValidationOptions) (*v3.PathItem, []*errors.ValidationError, string) {
 
 	var validationErrors []*errors.ValidationError
 
+	requestPath, escapedPath := request.URL.Path, request.URL.EscapedPath()
+	if options == nil || !options.StrictPathMatching {
+		requestPath, escapedPath = helpers.NormalizePath(requestPath), helpers.NormalizePath(escapedPath)
+	}
+
 	// extract base path from document to check against paths.
 	var basePaths []string
-	for _, s := range document.Servers {
-		u, _ := url.Parse(s.URL)
-		if u != nil && u.Path != "" {
-			basePaths = append(basePaths, u.Path)
-		}
+	if options == nil || !options.SkipBasePathStripping {
+		basePaths = findBasePaths(request, requestPath, document.Servers)
 	}
 
-	// strip any base path
-	stripped := stripBaseFromPath(request.URL.Path, basePaths)
+	// strip any base path, each segment is decoded on its own, so encoded slashes (%2F) don't split a segment.
+	stripped := stripBaseFromPath(escapedPath, basePaths)
 
-	reqPathSegments := strings.Split(stripped, "/")
+	reqPathSegments := helpers.SplitEscapedPath(stripped)
 	if reqPathSegments[0] == "" {
 		reqPathSegments = reqPathSegments[1:]
 	}
@@ -57,8 +80,8 @@ pathFound:
 		switch request.Method {
 		case http.MethodGet:
 			if pathItem.Get != nil {
-				p := append(params, pathItem.Get.Parameters...)
-				if checkPathAgainstBase(request.URL.Path, path, basePaths) {
+				p := helpers.MergeParameters(params, pathItem.Get.Parameters)
+				if checkPathAgainstBase(requestPath, path, basePaths) {
 					pItem = pathItem
 					foundPath = path
 					break pathFound
@@ -74,8 +97,8 @@ pathFound:
 			}
 		case http.MethodPost:
 			if pathItem.Post != nil {
-				p := append(params, pathItem.Post.Parameters...)
-				if checkPathAgainstBase(request.URL.Path, path, basePaths) {
+				p := helpers.MergeParameters(params, pathItem.Post.Parameters)
+				if checkPathAgainstBase(requestPath, path, basePaths) {
 					pItem = pathItem
 					foundPath = path
 					break pathFound
@@ -91,9 +114,9 @@ pathFound:
 			}
 		case http.MethodPut:
 			if pathItem.Put != nil {
-				p := append(params, pathItem.Put.Parameters...)
+				p := helpers.MergeParameters(params, pathItem.Put.Parameters)
 				// check for a literal match
-				if checkPathAgainstBase(request.URL.Path, path, basePaths) {
+				if checkPathAgainstBase(requestPath, path, basePaths) {
 					pItem = pathItem
 					foundPath = path
 					validationErrors = errs
@@ -110,9 +133,9 @@ pathFound:
 			}
 		case http.MethodDelete:
 			if pathItem.Delete != nil {
-				p := append(params, pathItem.Delete.Parameters...)
+				p := helpers.MergeParameters(params, pathItem.Delete.Parameters)
 				// check for a literal match
-				if checkPathAgainstBase(request.URL.Path, path, basePaths) {
+				if checkPathAgainstBase(requestPath, path, basePaths) {
 					pItem = pathItem
 					foundPath = path
 					break pathFound
@@ -128,9 +151,9 @@ pathFound:
 			}
 		case http.MethodOptions:
 			if pathItem.Options != nil {
-				p := append(params, pathItem.Options.Parameters...)
+				p := helpers.MergeParameters(params, pathItem.Options.Parameters)
 				// check for a literal match
-				if checkPathAgainstBase(request.URL.Path, path, basePaths) {
+				if checkPathAgainstBase(requestPath, path, basePaths) {
 					pItem = pathItem
 					foundPath = path
 					break pathFound
@@ -146,8 +169,8 @@ pathFound:
 			}
 		case http.MethodHead:
 			if pathItem.Head != nil {
-				p := append(params, pathItem.Head.Parameters...)
-				if checkPathAgainstBase(request.URL.Path, path, basePaths) {
+				p := helpers.MergeParameters(params, pathItem.Head.Parameters)
+				if checkPathAgainstBase(requestPath, path, basePaths) {
 					pItem = pathItem
 					foundPath = path
 					break pathFound
@@ -163,9 +186,9 @@ pathFound:
 			}
 		case http.MethodPatch:
 			if pathItem.Patch != nil {
-				p := append(params, pathItem.Patch.Parameters...)
+				p := helpers.MergeParameters(params, pathItem.Patch.Parameters)
 				// check for a literal match
-				if checkPathAgainstBase(request.URL.Path, path, basePaths) {
+				if checkPathAgainstBase(requestPath, path, basePaths) {
 					pItem = pathItem
 					foundPath = path
 					break pathFound
@@ -181,8 +204,8 @@ pathFound:
 			}
 		case http.MethodTrace:
 			if pathItem.Trace != nil {
-				p := append(params, pathItem.Trace.Parameters...)
-				if checkPathAgainstBase(request.URL.Path, path, basePaths) {
+				p := helpers.MergeParameters(params, pathItem.Trace.Parameters)
+				if checkPathAgainstBase(requestPath, path, basePaths) {
 					pItem = pathItem
 					foundPath = path
 					break pathFound
@@ -198,10 +221,21 @@ pathFound:
 			}
 		}
 	}
+	// a path that only fails to match because a path parameter is not a number (such as /pet/abc, where petId
+	// is an integer) is still matched, so the parameter validator can report the parameter.
+	if pItem == nil && len(validationErrors) == 0 {
+		pItem, foundPath = findPathIgnoringNumbers(request, document, reqPathSegments, basePaths)
+		if pItem != nil {
+			options.Debug("matched path, without checking numeric path parameters",
+				"method", request.Method, "path", request.URL.Path, "template", foundPath)
+			return pItem, validationErrors, foundPath
+		}
+	}
 	if pItem == nil && len(validationErrors) == 0 {
 		validationErrors = append(validationErrors, &errors.ValidationError{
 			ValidationType:    helpers.ParameterValidationPath,
 			ValidationSubType: "missing",
+			Code:              errors.ErrorCodePathNotFound,
 			Message:           fmt.Sprintf("%s Path '%s' not found", request.Method, request.URL.Path),
 			Reason: fmt.Sprintf("The %s request contains a path of '%s' "+
 				"however that path, or the %s method for that path does not exist in the specification",
@@ -210,12 +244,54 @@ pathFound:
 			SpecCol:  -1,
 			HowToFix: errors.HowToFixPath,
 		})
+		options.Debug("no path matched the request", "method", request.Method, "path", request.URL.Path)
 		return pItem, validationErrors, foundPath
 	} else {
+		if pItem != nil {
+			options.Debug("matched path", "method", request.Method, "path", request.URL.Path, "template", foundPath)
+		} else {
+			options.Debug("path parameters of the request are not valid", "method", request.Method,
+				"path", request.URL.Path, "errors", len(validationErrors))
+		}
 		return pItem, validationErrors, foundPath
 	}
 }
 
+// findPathIgnoringNumbers will find the path in the document that matches the request path and method, when the
+// values of integer and number path parameters are not checked. If more than one path matches, the first path
+// (sorted) is returned.
+func findPathIgnoringNumbers(request *http.Request,
+	document *v3.Document,
+	reqPathSegments, basePaths []string) (*v3.PathItem, string) {
+
+	var matched []string
+	for path, pathItem := range document.Paths.PathItems {
+		op := helpers.ExtractOperation(request, pathItem)
+		if op == nil {
+			continue
+		}
+		var params []*v3.Parameter
+		for _, p := range helpers.MergeParameters(pathItem.Parameters, op.Parameters) {
+			if p.Schema != nil && !slices.Contains(p.Schema.Schema().Type, helpers.Integer) &&
+				!slices.Contains(p.Schema.Schema().Type, helpers.Number) {
+				params = append(params, p)
+			}
+		}
+		segs := strings.Split(path, "/")
+		if segs[0] == "" {
+			segs = segs[1:]
+		}
+		if ok, _ := comparePaths(segs, reqPathSegments, params, basePaths); ok {
+			matched = append(matched, path)
+		}
+	}
+	if len(matched) == 0 {
+		return nil, ""
+	}
+	sort.Strings(matched)
+	return document.Paths.PathItems[matched[0]], matched[0]
+}
+
 func checkPathAgainstBase(docPath, urlPath string, basePaths []string) bool {
 	if docPath == urlPath {
 		return true
@@ -232,6 +308,102 @@ func checkPathAgainstBase(docPath, urlPath string, basePaths []string) bool {
 	return false
 }
 
+// findBasePaths will return the base path of the server that matches the request (and request path). Server URLs are templates,
+// variables are matched using their enum values when defined, otherwise any value (including the default) matches.
+// The first server that matches both the host and the path of the request is used. Requests are often sent to
+// a different host than the one in the specification (localhost, a gateway), so if no server matches the host,
+// the first server with a base path that matches the path of the request is used.
+func findBasePaths(request *http.Request, requestPath string, servers []*v3.Server) []string {
+	host := request.URL.Host
+	if host == "" {
+		host = request.Host
+	}
+	var fallback []string
+	for _, s := range servers {
+		hostTemplate, pathTemplate := splitServerURL(s.URL)
+		pathRegex, err := regexp.Compile("^" + serverTemplateRegex(strings.TrimSuffix(pathTemplate, "/"), s) + "(/|$)")
+		if err != nil {
+			continue
+		}
+		match := pathRegex.FindString(requestPath)
+		if match == "" && pathTemplate != "" && pathTemplate != "/" {
+			continue
+		}
+		var basePaths []string
+		if base := strings.TrimSuffix(match, "/"); base != "" {
+			basePaths = []string{base}
+		}
+		if hostTemplate == "" || host == "" || serverHostMatches(hostTemplate, host, s) {
+			return basePaths
+		}
+		if fallback == nil && basePaths != nil {
+			fallback = basePaths
+		}
+	}
+	return fallback
+}
+
+// splitServerURL will split a server URL template into the host template and the path template, relative server
+// URLs only have a path template.
+func splitServerURL(serverURL string) (string, string) {
+	if _, rest, found := strings.Cut(serverURL, "://"); found {
+		serverURL = "//" + rest
+	}
+	if !strings.HasPrefix(serverURL, "//") {
+		if serverURL != "" && !strings.HasPrefix(serverURL, "/") {
+			serverURL = "/" + serverURL
+		}
+		return "", serverURL
+	}
+	hostTemplate, pathTemplate, found := strings.Cut(serverURL[2:], "/")
+	if found {
+		pathTemplate = "/" + pathTemplate
+	}
+	return hostTemplate, pathTemplate
+}
+
+// serverHostMatches will check the host of a request against the host template of a server, the port of the
+// request is ignored if the template does not have one.
+func serverHostMatches(hostTemplate, host string, server *v3.Server) bool {
+	hostRegex, err := regexp.Compile("(?i)^" + serverTemplateRegex(hostTemplate, server) + "$")
+	if err != nil {
+		return false
+	}
+	if hostRegex.MatchString(host) {
+		return true
+	}
+	if hostname, _, found := strings.Cut(host, ":"); found {
+		return hostRegex.MatchString(hostname)
+	}
+	return false
+}
+
+// serverTemplateRegex will convert a part of a server URL template into a regular expression, variables are
+// replaced with their enum values, or with anything other than a slash.
+func serverTemplateRegex(template string, server *v3.Server) string {
+	var sb strings.Builder
+	for {
+		start := strings.Index(template, "{")
+		end := strings.Index(template, "}")
+		if start < 0 || end < start {
+			sb.WriteString(regexp.QuoteMeta(template))
+			return sb.String()
+		}
+		sb.WriteString(regexp.QuoteMeta(template[:start]))
+		variable := server.Variables[template[start+1:end]]
+		if variable != nil && len(variable.Enum) > 0 {
+			values := make([]string, len(variable.Enum))
+			for i := range variable.Enum {
+				values[i] = regexp.QuoteMeta(variable.Enum[i])
+			}
+			sb.WriteString("(" + strings.Join(values, "|") + ")")
+		} else {
+			sb.WriteString("[^/]+")
+		}
+		template = template[end+1:]
+	}
+}
+
 func stripBaseFromPath(path string, basePaths []string) string {
 	for i := range basePaths {
 		if strings.HasPrefix(path, basePaths[i]) {
@@ -253,6 +425,10 @@ func comparePaths(mapped, requested []string,
 	var imploded []string
 	for i, seg := range mapped {
 		s := seg
+		// literal segments are compared decoded, the same as the request path.
+		if unescaped, err := url.PathUnescape(seg); err == nil {
+			s = unescaped
+		}
 		//sOrig := seg
 		// check for braces
 		if strings.Contains(seg, "{") {
@@ -264,7 +440,20 @@ func comparePaths(mapped, requested []string,
 			if params[p].In == helpers.Path {
 				h := seg[1 : len(seg)-1]
 				if params[p].Name == h {
+					// matrix (;name=value) and label (.value) values carry their own prefix, the
+					// parameter validator checks these.
+					if params[p].Style == helpers.MatrixStyle || params[p].Style == helpers.LabelStyle {
+						continue
+					}
 					schema := params[p].Schema.Schema()
+
+					// a set of primitive types (such as [integer, null]) matches a value that fits any of them.
+					if types := helpers.SchemaTypes(schema); len(types) > 1 && helpers.IsPrimitiveType(types) {
+						if _, ok := helpers.CoerceValue(s, types); !ok {
+							s = helpers.FailSegment
+						}
+						continue
+					}
 					for t := range schema.Type {
 
 						switch schema.Type[t] {
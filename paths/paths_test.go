@@ -5,6 +5,7 @@ package paths
 
 import (
 	"github.com/pb33f/libopenapi"
+	"github.com/pb33f/libopenapi-validator/config"
 	"github.com/stretchr/testify/assert"
 	"net/http"
 	"os"
@@ -21,12 +22,12 @@ func TestNewValidator_BadParam(t *testing.T) {
 
 	m, _ := doc.BuildV3Model()
 
-	_, errs, _ := FindPath(request, &m.Model)
+	// the path is matched, so the parameter validator can report that petId is not an integer.
+	pathItem, errs, pathValue := FindPath(request, &m.Model)
 
-	assert.Equal(t, "GET Path '/pet/doggy' not found",
-		errs[0].Message)
-	assert.Equal(t, "The GET request contains a path of '/pet/doggy' however that path, or the GET method for that path does not exist in the specification",
-		errs[0].Reason)
+	assert.NotNil(t, pathItem)
+	assert.Len(t, errs, 0)
+	assert.Equal(t, "/pet/{petId}", pathValue)
 }
 
 func TestNewValidator_GoodParamFloat(t *testing.T) {
@@ -601,3 +602,118 @@ paths:
 
 	assert.Len(t, errs, 1)
 }
+
+func TestNewValidator_FindPathWithServerVariables(t *testing.T) {
+
+	spec := `openapi: 3.1.0
+servers:
+  - url: https://{region}.api.com/{version}
+    variables:
+      region:
+        default: eu
+      version:
+        default: v2
+        enum: [v1, v2]
+  - url: https://legacy.api.com/old/api
+paths:
+  /pet/{petId}:
+    get:
+      operationId: getPet
+      parameters:
+        - name: petId
+          in: path
+          required: true
+          schema:
+            type: integer
+`
+
+	doc, _ := libopenapi.NewDocument([]byte(spec))
+	m, _ := doc.BuildV3Model()
+
+	request, _ := http.NewRequest(http.MethodGet, "https://us.api.com/v2/pet/1", nil)
+	pathItem, errs, pathValue := FindPath(request, &m.Model)
+	assert.Len(t, errs, 0)
+	assert.Equal(t, "getPet", pathItem.Get.OperationId)
+	assert.Equal(t, "/pet/{petId}", pathValue)
+
+	// v3 is not one of the enum values of the version.
+	request, _ = http.NewRequest(http.MethodGet, "https://us.api.com/v3/pet/1", nil)
+	pathItem, _, _ = FindPath(request, &m.Model)
+	assert.Nil(t, pathItem)
+
+	request, _ = http.NewRequest(http.MethodGet, "https://legacy.api.com/old/api/pet/1", nil)
+	pathItem, _, _ = FindPath(request, &m.Model)
+	assert.Equal(t, "getPet", pathItem.Get.OperationId)
+
+	// requests sent to another host (such as localhost) still have the base path of a server stripped.
+	request, _ = http.NewRequest(http.MethodGet, "http://localhost:8080/v1/pet/1", nil)
+	pathItem, _, _ = FindPath(request, &m.Model)
+	assert.Equal(t, "getPet", pathItem.Get.OperationId)
+}
+
+func TestNewValidator_FindPathWithoutBasePathStripping(t *testing.T) {
+
+	spec := `openapi: 3.1.0
+servers:
+  - url: https://api.com/v2
+paths:
+  /pet:
+    get:
+      operationId: getPets
+`
+
+	doc, _ := libopenapi.NewDocument([]byte(spec))
+	m, _ := doc.BuildV3Model()
+
+	request, _ := http.NewRequest(http.MethodGet, "https://api.com/v2/pet", nil)
+	pathItem, _, _ := FindPathWithOptions(request, &m.Model, config.NewValidationOptions())
+	assert.Equal(t, "getPets", pathItem.Get.OperationId)
+
+	// the router has already stripped the base path.
+	options := config.NewValidationOptions(config.WithoutBasePathStripping())
+	pathItem, _, _ = FindPathWithOptions(request, &m.Model, options)
+	assert.Nil(t, pathItem)
+
+	request, _ = http.NewRequest(http.MethodGet, "https://api.com/pet", nil)
+	pathItem, _, _ = FindPathWithOptions(request, &m.Model, options)
+	assert.Equal(t, "getPets", pathItem.Get.OperationId)
+}
+
+func TestNewValidator_FindPathNormalization(t *testing.T) {
+
+	spec := `openapi: 3.1.0
+paths:
+  /pet:
+    get:
+      operationId: getPets
+  /pet/{petId}:
+    get:
+      operationId: getPet
+  /pet food/{brand}:
+    get:
+      operationId: getPetFood
+`
+
+	doc, _ := libopenapi.NewDocument([]byte(spec))
+	m, _ := doc.BuildV3Model()
+
+	find := func(url string, options *config.ValidationOptions) string {
+		request, _ := http.NewRequest(http.MethodGet, url, nil)
+		pathItem, _, _ := FindPathWithOptions(request, &m.Model, options)
+		if pathItem == nil {
+			return ""
+		}
+		return pathItem.Get.OperationId
+	}
+
+	assert.Equal(t, "getPets", find("https://things.com/pet/", nil))
+	assert.Equal(t, "getPet", find("https://things.com/pet//123", nil))
+	assert.Equal(t, "getPet", find("https://things.com//pet/123/", nil))
+	assert.Equal(t, "getPetFood", find("https://things.com/pet%20food/acme", nil))
+
+	// strict matching keeps the distinction.
+	strict := config.NewValidationOptions(config.WithStrictPathMatching())
+	assert.Equal(t, "getPets", find("https://things.com/pet", strict))
+	assert.NotEqual(t, "getPets", find("https://things.com/pet/", strict))
+	assert.Equal(t, "", find("https://things.com/pet//123", strict))
+}
@@ -4,10 +4,15 @@
 package requests
 
 import (
+	"context"
+	"github.com/pb33f/libopenapi-validator/config"
 	"github.com/pb33f/libopenapi-validator/errors"
+	"github.com/pb33f/libopenapi-validator/helpers"
 	"github.com/pb33f/libopenapi/datamodel/high/base"
 	"github.com/pb33f/libopenapi/datamodel/high/v3"
+	"github.com/santhosh-tekuri/jsonschema/v5"
 	"net/http"
+	"sync"
 )
 
 // RequestBodyValidator is an interface that defines the methods for validating request bodies for Operations.
@@ -21,15 +26,40 @@ type RequestBodyValidator interface {
 	// the body is not valid.
 	ValidateRequestBody(request *http.Request) (bool, []*errors.ValidationError)
 
+	// ValidateRequestBodyWithContext is the same as ValidateRequestBody, but will stop validating when the context
+	// is done. A cancelled validation returns false, and a single error (see ValidationError.IsCancelledError).
+	ValidateRequestBodyWithContext(ctx context.Context, request *http.Request) (bool, []*errors.ValidationError)
+
+	// ValidateRequestBodyWithPathItem is the same as ValidateRequestBodyWithContext, but uses the supplied pathItem
+	// and pathValue (as returned by paths.FindPath) instead of looking them up, or using the pathItem set by
+	// SetPathItem. Nothing is stored on the validator, so this is safe for concurrent use.
+	ValidateRequestBodyWithPathItem(ctx context.Context, request *http.Request, pathItem *v3.PathItem,
+		pathValue string) (bool, []*errors.ValidationError)
+
 	// SetPathItem will set the pathItem for the RequestBodyValidator, all validations will be performed
 	// against this pathItem otherwise if not set, each validation will perform a lookup for the pathItem
-	// based on the *http.Request
+	// based on the *http.Request. The pathItem is stored on the validator, so SetPathItem is not safe to use
+	// while the validator is shared between goroutines, use ValidateRequestBodyWithPathItem instead.
 	SetPathItem(path *v3.PathItem, pathValue string)
+
+	// WarmSchemaCache will render and compile the schemas of all request bodies (and the headers of their multipart
+	// parts) in the document up front. Schemas are otherwise compiled (once) the first time they are used.
+	WarmSchemaCache()
+
+	// WarmOperationSchemaCache is the same as WarmSchemaCache, but only compiles the schemas of the request body
+	// of a single operation.
+	WarmOperationSchemaCache(operation *v3.Operation)
 }
 
-// NewRequestBodyValidator will create a new RequestBodyValidator from an OpenAPI 3+ document
-func NewRequestBodyValidator(document *v3.Document) RequestBodyValidator {
-	return &requestBodyValidator{document: document, schemaCache: make(map[[32]byte]*schemaCache)}
+// NewRequestBodyValidator will create a new RequestBodyValidator from an OpenAPI 3+ document, any options
+// supplied will change the default validation behavior.
+func NewRequestBodyValidator(document *v3.Document, opts ...config.Option) RequestBodyValidator {
+	options := config.NewValidationOptions(opts...)
+	return &requestBodyValidator{
+		document:      document,
+		options:       options,
+		headerSchemas: helpers.NewSchemaCache(options),
+	}
 }
 
 func (v *requestBodyValidator) SetPathItem(path *v3.PathItem, pathValue string) {
@@ -41,12 +71,36 @@ type schemaCache struct {
 	schema         *base.Schema
 	renderedInline []byte
 	renderedJSON   []byte
+	compiledSchema *jsonschema.Schema
+	compileError   error // the schema cannot be compiled, so bodies cannot be validated against it.
+	discriminated  *helpers.DiscriminatedSchemas
+}
+
+// newSchemaCache will compile a rendered schema (and the schemas selected by a discriminator, if there is one)
+// ready to be cached, the options control how the schema is compiled.
+func newSchemaCache(
+	schema *base.Schema,
+	renderedInline,
+	renderedJSON []byte,
+	options *config.ValidationOptions) *schemaCache {
+
+	prepared := helpers.PrepareRequestSchema(renderedJSON)
+	compiledSchema, err := helpers.NewCompiledSchema(helpers.RequestBodyValidation, prepared, options)
+	return &schemaCache{
+		schema:         schema,
+		renderedInline: renderedInline,
+		renderedJSON:   renderedJSON,
+		compiledSchema: compiledSchema,
+		compileError:   err,
+		discriminated:  helpers.NewDiscriminatedSchemas(helpers.RequestBodyValidation, schema, prepared, options),
+	}
 }
 
 type requestBodyValidator struct {
-	document    *v3.Document
-	pathItem    *v3.PathItem
-	pathValue   string
-	errors      []*errors.ValidationError
-	schemaCache map[[32]byte]*schemaCache
+	document      *v3.Document
+	options       *config.ValidationOptions
+	pathItem      *v3.PathItem
+	pathValue     string
+	schemaCache   sync.Map             // schema key (helpers.SchemaCacheKey) -> *schemaCache
+	headerSchemas *helpers.SchemaCache // compiled schemas of the headers of multipart parts
 }
@@ -4,51 +4,92 @@
 package requests
 
 import (
+	"bytes"
+	"context"
+	"io"
 	"net/http"
 	"strings"
+	"time"
 
 	"github.com/pb33f/libopenapi-validator/errors"
 	"github.com/pb33f/libopenapi-validator/helpers"
 	"github.com/pb33f/libopenapi-validator/paths"
-	"github.com/pb33f/libopenapi/datamodel/high/base"
 	v3 "github.com/pb33f/libopenapi/datamodel/high/v3"
 	"github.com/pb33f/libopenapi/utils"
 )
 
 func (v *requestBodyValidator) ValidateRequestBody(request *http.Request) (bool, []*errors.ValidationError) {
+	return v.ValidateRequestBodyWithContext(context.Background(), request)
+}
+
+func (v *requestBodyValidator) ValidateRequestBodyWithContext(
+	ctx context.Context,
+	request *http.Request) (bool, []*errors.ValidationError) {
 
 	// find path
-	var pathItem *v3.PathItem = v.pathItem
-	if v.pathItem == nil {
+	pathItem, pathValue := v.pathItem, v.pathValue
+	if pathItem == nil {
 		var validationErrors []*errors.ValidationError
-		pathItem, validationErrors, _ = paths.FindPath(request, v.document)
+		pathItem, validationErrors, pathValue = paths.FindPathWithOptions(request, v.document, v.options)
 		if pathItem == nil || validationErrors != nil {
-			v.errors = validationErrors
 			return false, validationErrors
 		}
 	}
+	return v.ValidateRequestBodyWithPathItem(ctx, request, pathItem, pathValue)
+}
+
+func (v *requestBodyValidator) ValidateRequestBodyWithPathItem(
+	ctx context.Context,
+	request *http.Request,
+	pathItem *v3.PathItem,
+	pathValue string) (valid bool, validationErrors []*errors.ValidationError) {
+
+	// errors are labelled with the operation they were found in.
+	defer func() { errors.SetOperation(validationErrors, request, pathItem, pathValue) }()
 
 	operation := helpers.ExtractOperation(request, pathItem)
 	if operation.RequestBody == nil {
+		if v.options.RejectUnexpectedBody && hasRequestBody(request) {
+			return false, []*errors.ValidationError{errors.RequestBodyNotExpected(request)}
+		}
 		return true, nil
 	}
 
 	// extract the content type from the request
 	contentType := request.Header.Get(helpers.ContentTypeHeader)
+
+	// an optional body can be left out, a required body cannot.
+	required := operation.RequestBody.Required != nil && *operation.RequestBody.Required
+	hasBody := hasRequestBody(request)
+	if !hasBody && contentType == "" {
+		if required {
+			return false, []*errors.ValidationError{errors.RequestBodyMissing(operation, request)}
+		}
+		return true, nil
+	}
 	if contentType == "" {
-		return false, []*errors.ValidationError{errors.RequestContentTypeNotFound(operation, request)}
+		return false, []*errors.ValidationError{errors.RequestContentTypeMissing(operation, request)}
 	}
 
 	// extract the media type from the content type header.
 	ct, _, _ := helpers.ExtractContentType(contentType)
-	mediaType, ok := operation.RequestBody.Content[ct]
+	if v.options.StrictContentType {
+		ct = strings.TrimSpace(contentType)
+	}
+	mediaType, mediaTypeKey, ok := v.findMediaType(operation.RequestBody.Content, ct)
 	if !ok {
+		v.options.Debug("no request body media type matched the content type", "method", request.Method,
+			"template", pathValue, "contentType", contentType)
 		return false, []*errors.ValidationError{errors.RequestContentTypeNotFound(operation, request)}
 	}
+	v.options.Debug("selected request body media type", "method", request.Method, "template", pathValue,
+		"contentType", contentType, "mediaType", mediaTypeKey)
 
-	// we currently only support JSON validation for request bodies
-	// this will capture *everything* that contains some form of 'json' in the content type
-	if !strings.Contains(strings.ToLower(contentType), helpers.JSONType) {
+	// the content type is declared, but there is nothing in the body.
+	if !hasBody {
+		if required {
+			return false, []*errors.ValidationError{errors.RequestBodyEmpty(operation, request)}
+		}
 		return true, nil
 	}
 
@@ -57,34 +98,132 @@ func (v *requestBodyValidator) ValidateRequestBody(request *http.Request) (bool,
 		return true, nil
 	}
 
-	// extract schema from media type
-	var schema *base.Schema
-	var renderedInline, renderedJSON []byte
+	// the schema cannot be used, for example an external reference that cannot be resolved.
+	if mediaType.Schema.Schema() == nil {
+		return false, []*errors.ValidationError{errors.RequestBodySchemaUnresolved(request, mediaType.Schema)}
+	}
+
+	// JSON, XML and form (URL encoded and multipart) bodies are decoded before validation, any other body
+	// (text/plain for example) can only be validated as a raw string, so it needs a string schema.
+	if !helpers.IsStructuredContentType(contentType) && !helpers.IsStringSchema(mediaType.Schema.Schema()) {
+		return true, nil
+	}
+
+	// check the body is not too large to read, before it's buffered.
+	if v.options.MaxBodyBytes > 0 && !limitRequestBody(request, v.options.MaxBodyBytes) {
+		return false, []*errors.ValidationError{errors.RequestBodyTooLarge(request, v.options.MaxBodyBytes)}
+	}
+
+	// render and compile the schema (or use the cached copy), to be used for validation
+	v.options.Debug("validating request body against schema", "method", request.Method, "template", pathValue,
+		"mediaType", mediaTypeKey)
+	return validateRequestSchema(ctx, request, v.getSchema(mediaType), mediaType.Encoding, v.headerSchemas, v.options)
+}
+
+// hasRequestBody will return true if the request carries a body. When the length of the body is unknown, the first
+// byte is read to find out, and then put back.
+func hasRequestBody(request *http.Request) bool {
+	if request.ContentLength > 0 {
+		return true
+	}
+	if request.Body == nil || request.Body == http.NoBody {
+		return false
+	}
+	return peekRequestBody(request)
+}
+
+func peekRequestBody(request *http.Request) bool {
+	first := make([]byte, 1)
+	n, _ := io.ReadFull(request.Body, first)
+	request.Body = struct {
+		io.Reader
+		io.Closer
+	}{io.MultiReader(bytes.NewReader(first[:n]), request.Body), request.Body}
+	return n > 0
+}
+
+// limitRequestBody will return false if the body of the request is larger than maxBytes. The Content-Length is
+// checked first, then at most maxBytes+1 bytes are read. The body is restored either way, so nothing is lost if it's
+// read again.
+func limitRequestBody(request *http.Request, maxBytes int64) bool {
+	if request.ContentLength > maxBytes {
+		return false
+	}
+	if request.Body == nil || request.Body == http.NoBody {
+		return true
+	}
+	read, _ := io.ReadAll(io.LimitReader(request.Body, maxBytes+1))
+	if int64(len(read)) > maxBytes {
+		request.Body = struct {
+			io.Reader
+			io.Closer
+		}{io.MultiReader(bytes.NewReader(read), request.Body), request.Body}
+		return false
+	}
+	_ = request.Body.Close()
+	request.Body = io.NopCloser(bytes.NewReader(read))
+	return true
+}
+
+// findMediaType will locate the media type definition that matches the content type of the request, this
+// includes structured suffixes (+json) and wildcards (application/*). In strict mode only an exact match is used.
+func (v *requestBodyValidator) findMediaType(content map[string]*v3.MediaType,
+	mediaType string) (*v3.MediaType, string, bool) {
+	if v.options.StrictContentType {
+		mt, ok := content[mediaType]
+		return mt, mediaType, ok
+	}
+	return helpers.FindMediaType(content, mediaType)
+}
+
+// getSchema will return the rendered and compiled schema for a media type. Rendering and compiling a schema is
+// intensive, so it's only performed once per schema and cached in the validator. The cache is safe for concurrent use.
+func (v *requestBodyValidator) getSchema(mediaType *v3.MediaType) *schemaCache {
 
-	// have we seen this schema before? let's hash it and check the cache.
-	hash := mediaType.GoLow().Schema.Value.Hash()
+	// have we seen this schema before? check the cache.
+	key := helpers.SchemaCacheKey(mediaType.GoLow().Schema.Value)
+	if cacheHit, ok := v.schemaCache.Load(key); ok {
+		return cacheHit.(*schemaCache)
+	}
 
-	// perform work only once and cache the result in the validator.
-	if cacheHit, ch := v.schemaCache[hash]; ch {
-		// got a hit, use cached values
-		schema = cacheHit.schema
-		renderedInline = cacheHit.renderedInline
-		renderedJSON = cacheHit.renderedJSON
+	compiled := time.Now()
+	schema := mediaType.Schema.Schema()
+	renderedInline, _ := helpers.RenderSchemaInline(schema)
+	renderedJSON, _ := utils.ConvertYAMLtoJSON(renderedInline)
 
-	} else {
+	// if another request compiled the same schema in the meantime, use that one.
+	cached, _ := v.schemaCache.LoadOrStore(key, newSchemaCache(schema, renderedInline, renderedJSON, v.options))
+	v.options.Observer.SchemaCompiled(compiled)
+	return cached.(*schemaCache)
+}
 
-		// render the schema inline and perform the intensive work of rendering and converting
-		// this is only performed once per schema and cached in the validator.
-		schema = mediaType.Schema.Schema()
-		renderedInline, _ = schema.RenderInline()
-		renderedJSON, _ = utils.ConvertYAMLtoJSON(renderedInline)
-		v.schemaCache[hash] = &schemaCache{
-			schema:         schema,
-			renderedInline: renderedInline,
-			renderedJSON:   renderedJSON,
+// WarmSchemaCache will render and compile the schema of every request body in the document, so the first
+// request for each operation does not pay the cost.
+func (v *requestBodyValidator) WarmSchemaCache() {
+	if v.document == nil || v.document.Paths == nil {
+		return
+	}
+	for _, pathItem := range v.document.Paths.PathItems {
+		for _, operation := range pathItem.GetOperations() {
+			v.WarmOperationSchemaCache(operation)
 		}
 	}
+}
 
-	//render the schema, to be used for validation
-	return ValidateRequestSchema(request, schema, renderedInline, renderedJSON)
+func (v *requestBodyValidator) WarmOperationSchemaCache(operation *v3.Operation) {
+	if operation == nil || operation.RequestBody == nil {
+		return
+	}
+	for _, mediaType := range operation.RequestBody.Content {
+		if mediaType.Schema != nil {
+			v.getSchema(mediaType)
+		}
+		for _, enc := range mediaType.Encoding {
+			for name, header := range enc.Headers {
+				if header.Schema != nil && header.Schema.Schema() != nil {
+					_, _ = v.headerSchemas.Compile(name, header.Schema.Schema())
+				}
+			}
+		}
+	}
 }
@@ -5,11 +5,23 @@ package requests
 
 import (
 	"bytes"
+	"compress/gzip"
+	"compress/zlib"
+	"context"
 	"encoding/json"
+	"fmt"
+	"io"
+	"mime/multipart"
 	"net/http"
+	"net/textproto"
+	"regexp"
 	"testing"
+	"time"
 
 	"github.com/pb33f/libopenapi"
+	"github.com/pb33f/libopenapi-validator/config"
+	"github.com/pb33f/libopenapi-validator/errors"
+	"github.com/pb33f/libopenapi-validator/helpers"
 	"github.com/pb33f/libopenapi-validator/paths"
 	"github.com/stretchr/testify/assert"
 )
@@ -190,6 +202,44 @@ paths:
 
 }
 
+func TestValidateBody_BodyRestored(t *testing.T) {
+	spec := `openapi: 3.1.0
+paths:
+  /burgers/createBurger:
+    post:
+      requestBody:
+        content:
+          application/json:
+            schema:
+              type: object
+              properties:
+                name:
+                  type: string`
+
+	doc, _ := libopenapi.NewDocument([]byte(spec))
+
+	m, _ := doc.BuildV3Model()
+	v := NewRequestBodyValidator(&m.Model)
+
+	request, _ := http.NewRequest(http.MethodPost, "https://things.com/burgers/createBurger",
+		io.NopCloser(bytes.NewBufferString(`{"name": "Big Mac"}`)))
+	request.Header.Set("Content-Type", "application/json")
+
+	valid, errors := v.ValidateRequestBody(request)
+
+	assert.True(t, valid)
+	assert.Len(t, errors, 0)
+
+	// the body can be read again, and re-created, after validation.
+	body, _ := io.ReadAll(request.Body)
+	assert.Equal(t, `{"name": "Big Mac"}`, string(body))
+	assert.Equal(t, int64(len(body)), request.ContentLength)
+
+	reread, _ := request.GetBody()
+	body, _ = io.ReadAll(reread)
+	assert.Equal(t, `{"name": "Big Mac"}`, string(body))
+}
+
 func TestValidateBody_ContentTypeNotFound(t *testing.T) {
 	spec := `openapi: 3.1.0
 paths:
@@ -718,10 +768,17 @@ components:
 	assert.False(t, valid)
 	assert.Len(t, errors, 1)
 	assert.Len(t, errors[0].SchemaValidationErrors, 3)
-	assert.Equal(t, "oneOf failed", errors[0].SchemaValidationErrors[0].Reason)
+	assert.Equal(t, "oneOf failed, subschema 0: missing properties: 'uncookedWeight', 'uncookedHeight'; "+
+		"subschema 1: missing properties: 'usedOil', 'usedAnimalFat'", errors[0].SchemaValidationErrors[0].Reason)
 	assert.Equal(t, "missing properties: 'uncookedWeight', 'uncookedHeight'", errors[0].SchemaValidationErrors[1].Reason)
 	assert.Equal(t, "missing properties: 'usedOil', 'usedAnimalFat'", errors[0].SchemaValidationErrors[2].Reason)
 
+	// each subschema failure is also attached to the oneOf failure.
+	subschemas := errors[0].SchemaValidationErrors[0].SubschemaFailures
+	assert.Len(t, subschemas, 2)
+	assert.Equal(t, errors[0].SchemaValidationErrors[1], subschemas[0][0])
+	assert.Equal(t, errors[0].SchemaValidationErrors[2], subschemas[1][0])
+
 }
 
 func TestValidateBody_InvalidSchemaMinMax(t *testing.T) {
@@ -774,7 +831,7 @@ components:
 	assert.False(t, valid)
 	assert.Len(t, errors, 1)
 	assert.Len(t, errors[0].SchemaValidationErrors, 1)
-	assert.Equal(t, "must be <= 3 but found 5", errors[0].SchemaValidationErrors[0].Reason)
+	assert.Equal(t, "value 5 exceeds maximum 3", errors[0].SchemaValidationErrors[0].Reason)
 
 }
 
@@ -1031,3 +1088,1485 @@ components:
 	assert.Equal(t, "invalid character '}' looking for beginning of object key string", errors[0].SchemaValidationErrors[0].Reason)
 
 }
+
+func TestValidateBody_StrictContentType(t *testing.T) {
+	spec := `openapi: 3.1.0
+paths:
+  /burgers/createBurger:
+    post:
+      requestBody:
+        content:
+          application/json:
+            schema:
+              type: object
+              properties:
+                name:
+                  type: string`
+
+	doc, _ := libopenapi.NewDocument([]byte(spec))
+
+	m, _ := doc.BuildV3Model()
+
+	body := map[string]interface{}{
+		"name": "Big Mac",
+	}
+
+	bodyBytes, _ := json.Marshal(body)
+
+	// lenient matching ignores the charset.
+	v := NewRequestBodyValidator(&m.Model)
+	request, _ := http.NewRequest(http.MethodPost, "https://things.com/burgers/createBurger",
+		bytes.NewBuffer(bodyBytes))
+	request.Header.Set("Content-Type", "application/json; charset=utf-8")
+
+	valid, errors := v.ValidateRequestBody(request)
+	assert.True(t, valid)
+	assert.Len(t, errors, 0)
+
+	// strict matching requires the full content type to be declared.
+	v = NewRequestBodyValidator(&m.Model, config.WithStrictContentType())
+	request, _ = http.NewRequest(http.MethodPost, "https://things.com/burgers/createBurger",
+		bytes.NewBuffer(bodyBytes))
+	request.Header.Set("Content-Type", "application/json; charset=utf-8")
+
+	valid, errors = v.ValidateRequestBody(request)
+	assert.False(t, valid)
+	assert.Len(t, errors, 1)
+	assert.Equal(t, "POST operation request content type 'application/json; charset=utf-8' does not exist",
+		errors[0].Message)
+
+	request, _ = http.NewRequest(http.MethodPost, "https://things.com/burgers/createBurger",
+		bytes.NewBuffer(bodyBytes))
+	request.Header.Set("Content-Type", "application/json")
+
+	valid, errors = v.ValidateRequestBody(request)
+	assert.True(t, valid)
+	assert.Len(t, errors, 0)
+}
+
+func TestValidateBody_XML(t *testing.T) {
+	spec := `openapi: 3.1.0
+paths:
+  /burgers/createBurger:
+    post:
+      requestBody:
+        content:
+          application/xml:
+            schema:
+              type: object
+              xml:
+                name: burger
+              required: [id, name, patties, toppings]
+              properties:
+                id:
+                  type: integer
+                  xml:
+                    attribute: true
+                name:
+                  type: string
+                patties:
+                  type: integer
+                  minimum: 1
+                vegetarian:
+                  type: boolean
+                toppings:
+                  type: array
+                  xml:
+                    wrapped: true
+                  items:
+                    type: string
+                    xml:
+                      name: topping
+                sauces:
+                  type: array
+                  items:
+                    type: string
+                    xml:
+                      name: sauce`
+
+	doc, _ := libopenapi.NewDocument([]byte(spec))
+
+	m, _ := doc.BuildV3Model()
+	v := NewRequestBodyValidator(&m.Model)
+
+	send := func(body string) (bool, []*errors.ValidationError) {
+		request, _ := http.NewRequest(http.MethodPost, "https://things.com/burgers/createBurger",
+			bytes.NewBufferString(body))
+		request.Header.Set("Content-Type", "application/xml")
+		return v.ValidateRequestBody(request)
+	}
+
+	valid, errs := send(`<burger id="1234">
+  <name>Big Mac</name>
+  <patties>2</patties>
+  <vegetarian>false</vegetarian>
+  <toppings><topping>pickles</topping><topping>onions</topping></toppings>
+  <sauce>ketchup</sauce>
+  <sauce>mustard</sauce>
+</burger>`)
+	assert.True(t, valid)
+	assert.Len(t, errs, 0)
+
+	// schema violations
+	valid, errs = send(`<burger id="big">
+  <name>Big Mac</name>
+  <patties>0</patties>
+  <toppings><topping>pickles</topping></toppings>
+</burger>`)
+	assert.False(t, valid)
+	assert.Len(t, errs, 1)
+	assert.Equal(t, helpers.Schema, errs[0].ValidationSubType)
+	assert.Len(t, errs[0].SchemaValidationErrors, 2)
+
+	// missing wrapped array
+	valid, errs = send(`<burger id="1234"><name>Big Mac</name><patties>2</patties></burger>`)
+	assert.False(t, valid)
+	assert.Len(t, errs, 1)
+	assert.Equal(t, "missing properties: 'toppings'", errs[0].SchemaValidationErrors[0].Reason)
+
+	// malformed XML
+	valid, errs = send(`<burger id="1234"><name>Big Mac</burger>`)
+	assert.False(t, valid)
+	assert.Len(t, errs, 1)
+	assert.Equal(t, helpers.XMLType, errs[0].ValidationSubType)
+	assert.Equal(t, "POST request body for '/burgers/createBurger' is not valid XML", errs[0].Message)
+	assert.Len(t, errs[0].SchemaValidationErrors, 0)
+}
+
+func TestValidateBody_FormURLEncoded(t *testing.T) {
+	spec := `openapi: 3.1.0
+paths:
+  /login:
+    post:
+      requestBody:
+        content:
+          application/x-www-form-urlencoded:
+            schema:
+              type: object
+              required: [username, password]
+              properties:
+                username:
+                  type: string
+                password:
+                  type: string
+                  minLength: 8
+                remember:
+                  type: boolean
+                scopes:
+                  type: array
+                  items:
+                    type: string
+                    enum: [read, write]
+                ids:
+                  type: array
+                  items:
+                    type: integer
+                address:
+                  type: object
+                  properties:
+                    city:
+                      type: string
+                    zip:
+                      type: integer
+                meta:
+                  type: object
+                  required: [source]
+            encoding:
+              ids:
+                style: pipeDelimited
+              address:
+                style: deepObject
+              meta:
+                contentType: application/json`
+
+	doc, _ := libopenapi.NewDocument([]byte(spec))
+
+	m, _ := doc.BuildV3Model()
+	v := NewRequestBodyValidator(&m.Model)
+
+	send := func(body string) (bool, []*errors.ValidationError) {
+		request, _ := http.NewRequest(http.MethodPost, "https://things.com/login",
+			bytes.NewBufferString(body))
+		request.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+		return v.ValidateRequestBody(request)
+	}
+
+	valid, errs := send("username=dave&password=supersecret&remember=true&scopes=read&scopes=write" +
+		"&ids=1|2|3&address[city]=London&address[zip]=12345&meta=%7B%22source%22%3A%22web%22%7D")
+	assert.True(t, valid)
+	assert.Len(t, errs, 0)
+
+	// missing required field
+	valid, errs = send("username=dave")
+	assert.False(t, valid)
+	assert.Len(t, errs, 1)
+	assert.Equal(t, "missing properties: 'password'", errs[0].SchemaValidationErrors[0].Reason)
+
+	// bad values
+	valid, errs = send("username=dave&password=short&remember=maybe&scopes=read&scopes=delete" +
+		"&ids=1|two&address[zip]=abc&meta=%7B%7D")
+	assert.False(t, valid)
+	assert.Len(t, errs, 1)
+	assert.Len(t, errs[0].SchemaValidationErrors, 6)
+
+	// bad encoding
+	valid, errs = send("username=%zz")
+	assert.False(t, valid)
+	assert.Len(t, errs, 1)
+	assert.Equal(t, "POST request body for '/login' is not a valid URL encoded form", errs[0].Message)
+}
+
+func TestValidateBody_MultipartFormData(t *testing.T) {
+	spec := `openapi: 3.1.0
+paths:
+  /burgers/photos:
+    post:
+      requestBody:
+        content:
+          multipart/form-data:
+            schema:
+              type: object
+              required: [name, photo]
+              additionalProperties: false
+              properties:
+                name:
+                  type: string
+                rating:
+                  type: integer
+                  maximum: 5
+                photo:
+                  type: string
+                  format: binary
+            encoding:
+              photo:
+                contentType: image/png, image/jpeg`
+
+	doc, _ := libopenapi.NewDocument([]byte(spec))
+
+	m, _ := doc.BuildV3Model()
+	v := NewRequestBodyValidator(&m.Model)
+
+	type part struct {
+		name, fileName, contentType, value string
+	}
+
+	send := func(parts ...part) (bool, []*errors.ValidationError) {
+		var body bytes.Buffer
+		writer := multipart.NewWriter(&body)
+		for _, p := range parts {
+			h := make(textproto.MIMEHeader)
+			if p.fileName != "" {
+				h.Set("Content-Disposition",
+					fmt.Sprintf(`form-data; name="%s"; filename="%s"`, p.name, p.fileName))
+			} else {
+				h.Set("Content-Disposition", fmt.Sprintf(`form-data; name="%s"`, p.name))
+			}
+			if p.contentType != "" {
+				h.Set("Content-Type", p.contentType)
+			}
+			w, _ := writer.CreatePart(h)
+			_, _ = w.Write([]byte(p.value))
+		}
+		_ = writer.Close()
+
+		request, _ := http.NewRequest(http.MethodPost, "https://things.com/burgers/photos", &body)
+		request.Header.Set("Content-Type", writer.FormDataContentType())
+		return v.ValidateRequestBody(request)
+	}
+
+	valid, errs := send(
+		part{name: "name", value: "Big Mac"},
+		part{name: "rating", value: "5"},
+		part{name: "photo", fileName: "bigmac.png", contentType: "image/png", value: "\x89PNG..."})
+	assert.True(t, valid)
+	assert.Len(t, errs, 0)
+
+	// wrong file content type
+	valid, errs = send(
+		part{name: "name", value: "Big Mac"},
+		part{name: "photo", fileName: "bigmac.gif", contentType: "image/gif", value: "GIF89a"})
+	assert.False(t, valid)
+	assert.Len(t, errs, 1)
+	assert.Equal(t, "POST request body part 'photo' content type 'image/gif' is not allowed", errs[0].Message)
+	assert.Equal(t, "Send the part 'photo' using one of the following content types: 'image/png, image/jpeg'",
+		errs[0].HowToFix)
+
+	// missing required part, unexpected part and a bad value.
+	valid, errs = send(
+		part{name: "name", value: "Big Mac"},
+		part{name: "rating", value: "6"},
+		part{name: "sauce", value: "ketchup"})
+	assert.False(t, valid)
+	assert.Len(t, errs, 1)
+	assert.Len(t, errs[0].SchemaValidationErrors, 3)
+
+	// no boundary
+	request, _ := http.NewRequest(http.MethodPost, "https://things.com/burgers/photos",
+		bytes.NewBufferString("--nope"))
+	request.Header.Set("Content-Type", "multipart/form-data")
+	valid, errs = v.ValidateRequestBody(request)
+	assert.False(t, valid)
+	assert.Len(t, errs, 1)
+	assert.Equal(t, "POST request body for '/burgers/photos' is not a valid multipart form", errs[0].Message)
+}
+
+func TestValidateBody_MultipartPartHeaders(t *testing.T) {
+	spec := `openapi: 3.1.0
+paths:
+  /burgers/photos:
+    post:
+      requestBody:
+        content:
+          multipart/form-data:
+            schema:
+              type: object
+              properties:
+                photo:
+                  type: string
+                  format: binary
+            encoding:
+              photo:
+                contentType: image/png
+                headers:
+                  X-Checksum:
+                    required: true
+                    schema:
+                      type: string
+                      pattern: '^[a-f0-9]{8}$'
+                  X-Rate-Limit:
+                    schema:
+                      type: integer`
+
+	doc, _ := libopenapi.NewDocument([]byte(spec))
+
+	m, _ := doc.BuildV3Model()
+	v := NewRequestBodyValidator(&m.Model)
+
+	send := func(headers map[string]string) (bool, []*errors.ValidationError) {
+		var body bytes.Buffer
+		writer := multipart.NewWriter(&body)
+		h := make(textproto.MIMEHeader)
+		h.Set("Content-Disposition", `form-data; name="photo"; filename="bigmac.png"`)
+		h.Set("Content-Type", "image/png")
+		for k, val := range headers {
+			h.Set(k, val)
+		}
+		w, _ := writer.CreatePart(h)
+		_, _ = w.Write([]byte("\x89PNG..."))
+		_ = writer.Close()
+
+		request, _ := http.NewRequest(http.MethodPost, "https://things.com/burgers/photos", &body)
+		request.Header.Set("Content-Type", writer.FormDataContentType())
+		return v.ValidateRequestBody(request)
+	}
+
+	valid, errs := send(map[string]string{"X-Checksum": "deadbeef", "X-Rate-Limit": "10"})
+	assert.True(t, valid)
+	assert.Len(t, errs, 0)
+
+	// missing required header
+	valid, errs = send(nil)
+	assert.False(t, valid)
+	assert.Len(t, errs, 1)
+	assert.Equal(t, "POST request body part 'photo' header 'X-Checksum' is missing", errs[0].Message)
+
+	// malformed headers
+	valid, errs = send(map[string]string{"X-Checksum": "not-a-checksum", "X-Rate-Limit": "ten"})
+	assert.False(t, valid)
+	assert.Len(t, errs, 2)
+	for _, e := range errs {
+		assert.Equal(t, helpers.Multipart, e.ValidationSubType)
+	}
+}
+
+func TestValidateBody_WithContextCancelled(t *testing.T) {
+	spec := `openapi: 3.1.0
+paths:
+  /burgers/createBurger:
+    post:
+      requestBody:
+        content:
+          application/json:
+            schema:
+              type: object
+              properties:
+                name:
+                  type: string`
+
+	doc, _ := libopenapi.NewDocument([]byte(spec))
+
+	m, _ := doc.BuildV3Model()
+	v := NewRequestBodyValidator(&m.Model)
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Nanosecond)
+	defer cancel()
+	<-ctx.Done()
+
+	request, _ := http.NewRequest(http.MethodPost, "https://things.com/burgers/createBurger",
+		bytes.NewBufferString(`{"name": 12}`))
+	request.Header.Set("Content-Type", "application/json")
+
+	valid, errs := v.ValidateRequestBodyWithContext(ctx, request)
+	assert.False(t, valid)
+	assert.Len(t, errs, 1)
+	assert.True(t, errs[0].IsCancelledError())
+	assert.Equal(t, "The context used for validation is done: context deadline exceeded", errs[0].Reason)
+}
+
+func TestValidateBody_SchemaCache(t *testing.T) {
+	spec := `openapi: 3.1.0
+paths:
+  /burgers/createBurger:
+    post:
+      requestBody:
+        content:
+          application/json:
+            schema:
+              type: object
+              properties:
+                name:
+                  type: string
+  /burgers/updateBurger:
+    put:
+      requestBody:
+        content:
+          application/json:
+            schema:
+              type: object
+              required: [id]
+              properties:
+                id:
+                  type: integer`
+
+	doc, _ := libopenapi.NewDocument([]byte(spec))
+
+	m, _ := doc.BuildV3Model()
+	v := NewRequestBodyValidator(&m.Model)
+
+	countSchemas := func() int {
+		count := 0
+		v.(*requestBodyValidator).schemaCache.Range(func(_, value any) bool {
+			assert.NotNil(t, value.(*schemaCache).compiledSchema)
+			count++
+			return true
+		})
+		return count
+	}
+	assert.Equal(t, 0, countSchemas())
+
+	v.WarmSchemaCache()
+	assert.Equal(t, 2, countSchemas())
+
+	mediaType := m.Model.Paths.PathItems["/burgers/createBurger"].Post.RequestBody.Content["application/json"]
+	compiled := v.(*requestBodyValidator).getSchema(mediaType).compiledSchema
+
+	// the compiled schema is re-used by every request.
+	for i := 0; i < 3; i++ {
+		request, _ := http.NewRequest(http.MethodPost, "https://things.com/burgers/createBurger",
+			bytes.NewBufferString(`{"name": "Big Mac"}`))
+		request.Header.Set("Content-Type", "application/json")
+		valid, errs := v.ValidateRequestBody(request)
+		assert.True(t, valid)
+		assert.Len(t, errs, 0)
+	}
+	assert.Equal(t, 2, countSchemas())
+	assert.Same(t, compiled, v.(*requestBodyValidator).getSchema(mediaType).compiledSchema)
+}
+
+func TestValidateBody_FormatAssertions(t *testing.T) {
+	spec := `openapi: 3.1.0
+paths:
+  /burgers/createBurger:
+    post:
+      requestBody:
+        content:
+          application/json:
+            schema:
+              type: object
+              properties:
+                id:
+                  type: string
+                  format: uuid
+                chef:
+                  type: string
+                  format: email
+                cooked:
+                  type: string
+                  format: date-time`
+
+	doc, _ := libopenapi.NewDocument([]byte(spec))
+
+	m, _ := doc.BuildV3Model()
+
+	send := func(v RequestBodyValidator, body string) (bool, []*errors.ValidationError) {
+		request, _ := http.NewRequest(http.MethodPost, "https://things.com/burgers/createBurger",
+			bytes.NewBufferString(body))
+		request.Header.Set("Content-Type", "application/json")
+		return v.ValidateRequestBody(request)
+	}
+
+	valid := `{"id": "0b5d4c1e-4f4c-4b0e-9d1c-4c0b5e5d9f3a", "chef": "ronald@mcdonalds.com", "cooked": "2023-10-11T12:00:00Z"}`
+	invalid := `{"id": "not-a-uuid", "chef": "ronald", "cooked": "not-a-date"}`
+
+	// formats are annotations by default.
+	v := NewRequestBodyValidator(&m.Model)
+	ok, errs := send(v, invalid)
+	assert.True(t, ok)
+	assert.Len(t, errs, 0)
+
+	v = NewRequestBodyValidator(&m.Model, config.WithFormatAssertions())
+	ok, errs = send(v, valid)
+	assert.True(t, ok)
+	assert.Len(t, errs, 0)
+
+	ok, errs = send(v, invalid)
+	assert.False(t, ok)
+	assert.Len(t, errs, 1)
+	assert.Len(t, errs[0].SchemaValidationErrors, 3)
+
+	reasons := make(map[string]string)
+	for _, e := range errs[0].SchemaValidationErrors {
+		reasons[e.Location] = e.Reason
+	}
+	assert.Equal(t, "'not-a-uuid' is not valid 'uuid'", reasons["/properties/id/format"])
+	assert.Equal(t, "'ronald' is not valid 'email'", reasons["/properties/chef/format"])
+	assert.Equal(t, "'not-a-date' is not valid 'date-time'", reasons["/properties/cooked/format"])
+}
+
+func TestValidateBody_CustomFormats(t *testing.T) {
+	spec := `openapi: 3.1.0
+paths:
+  /burgers/createBurger:
+    post:
+      requestBody:
+        content:
+          application/json:
+            schema:
+              type: object
+              properties:
+                currency:
+                  type: string
+                  format: iso-currency
+                phone:
+                  type: string
+                  format: e164-phone
+                cooked:
+                  type: string
+                  format: date-time`
+
+	doc, _ := libopenapi.NewDocument([]byte(spec))
+
+	m, _ := doc.BuildV3Model()
+
+	e164 := regexp.MustCompile(`^\+[1-9]\d{1,14}$`)
+	v := NewRequestBodyValidator(&m.Model,
+		config.WithCustomFormat("iso-currency", func(s string) bool {
+			return s == "USD" || s == "EUR" || s == "GBP"
+		}),
+		config.WithCustomFormat("e164-phone", e164.MatchString))
+
+	send := func(body string) (bool, []*errors.ValidationError) {
+		request, _ := http.NewRequest(http.MethodPost, "https://things.com/burgers/createBurger",
+			bytes.NewBufferString(body))
+		request.Header.Set("Content-Type", "application/json")
+		return v.ValidateRequestBody(request)
+	}
+
+	// built-in formats are still annotations, custom formats are always checked.
+	valid, errs := send(`{"currency": "EUR", "phone": "+442071234567", "cooked": "not-a-date"}`)
+	assert.True(t, valid)
+	assert.Len(t, errs, 0)
+
+	valid, errs = send(`{"currency": "BTC", "phone": "07123 456789"}`)
+	assert.False(t, valid)
+	assert.Len(t, errs, 1)
+	assert.Len(t, errs[0].SchemaValidationErrors, 2)
+
+	reasons := make(map[string]string)
+	for _, e := range errs[0].SchemaValidationErrors {
+		reasons[e.Location] = e.Reason
+	}
+	assert.Equal(t, "'BTC' is not valid 'iso-currency'", reasons["/properties/currency/format"])
+	assert.Equal(t, "'07123 456789' is not valid 'e164-phone'", reasons["/properties/phone/format"])
+}
+
+func TestValidateBody_ReadOnlyNotRequired(t *testing.T) {
+	spec := `openapi: 3.1.0
+paths:
+  /burgers/createBurger:
+    post:
+      requestBody:
+        content:
+          application/json:
+            schema:
+              type: object
+              required: [id, name]
+              properties:
+                id:
+                  type: integer
+                  readOnly: true
+                name:
+                  type: string`
+
+	doc, _ := libopenapi.NewDocument([]byte(spec))
+
+	m, _ := doc.BuildV3Model()
+	v := NewRequestBodyValidator(&m.Model)
+
+	send := func(body string) (bool, []*errors.ValidationError) {
+		request, _ := http.NewRequest(http.MethodPost, "https://things.com/burgers/createBurger",
+			bytes.NewBufferString(body))
+		request.Header.Set("Content-Type", "application/json")
+		return v.ValidateRequestBody(request)
+	}
+
+	// the server generates the id, so the client doesn't need to send it.
+	valid, errs := send(`{"name": "Big Mac"}`)
+	assert.True(t, valid)
+	assert.Len(t, errs, 0)
+
+	// other requirements still apply.
+	valid, errs = send(`{"id": 1}`)
+	assert.False(t, valid)
+	assert.Len(t, errs, 1)
+	assert.Equal(t, "missing properties: 'name'", errs[0].SchemaValidationErrors[0].Reason)
+}
+
+func TestValidateBody_Discriminator(t *testing.T) {
+	spec := `openapi: 3.1.0
+paths:
+  /pets:
+    post:
+      requestBody:
+        content:
+          application/json:
+            schema:
+              oneOf:
+                - $ref: '#/components/schemas/Cat'
+                - $ref: '#/components/schemas/Dog'
+              discriminator:
+                propertyName: petType
+                mapping:
+                  cat: '#/components/schemas/Cat'
+                  dog: '#/components/schemas/Dog'
+components:
+  schemas:
+    Cat:
+      type: object
+      required: [petType, lives]
+      properties:
+        petType:
+          type: string
+        lives:
+          type: integer
+    Dog:
+      type: object
+      required: [petType, barks]
+      properties:
+        petType:
+          type: string
+        barks:
+          type: boolean`
+
+	doc, _ := libopenapi.NewDocument([]byte(spec))
+
+	m, _ := doc.BuildV3Model()
+	v := NewRequestBodyValidator(&m.Model)
+
+	send := func(body string) (bool, []*errors.ValidationError) {
+		request, _ := http.NewRequest(http.MethodPost, "https://things.com/pets",
+			bytes.NewBufferString(body))
+		request.Header.Set("Content-Type", "application/json")
+		return v.ValidateRequestBody(request)
+	}
+
+	valid, errs := send(`{"petType": "cat", "lives": 9}`)
+	assert.True(t, valid)
+	assert.Len(t, errs, 0)
+
+	// only the selected schema is reported, not every branch of the oneOf.
+	valid, errs = send(`{"petType": "dog", "barks": "loudly"}`)
+	assert.False(t, valid)
+	assert.Len(t, errs, 1)
+	assert.Len(t, errs[0].SchemaValidationErrors, 1)
+	assert.Equal(t, "expected boolean, but got string", errs[0].SchemaValidationErrors[0].Reason)
+
+	valid, errs = send(`{"lives": 9}`)
+	assert.False(t, valid)
+	assert.Len(t, errs, 1)
+	assert.Equal(t, "POST request body for '/pets' is missing the discriminator property 'petType'",
+		errs[0].Message)
+
+	valid, errs = send(`{"petType": "fish"}`)
+	assert.False(t, valid)
+	assert.Len(t, errs, 1)
+	assert.Equal(t, "POST request body for '/pets' has an unknown discriminator value 'fish'", errs[0].Message)
+	assert.Equal(t, "Set the 'petType' property to one of the following values: 'cat, dog'", errs[0].HowToFix)
+}
+
+func TestValidateBody_InstanceLocation(t *testing.T) {
+	spec := `openapi: 3.1.0
+paths:
+  /pets:
+    post:
+      requestBody:
+        content:
+          application/json:
+            schema:
+              type: object
+              properties:
+                category:
+                  type: object
+                  properties:
+                    name:
+                      type: string
+                photoUrls:
+                  type: array
+                  items:
+                    type: string
+                    format: uri
+                tags/labels:
+                  type: integer`
+
+	doc, _ := libopenapi.NewDocument([]byte(spec))
+
+	m, _ := doc.BuildV3Model()
+	v := NewRequestBodyValidator(&m.Model)
+
+	request, _ := http.NewRequest(http.MethodPost, "https://things.com/pets",
+		bytes.NewBufferString(`{"category": {"name": 1}, "photoUrls": ["a", "b", 3], "tags/labels": "x"}`))
+	request.Header.Set("Content-Type", "application/json")
+
+	valid, errs := v.ValidateRequestBody(request)
+	assert.False(t, valid)
+	assert.Len(t, errs, 1)
+
+	locations := make(map[string]string)
+	for _, e := range errs[0].SchemaValidationErrors {
+		locations[e.InstanceLocation] = e.DeepLocation
+	}
+	assert.Len(t, locations, 3)
+	assert.Equal(t, "/properties/category/properties/name/type", locations["/category/name"])
+	assert.Equal(t, "/properties/photoUrls/items/type", locations["/photoUrls/2"])
+	assert.Equal(t, "/properties/tags~1labels/type", locations["/tags~1labels"])
+}
+
+func TestValidateBody_MediaTypeWildcards(t *testing.T) {
+	spec := `openapi: 3.1.0
+paths:
+  /burgers/createBurger:
+    post:
+      requestBody:
+        content:
+          application/json:
+            schema:
+              type: object
+              required: [name]
+          application/*+json:
+            schema:
+              type: object
+              required: [vendor]
+          text/*:
+            schema:
+              type: string`
+
+	doc, _ := libopenapi.NewDocument([]byte(spec))
+
+	m, _ := doc.BuildV3Model()
+	v := NewRequestBodyValidator(&m.Model)
+
+	send := func(contentType, body string) (bool, []*errors.ValidationError) {
+		request, _ := http.NewRequest(http.MethodPost, "https://things.com/burgers/createBurger",
+			bytes.NewBufferString(body))
+		request.Header.Set("Content-Type", contentType)
+		return v.ValidateRequestBody(request)
+	}
+
+	valid, errs := send("application/json", `{"name": "Big Mac"}`)
+	assert.True(t, valid)
+	assert.Len(t, errs, 0)
+
+	// the structured suffix wildcard is more specific than application/json.
+	valid, errs = send("application/vnd.burger+json; charset=utf-8", `{"name": "Big Mac"}`)
+	assert.False(t, valid)
+	assert.Len(t, errs, 1)
+	assert.Equal(t, "missing properties: 'vendor'", errs[0].SchemaValidationErrors[0].Reason)
+
+	valid, errs = send("Application/VND.Burger+JSON", `{"vendor": "McDonalds"}`)
+	assert.True(t, valid)
+	assert.Len(t, errs, 0)
+
+	valid, errs = send("image/png", `burger`)
+	assert.False(t, valid)
+	assert.Len(t, errs, 1)
+	assert.Equal(t, "POST operation request content type 'image/png' does not exist", errs[0].Message)
+	assert.Equal(t, "The content type is invalid, Use one of the 3 supported types for this operation: "+
+		"application/*+json, application/json, text/*", errs[0].HowToFix)
+}
+
+func TestValidateBody_TextPlain(t *testing.T) {
+	spec := `openapi: 3.1.0
+paths:
+  /burgers/{burgerId}/name:
+    put:
+      requestBody:
+        content:
+          text/plain:
+            schema:
+              type: string
+              maxLength: 10
+              pattern: '^[A-Za-z ]+$'
+  /burgers/{burgerId}/notes:
+    put:
+      requestBody:
+        content:
+          text/csv:
+            schema:
+              type: object`
+
+	doc, _ := libopenapi.NewDocument([]byte(spec))
+
+	m, _ := doc.BuildV3Model()
+	v := NewRequestBodyValidator(&m.Model)
+
+	send := func(path, body string) (bool, []*errors.ValidationError) {
+		request, _ := http.NewRequest(http.MethodPut, "https://things.com/burgers/1/"+path,
+			bytes.NewBufferString(body))
+		request.Header.Set("Content-Type", "text/plain; charset=utf-8")
+		if path == "notes" {
+			request.Header.Set("Content-Type", "text/csv")
+		}
+		return v.ValidateRequestBody(request)
+	}
+
+	// the body is validated as a raw string, not as JSON.
+	valid, errs := send("name", "Big Mac")
+	assert.True(t, valid)
+	assert.Len(t, errs, 0)
+
+	valid, errs = send("name", "Quarter Pounder 2")
+	assert.False(t, valid)
+	assert.Len(t, errs, 1)
+	assert.Len(t, errs[0].SchemaValidationErrors, 2)
+
+	reasons := make(map[string]string)
+	for _, e := range errs[0].SchemaValidationErrors {
+		reasons[e.Location] = e.Reason
+	}
+	assert.Equal(t, "length must be <= 10, but got 17", reasons["/maxLength"])
+	assert.Equal(t, "does not match pattern '^[A-Za-z ]+$'", reasons["/pattern"])
+
+	// a raw body can't be validated against a schema that isn't a string.
+	valid, errs = send("notes", "a,b,c")
+	assert.True(t, valid)
+	assert.Len(t, errs, 0)
+}
+
+func TestValidateBody_MaxBodyBytes(t *testing.T) {
+	spec := `openapi: 3.1.0
+paths:
+  /burgers/createBurger:
+    post:
+      requestBody:
+        content:
+          application/json:
+            schema:
+              type: object
+              properties:
+                name:
+                  type: string`
+
+	doc, _ := libopenapi.NewDocument([]byte(spec))
+
+	m, _ := doc.BuildV3Model()
+	v := NewRequestBodyValidator(&m.Model, config.WithMaxBodyBytes(20))
+
+	// within the limit.
+	request, _ := http.NewRequest(http.MethodPost, "https://things.com/burgers/createBurger",
+		bytes.NewBufferString(`{"name": "Big Mac"}`))
+	request.Header.Set("Content-Type", "application/json")
+
+	valid, errors := v.ValidateRequestBody(request)
+	assert.True(t, valid)
+	assert.Len(t, errors, 0)
+
+	// the Content-Length is too large, the body is not read.
+	request, _ = http.NewRequest(http.MethodPost, "https://things.com/burgers/createBurger",
+		bytes.NewBufferString(`{"name": "Quarter Pounder"}`))
+	request.Header.Set("Content-Type", "application/json")
+
+	valid, errors = v.ValidateRequestBody(request)
+	assert.False(t, valid)
+	assert.Len(t, errors, 1)
+	assert.Equal(t, "POST request body for '/burgers/createBurger' is too large", errors[0].Message)
+	assert.Equal(t, "The request body is larger than the maximum of 20 bytes", errors[0].Reason)
+	assert.Equal(t, helpers.BodySize, errors[0].ValidationSubType)
+
+	// a streamed body with no Content-Length crosses the limit, nothing is lost.
+	request, _ = http.NewRequest(http.MethodPost, "https://things.com/burgers/createBurger",
+		io.NopCloser(bytes.NewBufferString(`{"name": "Quarter Pounder"}`)))
+	request.Header.Set("Content-Type", "application/json")
+
+	valid, errors = v.ValidateRequestBody(request)
+	assert.False(t, valid)
+	assert.Len(t, errors, 1)
+
+	body, _ := io.ReadAll(request.Body)
+	assert.Equal(t, `{"name": "Quarter Pounder"}`, string(body))
+}
+
+func TestValidateBody_AdditionalPropertiesNotAllowed(t *testing.T) {
+	spec := `openapi: 3.1.0
+paths:
+  /burgers/createBurger:
+    post:
+      requestBody:
+        content:
+          application/json:
+            schema:
+              type: object
+              additionalProperties: false
+              properties:
+                name:
+                  type: string`
+
+	doc, _ := libopenapi.NewDocument([]byte(spec))
+
+	m, _ := doc.BuildV3Model()
+	v := NewRequestBodyValidator(&m.Model)
+
+	request, _ := http.NewRequest(http.MethodPost, "https://things.com/burgers/createBurger",
+		bytes.NewBufferString(`{"name": "Big Mac", "fries": true, "drink": "cola"}`))
+	request.Header.Set("Content-Type", "application/json")
+
+	valid, errors := v.ValidateRequestBody(request)
+
+	assert.False(t, valid)
+	assert.Len(t, errors, 1)
+	assert.Len(t, errors[0].SchemaValidationErrors, 2)
+	assert.Equal(t, "property 'drink' is not allowed", errors[0].SchemaValidationErrors[0].Reason)
+	assert.Equal(t, "/drink", errors[0].SchemaValidationErrors[0].InstanceLocation)
+	assert.Equal(t, "property 'fries' is not allowed", errors[0].SchemaValidationErrors[1].Reason)
+	assert.Equal(t, "/fries", errors[0].SchemaValidationErrors[1].InstanceLocation)
+	assert.Equal(t, "/additionalProperties", errors[0].SchemaValidationErrors[1].DeepLocation)
+}
+
+func TestValidateBody_RejectUnexpectedBody(t *testing.T) {
+	spec := `openapi: 3.1.0
+paths:
+  /burgers/{burgerId}:
+    delete:
+      parameters:
+        - name: burgerId
+          in: path
+          required: true
+          schema:
+            type: string`
+
+	doc, _ := libopenapi.NewDocument([]byte(spec))
+
+	m, _ := doc.BuildV3Model()
+
+	// permissive by default.
+	request, _ := http.NewRequest(http.MethodDelete, "https://things.com/burgers/big-mac",
+		bytes.NewBufferString(`{"reason": "cold"}`))
+	request.Header.Set("Content-Type", "application/json")
+
+	valid, errors := NewRequestBodyValidator(&m.Model).ValidateRequestBody(request)
+	assert.True(t, valid)
+	assert.Len(t, errors, 0)
+
+	v := NewRequestBodyValidator(&m.Model, config.WithRejectUnexpectedBody())
+	valid, errors = v.ValidateRequestBody(request)
+	assert.False(t, valid)
+	assert.Len(t, errors, 1)
+	assert.Equal(t, "DELETE request for '/burgers/big-mac' has a body, but the operation accepts no request body",
+		errors[0].Message)
+	assert.Equal(t, helpers.Unexpected, errors[0].ValidationSubType)
+
+	// a body of unknown length is checked, and put back.
+	request, _ = http.NewRequest(http.MethodDelete, "https://things.com/burgers/big-mac",
+		io.NopCloser(bytes.NewBufferString(`{"reason": "cold"}`)))
+	valid, _ = v.ValidateRequestBody(request)
+	assert.False(t, valid)
+	body, _ := io.ReadAll(request.Body)
+	assert.Equal(t, `{"reason": "cold"}`, string(body))
+
+	// no body at all.
+	request, _ = http.NewRequest(http.MethodDelete, "https://things.com/burgers/big-mac", nil)
+	valid, errors = v.ValidateRequestBody(request)
+	assert.True(t, valid)
+	assert.Len(t, errors, 0)
+
+	request, _ = http.NewRequest(http.MethodDelete, "https://things.com/burgers/big-mac",
+		io.NopCloser(bytes.NewBufferString("")))
+	valid, _ = v.ValidateRequestBody(request)
+	assert.True(t, valid)
+}
+
+func TestValidateBody_RequiredBody(t *testing.T) {
+	spec := `openapi: 3.1.0
+paths:
+  /burgers/createBurger:
+    post:
+      requestBody:
+        required: true
+        content:
+          application/json:
+            schema:
+              type: object
+              properties:
+                name:
+                  type: string
+  /burgers/updateBurger:
+    post:
+      requestBody:
+        content:
+          application/json:
+            schema:
+              type: object
+              properties:
+                name:
+                  type: string`
+
+	doc, _ := libopenapi.NewDocument([]byte(spec))
+
+	m, _ := doc.BuildV3Model()
+	v := NewRequestBodyValidator(&m.Model)
+
+	// the body is missing entirely.
+	request, _ := http.NewRequest(http.MethodPost, "https://things.com/burgers/createBurger", nil)
+
+	valid, errors := v.ValidateRequestBody(request)
+	assert.False(t, valid)
+	assert.Len(t, errors, 1)
+	assert.Equal(t, "POST request body for '/burgers/createBurger' is missing", errors[0].Message)
+	assert.Equal(t, helpers.Missing, errors[0].ValidationSubType)
+	assert.Equal(t, 6, errors[0].SpecLine)
+
+	// the content type is sent, but the body is empty.
+	request, _ = http.NewRequest(http.MethodPost, "https://things.com/burgers/createBurger",
+		bytes.NewBufferString(""))
+	request.Header.Set("Content-Type", "application/json")
+
+	valid, errors = v.ValidateRequestBody(request)
+	assert.False(t, valid)
+	assert.Len(t, errors, 1)
+	assert.Equal(t, "POST request body for '/burgers/createBurger' is empty", errors[0].Message)
+	assert.Equal(t, helpers.Empty, errors[0].ValidationSubType)
+
+	// an empty object is still a body.
+	request, _ = http.NewRequest(http.MethodPost, "https://things.com/burgers/createBurger",
+		bytes.NewBufferString("{}"))
+	request.Header.Set("Content-Type", "application/json")
+
+	valid, errors = v.ValidateRequestBody(request)
+	assert.True(t, valid)
+	assert.Len(t, errors, 0)
+
+	// an optional body can be left out.
+	request, _ = http.NewRequest(http.MethodPost, "https://things.com/burgers/updateBurger", nil)
+
+	valid, errors = v.ValidateRequestBody(request)
+	assert.True(t, valid)
+	assert.Len(t, errors, 0)
+}
+
+func TestValidateBody_Nullable(t *testing.T) {
+	spec := `openapi: 3.0.3
+paths:
+  /burgers/createBurger:
+    post:
+      requestBody:
+        content:
+          application/json:
+            schema:
+              type: object
+              properties:
+                name:
+                  type: string
+                sauce:
+                  type: string
+                  nullable: true
+                size:
+                  type: string
+                  nullable: true
+                  enum: [small, large]`
+
+	doc, _ := libopenapi.NewDocument([]byte(spec))
+
+	m, _ := doc.BuildV3Model()
+	v := NewRequestBodyValidator(&m.Model)
+
+	request, _ := http.NewRequest(http.MethodPost, "https://things.com/burgers/createBurger",
+		bytes.NewBufferString(`{"name": "Big Mac", "sauce": null, "size": null}`))
+	request.Header.Set("Content-Type", "application/json")
+
+	valid, errors := v.ValidateRequestBody(request)
+	assert.True(t, valid)
+	assert.Len(t, errors, 0)
+
+	// name is not nullable.
+	request, _ = http.NewRequest(http.MethodPost, "https://things.com/burgers/createBurger",
+		bytes.NewBufferString(`{"name": null, "sauce": "ketchup"}`))
+	request.Header.Set("Content-Type", "application/json")
+
+	valid, errors = v.ValidateRequestBody(request)
+	assert.False(t, valid)
+	assert.Len(t, errors, 1)
+	assert.Len(t, errors[0].SchemaValidationErrors, 1)
+	assert.Equal(t, "expected string, but got null", errors[0].SchemaValidationErrors[0].Reason)
+	assert.Equal(t, "/name", errors[0].SchemaValidationErrors[0].InstanceLocation)
+}
+
+func TestValidateBody_NumericLimits(t *testing.T) {
+	spec := `openapi: 3.1.0
+paths:
+  /burgers/createBurger:
+    post:
+      requestBody:
+        content:
+          application/json:
+            schema:
+              type: object
+              properties:
+                patties:
+                  type: integer
+                  minimum: 1
+                  maximum: 100
+                pickles:
+                  type: integer
+                  exclusiveMinimum: 0
+                  exclusiveMaximum: 10
+                nuggets:
+                  type: integer
+                  multipleOf: 3`
+
+	doc, _ := libopenapi.NewDocument([]byte(spec))
+
+	m, _ := doc.BuildV3Model()
+	v := NewRequestBodyValidator(&m.Model)
+
+	tests := map[string]string{
+		`{"patties": 150}`: "value 150 exceeds maximum 100",
+		`{"patties": 0}`:   "value 0 is less than minimum 1",
+		`{"pickles": 10}`:  "value 10 is not less than exclusive maximum 10",
+		`{"pickles": 0}`:   "value 0 is not greater than exclusive minimum 0",
+		`{"nuggets": 10}`:  "value 10 is not a multiple of 3",
+	}
+	for body, reason := range tests {
+		request, _ := http.NewRequest(http.MethodPost, "https://things.com/burgers/createBurger",
+			bytes.NewBufferString(body))
+		request.Header.Set("Content-Type", "application/json")
+
+		valid, errors := v.ValidateRequestBody(request)
+		assert.False(t, valid, body)
+		assert.Len(t, errors, 1, body)
+		assert.Len(t, errors[0].SchemaValidationErrors, 1, body)
+		assert.Equal(t, reason, errors[0].SchemaValidationErrors[0].Reason)
+	}
+}
+
+func TestValidateBody_ExclusiveBoundsBoolean(t *testing.T) {
+	spec := `openapi: 3.0.3
+paths:
+  /burgers/createBurger:
+    post:
+      requestBody:
+        content:
+          application/json:
+            schema:
+              type: object
+              properties:
+                pickles:
+                  type: integer
+                  minimum: 0
+                  exclusiveMinimum: true
+                  maximum: 10
+                  exclusiveMaximum: true`
+
+	doc, _ := libopenapi.NewDocument([]byte(spec))
+
+	m, _ := doc.BuildV3Model()
+	v := NewRequestBodyValidator(&m.Model)
+
+	request, _ := http.NewRequest(http.MethodPost, "https://things.com/burgers/createBurger",
+		bytes.NewBufferString(`{"pickles": 5}`))
+	request.Header.Set("Content-Type", "application/json")
+
+	valid, errors := v.ValidateRequestBody(request)
+	assert.True(t, valid)
+	assert.Len(t, errors, 0)
+
+	request, _ = http.NewRequest(http.MethodPost, "https://things.com/burgers/createBurger",
+		bytes.NewBufferString(`{"pickles": 10}`))
+	request.Header.Set("Content-Type", "application/json")
+
+	valid, errors = v.ValidateRequestBody(request)
+	assert.False(t, valid)
+	assert.Len(t, errors, 1)
+	assert.Len(t, errors[0].SchemaValidationErrors, 1)
+	assert.Equal(t, "value 10 is not less than exclusive maximum 10", errors[0].SchemaValidationErrors[0].Reason)
+}
+
+func TestValidateBody_ContentEncoding(t *testing.T) {
+	spec := `openapi: 3.1.0
+paths:
+  /burgers/createBurger:
+    post:
+      requestBody:
+        content:
+          application/json:
+            schema:
+              type: object
+              properties:
+                name:
+                  type: string`
+
+	doc, _ := libopenapi.NewDocument([]byte(spec))
+
+	m, _ := doc.BuildV3Model()
+	v := NewRequestBodyValidator(&m.Model)
+
+	gzipped := func(body string) []byte {
+		var buf bytes.Buffer
+		w := gzip.NewWriter(&buf)
+		_, _ = w.Write([]byte(body))
+		_ = w.Close()
+		return buf.Bytes()
+	}
+	deflated := func(body string) []byte {
+		var buf bytes.Buffer
+		w := zlib.NewWriter(&buf)
+		_, _ = w.Write([]byte(body))
+		_ = w.Close()
+		return buf.Bytes()
+	}
+	send := func(v RequestBodyValidator, encoding string, body []byte) (bool, []*errors.ValidationError) {
+		request, _ := http.NewRequest(http.MethodPost, "https://things.com/burgers/createBurger",
+			bytes.NewReader(body))
+		request.Header.Set(helpers.ContentTypeHeader, helpers.JSONContentType)
+		request.Header.Set(helpers.ContentEncodingHeader, encoding)
+		return v.ValidateRequestBody(request)
+	}
+
+	valid, errs := send(v, helpers.Gzip, gzipped(`{"name": "Big Mac"}`))
+	assert.True(t, valid)
+	assert.Len(t, errs, 0)
+
+	valid, errs = send(v, helpers.Deflate, deflated(`{"name": "Big Mac"}`))
+	assert.True(t, valid)
+	assert.Len(t, errs, 0)
+
+	// the decompressed body is validated against the schema.
+	valid, errs = send(v, helpers.Gzip, gzipped(`{"name": 12}`))
+	assert.False(t, valid)
+	assert.Len(t, errs, 1)
+	assert.Equal(t, "expected string, but got number", errs[0].SchemaValidationErrors[0].Reason)
+
+	// a body that is not compressed using the declared encoding.
+	valid, errs = send(v, helpers.Gzip, []byte(`{"name": "Big Mac"}`))
+	assert.False(t, valid)
+	assert.Len(t, errs, 1)
+	assert.Equal(t, helpers.ContentEncoding, errs[0].ValidationSubType)
+	assert.Equal(t, "POST request body for '/burgers/createBurger' cannot be decompressed", errs[0].Message)
+
+	// a body that decompresses to more than the limit.
+	v = NewRequestBodyValidator(&m.Model, config.WithMaxBodyBytes(64))
+	valid, errs = send(v, helpers.Gzip, gzipped(fmt.Sprintf(`{"name": "%0100d"}`, 0)))
+	assert.False(t, valid)
+	assert.Len(t, errs, 1)
+	assert.Equal(t, helpers.ContentEncoding, errs[0].ValidationSubType)
+	assert.Contains(t, errs[0].Reason, "decompresses to more than 64 bytes")
+}
+
+func TestValidateBody_RejectDuplicateKeys(t *testing.T) {
+	spec := `openapi: 3.1.0
+paths:
+  /burgers/createBurger:
+    post:
+      requestBody:
+        content:
+          application/json:
+            schema:
+              type: object
+              properties:
+                name:
+                  type: string
+                toppings:
+                  type: array
+                  items:
+                    type: object`
+
+	doc, _ := libopenapi.NewDocument([]byte(spec))
+
+	m, _ := doc.BuildV3Model()
+	body := `{"name": "Big Mac", "toppings": [{"cheese": 1}, {"sauce": "mayo", "sauce": "ketchup"}], "name": 12}`
+
+	send := func(v RequestBodyValidator) (bool, []*errors.ValidationError) {
+		request, _ := http.NewRequest(http.MethodPost, "https://things.com/burgers/createBurger",
+			bytes.NewBufferString(body))
+		request.Header.Set(helpers.ContentTypeHeader, helpers.JSONContentType)
+		return v.ValidateRequestBody(request)
+	}
+
+	// by default, the last value of a duplicate key is validated.
+	valid, errs := send(NewRequestBodyValidator(&m.Model))
+	assert.False(t, valid)
+	assert.Len(t, errs, 1)
+	assert.Equal(t, helpers.Schema, errs[0].ValidationSubType)
+
+	valid, errs = send(NewRequestBodyValidator(&m.Model, config.WithRejectDuplicateKeys()))
+	assert.False(t, valid)
+	assert.Len(t, errs, 2)
+	assert.Equal(t, helpers.DuplicateKey, errs[0].ValidationSubType)
+	assert.Equal(t, "POST request body for '/burgers/createBurger' contains the duplicate key 'sauce'",
+		errs[0].Message)
+	assert.Equal(t, "/toppings/1/sauce", errs[0].Context)
+	assert.Equal(t, "/name", errs[1].Context)
+
+	// no duplicates, no problem.
+	body = `{"name": "Big Mac", "toppings": [{"sauce": "mayo"}, {"sauce": "ketchup"}]}`
+	valid, errs = send(NewRequestBodyValidator(&m.Model, config.WithRejectDuplicateKeys()))
+	assert.True(t, valid)
+	assert.Len(t, errs, 0)
+}
+
+func TestValidateBody_ContentTypeNotAccepted(t *testing.T) {
+	spec := `openapi: 3.1.0
+paths:
+  /burgers/createBurger:
+    post:
+      requestBody:
+        content:
+          application/json:
+            schema:
+              type: object
+              required: [name]
+          application/xml:
+            schema:
+              type: object`
+
+	doc, _ := libopenapi.NewDocument([]byte(spec))
+	m, _ := doc.BuildV3Model()
+	v := NewRequestBodyValidator(&m.Model)
+
+	// JSON sent as plain text is rejected before the body is read.
+	request, _ := http.NewRequest(http.MethodPost, "https://things.com/burgers/createBurger",
+		bytes.NewBufferString(`{"patties": 2}`))
+	request.Header.Set("Content-Type", "text/plain; charset=utf-8")
+
+	valid, errs := v.ValidateRequestBody(request)
+	assert.False(t, valid)
+	assert.Len(t, errs, 1)
+	assert.Equal(t, helpers.RequestBodyContentType, errs[0].ValidationSubType)
+	assert.Equal(t, "POST operation request content type 'text/plain; charset=utf-8' does not exist", errs[0].Message)
+	assert.Equal(t, "The content type 'text/plain; charset=utf-8' of the POST request submitted has not been "+
+		"defined, it's an unknown type. The request body accepts: application/json, application/xml", errs[0].Reason)
+	assert.Equal(t, "The content type is invalid, Use one of the 2 supported types for this operation: "+
+		"application/json, application/xml", errs[0].HowToFix)
+	body, _ := io.ReadAll(request.Body)
+	assert.Equal(t, `{"patties": 2}`, string(body))
+
+	// the charset is not part of the comparison.
+	request, _ = http.NewRequest(http.MethodPost, "https://things.com/burgers/createBurger",
+		bytes.NewBufferString(`{"name": "Big Mac"}`))
+	request.Header.Set("Content-Type", "Application/JSON; charset=utf-8")
+
+	valid, errs = v.ValidateRequestBody(request)
+	assert.True(t, valid)
+	assert.Len(t, errs, 0)
+
+	// a body without a content type cannot be matched to a media type.
+	request, _ = http.NewRequest(http.MethodPost, "https://things.com/burgers/createBurger",
+		bytes.NewBufferString(`{"name": "Big Mac"}`))
+
+	valid, errs = v.ValidateRequestBody(request)
+	assert.False(t, valid)
+	assert.Len(t, errs, 1)
+	assert.Equal(t, "POST request body for '/burgers/createBurger' has no content type", errs[0].Message)
+	assert.Equal(t, "Set the Content-Type header to one of the 2 supported types for this operation: "+
+		"application/json, application/xml", errs[0].HowToFix)
+}
+
+func TestValidateBody_IntegerPrecision(t *testing.T) {
+	spec := `openapi: 3.1.0
+paths:
+  /burgers/createBurger:
+    post:
+      requestBody:
+        content:
+          application/json:
+            schema:
+              type: object
+              properties:
+                patties:
+                  type: integer
+                id:
+                  type: integer
+                  format: int64
+                  maximum: 9007199254740992`
+
+	doc, _ := libopenapi.NewDocument([]byte(spec))
+	m, _ := doc.BuildV3Model()
+	v := NewRequestBodyValidator(&m.Model)
+
+	validate := func(body string) (bool, []*errors.ValidationError) {
+		request, _ := http.NewRequest(http.MethodPost, "https://things.com/burgers/createBurger",
+			bytes.NewBufferString(body))
+		request.Header.Set("Content-Type", "application/json")
+		return v.ValidateRequestBody(request)
+	}
+
+	// a number without a fraction is an integer, however it's written.
+	for _, body := range []string{`{"patties": 1.0}`, `{"patties": 1e0}`, `{"id": 9007199254740991}`} {
+		valid, errs := validate(body)
+		assert.True(t, valid, body)
+		assert.Len(t, errs, 0)
+	}
+
+	// a fraction is not, and the value sent is named.
+	valid, errs := validate(`{"patties": 1.5}`)
+	assert.False(t, valid)
+	assert.Len(t, errs, 1)
+	assert.Equal(t, "value 1.5 is not an integer", errs[0].SchemaValidationErrors[0].Reason)
+	assert.Equal(t, "/patties", errs[0].SchemaValidationErrors[0].InstanceLocation)
+
+	// integers beyond the precision of a float64 are compared exactly.
+	valid, errs = validate(`{"id": 9007199254740993}`)
+	assert.False(t, valid)
+	assert.Len(t, errs, 1)
+	assert.Equal(t, "value 9007199254740993 exceeds maximum 9007199254740992", errs[0].SchemaValidationErrors[0].Reason)
+}
+
+func TestValidateBody_SchemaCannotBeCompiled(t *testing.T) {
+	spec := `openapi: 3.0.0
+paths:
+  /burgers/createBurger:
+    post:
+      requestBody:
+        content:
+          application/json:
+            schema:
+              type: object
+              properties:
+                patties:
+                  type: integer
+                  exclusiveMinimum: 0`
+
+	doc, _ := libopenapi.NewDocument([]byte(spec))
+	m, _ := doc.BuildV3Model()
+	v := NewRequestBodyValidator(&m.Model)
+
+	request, _ := http.NewRequest(http.MethodPost, "https://things.com/burgers/createBurger",
+		bytes.NewBufferString(`{"patties": 2}`))
+	request.Header.Set("Content-Type", "application/json")
+
+	// a numeric exclusiveMinimum is not valid in 3.0, so the body cannot be validated.
+	valid, errs := v.ValidateRequestBody(request)
+	assert.False(t, valid)
+	assert.Len(t, errs, 1)
+	assert.Equal(t, "POST request body for '/burgers/createBurger' cannot be validated, the schema cannot be compiled",
+		errs[0].Message)
+	assert.Equal(t, helpers.Schema, errs[0].ValidationSubType)
+}
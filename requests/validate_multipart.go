@@ -0,0 +1,137 @@
+// Copyright 2023 Princess B33f Heavy Industries / Dave Shanley
+// SPDX-License-Identifier: MIT
+
+package requests
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"mime/multipart"
+	"net/http"
+	"net/textproto"
+	"strings"
+
+	"github.com/pb33f/libopenapi-validator/errors"
+	"github.com/pb33f/libopenapi-validator/helpers"
+	"github.com/pb33f/libopenapi-validator/parameters"
+	"github.com/pb33f/libopenapi/datamodel/high/base"
+	v3 "github.com/pb33f/libopenapi/datamodel/high/v3"
+)
+
+// decodeMultipartBody will decode a multipart/form-data request body into a map that can be validated against the
+// schema of the request body. Each part is read as a stream, file parts (those with a file name, or described by a
+// 'type: string, format: binary' schema) are never held in memory, only their content type is checked against the
+// encoding of the part. Any other parts are decoded using the same rules as URL encoded forms.
+func decodeMultipartBody(
+	request *http.Request,
+	body io.Reader,
+	schema *base.Schema,
+	encoding map[string]*v3.Encoding,
+	headerSchemas *helpers.SchemaCache) (map[string]any, []*errors.ValidationError) {
+
+	_, _, boundary := helpers.ExtractContentType(request.Header.Get(helpers.ContentTypeHeader))
+	if boundary == "" {
+		return nil, []*errors.ValidationError{errors.RequestBodyInvalidMultipart(request,
+			fmt.Errorf("the content type does not contain a boundary"))}
+	}
+
+	var validationErrors []*errors.ValidationError
+	values := make(map[string][]string)
+	reader := multipart.NewReader(body, boundary)
+	for {
+		part, err := reader.NextPart()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, []*errors.ValidationError{errors.RequestBodyInvalidMultipart(request, err)}
+		}
+		name := part.FormName()
+		if name == "" {
+			_ = part.Close()
+			continue
+		}
+
+		// check the content type and the headers of the part are allowed by the encoding.
+		if enc, ok := encoding[name]; ok {
+			partContentType, _, _ := helpers.ExtractContentType(part.Header.Get(helpers.ContentTypeHeader))
+			if enc.ContentType != "" && !helpers.MediaTypeMatches(partContentType, enc.ContentType) {
+				validationErrors = append(validationErrors,
+					errors.RequestBodyPartContentTypeInvalid(request, name, partContentType, enc))
+			}
+			validationErrors = append(validationErrors, validatePartHeaders(request, name, part.Header, enc, headerSchemas)...)
+		}
+
+		if part.FileName() != "" || isBinaryProperty(schema, name) {
+			// file contents are not validated, so don't hold on to them.
+			if _, err = io.Copy(io.Discard, part); err != nil {
+				return nil, []*errors.ValidationError{errors.RequestBodyInvalidMultipart(request, err)}
+			}
+			values[name] = append(values[name], "")
+		} else {
+			var buf bytes.Buffer
+			if _, err = io.Copy(&buf, part); err != nil {
+				return nil, []*errors.ValidationError{errors.RequestBodyInvalidMultipart(request, err)}
+			}
+			values[name] = append(values[name], buf.String())
+		}
+		_ = part.Close()
+	}
+	return helpers.DecodeFormValues(values, schema, encoding), validationErrors
+}
+
+// isBinaryProperty will return true if the property (or the items of the property) is a binary string.
+func isBinaryProperty(schema *base.Schema, name string) bool {
+	if schema == nil || schema.Properties[name] == nil {
+		return false
+	}
+	prop := schema.Properties[name].Schema()
+	if prop != nil && prop.Items != nil && prop.Items.IsA() {
+		prop = prop.Items.A.Schema()
+	}
+	return prop != nil && prop.Format == helpers.Binary
+}
+
+// validatePartHeaders will validate the headers of a multipart part against the headers declared by the encoding
+// of the part. Content-Type is ignored, it's described by the encoding content type.
+func validatePartHeaders(
+	request *http.Request,
+	part string,
+	partHeaders textproto.MIMEHeader,
+	enc *v3.Encoding,
+	headerSchemas *helpers.SchemaCache) []*errors.ValidationError {
+
+	var validationErrors []*errors.ValidationError
+	for name, header := range enc.Headers {
+		if strings.EqualFold(name, helpers.ContentTypeHeader) {
+			continue
+		}
+		values := partHeaders.Values(name)
+		if len(values) == 0 {
+			if header.Required {
+				validationErrors = append(validationErrors,
+					errors.RequestBodyPartHeaderMissing(request, part, name, header))
+			}
+			continue
+		}
+		if header.Schema == nil {
+			continue
+		}
+		sch := header.Schema.Schema()
+		if sch == nil {
+			continue
+		}
+		validationErrors = append(validationErrors,
+			parameters.ValidateParameterSchema(sch,
+				helpers.DecodeHeaderValue(strings.Join(values, helpers.Comma), header, sch),
+				"",
+				fmt.Sprintf("Request body part '%s' header", part),
+				fmt.Sprintf("The request body part '%s' header", part),
+				name,
+				helpers.RequestBodyValidation,
+				helpers.Multipart,
+				headerSchemas)...)
+	}
+	return validationErrors
+}
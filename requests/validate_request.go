@@ -5,20 +5,25 @@ package requests
 
 import (
 	"bytes"
+	"context"
 	"encoding/json"
 	"fmt"
+	"github.com/pb33f/libopenapi-validator/config"
 	"github.com/pb33f/libopenapi-validator/errors"
 	"github.com/pb33f/libopenapi-validator/helpers"
 	"github.com/pb33f/libopenapi-validator/schema_validation"
 	"github.com/pb33f/libopenapi/datamodel/high/base"
+	v3 "github.com/pb33f/libopenapi/datamodel/high/v3"
 	"github.com/santhosh-tekuri/jsonschema/v5"
 	"gopkg.in/yaml.v3"
 	"io"
 	"net/http"
+	"net/url"
 	"reflect"
 	"regexp"
 	"strconv"
 	"strings"
+	"time"
 )
 
 var instanceLocationRegex = regexp.MustCompile(`^/(\d+)`)
@@ -30,19 +35,98 @@ func ValidateRequestSchema(
 	schema *base.Schema,
 	renderedSchema,
 	jsonSchema []byte) (bool, []*errors.ValidationError) {
+	return validateRequestSchema(context.Background(), request,
+		newSchemaCache(schema, renderedSchema, jsonSchema, nil), nil, nil, config.NewValidationOptions())
+}
+
+// validateRequestSchema will validate an http.Request pointer against a rendered and compiled schema, form encoded
+// bodies are decoded using the encoding rules of the media type. Compressed bodies are decompressed first, up to
+// the MaxBodyBytes of the options (or the default limit, when it's 0). Validation stops if the context is done
+// before the body is read, or before the body is validated.
+func validateRequestSchema(
+	ctx context.Context,
+	request *http.Request,
+	cached *schemaCache,
+	encoding map[string]*v3.Encoding,
+	headerSchemas *helpers.SchemaCache,
+	options *config.ValidationOptions) (bool, []*errors.ValidationError) {
+
+	schema := cached.schema
+	renderedSchema := cached.renderedInline
 
 	var validationErrors []*errors.ValidationError
 
-	requestBody, _ := io.ReadAll(request.Body)
+	if ctx.Err() != nil {
+		return false, []*errors.ValidationError{errors.ValidationCancelled(ctx)}
+	}
+
+	// no request body? nothing to read.
+	if request.Body == nil || request.Body == http.NoBody {
+		return true, nil
+	}
 
-	// close the request body, so it can be re-read later by another player in the chain
+	parsed := time.Now()
+	rawBody, _ := io.ReadAll(request.Body)
+
+	// close the request body, and replace it with a copy, so it can be re-read later by another player in the
+	// chain. the content length and GetBody are kept in step, so the request can also be re-sent.
 	_ = request.Body.Close()
-	request.Body = io.NopCloser(bytes.NewBuffer(requestBody))
+	request.Body = io.NopCloser(bytes.NewReader(rawBody))
+	request.ContentLength = int64(len(rawBody))
+	request.GetBody = func() (io.ReadCloser, error) {
+		return io.NopCloser(bytes.NewReader(rawBody)), nil
+	}
+
+	// a compressed body is decompressed to be validated, the body of the request is left as it was sent.
+	requestBody, err := helpers.DecodeContentEncoding(
+		request.Header.Get(helpers.ContentEncodingHeader), rawBody, options.MaxBodyBytes)
+	if err != nil {
+		return false, []*errors.ValidationError{
+			errors.RequestBodyContentEncodingInvalid(request, err, options.MaxBodyBytes)}
+	}
 
 	var decodedObj interface{}
 
-	if len(requestBody) > 0 {
-		err := json.Unmarshal(requestBody, &decodedObj)
+	contentType := strings.ToLower(request.Header.Get(helpers.ContentTypeHeader))
+
+	// XML bodies are decoded into a structure that can be validated, using the schema to guide the conversion.
+	if len(requestBody) > 0 && strings.Contains(contentType, helpers.XMLType) {
+		var err error
+		if decodedObj, err = helpers.DecodeXML(requestBody, schema); err != nil {
+			return false, []*errors.ValidationError{errors.RequestBodyInvalidXML(request, err)}
+		}
+	} else if len(requestBody) > 0 && strings.Contains(contentType, helpers.FormURLEncodedContentType) {
+		// form bodies are decoded into a map, using the encoding rules of the media type.
+		values, err := url.ParseQuery(string(requestBody))
+		if err != nil {
+			return false, []*errors.ValidationError{errors.RequestBodyInvalidFormEncoding(request, err)}
+		}
+		decodedObj = helpers.DecodeFormValues(values, schema, encoding)
+	} else if len(requestBody) > 0 && strings.Contains(contentType, helpers.MultipartFormContentType) {
+		// multipart bodies are decoded part by part, problems with individual parts are reported
+		// along with any schema violations.
+		parts, partErrs := decodeMultipartBody(request, bytes.NewReader(requestBody), schema, encoding, headerSchemas)
+		if parts == nil {
+			return false, partErrs
+		}
+		validationErrors = append(validationErrors, partErrs...)
+		decodedObj = parts
+	} else if len(requestBody) > 0 && contentType != "" && !helpers.IsStructuredContentType(contentType) {
+		// unstructured bodies (text/plain for example) are validated as a raw string.
+		decodedObj = string(requestBody)
+	} else if len(requestBody) > 0 {
+		// duplicate keys are lost once the body is decoded, so they are found first.
+		if options.RejectDuplicateKeys {
+			if duplicates, dErr := helpers.FindDuplicateKeys(requestBody); dErr == nil && len(duplicates) > 0 {
+				for _, duplicate := range duplicates {
+					validationErrors = append(validationErrors,
+						errors.RequestBodyDuplicateKey(request, duplicate.Key, duplicate.Pointer))
+				}
+				return false, validationErrors
+			}
+		}
+		var err error
+		decodedObj, err = helpers.UnmarshalJSON(requestBody)
 
 		if err != nil {
 			// cannot decode the request body, so it's not valid
@@ -55,6 +139,7 @@ func ValidateRequestSchema(
 			validationErrors = append(validationErrors, &errors.ValidationError{
 				ValidationType:    helpers.RequestBodyValidation,
 				ValidationSubType: helpers.Schema,
+				Code:              errors.ErrorCodeBodyMalformed,
 				Message: fmt.Sprintf("%s request body for '%s' failed to validate schema",
 					request.Method, request.URL.Path),
 				Reason:                 fmt.Sprintf("The request body cannot be decoded: %s", err.Error()),
@@ -68,17 +153,41 @@ func ValidateRequestSchema(
 		}
 	}
 
+	options.Observer.BodyParsed(parsed)
+
 	// no request body? failed to decode anything? nothing to do here.
 	if requestBody == nil || decodedObj == nil {
 		return true, nil
 	}
 
-	compiler := jsonschema.NewCompiler()
-	_ = compiler.AddResource("requestBody.json", strings.NewReader(string(jsonSchema)))
-	jsch, _ := compiler.Compile("requestBody.json")
+	if ctx.Err() != nil {
+		return false, []*errors.ValidationError{errors.ValidationCancelled(ctx)}
+	}
+
+	// the schema is not valid JSON Schema, so it could not be compiled.
+	if cached.compiledSchema == nil {
+		return false, append(validationErrors, errors.RequestBodySchemaInvalid(request, schema, cached.compileError))
+	}
+
+	// a discriminator selects the single schema an object is validated against.
+	jsch := cached.compiledSchema
+	if _, isObject := decodedObj.(map[string]any); isObject && cached.discriminated != nil {
+		selected, value, present := cached.discriminated.Select(decodedObj)
+		if !present {
+			return false, append(validationErrors,
+				errors.RequestBodyDiscriminatorMissing(request, schema, cached.discriminated))
+		}
+		if selected == nil {
+			return false, append(validationErrors,
+				errors.RequestBodyDiscriminatorInvalid(request, schema, cached.discriminated, value))
+		}
+		jsch = selected
+	}
 
 	// validate the object against the schema
+	validated := time.Now()
 	scErrs := jsch.Validate(decodedObj)
+	options.Observer.Validated(validated)
 	if scErrs != nil {
 		jk := scErrs.(*jsonschema.ValidationError)
 
@@ -116,11 +225,14 @@ func ValidateRequestSchema(
 				}
 
 				violation := &errors.SchemaValidationFailure{
-					Reason:          er.Error,
-					Location:        er.KeywordLocation,
-					ReferenceSchema: string(renderedSchema),
-					ReferenceObject: referenceObject,
-					OriginalError:   jk,
+					Reason:           er.Error,
+					Location:         er.KeywordLocation,
+					DeepLocation:     er.KeywordLocation,
+					AbsoluteLocation: er.AbsoluteKeywordLocation,
+					InstanceLocation: er.InstanceLocation,
+					ReferenceSchema:  string(renderedSchema),
+					ReferenceObject:  referenceObject,
+					OriginalError:    jk,
 				}
 				// if we have a location within the schema, add it to the error
 				if located != nil {
@@ -142,6 +254,17 @@ func ValidateRequestSchema(
 			}
 		}
 
+		// name each property that is not allowed, the index of each tuple item that failed and every duplicate item,
+		// describe numeric limits that were crossed (and numbers that are not integers), explain which branch of an
+		// if/then/else was applied, and why each subschema of a oneOf or anyOf failed.
+		schemaValidationErrors = schema_validation.SplitAdditionalPropertyFailures(schemaValidationErrors)
+		schema_validation.DescribeNumericFailures(schemaValidationErrors)
+		schema_validation.DescribeIntegerFailures(schemaValidationErrors, decodedObj)
+		schema_validation.DescribeArrayFailures(schemaValidationErrors, decodedObj)
+		schema_validation.DescribeTupleFailures(schemaValidationErrors)
+		schema_validation.DescribeConditionalFailures(schemaValidationErrors)
+		schema_validation.GroupSubschemaFailures(schemaValidationErrors)
+
 		line := 1
 		col := 0
 		if schema.GoLow().Type.KeyNode != nil {
@@ -153,6 +276,7 @@ func ValidateRequestSchema(
 		validationErrors = append(validationErrors, &errors.ValidationError{
 			ValidationType:    helpers.RequestBodyValidation,
 			ValidationSubType: helpers.Schema,
+			Code:              errors.ErrorCodeSchema,
 			Message: fmt.Sprintf("%s request body for '%s' failed to validate schema",
 				request.Method, request.URL.Path),
 			Reason: "The request body is defined as an object. " +
@@ -4,10 +4,14 @@
 package responses
 
 import (
+	"github.com/pb33f/libopenapi-validator/config"
 	"github.com/pb33f/libopenapi-validator/errors"
+	"github.com/pb33f/libopenapi-validator/helpers"
 	"github.com/pb33f/libopenapi/datamodel/high/base"
 	"github.com/pb33f/libopenapi/datamodel/high/v3"
+	"github.com/santhosh-tekuri/jsonschema/v5"
 	"net/http"
+	"sync"
 )
 
 // ResponseBodyValidator is an interface that defines the methods for validating response bodies for Operations.
@@ -17,14 +21,29 @@ import (
 type ResponseBodyValidator interface {
 
 	// ValidateResponseBody will validate the response body for a http.Response pointer. The request is used to
-	// locate the operation in the specification, the response is used to ensure the response code, media type and the
-	// schema of the response body are valid.
+	// locate the operation in the specification, the response is used to ensure the response code, media type, headers
+	// and the schema of the response body are valid.
 	ValidateResponseBody(request *http.Request, response *http.Response) (bool, []*errors.ValidationError)
 
+	// ValidateResponseBodyWithPathItem is the same as ValidateResponseBody, but uses the supplied pathItem and
+	// pathValue (as returned by paths.FindPath) instead of looking them up, or using the pathItem set by
+	// SetPathItem. Nothing is stored on the validator, so this is safe for concurrent use.
+	ValidateResponseBodyWithPathItem(request *http.Request, response *http.Response, pathItem *v3.PathItem,
+		pathValue string) (bool, []*errors.ValidationError)
+
 	// SetPathItem will set the pathItem for the ResponseBodyValidator, all validations will be performed
 	// against this pathItem otherwise if not set, each validation will perform a lookup for the
-	// pathItem based on the *http.Request
+	// pathItem based on the *http.Request. The pathItem is stored on the validator, so SetPathItem is not safe
+	// to use while the validator is shared between goroutines, use ValidateResponseBodyWithPathItem instead.
 	SetPathItem(path *v3.PathItem, pathValue string)
+
+	// WarmSchemaCache will render and compile the schemas of all response bodies (and response headers) in the
+	// document up front. Schemas are otherwise compiled (once) the first time they are used.
+	WarmSchemaCache()
+
+	// WarmOperationSchemaCache is the same as WarmSchemaCache, but only compiles the schemas of the responses of a
+	// single operation.
+	WarmOperationSchemaCache(operation *v3.Operation)
 }
 
 func (v *responseBodyValidator) SetPathItem(path *v3.PathItem, pathValue string) {
@@ -32,21 +51,54 @@ func (v *responseBodyValidator) SetPathItem(path *v3.PathItem, pathValue string)
 	v.pathValue = pathValue
 }
 
-// NewResponseBodyValidator will create a new ResponseBodyValidator from an OpenAPI 3+ document
-func NewResponseBodyValidator(document *v3.Document) ResponseBodyValidator {
-	return &responseBodyValidator{document: document, schemaCache: make(map[[32]byte]*schemaCache)}
+// NewResponseBodyValidator will create a new ResponseBodyValidator from an OpenAPI 3+ document, any options
+// supplied will change the default validation behavior.
+func NewResponseBodyValidator(document *v3.Document, opts ...config.Option) ResponseBodyValidator {
+	options := config.NewValidationOptions(opts...)
+	return &responseBodyValidator{
+		document:      document,
+		options:       options,
+		headerSchemas: helpers.NewSchemaCache(options),
+	}
 }
 
 type schemaCache struct {
 	schema         *base.Schema
 	renderedInline []byte
 	renderedJSON   []byte
+	compiledSchema *jsonschema.Schema
+	compileError   error // the schema cannot be compiled, so bodies cannot be validated against it.
+	discriminated  *helpers.DiscriminatedSchemas
+	warnWriteOnly  bool // writeOnly properties are rejected by the schema, but reported as warnings.
+}
+
+// newSchemaCache will compile a rendered schema (and the schemas selected by a discriminator, if there is one)
+// ready to be cached, the options control how the schema is compiled.
+func newSchemaCache(
+	schema *base.Schema,
+	renderedInline,
+	renderedJSON []byte,
+	options *config.ValidationOptions) *schemaCache {
+
+	warnWriteOnly := options != nil && options.WarnWriteOnly && !options.StrictWriteOnly
+	prepared := helpers.PrepareResponseSchema(renderedJSON, warnWriteOnly || options != nil && options.StrictWriteOnly)
+	compiledSchema, err := helpers.NewCompiledSchema(helpers.ResponseBodyValidation, prepared, options)
+	return &schemaCache{
+		schema:         schema,
+		renderedInline: renderedInline,
+		renderedJSON:   renderedJSON,
+		compiledSchema: compiledSchema,
+		compileError:   err,
+		discriminated:  helpers.NewDiscriminatedSchemas(helpers.ResponseBodyValidation, schema, prepared, options),
+		warnWriteOnly:  warnWriteOnly,
+	}
 }
 
 type responseBodyValidator struct {
-	document    *v3.Document
-	pathItem    *v3.PathItem
-	pathValue   string
-	errors      []*errors.ValidationError
-	schemaCache map[[32]byte]*schemaCache
+	document      *v3.Document
+	options       *config.ValidationOptions
+	pathItem      *v3.PathItem
+	pathValue     string
+	schemaCache   sync.Map             // schema key (helpers.SchemaCacheKey) -> *schemaCache
+	headerSchemas *helpers.SchemaCache // compiled schemas of response headers (and cookies)
 }
@@ -7,12 +7,12 @@ import (
 	"github.com/pb33f/libopenapi-validator/errors"
 	"github.com/pb33f/libopenapi-validator/helpers"
 	"github.com/pb33f/libopenapi-validator/paths"
-	"github.com/pb33f/libopenapi/datamodel/high/base"
 	"github.com/pb33f/libopenapi/datamodel/high/v3"
 	"github.com/pb33f/libopenapi/utils"
 	"net/http"
 	"strconv"
 	"strings"
+	"time"
 )
 
 func (v *responseBodyValidator) ValidateResponseBody(
@@ -20,19 +20,26 @@ func (v *responseBodyValidator) ValidateResponseBody(
 	response *http.Response) (bool, []*errors.ValidationError) {
 
 	// find path
-	var pathItem *v3.PathItem
-	var errs []*errors.ValidationError
-	if v.pathItem == nil {
-		pathItem, errs, _ = paths.FindPath(request, v.document)
+	pathItem, pathValue := v.pathItem, v.pathValue
+	if pathItem == nil {
+		var errs []*errors.ValidationError
+		pathItem, errs, pathValue = paths.FindPathWithOptions(request, v.document, v.options)
 		if pathItem == nil || errs != nil {
-			v.errors = errs
 			return false, errs
 		}
-	} else {
-		pathItem = v.pathItem
 	}
+	return v.ValidateResponseBodyWithPathItem(request, response, pathItem, pathValue)
+}
+
+func (v *responseBodyValidator) ValidateResponseBodyWithPathItem(
+	request *http.Request,
+	response *http.Response,
+	pathItem *v3.PathItem,
+	pathValue string) (valid bool, validationErrors []*errors.ValidationError) {
+
+	// errors are labelled with the operation they were found in.
+	defer func() { errors.SetOperation(validationErrors, request, pathItem, pathValue) }()
 
-	var validationErrors []*errors.ValidationError
 	operation := helpers.ExtractOperation(request, pathItem)
 
 	// extract the response code from the response
@@ -41,13 +48,34 @@ func (v *responseBodyValidator) ValidateResponseBody(
 
 	// extract the media type from the content type header.
 	mediaTypeSting, _, _ := helpers.ExtractContentType(contentType)
+	if v.options.StrictContentType {
+		mediaTypeSting = strings.TrimSpace(contentType)
+	}
+
+	// a response with a content type the client does not accept should have been a 406 (Not Acceptable).
+	if v.options.CheckAcceptHeader && contentType != "" && httpCode != http.StatusNotAcceptable {
+		if responseMediaType, _, _ := helpers.ExtractContentType(contentType); !helpers.AcceptsMediaType(
+			request.Header.Values(helpers.AcceptHeader), responseMediaType) {
+			validationErrors = append(validationErrors, errors.ResponseContentTypeNotAcceptable(request, response))
+		}
+	}
 
-	// check if the response code is in the contract
-	foundResponse := operation.Responses.FindResponseByCode(httpCode)
+	// check if the response code is in the contract, either as an exact code or as a range (2XX)
+	foundResponse, responseCode := helpers.FindResponseByCode(operation.Responses, httpCode)
 	if foundResponse != nil {
+		v.options.Debug("selected response", "method", request.Method, "template", pathValue,
+			"status", httpCode, "response", responseCode)
 
-		// check content type has been defined in the contract
-		if mediaType, ok := foundResponse.Content[mediaTypeSting]; ok {
+		// check the headers defined for the response
+		validationErrors = append(validationErrors,
+			validateResponseHeaders(request, response, foundResponse.Headers, v.headerSchemas)...)
+
+		// check content type has been defined in the contract, some status codes never carry
+		// a body (204, 304), so there is no content to check for those.
+		mediaType, mediaTypeKey, ok := v.findMediaType(foundResponse.Content, mediaTypeSting)
+		if ok && responseCanHaveBody(httpCode) {
+			v.options.Debug("selected response media type", "method", request.Method, "template", pathValue,
+				"contentType", contentType, "mediaType", mediaTypeKey)
 
 			validationErrors = append(validationErrors,
 				v.checkResponseSchema(request, response, mediaTypeSting, mediaType)...)
@@ -55,12 +83,11 @@ func (v *responseBodyValidator) ValidateResponseBody(
 		} else {
 
 			// check that the operation *actually* returns a body. (i.e. a 204 response)
-			if foundResponse.Content != nil && len(foundResponse.Content) > 0 {
+			if responseCanHaveBody(httpCode) && len(foundResponse.Content) > 0 {
 
 				// content type not found in the contract
-				codeStr := strconv.Itoa(httpCode)
 				validationErrors = append(validationErrors,
-					errors.ResponseContentTypeNotFound(operation, request, response, codeStr, false))
+					errors.ResponseContentTypeNotFound(operation, request, response, responseCode, false))
 
 			}
 		}
@@ -68,17 +95,27 @@ func (v *responseBodyValidator) ValidateResponseBody(
 
 		// no code match, check for default response
 		if operation.Responses.Default != nil {
+			v.options.Debug("selected default response", "method", request.Method, "template", pathValue,
+				"status", httpCode)
+
+			// check the headers defined for the default response
+			validationErrors = append(validationErrors,
+				validateResponseHeaders(request, response, operation.Responses.Default.Headers, v.headerSchemas)...)
 
-			// check content type has been defined in the contract
-			if mediaType, ok := operation.Responses.Default.Content[mediaTypeSting]; ok {
+			// check content type has been defined in the contract, some status codes never carry
+			// a body (204, 304), so there is no content to check for those.
+			mediaType, mediaTypeKey, ok := v.findMediaType(operation.Responses.Default.Content, mediaTypeSting)
+			if ok && responseCanHaveBody(httpCode) {
+				v.options.Debug("selected response media type", "method", request.Method, "template", pathValue,
+					"contentType", contentType, "mediaType", mediaTypeKey)
 
 				validationErrors = append(validationErrors,
-					v.checkResponseSchema(request, response, contentType, mediaType)...)
+					v.checkResponseSchema(request, response, mediaTypeSting, mediaType)...)
 
 			} else {
 
 				// check that the operation *actually* returns a body. (i.e. a 204 response)
-				if operation.Responses.Default.Content != nil && len(operation.Responses.Default.Content) > 0 {
+				if responseCanHaveBody(httpCode) && len(operation.Responses.Default.Content) > 0 {
 
 					// content type not found in the contract
 					codeStr := strconv.Itoa(httpCode)
@@ -88,16 +125,33 @@ func (v *responseBodyValidator) ValidateResponseBody(
 			}
 
 		} else {
-			// TODO: add support for '2XX' and '3XX' responses in the contract
-			// no default, no code match, nothing!
+			// no default, no code or range match, nothing!
+			v.options.Debug("no response matched the status code", "method", request.Method,
+				"template", pathValue, "status", httpCode)
 			validationErrors = append(validationErrors,
 				errors.ResponseCodeNotFound(operation, request, httpCode))
 		}
 	}
-	if len(validationErrors) > 0 {
+	if errors.HasErrors(validationErrors) {
 		return false, validationErrors
 	}
-	return true, nil
+	return true, validationErrors
+}
+
+// findMediaType will locate the media type definition that matches the content type of the response, this
+// includes structured suffixes (+json) and wildcards (application/*). In strict mode only an exact match is used.
+func (v *responseBodyValidator) findMediaType(content map[string]*v3.MediaType,
+	mediaType string) (*v3.MediaType, string, bool) {
+	if v.options.StrictContentType {
+		mt, ok := content[mediaType]
+		return mt, mediaType, ok
+	}
+	return helpers.FindMediaType(content, mediaType)
+}
+
+// responseCanHaveBody will return false for status codes that must not include a response body.
+func responseCanHaveBody(code int) bool {
+	return !(code >= 100 && code < 200) && code != http.StatusNoContent && code != http.StatusNotModified
 }
 
 func (v *responseBodyValidator) checkResponseSchema(
@@ -108,47 +162,93 @@ func (v *responseBodyValidator) checkResponseSchema(
 
 	var validationErrors []*errors.ValidationError
 
-	// currently, we can only validate JSON based responses, so check for the presence
-	// of 'json' in the content type (what ever it may be) so we can perform a schema check on it.
-	// anything other than JSON, will be ignored.
-	if strings.Contains(strings.ToLower(contentType), helpers.JSONType) {
+	// response body validation has been switched off.
+	if v.options.SkipResponseBodyValidation {
+		v.options.Debug("skipped response body validation", "method", request.Method, "path", request.URL.Path)
+		return validationErrors
+	}
+
+	// JSON and XML based responses are decoded before validation, so check for the presence of 'json' or 'xml'
+	// in the content type (what ever it may be) so we can perform a schema check on it. Any other response
+	// (text/plain for example) can only be validated as a raw string, so it needs a string schema.
+	lowerContentType := strings.ToLower(contentType)
+	structured := strings.Contains(lowerContentType, helpers.JSONType) ||
+		strings.Contains(lowerContentType, helpers.XMLType)
+	if structured || !helpers.IsStructuredContentType(contentType) {
+
+		// the schema cannot be used, for example an external reference that cannot be resolved.
+		if mediaType.Schema != nil && mediaType.Schema.Schema() == nil {
+			return append(validationErrors, errors.ResponseBodySchemaUnresolved(request, response, mediaType.Schema))
+		}
 
 		// extract schema from media type
-		if mediaType.Schema != nil {
+		if mediaType.Schema != nil && (structured || helpers.IsStringSchema(mediaType.Schema.Schema())) {
 
-			var schema *base.Schema
-			var renderedInline, renderedJSON []byte
+			// render and compile the schema (or use the cached copy), to be used for validation
+			v.options.Debug("validating response body against schema", "method", request.Method,
+				"path", request.URL.Path, "status", response.StatusCode)
+			_, vErrs := validateResponseSchema(request, response, v.getSchema(mediaType), v.options)
+			validationErrors = append(validationErrors, vErrs...)
+		}
+	}
+	return validationErrors
+}
 
-			// have we seen this schema before? let's hash it and check the cache.
-			hash := mediaType.GoLow().Schema.Value.Hash()
+// getSchema will return the rendered and compiled schema for a media type. Rendering and compiling a schema is
+// intensive, so it's only performed once per schema and cached in the validator. The cache is safe for concurrent use.
+func (v *responseBodyValidator) getSchema(mediaType *v3.MediaType) *schemaCache {
 
-			if cacheHit, ch := v.schemaCache[hash]; ch {
+	// have we seen this schema before? check the cache.
+	key := helpers.SchemaCacheKey(mediaType.GoLow().Schema.Value)
+	if cacheHit, ok := v.schemaCache.Load(key); ok {
+		return cacheHit.(*schemaCache)
+	}
 
-				// got a hit, use cached values
-				schema = cacheHit.schema
-				renderedInline = cacheHit.renderedInline
-				renderedJSON = cacheHit.renderedJSON
+	compiled := time.Now()
+	schema := mediaType.Schema.Schema()
+	renderedInline, _ := helpers.RenderSchemaInline(schema)
+	renderedJSON, _ := utils.ConvertYAMLtoJSON(renderedInline)
 
-			} else {
+	// if another response compiled the same schema in the meantime, use that one.
+	cached, _ := v.schemaCache.LoadOrStore(key, newSchemaCache(schema, renderedInline, renderedJSON, v.options))
+	v.options.Observer.SchemaCompiled(compiled)
+	return cached.(*schemaCache)
+}
 
-				// render the schema inline and perform the intensive work of rendering and converting
-				// this is only performed once per schema and cached in the validator.
-				schema = mediaType.Schema.Schema()
-				renderedInline, _ = schema.RenderInline()
-				renderedJSON, _ = utils.ConvertYAMLtoJSON(renderedInline)
-				v.schemaCache[hash] = &schemaCache{
-					schema:         schema,
-					renderedInline: renderedInline,
-					renderedJSON:   renderedJSON,
-				}
-			}
+// WarmSchemaCache will render and compile the schema of every response body in the document, so the first
+// response for each operation does not pay the cost.
+func (v *responseBodyValidator) WarmSchemaCache() {
+	if v.document == nil || v.document.Paths == nil {
+		return
+	}
+	for _, pathItem := range v.document.Paths.PathItems {
+		for _, operation := range pathItem.GetOperations() {
+			v.WarmOperationSchemaCache(operation)
+		}
+	}
+}
 
-			// render the schema, to be used for validation
-			valid, vErrs := ValidateResponseSchema(request, response, schema, renderedInline, renderedJSON)
-			if !valid {
-				validationErrors = append(validationErrors, vErrs...)
+func (v *responseBodyValidator) WarmOperationSchemaCache(operation *v3.Operation) {
+	if operation == nil || operation.Responses == nil {
+		return
+	}
+	responses := make([]*v3.Response, 0, len(operation.Responses.Codes)+1)
+	for _, response := range operation.Responses.Codes {
+		responses = append(responses, response)
+	}
+	if operation.Responses.Default != nil {
+		responses = append(responses, operation.Responses.Default)
+	}
+	for _, response := range responses {
+		for _, mediaType := range response.Content {
+			if mediaType.Schema != nil {
+				v.getSchema(mediaType)
+			}
+		}
+		for name, header := range response.Headers {
+			if header.Schema != nil && header.Schema.Schema() != nil {
+				_, _ = v.headerSchemas.Compile(name, header.Schema.Schema())
 			}
 		}
 	}
-	return validationErrors
 }
@@ -5,14 +5,19 @@ package responses
 
 import (
 	"bytes"
+	"compress/gzip"
 	"encoding/json"
 	"fmt"
 	"github.com/pb33f/libopenapi"
+	"github.com/pb33f/libopenapi-validator/config"
+	"github.com/pb33f/libopenapi-validator/errors"
 	"github.com/pb33f/libopenapi-validator/helpers"
 	"github.com/pb33f/libopenapi-validator/paths"
 	"github.com/stretchr/testify/assert"
+	"io"
 	"net/http"
 	"net/http/httptest"
+	"strings"
 	"testing"
 )
 
@@ -252,7 +257,45 @@ paths:
 	assert.False(t, valid)
 	assert.Len(t, errors, 1)
 	assert.Equal(t, "POST operation request response code '422' does not exist", errors[0].Message)
-	assert.Equal(t, "The service is responding with a code that is not defined in the spec, fix the service or add the code to the specification", errors[0].HowToFix)
+	assert.Equal(t, "The reponse code '422' of the POST request submitted has not been defined, there is no "+
+		"'422' response, no '4XX' response and no default response. The operation declares: 200", errors[0].Reason)
+	assert.Equal(t, "The service is responding with a code that is not defined in the spec, fix the service to "+
+		"respond with one of the declared responses (200), or add the code (or its range '4XX', or a default "+
+		"response) to the specification", errors[0].HowToFix)
+}
+
+func TestValidateBody_ResponseCodeNotDeclared(t *testing.T) {
+	spec := `openapi: 3.1.0
+paths:
+  /burgers/createBurger:
+    post:
+      responses:
+        '400':
+          description: bad burger
+        '2XX':
+          description: good burger
+        '201':
+          description: new burger`
+
+	doc, _ := libopenapi.NewDocument([]byte(spec))
+
+	m, _ := doc.BuildV3Model()
+	v := NewResponseBodyValidator(&m.Model)
+
+	request, _ := http.NewRequest(http.MethodPost, "https://things.com/burgers/createBurger", nil)
+	response := &http.Response{StatusCode: http.StatusProxyAuthRequired, Header: http.Header{}, Body: http.NoBody}
+
+	// the declared responses are listed, sorted, so the fix is obvious.
+	valid, errors := v.ValidateResponseBody(request, response)
+	assert.False(t, valid)
+	assert.Len(t, errors, 1)
+	assert.Equal(t, "POST operation request response code '407' does not exist", errors[0].Message)
+	assert.Equal(t, "The reponse code '407' of the POST request submitted has not been defined, there is no "+
+		"'407' response, no '4XX' response and no default response. The operation declares: 201, 2XX, 400",
+		errors[0].Reason)
+	assert.Equal(t, "The service is responding with a code that is not defined in the spec, fix the service to "+
+		"respond with one of the declared responses (201, 2XX, 400), or add the code (or its range '4XX', or a "+
+		"default response) to the specification", errors[0].HowToFix)
 }
 
 func TestValidateBody_InvalidBasicSchema(t *testing.T) {
@@ -1042,3 +1085,1190 @@ paths:
 	assert.Equal(t, "invalid character '}' looking for beginning of object key string", errors[0].SchemaValidationErrors[0].Reason)
 
 }
+
+func TestValidateBody_SkipResponseBodyValidation(t *testing.T) {
+	spec := `openapi: 3.1.0
+paths:
+  /burgers/createBurger:
+    post:
+      responses:
+        '200':
+          content:
+            application/json:
+              schema:
+                type: object
+                properties:
+                  name:
+                    type: string
+                  patties:
+                    type: integer`
+
+	doc, _ := libopenapi.NewDocument([]byte(spec))
+
+	m, _ := doc.BuildV3Model()
+	v := NewResponseBodyValidator(&m.Model, config.WithoutResponseBodyValidation())
+
+	body := map[string]interface{}{
+		"name":    "Big Mac",
+		"patties": false,
+	}
+
+	bodyBytes, _ := json.Marshal(body)
+
+	// build a request
+	request, _ := http.NewRequest(http.MethodPost, "https://things.com/burgers/createBurger", bytes.NewReader(bodyBytes))
+	request.Header.Set(helpers.ContentTypeHeader, helpers.JSONContentType)
+
+	// simulate a request/response
+	res := httptest.NewRecorder()
+	handler := func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set(helpers.ContentTypeHeader, helpers.JSONContentType)
+		w.WriteHeader(http.StatusOK)
+		_, _ = w.Write(bodyBytes)
+	}
+
+	// fire the request
+	handler(res, request)
+
+	// record response
+	response := res.Result()
+
+	// validate!
+	valid, errors := v.ValidateResponseBody(request, response)
+
+	assert.True(t, valid)
+	assert.Len(t, errors, 0)
+
+	// the status code is still checked.
+	res = httptest.NewRecorder()
+	handler = func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set(helpers.ContentTypeHeader, helpers.JSONContentType)
+		w.WriteHeader(http.StatusTeapot)
+		_, _ = w.Write(bodyBytes)
+	}
+	handler(res, request)
+
+	valid, errors = v.ValidateResponseBody(request, res.Result())
+
+	assert.False(t, valid)
+	assert.Len(t, errors, 1)
+}
+
+func TestValidateBody_StrictContentType(t *testing.T) {
+	spec := `openapi: 3.1.0
+paths:
+  /burgers/createBurger:
+    post:
+      responses:
+        '200':
+          content:
+            application/json:
+              schema:
+                type: object
+                properties:
+                  name:
+                    type: string`
+
+	doc, _ := libopenapi.NewDocument([]byte(spec))
+
+	m, _ := doc.BuildV3Model()
+	v := NewResponseBodyValidator(&m.Model, config.WithStrictContentType())
+
+	bodyBytes, _ := json.Marshal(map[string]interface{}{"name": "Big Mac"})
+
+	// build a request
+	request, _ := http.NewRequest(http.MethodPost, "https://things.com/burgers/createBurger", bytes.NewReader(bodyBytes))
+	request.Header.Set(helpers.ContentTypeHeader, helpers.JSONContentType)
+
+	// simulate a request/response
+	res := httptest.NewRecorder()
+	handler := func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set(helpers.ContentTypeHeader, "application/json; charset=utf-8")
+		w.WriteHeader(http.StatusOK)
+		_, _ = w.Write(bodyBytes)
+	}
+
+	// fire the request
+	handler(res, request)
+
+	// validate!
+	valid, errors := v.ValidateResponseBody(request, res.Result())
+
+	assert.False(t, valid)
+	assert.Len(t, errors, 1)
+	assert.Equal(t, "The content type is invalid, Use one of the 1 "+
+		"supported types for this operation: application/json", errors[0].HowToFix)
+}
+
+func TestValidateBody_ResponseHeaders(t *testing.T) {
+	spec := `openapi: 3.1.0
+paths:
+  /burgers/createBurger:
+    post:
+      responses:
+        '200':
+          headers:
+            X-RateLimit-Remaining:
+              required: true
+              schema:
+                type: integer
+                minimum: 0
+            X-Burger-Tags:
+              schema:
+                type: array
+                items:
+                  type: string
+                  enum: [cheesy, meaty]
+          content:
+            application/json:
+              schema:
+                type: object`
+
+	doc, _ := libopenapi.NewDocument([]byte(spec))
+
+	m, _ := doc.BuildV3Model()
+	v := NewResponseBodyValidator(&m.Model)
+
+	bodyBytes, _ := json.Marshal(map[string]interface{}{"name": "Big Mac"})
+
+	request, _ := http.NewRequest(http.MethodPost, "https://things.com/burgers/createBurger", nil)
+
+	respond := func(headers map[string]string) *http.Response {
+		res := httptest.NewRecorder()
+		handler := func(w http.ResponseWriter, r *http.Request) {
+			w.Header().Set(helpers.ContentTypeHeader, helpers.JSONContentType)
+			for k, val := range headers {
+				w.Header()[k] = []string{val}
+			}
+			w.WriteHeader(http.StatusOK)
+			_, _ = w.Write(bodyBytes)
+		}
+		handler(res, request)
+		return res.Result()
+	}
+
+	// header names are not case-sensitive.
+	valid, errors := v.ValidateResponseBody(request, respond(map[string]string{
+		"X-Ratelimit-Remaining": "10",
+		"X-Burger-Tags":         "cheesy,meaty",
+	}))
+	assert.True(t, valid)
+	assert.Len(t, errors, 0)
+
+	// missing required header
+	valid, errors = v.ValidateResponseBody(request, respond(nil))
+	assert.False(t, valid)
+	assert.Len(t, errors, 1)
+	assert.Equal(t, "POST / 200 operation response header 'X-RateLimit-Remaining' is missing", errors[0].Message)
+	assert.Equal(t, helpers.Header, errors[0].ValidationSubType)
+
+	// invalid header values
+	valid, errors = v.ValidateResponseBody(request, respond(map[string]string{
+		"X-Ratelimit-Remaining": "lots",
+		"X-Burger-Tags":         "cheesy,veggie",
+	}))
+	assert.False(t, valid)
+	assert.Len(t, errors, 2)
+	for _, e := range errors {
+		assert.Equal(t, helpers.Header, e.ValidationSubType)
+		assert.Len(t, e.SchemaValidationErrors, 1)
+	}
+}
+
+func TestValidateBody_ResponseTrailers(t *testing.T) {
+	spec := `openapi: 3.1.0
+paths:
+  /burgers/stream:
+    get:
+      responses:
+        '200':
+          headers:
+            X-Burger-Count:
+              required: true
+              schema:
+                type: integer
+          content:
+            application/json:
+              schema:
+                type: array`
+
+	doc, _ := libopenapi.NewDocument([]byte(spec))
+
+	m, _ := doc.BuildV3Model()
+	v := NewResponseBodyValidator(&m.Model)
+
+	// the count is only known once every burger has been streamed, so it's sent as a trailer.
+	handler := func(count string) http.HandlerFunc {
+		return func(w http.ResponseWriter, r *http.Request) {
+			w.Header().Set(helpers.ContentTypeHeader, helpers.JSONContentType)
+			w.Header().Set(helpers.TrailerHeader, "X-Burger-Count")
+			w.WriteHeader(http.StatusOK)
+			_, _ = w.Write([]byte(`[{"name": "Big Mac"}, {"name": "Whopper"}]`))
+			if count != "" {
+				w.Header().Set("X-Burger-Count", count)
+			}
+		}
+	}
+
+	// a streamed (chunked) response, the trailers are only received after the body has been read.
+	server := httptest.NewServer(handler("2"))
+	defer server.Close()
+
+	response, err := http.Get(server.URL + "/burgers/stream")
+	assert.NoError(t, err)
+	request, _ := http.NewRequest(http.MethodGet, server.URL+"/burgers/stream", nil)
+
+	valid, errors := v.ValidateResponseBody(request, response)
+	assert.True(t, valid)
+	assert.Len(t, errors, 0)
+	assert.Equal(t, "2", response.Trailer.Get("X-Burger-Count"))
+
+	// the body can still be read.
+	body, _ := io.ReadAll(response.Body)
+	assert.Equal(t, `[{"name": "Big Mac"}, {"name": "Whopper"}]`, string(body))
+
+	respond := func(count string) *http.Response {
+		res := httptest.NewRecorder()
+		handler(count)(res, request)
+		return res.Result()
+	}
+
+	// trailer values are validated against the schema of the header.
+	valid, errors = v.ValidateResponseBody(request, respond("lots"))
+	assert.False(t, valid)
+	assert.Len(t, errors, 1)
+	assert.Equal(t, helpers.Trailer, errors[0].ValidationSubType)
+	assert.Len(t, errors[0].SchemaValidationErrors, 1)
+
+	// a trailer that was announced, but never sent, is missing.
+	valid, errors = v.ValidateResponseBody(request, respond(""))
+	assert.False(t, valid)
+	assert.Len(t, errors, 1)
+	assert.Equal(t, "GET / 200 operation response trailer 'X-Burger-Count' is missing", errors[0].Message)
+	assert.Equal(t, helpers.Trailer, errors[0].ValidationSubType)
+}
+
+func TestValidateBody_ResponseCodeRange(t *testing.T) {
+	spec := `openapi: 3.1.0
+paths:
+  /burgers/createBurger:
+    post:
+      responses:
+        '2XX':
+          headers:
+            X-Burger-Id:
+              required: true
+              schema:
+                type: integer
+          content:
+            application/json:
+              schema:
+                type: object
+                properties:
+                  patties:
+                    type: integer
+        '4XX':
+          content:
+            application/json:
+              schema:
+                type: object
+                required: [message]
+                properties:
+                  message:
+                    type: string`
+
+	doc, _ := libopenapi.NewDocument([]byte(spec))
+
+	m, _ := doc.BuildV3Model()
+	v := NewResponseBodyValidator(&m.Model)
+
+	request, _ := http.NewRequest(http.MethodPost, "https://things.com/burgers/createBurger", nil)
+
+	respond := func(code int, body map[string]interface{}, headers map[string]string) *http.Response {
+		res := httptest.NewRecorder()
+		handler := func(w http.ResponseWriter, r *http.Request) {
+			for k, val := range headers {
+				w.Header().Set(k, val)
+			}
+			if body != nil {
+				w.Header().Set(helpers.ContentTypeHeader, helpers.JSONContentType)
+			}
+			w.WriteHeader(code)
+			if body != nil {
+				bodyBytes, _ := json.Marshal(body)
+				_, _ = w.Write(bodyBytes)
+			}
+		}
+		handler(res, request)
+		return res.Result()
+	}
+
+	// a 204 matches 2XX, there is no body to check, but the headers are still checked.
+	valid, errors := v.ValidateResponseBody(request, respond(http.StatusNoContent, nil,
+		map[string]string{"X-Burger-Id": "1234"}))
+	assert.True(t, valid)
+	assert.Len(t, errors, 0)
+
+	valid, errors = v.ValidateResponseBody(request, respond(http.StatusNoContent, nil, nil))
+	assert.False(t, valid)
+	assert.Len(t, errors, 1)
+	assert.Equal(t, "POST / 204 operation response header 'X-Burger-Id' is missing", errors[0].Message)
+
+	// a 201 matches 2XX, and the body is validated against the range schema.
+	valid, errors = v.ValidateResponseBody(request, respond(http.StatusCreated,
+		map[string]interface{}{"patties": "two"}, map[string]string{"X-Burger-Id": "1234"}))
+	assert.False(t, valid)
+	assert.Len(t, errors, 1)
+	assert.Equal(t, "201 response body for '/burgers/createBurger' failed to validate schema", errors[0].Message)
+
+	// a 407 matches 4XX
+	valid, errors = v.ValidateResponseBody(request, respond(http.StatusProxyAuthRequired,
+		map[string]interface{}{"message": "nope"}, nil))
+	assert.True(t, valid)
+	assert.Len(t, errors, 0)
+
+	// a 500 has no match, and no default.
+	valid, errors = v.ValidateResponseBody(request, respond(http.StatusInternalServerError,
+		map[string]interface{}{"message": "nope"}, nil))
+	assert.False(t, valid)
+	assert.Len(t, errors, 1)
+	assert.Equal(t, "POST operation request response code '500' does not exist", errors[0].Message)
+}
+
+func TestValidateBody_DefaultResponseNoContent(t *testing.T) {
+	spec := `openapi: 3.1.0
+paths:
+  /burgers/createBurger:
+    post:
+      responses:
+        default:
+          content:
+            application/json:
+              schema:
+                type: object
+                required: [message]
+                properties:
+                  message:
+                    type: string`
+
+	doc, _ := libopenapi.NewDocument([]byte(spec))
+
+	m, _ := doc.BuildV3Model()
+	v := NewResponseBodyValidator(&m.Model)
+
+	request, _ := http.NewRequest(http.MethodPost, "https://things.com/burgers/createBurger", nil)
+
+	// a 204 falls through to the default response, it never carries a body (clients discard one, if it's sent),
+	// so the body is not checked against the default schema.
+	response := &http.Response{
+		StatusCode: http.StatusNoContent,
+		Header:     http.Header{helpers.ContentTypeHeader: {"application/json; charset=utf-8"}},
+		Body:       io.NopCloser(strings.NewReader(`{"message": 1}`)),
+	}
+	valid, errors := v.ValidateResponseBody(request, response)
+	assert.True(t, valid)
+	assert.Len(t, errors, 0)
+
+	// a 500 falls through to the default response, and the body is validated against the default schema.
+	response = &http.Response{
+		StatusCode: http.StatusInternalServerError,
+		Header:     http.Header{helpers.ContentTypeHeader: {"application/json; charset=utf-8"}},
+		Body:       io.NopCloser(strings.NewReader(`{"message": 1}`)),
+	}
+	valid, errors = v.ValidateResponseBody(request, response)
+	assert.False(t, valid)
+	assert.Len(t, errors, 1)
+	assert.Equal(t, "500 response body for '/burgers/createBurger' failed to validate schema", errors[0].Message)
+}
+
+func TestValidateBody_ResponseMediaTypeMatching(t *testing.T) {
+	spec := `openapi: 3.1.0
+paths:
+  /burgers/createBurger:
+    post:
+      responses:
+        '200':
+          content:
+            application/json:
+              schema:
+                type: object
+                required: [name]
+            application/*+json:
+              schema:
+                type: object
+                required: [id]
+            text/*:
+              schema:
+                type: string`
+
+	doc, _ := libopenapi.NewDocument([]byte(spec))
+
+	m, _ := doc.BuildV3Model()
+	v := NewResponseBodyValidator(&m.Model)
+
+	request, _ := http.NewRequest(http.MethodPost, "https://things.com/burgers/createBurger", nil)
+
+	respond := func(contentType string, body []byte) *http.Response {
+		res := httptest.NewRecorder()
+		handler := func(w http.ResponseWriter, r *http.Request) {
+			w.Header().Set(helpers.ContentTypeHeader, contentType)
+			w.WriteHeader(http.StatusOK)
+			_, _ = w.Write(body)
+		}
+		handler(res, request)
+		return res.Result()
+	}
+
+	// exact match, media types are case-insensitive.
+	valid, errors := v.ValidateResponseBody(request, respond("Application/JSON", []byte(`{"name":"Big Mac"}`)))
+	assert.True(t, valid)
+	assert.Len(t, errors, 0)
+
+	// suffix wildcard wins over the plain suffix.
+	valid, errors = v.ValidateResponseBody(request, respond("application/vnd.burger+json", []byte(`{"name":"Big Mac"}`)))
+	assert.False(t, valid)
+	assert.Len(t, errors, 1)
+	assert.Equal(t, "missing properties: 'id'", errors[0].SchemaValidationErrors[0].Reason)
+
+	// subtype wildcard
+	valid, errors = v.ValidateResponseBody(request, respond("text/plain", []byte(`Big Mac`)))
+	assert.True(t, valid)
+	assert.Len(t, errors, 0)
+
+	// no match at all
+	valid, errors = v.ValidateResponseBody(request, respond("image/png", []byte(`Big Mac`)))
+	assert.False(t, valid)
+	assert.Len(t, errors, 1)
+	assert.Equal(t, "POST / 200 operation response content type 'image/png' does not exist", errors[0].Message)
+}
+
+func TestValidateBody_ResponseMediaTypeSuffix(t *testing.T) {
+	spec := `openapi: 3.1.0
+paths:
+  /burgers/createBurger:
+    post:
+      responses:
+        '200':
+          content:
+            application/json:
+              schema:
+                type: object
+                required: [name]`
+
+	doc, _ := libopenapi.NewDocument([]byte(spec))
+
+	m, _ := doc.BuildV3Model()
+	v := NewResponseBodyValidator(&m.Model)
+
+	request, _ := http.NewRequest(http.MethodPost, "https://things.com/burgers/createBurger", nil)
+
+	res := httptest.NewRecorder()
+	handler := func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set(helpers.ContentTypeHeader, "application/problem+json")
+		w.WriteHeader(http.StatusOK)
+		_, _ = w.Write([]byte(`{"title":"nope"}`))
+	}
+	handler(res, request)
+
+	valid, errors := v.ValidateResponseBody(request, res.Result())
+	assert.False(t, valid)
+	assert.Len(t, errors, 1)
+	assert.Equal(t, "missing properties: 'name'", errors[0].SchemaValidationErrors[0].Reason)
+}
+
+func TestValidateBody_XMLResponse(t *testing.T) {
+	spec := `openapi: 3.1.0
+paths:
+  /burgers:
+    get:
+      responses:
+        '200':
+          content:
+            application/xml:
+              schema:
+                type: array
+                xml:
+                  name: burgers
+                  wrapped: true
+                items:
+                  type: object
+                  xml:
+                    name: burger
+                  required: [name]
+                  properties:
+                    name:
+                      type: string
+                    patties:
+                      type: integer`
+
+	doc, _ := libopenapi.NewDocument([]byte(spec))
+
+	m, _ := doc.BuildV3Model()
+	v := NewResponseBodyValidator(&m.Model)
+
+	request, _ := http.NewRequest(http.MethodGet, "https://things.com/burgers", nil)
+
+	respond := func(body string) *http.Response {
+		res := httptest.NewRecorder()
+		handler := func(w http.ResponseWriter, r *http.Request) {
+			w.Header().Set(helpers.ContentTypeHeader, "application/xml; charset=utf-8")
+			w.WriteHeader(http.StatusOK)
+			_, _ = w.Write([]byte(body))
+		}
+		handler(res, request)
+		return res.Result()
+	}
+
+	valid, errors := v.ValidateResponseBody(request, respond(`<?xml version="1.0"?>
+<burgers><burger><name>Big Mac</name><patties>2</patties></burger></burgers>`))
+	assert.True(t, valid)
+	assert.Len(t, errors, 0)
+
+	valid, errors = v.ValidateResponseBody(request, respond(
+		`<burgers><burger><name>Big Mac</name></burger><burger><patties>two</patties></burger></burgers>`))
+	assert.False(t, valid)
+	assert.Len(t, errors, 1)
+	assert.Len(t, errors[0].SchemaValidationErrors, 2)
+
+	valid, errors = v.ValidateResponseBody(request, respond(`<burgers><burger></burgers>`))
+	assert.False(t, valid)
+	assert.Len(t, errors, 1)
+	assert.Equal(t, "200 response body for '/burgers' is not valid XML", errors[0].Message)
+	assert.Equal(t, helpers.XMLType, errors[0].ValidationSubType)
+}
+
+func TestValidateBody_WarmSchemaCache(t *testing.T) {
+	spec := `openapi: 3.1.0
+paths:
+  /burgers/createBurger:
+    post:
+      responses:
+        '200':
+          content:
+            application/json:
+              schema:
+                type: object
+                properties:
+                  name:
+                    type: string
+        default:
+          content:
+            application/json:
+              schema:
+                type: object
+                properties:
+                  error:
+                    type: string`
+
+	doc, _ := libopenapi.NewDocument([]byte(spec))
+
+	m, _ := doc.BuildV3Model()
+	v := NewResponseBodyValidator(&m.Model)
+	v.WarmSchemaCache()
+
+	count := 0
+	v.(*responseBodyValidator).schemaCache.Range(func(_, value any) bool {
+		assert.NotNil(t, value.(*schemaCache).compiledSchema)
+		count++
+		return true
+	})
+	assert.Equal(t, 2, count)
+}
+
+func TestValidateBody_WriteOnly(t *testing.T) {
+	spec := `openapi: 3.1.0
+paths:
+  /chefs:
+    post:
+      responses:
+        '200':
+          content:
+            application/json:
+              schema:
+                type: object
+                required: [name, password]
+                properties:
+                  name:
+                    type: string
+                  password:
+                    type: string
+                    writeOnly: true`
+
+	doc, _ := libopenapi.NewDocument([]byte(spec))
+
+	m, _ := doc.BuildV3Model()
+
+	send := func(v ResponseBodyValidator, body string) (bool, []*errors.ValidationError) {
+		request, _ := http.NewRequest(http.MethodPost, "https://things.com/chefs", nil)
+		res := httptest.NewRecorder()
+		res.Header().Set(helpers.ContentTypeHeader, helpers.JSONContentType)
+		res.WriteHeader(http.StatusOK)
+		_, _ = res.WriteString(body)
+		return v.ValidateResponseBody(request, res.Result())
+	}
+
+	// the password is never returned, so it isn't required.
+	v := NewResponseBodyValidator(&m.Model)
+	valid, errs := send(v, `{"name": "Ronald"}`)
+	assert.True(t, valid)
+	assert.Len(t, errs, 0)
+
+	valid, errs = send(v, `{"name": "Ronald", "password": "fries"}`)
+	assert.True(t, valid)
+	assert.Len(t, errs, 0)
+
+	// in strict mode, a leaked writeOnly property is reported.
+	v = NewResponseBodyValidator(&m.Model, config.WithStrictWriteOnly())
+	valid, errs = send(v, `{"name": "Ronald"}`)
+	assert.True(t, valid)
+	assert.Len(t, errs, 0)
+
+	valid, errs = send(v, `{"name": "Ronald", "password": "fries"}`)
+	assert.False(t, valid)
+	assert.Len(t, errs, 1)
+	assert.Len(t, errs[0].SchemaValidationErrors, 1)
+	assert.Equal(t, "/properties/password", errs[0].SchemaValidationErrors[0].Location)
+}
+
+func TestValidateBody_WarnWriteOnly(t *testing.T) {
+	spec := `openapi: 3.1.0
+paths:
+  /chefs:
+    post:
+      responses:
+        '200':
+          content:
+            application/json:
+              schema:
+                type: object
+                properties:
+                  name:
+                    type: string
+                  password:
+                    type: string
+                    writeOnly: true`
+
+	doc, _ := libopenapi.NewDocument([]byte(spec))
+
+	m, _ := doc.BuildV3Model()
+
+	send := func(v ResponseBodyValidator, body string) (bool, []*errors.ValidationError) {
+		request, _ := http.NewRequest(http.MethodPost, "https://things.com/chefs", nil)
+		res := httptest.NewRecorder()
+		res.Header().Set(helpers.ContentTypeHeader, helpers.JSONContentType)
+		res.WriteHeader(http.StatusOK)
+		_, _ = res.WriteString(body)
+		return v.ValidateResponseBody(request, res.Result())
+	}
+
+	v := NewResponseBodyValidator(&m.Model, config.WithWarnWriteOnly())
+	valid, errs := send(v, `{"name": "Ronald"}`)
+	assert.True(t, valid)
+	assert.Len(t, errs, 0)
+
+	// a leaked writeOnly property is a warning, the response is still valid.
+	valid, errs = send(v, `{"name": "Ronald", "password": "fries"}`)
+	assert.True(t, valid)
+	assert.Len(t, errs, 1)
+	assert.True(t, errs[0].IsWarning())
+	assert.Equal(t, helpers.SeverityWarning, errs[0].Severity)
+	assert.Equal(t, "The properties at '/password' are marked as writeOnly in the specification, "+
+		"however they are returned in the response body", errs[0].Reason)
+	assert.Len(t, errs[0].SchemaValidationErrors, 1)
+	assert.Equal(t, "/password", errs[0].SchemaValidationErrors[0].InstanceLocation)
+
+	// errors are still errors, and are returned with the warnings.
+	valid, errs = send(v, `{"name": 1, "password": "fries"}`)
+	assert.False(t, valid)
+	assert.Len(t, errs, 2)
+	assert.False(t, errs[0].IsWarning())
+	assert.Equal(t, "expected string, but got number", errs[0].SchemaValidationErrors[0].Reason)
+	assert.True(t, errs[1].IsWarning())
+
+	// strict mode takes precedence.
+	v = NewResponseBodyValidator(&m.Model, config.WithWarnWriteOnly(), config.WithStrictWriteOnly())
+	valid, errs = send(v, `{"name": "Ronald", "password": "fries"}`)
+	assert.False(t, valid)
+	assert.Len(t, errs, 1)
+	assert.False(t, errs[0].IsWarning())
+}
+
+func TestValidateBody_Discriminator(t *testing.T) {
+	spec := `openapi: 3.1.0
+paths:
+  /pets:
+    get:
+      responses:
+        '200':
+          content:
+            application/json:
+              schema:
+                oneOf:
+                  - $ref: '#/components/schemas/Cat'
+                  - $ref: '#/components/schemas/Dog'
+                discriminator:
+                  propertyName: petType
+components:
+  schemas:
+    Cat:
+      type: object
+      required: [lives]
+      properties:
+        lives:
+          type: integer
+    Dog:
+      type: object
+      required: [barks]
+      properties:
+        barks:
+          type: boolean`
+
+	doc, _ := libopenapi.NewDocument([]byte(spec))
+
+	m, _ := doc.BuildV3Model()
+	v := NewResponseBodyValidator(&m.Model)
+
+	send := func(body string) (bool, []*errors.ValidationError) {
+		request, _ := http.NewRequest(http.MethodGet, "https://things.com/pets", nil)
+		res := httptest.NewRecorder()
+		res.Header().Set(helpers.ContentTypeHeader, helpers.JSONContentType)
+		res.WriteHeader(http.StatusOK)
+		_, _ = res.WriteString(body)
+		return v.ValidateResponseBody(request, res.Result())
+	}
+
+	// without a mapping, the schema names are the discriminator values.
+	valid, errs := send(`{"petType": "Dog", "barks": true}`)
+	assert.True(t, valid)
+	assert.Len(t, errs, 0)
+
+	valid, errs = send(`{"petType": "Cat", "barks": true}`)
+	assert.False(t, valid)
+	assert.Len(t, errs, 1)
+	assert.Len(t, errs[0].SchemaValidationErrors, 1)
+	assert.Equal(t, "missing properties: 'lives'", errs[0].SchemaValidationErrors[0].Reason)
+
+	valid, errs = send(`{"barks": true}`)
+	assert.False(t, valid)
+	assert.Len(t, errs, 1)
+	assert.Equal(t, "200 response body for '/pets' is missing the discriminator property 'petType'",
+		errs[0].Message)
+}
+
+func TestValidateBody_TextPlain(t *testing.T) {
+	spec := `openapi: 3.1.0
+paths:
+  /burgers/{burgerId}/name:
+    get:
+      responses:
+        '200':
+          content:
+            text/plain:
+              schema:
+                type: string
+                maxLength: 10`
+
+	doc, _ := libopenapi.NewDocument([]byte(spec))
+
+	m, _ := doc.BuildV3Model()
+	v := NewResponseBodyValidator(&m.Model)
+
+	send := func(body string) (bool, []*errors.ValidationError) {
+		request, _ := http.NewRequest(http.MethodGet, "https://things.com/burgers/1/name", nil)
+		res := httptest.NewRecorder()
+		res.Header().Set(helpers.ContentTypeHeader, "text/plain")
+		res.WriteHeader(http.StatusOK)
+		_, _ = res.WriteString(body)
+		return v.ValidateResponseBody(request, res.Result())
+	}
+
+	valid, errs := send("Big Mac")
+	assert.True(t, valid)
+	assert.Len(t, errs, 0)
+
+	valid, errs = send("Quarter Pounder")
+	assert.False(t, valid)
+	assert.Len(t, errs, 1)
+	assert.Equal(t, "length must be <= 10, but got 15", errs[0].SchemaValidationErrors[0].Reason)
+}
+
+func TestValidateBody_ContentEncoding(t *testing.T) {
+	spec := `openapi: 3.1.0
+paths:
+  /burgers/{burgerId}:
+    get:
+      responses:
+        '200':
+          content:
+            application/json:
+              schema:
+                type: object
+                properties:
+                  name:
+                    type: string`
+
+	doc, _ := libopenapi.NewDocument([]byte(spec))
+
+	m, _ := doc.BuildV3Model()
+	v := NewResponseBodyValidator(&m.Model)
+
+	send := func(body []byte) (bool, []*errors.ValidationError) {
+		request, _ := http.NewRequest(http.MethodGet, "https://things.com/burgers/big-mac", nil)
+		res := httptest.NewRecorder()
+		res.Header().Set(helpers.ContentTypeHeader, helpers.JSONContentType)
+		res.Header().Set(helpers.ContentEncodingHeader, helpers.Gzip)
+		res.WriteHeader(http.StatusOK)
+		_, _ = res.Write(body)
+		return v.ValidateResponseBody(request, res.Result())
+	}
+
+	var buf bytes.Buffer
+	w := gzip.NewWriter(&buf)
+	_, _ = w.Write([]byte(`{"name": "Big Mac"}`))
+	_ = w.Close()
+
+	valid, errs := send(buf.Bytes())
+	assert.True(t, valid)
+	assert.Len(t, errs, 0)
+
+	valid, errs = send([]byte(`{"name": "Big Mac"}`))
+	assert.False(t, valid)
+	assert.Len(t, errs, 1)
+	assert.Equal(t, helpers.ContentEncoding, errs[0].ValidationSubType)
+	assert.Equal(t, "200 response body for '/burgers/big-mac' cannot be decompressed", errs[0].Message)
+}
+
+func TestValidateBody_InvalidArrayItem(t *testing.T) {
+	spec := `openapi: 3.1.0
+paths:
+  /burgers/findByPatties:
+    get:
+      responses:
+        '200':
+          content:
+            application/json:
+              schema:
+                type: array
+                items:
+                  type: object
+                  required:
+                    - name
+                  properties:
+                    name:
+                      type: string
+                    patties:
+                      type: integer`
+
+	doc, _ := libopenapi.NewDocument([]byte(spec))
+
+	m, _ := doc.BuildV3Model()
+	v := NewResponseBodyValidator(&m.Model)
+
+	// the third burger has no name.
+	body := []map[string]interface{}{
+		{"name": "Big Mac", "patties": 2},
+		{"name": "Quarter Pounder", "patties": 1},
+		{"patties": 2},
+		{"name": "Whopper", "patties": 1},
+		{"name": "Double Whopper", "patties": 2},
+	}
+
+	bodyBytes, _ := json.Marshal(body)
+
+	// build a request
+	request, _ := http.NewRequest(http.MethodGet, "https://things.com/burgers/findByPatties", nil)
+
+	// simulate a request/response
+	res := httptest.NewRecorder()
+	handler := func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set(helpers.ContentTypeHeader, helpers.JSONContentType)
+		w.WriteHeader(http.StatusOK)
+		_, _ = w.Write(bodyBytes)
+	}
+
+	// fire the request
+	handler(res, request)
+
+	// record response
+	response := res.Result()
+
+	// validate!
+	valid, errors := v.ValidateResponseBody(request, response)
+
+	assert.False(t, valid)
+	assert.Len(t, errors, 1)
+	assert.Equal(t, "The response body for status code '200' is defined as an array. "+
+		"However, the items at index 2 do not meet the schema requirements of the specification", errors[0].Reason)
+	assert.Len(t, errors[0].SchemaValidationErrors, 1)
+	assert.Equal(t, "missing properties: 'name'", errors[0].SchemaValidationErrors[0].Reason)
+	assert.Equal(t, "/2", errors[0].SchemaValidationErrors[0].InstanceLocation)
+	assert.JSONEq(t, `{"patties": 2}`, errors[0].SchemaValidationErrors[0].ReferenceObject)
+
+	// a property of an element that fails is located by its index and its name.
+	body[3]["patties"] = "two"
+	bodyBytes, _ = json.Marshal(body)
+	res = httptest.NewRecorder()
+	handler(res, request)
+	response = res.Result()
+
+	valid, errors = v.ValidateResponseBody(request, response)
+
+	assert.False(t, valid)
+	assert.Len(t, errors, 1)
+	assert.Equal(t, "The response body for status code '200' is defined as an array. "+
+		"However, the items at index 2, 3 do not meet the schema requirements of the specification", errors[0].Reason)
+	assert.Len(t, errors[0].SchemaValidationErrors, 2)
+	assert.Equal(t, "expected integer, but got string", errors[0].SchemaValidationErrors[1].Reason)
+	assert.Equal(t, "/3/patties", errors[0].SchemaValidationErrors[1].InstanceLocation)
+}
+
+func TestValidateBody_AcceptHeader(t *testing.T) {
+	spec := `openapi: 3.1.0
+paths:
+  /burgers:
+    get:
+      responses:
+        '200':
+          content:
+            application/json:
+              schema:
+                type: object
+            text/csv:
+              schema:
+                type: string
+        '406':
+          content:
+            application/problem+json:
+              schema:
+                type: object`
+
+	doc, _ := libopenapi.NewDocument([]byte(spec))
+
+	m, _ := doc.BuildV3Model()
+
+	send := func(v ResponseBodyValidator, accept, contentType string, code int, body string) (bool,
+		[]*errors.ValidationError) {
+		request, _ := http.NewRequest(http.MethodGet, "https://things.com/burgers", nil)
+		if accept != "" {
+			request.Header.Set(helpers.AcceptHeader, accept)
+		}
+		res := httptest.NewRecorder()
+		res.Header().Set(helpers.ContentTypeHeader, contentType)
+		res.WriteHeader(code)
+		_, _ = res.WriteString(body)
+		return v.ValidateResponseBody(request, res.Result())
+	}
+
+	// the Accept header is ignored by default.
+	v := NewResponseBodyValidator(&m.Model)
+	valid, errs := send(v, "text/csv", "application/json", http.StatusOK, `{}`)
+	assert.True(t, valid)
+	assert.Len(t, errs, 0)
+
+	v = NewResponseBodyValidator(&m.Model, config.WithAcceptHeaderCheck())
+	for _, accept := range []string{"", "application/json", "text/csv, application/*;q=0.5", "*/*",
+		"text/csv;q=1.0,application/json; charset=utf-8"} {
+		valid, errs = send(v, accept, "application/json; charset=utf-8", http.StatusOK, `{}`)
+		assert.True(t, valid, accept)
+		assert.Len(t, errs, 0, accept)
+	}
+
+	// a content type the client did not accept is a warning, the response is still valid.
+	for _, accept := range []string{"text/csv", "text/*", "*/*, application/json;q=0"} {
+		valid, errs = send(v, accept, "application/json", http.StatusOK, `{}`)
+		assert.True(t, valid, accept)
+		if assert.Len(t, errs, 1, accept) {
+			assert.True(t, errs[0].IsWarning())
+			assert.Equal(t, errors.ErrorCodeNotAcceptable, errs[0].Code)
+			assert.Equal(t, "200 response content type 'application/json' is not acceptable to the client",
+				errs[0].Message)
+			assert.Equal(t, "The GET request to '/burgers' only accepts '"+accept+"', however the response has "+
+				"a content type of 'application/json'", errs[0].Reason)
+		}
+	}
+
+	// a 406 says the content type is not acceptable.
+	valid, errs = send(v, "text/csv", "application/problem+json", http.StatusNotAcceptable, `{}`)
+	assert.True(t, valid)
+	assert.Len(t, errs, 0)
+}
+
+func TestValidateBody_NDJSON(t *testing.T) {
+	spec := `openapi: 3.1.0
+paths:
+  /burgers/export:
+    get:
+      responses:
+        '200':
+          content:
+            application/x-ndjson:
+              schema:
+                type: object
+                required: [name]
+                properties:
+                  name:
+                    type: string`
+
+	doc, _ := libopenapi.NewDocument([]byte(spec))
+
+	m, _ := doc.BuildV3Model()
+	v := NewResponseBodyValidator(&m.Model)
+
+	send := func(body string) (bool, []*errors.ValidationError) {
+		request, _ := http.NewRequest(http.MethodGet, "https://things.com/burgers/export", nil)
+		res := httptest.NewRecorder()
+		res.Header().Set(helpers.ContentTypeHeader, "application/x-ndjson")
+		res.WriteHeader(http.StatusOK)
+		_, _ = res.WriteString(body)
+		return v.ValidateResponseBody(request, res.Result())
+	}
+
+	// each line is validated, blank lines (and the trailing newline) are ignored.
+	valid, errs := send("{\"name\": \"Big Mac\"}\n{\"name\": \"Whopper\"}\r\n\n")
+	assert.True(t, valid)
+	assert.Len(t, errs, 0)
+
+	// the line that failed is named.
+	valid, errs = send("{\"name\": \"Big Mac\"}\n{\"name\": 1}\n{}\n")
+	assert.False(t, valid)
+	if assert.Len(t, errs, 2) {
+		assert.Equal(t, "200 response body for '/burgers/export' failed to validate schema (line 2)", errs[0].Message)
+		assert.Equal(t, "The response body for status code '200' is defined as newline delimited JSON. "+
+			"However, line 2 does not meet the schema requirements of the specification", errs[0].Reason)
+		assert.Equal(t, "expected string, but got number", errs[0].SchemaValidationErrors[0].Reason)
+		assert.Equal(t, "/name", errs[0].SchemaValidationErrors[0].InstanceLocation)
+		assert.Equal(t, "200 response body for '/burgers/export' failed to validate schema (line 3)", errs[1].Message)
+		assert.Equal(t, "missing properties: 'name'", errs[1].SchemaValidationErrors[0].Reason)
+	}
+
+	// a line that is not JSON is reported, the other lines are still validated.
+	valid, errs = send("{\"name\": \"Big Mac\"}\n{\"name\": \n{\"name\": 1}")
+	assert.False(t, valid)
+	if assert.Len(t, errs, 2) {
+		assert.Equal(t, "200 response body for '/burgers/export' is not valid JSON (line 2)", errs[0].Message)
+		assert.Equal(t, errors.ErrorCodeBodyMalformed, errs[0].Code)
+		assert.Equal(t, "200 response body for '/burgers/export' failed to validate schema (line 3)", errs[1].Message)
+	}
+}
+
+func TestValidateBody_ResponseCookies(t *testing.T) {
+	spec := `openapi: 3.1.0
+paths:
+  /login:
+    post:
+      responses:
+        '204':
+          description: logged in
+          headers:
+            Set-Cookie:
+              required: true
+              schema:
+                type: object
+                required: [session]
+                properties:
+                  session:
+                    type: string
+                    pattern: ^[a-f0-9]{8}$
+                  remember:
+                    type: boolean
+  /logout:
+    post:
+      responses:
+        '204':
+          description: logged out
+          headers:
+            Set-Cookie:
+              schema:
+                type: string
+                pattern: ^session=$`
+
+	doc, _ := libopenapi.NewDocument([]byte(spec))
+
+	m, _ := doc.BuildV3Model()
+	v := NewResponseBodyValidator(&m.Model)
+
+	send := func(path string, cookies ...string) (bool, []*errors.ValidationError) {
+		request, _ := http.NewRequest(http.MethodPost, "https://things.com"+path, nil)
+		res := httptest.NewRecorder()
+		for _, cookie := range cookies {
+			res.Header().Add(helpers.SetCookieHeader, cookie)
+		}
+		res.WriteHeader(http.StatusNoContent)
+		return v.ValidateResponseBody(request, res.Result())
+	}
+
+	// only the name and value of each cookie are validated, the attributes are ignored.
+	valid, errs := send("/login", "session=0a1b2c3d; Path=/; Domain=things.com; Secure; HttpOnly; SameSite=Strict",
+		"remember=true; Expires=Wed, 21 Oct 2026 07:28:00 GMT", "tracking=xyz")
+	assert.True(t, valid)
+	assert.Len(t, errs, 0)
+
+	// each cookie value is validated against its property.
+	valid, errs = send("/login", "session=nope; Secure", "remember=sure")
+	assert.False(t, valid)
+	assert.Len(t, errs, 2)
+	for _, e := range errs {
+		assert.Equal(t, helpers.Cookie, e.ValidationSubType)
+	}
+
+	// a required cookie that is not set is reported.
+	valid, errs = send("/login", "remember=false; Path=/")
+	assert.False(t, valid)
+	if assert.Len(t, errs, 1) {
+		assert.Equal(t, "POST / 204 operation response cookie 'session' is missing", errs[0].Message)
+		assert.Equal(t, errors.ErrorCodeResponseHeaderMissing, errs[0].Code)
+	}
+
+	// no Set-Cookie header at all is a missing header.
+	valid, errs = send("/login")
+	assert.False(t, valid)
+	if assert.Len(t, errs, 1) {
+		assert.Equal(t, "POST / 204 operation response header 'Set-Cookie' is missing", errs[0].Message)
+	}
+
+	// any other schema validates each cookie as name=value.
+	valid, errs = send("/logout", "session=; Path=/; Max-Age=0")
+	assert.True(t, valid)
+	assert.Len(t, errs, 0)
+
+	valid, errs = send("/logout", "session=0a1b2c3d; Path=/")
+	assert.False(t, valid)
+	assert.Len(t, errs, 1)
+}
+
+func TestValidateBody_ResponseHeaderFormatAssertions(t *testing.T) {
+	spec := `openapi: 3.1.0
+paths:
+  /burgers:
+    get:
+      responses:
+        '200':
+          description: ok
+          headers:
+            X-Id:
+              schema:
+                type: string
+                format: uuid`
+
+	doc, _ := libopenapi.NewDocument([]byte(spec))
+
+	m, _ := doc.BuildV3Model()
+
+	send := func(v ResponseBodyValidator) (bool, []*errors.ValidationError) {
+		request, _ := http.NewRequest(http.MethodGet, "https://things.com/burgers", nil)
+		res := httptest.NewRecorder()
+		res.Header().Set("X-Id", "nope")
+		res.WriteHeader(http.StatusOK)
+		return v.ValidateResponseBody(request, res.Result())
+	}
+
+	// formats are annotations by default.
+	valid, errs := send(NewResponseBodyValidator(&m.Model))
+	assert.True(t, valid)
+	assert.Len(t, errs, 0)
+
+	valid, errs = send(NewResponseBodyValidator(&m.Model, config.WithFormatAssertions()))
+	assert.False(t, valid)
+	assert.Len(t, errs, 1)
+	assert.Equal(t, "Response header 'X-Id' failed to validate", errs[0].Message)
+}
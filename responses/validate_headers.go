@@ -0,0 +1,186 @@
+// Copyright 2023 Princess B33f Heavy Industries / Dave Shanley
+// SPDX-License-Identifier: MIT
+
+package responses
+
+import (
+	"bytes"
+	"fmt"
+	"github.com/pb33f/libopenapi-validator/errors"
+	"github.com/pb33f/libopenapi-validator/helpers"
+	"github.com/pb33f/libopenapi-validator/parameters"
+	"github.com/pb33f/libopenapi/datamodel/high/base"
+	"github.com/pb33f/libopenapi/datamodel/high/v3"
+	"io"
+	"net/http"
+	"strings"
+)
+
+// ValidateResponseHeaders will validate the headers of a http.Response pointer against the headers defined for the
+// response in the specification. Each required header must be present, and each header value must match the schema
+// defined for that header. Header names are matched case-insensitively. Content-Type is ignored, as it is
+// described by the response content.
+//
+// Headers that are not in the response headers are looked up in the trailers of the response (response.Trailer).
+// Trailers are only known once the body has been read, so the body is read (and replaced with a copy that can be
+// read again) first. A required header that the response announced as a trailer, but did not send, is reported
+// as a missing trailer.
+//
+// The cookies set by the Set-Cookie header are validated by name and value, without their attributes (see
+// validateResponseCookies), so a session cookie can be described by an object schema with a required property.
+//
+// This function is used by the ValidateResponseBody function, but can be used independently.
+func ValidateResponseHeaders(
+	request *http.Request,
+	response *http.Response,
+	headers map[string]*v3.Header) []*errors.ValidationError {
+	return validateResponseHeaders(request, response, headers, nil)
+}
+
+// validateResponseHeaders is the same as ValidateResponseHeaders, the schemas of the headers are compiled once, and
+// kept in the cache of schemas (using the options of the cache).
+func validateResponseHeaders(
+	request *http.Request,
+	response *http.Response,
+	headers map[string]*v3.Header,
+	schemas *helpers.SchemaCache) []*errors.ValidationError {
+
+	var validationErrors []*errors.ValidationError
+	for name, header := range headers {
+		if strings.EqualFold(name, helpers.ContentTypeHeader) {
+			continue
+		}
+
+		// http.Header canonicalizes the name, so this lookup is case-insensitive.
+		values := response.Header.Values(name)
+		subType := helpers.Header
+		if len(values) == 0 && (response.Trailer != nil || announcedTrailer(response, name)) {
+			readTrailers(response)
+			values = response.Trailer.Values(name)
+			subType = helpers.Trailer
+		}
+		if len(values) == 0 {
+			if header.Required && announcedTrailer(response, name) {
+				validationErrors = append(validationErrors,
+					errors.ResponseTrailerMissing(header, name, request, response.StatusCode))
+			} else if header.Required {
+				validationErrors = append(validationErrors,
+					errors.ResponseHeaderMissing(header, name, request, response.StatusCode))
+			}
+			continue
+		}
+		if header.Schema == nil {
+			continue
+		}
+		sch := header.Schema.Schema()
+		if sch == nil {
+			continue
+		}
+		if strings.EqualFold(name, helpers.SetCookieHeader) {
+			validationErrors = append(validationErrors,
+				validateResponseCookies(request, response.StatusCode, sch, values, schemas)...)
+			continue
+		}
+		validationErrors = append(validationErrors,
+			parameters.ValidateParameterSchema(sch,
+				helpers.DecodeHeaderValue(strings.Join(values, helpers.Comma), header, sch),
+				"",
+				"Response header",
+				"The response header",
+				name,
+				helpers.ResponseBodyValidation,
+				subType,
+				schemas)...)
+	}
+	return validationErrors
+}
+
+// validateResponseCookies will validate the cookies set by the Set-Cookie headers of a response. Only the name and
+// value of each cookie are validated, the attributes (Path, Domain, Secure, SameSite etc.) are ignored. An object
+// schema describes the cookies by name, each required property is a cookie that must be set, and the value of each
+// cookie is validated against its property (cookies that are not properties are ignored). Any other schema is
+// used to validate each cookie as 'name=value'.
+func validateResponseCookies(request *http.Request, code int, sch *base.Schema,
+	values []string, schemas *helpers.SchemaCache) []*errors.ValidationError {
+
+	var validationErrors []*errors.ValidationError
+	cookies := (&http.Response{Header: http.Header{helpers.SetCookieHeader: values}}).Cookies()
+	if len(sch.Type) == 0 || sch.Type[0] != helpers.Object {
+		for _, cookie := range cookies {
+			validationErrors = append(validationErrors,
+				parameters.ValidateParameterSchema(sch,
+					fmt.Sprintf("%s=%s", cookie.Name, cookie.Value),
+					"",
+					"Response header",
+					"The response header",
+					helpers.SetCookieHeader,
+					helpers.ResponseBodyValidation,
+					helpers.Cookie,
+					schemas)...)
+		}
+		return validationErrors
+	}
+
+	set := make(map[string]bool)
+	for _, cookie := range cookies {
+		set[cookie.Name] = true
+	}
+	for _, required := range sch.Required {
+		if !set[required] {
+			validationErrors = append(validationErrors, errors.ResponseCookieMissing(sch, required, request, code))
+		}
+	}
+	for _, cookie := range cookies {
+		prop, ok := sch.Properties[cookie.Name]
+		if !ok || prop == nil || prop.Schema() == nil {
+			continue
+		}
+		propSchema := prop.Schema()
+		var value any = cookie.Value
+		if helpers.IsPrimitiveType(propSchema.Type) {
+			if coerced, ok := helpers.CoerceValue(cookie.Value, helpers.SchemaTypes(propSchema)); ok {
+				value = coerced
+			}
+		}
+		validationErrors = append(validationErrors,
+			parameters.ValidateParameterSchema(propSchema,
+				value,
+				"",
+				"Response cookie",
+				"The response cookie",
+				cookie.Name,
+				helpers.ResponseBodyValidation,
+				helpers.Cookie,
+				schemas)...)
+	}
+	return validationErrors
+}
+
+// announcedTrailer returns true if the response announced that the header would be sent as a trailer, either in
+// the Trailer header, or as a key of response.Trailer (the http client moves the Trailer header there).
+func announcedTrailer(response *http.Response, name string) bool {
+	if _, ok := response.Trailer[http.CanonicalHeaderKey(name)]; ok {
+		return true
+	}
+	for _, announced := range response.Header.Values(helpers.TrailerHeader) {
+		for _, trailer := range strings.Split(announced, helpers.Comma) {
+			if strings.EqualFold(strings.TrimSpace(trailer), name) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// readTrailers will read the body of the response to the end, so the trailers are received, and replace the body
+// with a copy so it can be read again.
+func readTrailers(response *http.Response) {
+	if response.Body != nil {
+		body, _ := io.ReadAll(response.Body)
+		_ = response.Body.Close()
+		response.Body = io.NopCloser(bytes.NewReader(body))
+	}
+	if response.Trailer == nil {
+		response.Trailer = http.Header{}
+	}
+}
@@ -7,6 +7,7 @@ import (
 	"bytes"
 	"encoding/json"
 	"fmt"
+	"github.com/pb33f/libopenapi-validator/config"
 	"github.com/pb33f/libopenapi-validator/errors"
 	"github.com/pb33f/libopenapi-validator/helpers"
 	"github.com/pb33f/libopenapi-validator/schema_validation"
@@ -17,8 +18,10 @@ import (
 	"net/http"
 	"reflect"
 	"regexp"
+	"sort"
 	"strconv"
 	"strings"
+	"time"
 )
 
 var instanceLocationRegex = regexp.MustCompile(`^/(\d+)`)
@@ -34,19 +37,64 @@ func ValidateResponseSchema(
 	schema *base.Schema,
 	renderedSchema,
 	jsonSchema []byte) (bool, []*errors.ValidationError) {
+	return validateResponseSchema(request, response,
+		newSchemaCache(schema, renderedSchema, jsonSchema, nil), config.NewValidationOptions())
+}
+
+// validateResponseSchema will validate the response body against a rendered and compiled schema. Compressed bodies
+// are decompressed first, up to the MaxBodyBytes of the options (or the default limit, when it's 0).
+func validateResponseSchema(
+	request *http.Request,
+	response *http.Response,
+	cached *schemaCache,
+	options *config.ValidationOptions) (bool, []*errors.ValidationError) {
+
+	schema := cached.schema
+	renderedSchema := cached.renderedInline
 
 	var validationErrors []*errors.ValidationError
 
-	responseBody, _ := io.ReadAll(response.Body)
+	// no response body? validate it as empty.
+	if response.Body == nil {
+		response.Body = http.NoBody
+	}
+	parsed := time.Now()
+	rawBody, _ := io.ReadAll(response.Body)
 
-	// close the request body, so it can be re-read later by another player in the chain
+	// close the response body, and replace it with a copy, so it can be re-read later by another player in the chain
 	_ = response.Body.Close()
-	response.Body = io.NopCloser(bytes.NewBuffer(responseBody))
+	response.Body = io.NopCloser(bytes.NewReader(rawBody))
+	response.ContentLength = int64(len(rawBody))
+
+	// a compressed body is decompressed to be validated, the body of the response is left as it was sent.
+	responseBody, err := helpers.DecodeContentEncoding(
+		response.Header.Get(helpers.ContentEncodingHeader), rawBody, options.MaxBodyBytes)
+	if err != nil {
+		return false, []*errors.ValidationError{
+			errors.ResponseBodyContentEncodingInvalid(request, response, err, options.MaxBodyBytes)}
+	}
+
+	// newline delimited JSON bodies are a stream of values, each line is validated against the schema.
+	if len(responseBody) > 0 && helpers.IsNDJSONContentType(response.Header.Get(helpers.ContentTypeHeader)) {
+		return validateNDJSONResponse(request, response, cached, options, responseBody, parsed)
+	}
 
 	var decodedObj interface{}
 
-	if len(responseBody) > 0 {
-		err := json.Unmarshal(responseBody, &decodedObj)
+	// XML bodies are decoded into a structure that can be validated, using the schema to guide the conversion.
+	if len(responseBody) > 0 && strings.Contains(
+		strings.ToLower(response.Header.Get(helpers.ContentTypeHeader)), helpers.XMLType) {
+		var err error
+		if decodedObj, err = helpers.DecodeXML(responseBody, schema); err != nil {
+			return false, []*errors.ValidationError{errors.ResponseBodyInvalidXML(request, response, err)}
+		}
+	} else if contentType := response.Header.Get(helpers.ContentTypeHeader); len(responseBody) > 0 &&
+		contentType != "" && !helpers.IsStructuredContentType(contentType) {
+		// unstructured bodies (text/plain for example) are validated as a raw string.
+		decodedObj = string(responseBody)
+	} else if len(responseBody) > 0 {
+		var err error
+		decodedObj, err = helpers.UnmarshalJSON(responseBody)
 
 		if err != nil {
 			// cannot decode the response body, so it's not valid
@@ -59,6 +107,7 @@ func ValidateResponseSchema(
 			validationErrors = append(validationErrors, &errors.ValidationError{
 				ValidationType:    helpers.ResponseBodyValidation,
 				ValidationSubType: helpers.Schema,
+				Code:              errors.ErrorCodeBodyMalformed,
 				Message: fmt.Sprintf("%s response body for '%s' failed to validate schema",
 					request.Method, request.URL.Path),
 				Reason:                 fmt.Sprintf("The response body cannot be decoded: %s", err.Error()),
@@ -72,26 +121,62 @@ func ValidateResponseSchema(
 		}
 	}
 
+	options.Observer.BodyParsed(parsed)
+
 	// no response body? failed to decode anything? nothing to do here.
 	if responseBody == nil || decodedObj == nil {
 		return true, nil
 	}
+	return validateResponseObject(request, response, cached, options, decodedObj, responseBody)
+}
 
-	// create a new jsonschema compiler and add in the rendered JSON schema.
-	compiler := jsonschema.NewCompiler()
-	fName := fmt.Sprintf("%s.json", helpers.ResponseBodyValidation)
-	_ = compiler.AddResource(fName,
-		strings.NewReader(string(jsonSchema)))
-	jsch, _ := compiler.Compile(fName)
+// validateResponseObject will validate a decoded response body (or a single line of a newline delimited JSON body)
+// against the compiled schema. The body is used as the reference object of the schema failures.
+func validateResponseObject(
+	request *http.Request,
+	response *http.Response,
+	cached *schemaCache,
+	options *config.ValidationOptions,
+	decodedObj any,
+	responseBody []byte) (bool, []*errors.ValidationError) {
+
+	schema := cached.schema
+	renderedSchema := cached.renderedInline
+
+	var validationErrors []*errors.ValidationError
+
+	// the schema is not valid JSON Schema, so it could not be compiled.
+	if cached.compiledSchema == nil {
+		return false, []*errors.ValidationError{
+			errors.ResponseBodySchemaInvalid(request, response, schema, cached.compileError)}
+	}
+
+	// a discriminator selects the single schema an object is validated against.
+	jsch := cached.compiledSchema
+	if _, isObject := decodedObj.(map[string]any); isObject && cached.discriminated != nil {
+		selected, value, present := cached.discriminated.Select(decodedObj)
+		if !present {
+			return false, []*errors.ValidationError{
+				errors.ResponseBodyDiscriminatorMissing(request, response, schema, cached.discriminated)}
+		}
+		if selected == nil {
+			return false, []*errors.ValidationError{
+				errors.ResponseBodyDiscriminatorInvalid(request, response, schema, cached.discriminated, value)}
+		}
+		jsch = selected
+	}
 
 	// validate the object against the schema
+	validated := time.Now()
 	scErrs := jsch.Validate(decodedObj)
+	options.Observer.Validated(validated)
 	if scErrs != nil {
 		jk := scErrs.(*jsonschema.ValidationError)
 
 		// flatten the validationErrors
 		schFlatErrs := jk.BasicOutput().Errors
-		var schemaValidationErrors []*errors.SchemaValidationFailure
+		var schemaValidationErrors, writeOnlyFailures []*errors.SchemaValidationFailure
+		failedItems := make(map[int]bool)
 		for q := range schFlatErrs {
 			er := schFlatErrs[q]
 			if er.KeywordLocation == "" || strings.HasPrefix(er.Error, "doesn't validate with") {
@@ -109,10 +194,12 @@ func ValidateResponseSchema(
 				// extract the element specified by the instance
 				val := instanceLocationRegex.FindStringSubmatch(er.InstanceLocation)
 				var referenceObject string
+				referenceIndex := -1
 
 				if len(val) > 0 {
-					referenceIndex, _ := strconv.Atoi(val[1])
+					index, _ := strconv.Atoi(val[1])
 					if reflect.ValueOf(decodedObj).Type().Kind() == reflect.Slice {
+						referenceIndex = index
 						found := decodedObj.([]any)[referenceIndex]
 						recoded, _ := json.MarshalIndent(found, "", "  ")
 						referenceObject = string(recoded)
@@ -123,11 +210,14 @@ func ValidateResponseSchema(
 				}
 
 				violation := &errors.SchemaValidationFailure{
-					Reason:          er.Error,
-					Location:        er.KeywordLocation,
-					ReferenceSchema: string(renderedSchema),
-					ReferenceObject: referenceObject,
-					OriginalError:   jk,
+					Reason:           er.Error,
+					Location:         er.KeywordLocation,
+					DeepLocation:     er.KeywordLocation,
+					AbsoluteLocation: er.AbsoluteKeywordLocation,
+					InstanceLocation: er.InstanceLocation,
+					ReferenceSchema:  string(renderedSchema),
+					ReferenceObject:  referenceObject,
+					OriginalError:    jk,
 				}
 				// if we have a location within the schema, add it to the error
 				if located != nil {
@@ -145,34 +235,150 @@ func ValidateResponseSchema(
 					violation.Line = line
 					violation.Column = located.Column
 				}
+
+				// a leaked writeOnly property is rejected by the schema, it may only be a warning.
+				if cached.warnWriteOnly && er.Error == "not allowed" && isWriteOnlyProperty(located) {
+					writeOnlyFailures = append(writeOnlyFailures, violation)
+					continue
+				}
+				if referenceIndex >= 0 {
+					failedItems[referenceIndex] = true
+				}
 				schemaValidationErrors = append(schemaValidationErrors, violation)
 			}
 		}
 
-		line := 1
-		col := 0
-		if schema.GoLow().Type.KeyNode != nil {
-			line = schema.GoLow().Type.KeyNode.Line
-			col = schema.GoLow().Type.KeyNode.Column
+		// name each property that is not allowed, the index of each tuple item that failed and every duplicate item,
+		// describe numeric limits that were crossed (and numbers that are not integers), explain which branch of an
+		// if/then/else was applied, and why each subschema of a oneOf or anyOf failed.
+		schemaValidationErrors = schema_validation.SplitAdditionalPropertyFailures(schemaValidationErrors)
+		schema_validation.DescribeNumericFailures(schemaValidationErrors)
+		schema_validation.DescribeIntegerFailures(schemaValidationErrors, decodedObj)
+		schema_validation.DescribeArrayFailures(schemaValidationErrors, decodedObj)
+		schema_validation.DescribeTupleFailures(schemaValidationErrors)
+		schema_validation.DescribeConditionalFailures(schemaValidationErrors)
+		schema_validation.GroupSubschemaFailures(schemaValidationErrors)
+
+		if len(schemaValidationErrors) > 0 {
+			line := 1
+			col := 0
+			if schema.GoLow().Type.KeyNode != nil {
+				line = schema.GoLow().Type.KeyNode.Line
+				col = schema.GoLow().Type.KeyNode.Column
+			}
+
+			// add the error to the list
+			validationErrors = append(validationErrors, &errors.ValidationError{
+				ValidationType:    helpers.ResponseBodyValidation,
+				ValidationSubType: helpers.Schema,
+				Code:              errors.ErrorCodeSchema,
+				Message: fmt.Sprintf("%d response body for '%s' failed to validate schema",
+					response.StatusCode, request.URL.Path),
+				Reason:                 responseBodySchemaReason(response.StatusCode, decodedObj, failedItems),
+				SpecLine:               line,
+				SpecCol:                col,
+				SchemaValidationErrors: schemaValidationErrors,
+				HowToFix:               errors.HowToFixInvalidSchema,
+				Context:                string(renderedSchema), // attach the rendered schema to the error
+			})
+		}
+		if len(writeOnlyFailures) > 0 {
+			validationErrors = append(validationErrors,
+				errors.ResponseBodyWriteOnlyProperty(request, response, writeOnlyFailures, renderedSchema))
+		}
+	}
+	if errors.HasErrors(validationErrors) {
+		return false, validationErrors
+	}
+	return true, validationErrors
+}
+
+// validateNDJSONResponse will validate each line of a newline delimited JSON (NDJSON) response body against the
+// schema, blank lines (such as a trailing newline) are ignored. The line number of each line that fails is added to
+// the message of its errors.
+func validateNDJSONResponse(
+	request *http.Request,
+	response *http.Response,
+	cached *schemaCache,
+	options *config.ValidationOptions,
+	responseBody []byte,
+	parsed time.Time) (bool, []*errors.ValidationError) {
+
+	var validationErrors []*errors.ValidationError
+	lines := bytes.Split(responseBody, []byte("\n"))
+	decoded := make([]any, len(lines))
+	isDecoded := make([]bool, len(lines))
+	for i, line := range lines {
+		line = bytes.TrimSpace(line)
+		if len(line) == 0 {
+			continue
+		}
+		decodedLine, err := helpers.UnmarshalJSON(line)
+		if err != nil {
+			validationErrors = append(validationErrors,
+				errors.ResponseBodyInvalidNDJSON(request, response, i+1, err))
+			continue
 		}
+		decoded[i], isDecoded[i] = decodedLine, true
+	}
+	options.Observer.BodyParsed(parsed)
 
-		// add the error to the list
-		validationErrors = append(validationErrors, &errors.ValidationError{
-			ValidationType:    helpers.ResponseBodyValidation,
-			ValidationSubType: helpers.Schema,
-			Message: fmt.Sprintf("%d response body for '%s' failed to validate schema",
-				response.StatusCode, request.URL.Path),
-			Reason: fmt.Sprintf("The response body for status code '%d' is defined as an object. "+
-				"However, it does not meet the schema requirements of the specification", response.StatusCode),
-			SpecLine:               line,
-			SpecCol:                col,
-			SchemaValidationErrors: schemaValidationErrors,
-			HowToFix:               errors.HowToFixInvalidSchema,
-			Context:                string(renderedSchema), // attach the rendered schema to the error
-		})
-	}
-	if len(validationErrors) > 0 {
+	for i, decodedLine := range decoded {
+		if !isDecoded[i] {
+			continue
+		}
+		_, lineErrors := validateResponseObject(request, response, cached, options, decodedLine,
+			bytes.TrimSpace(lines[i]))
+		for _, lineError := range lineErrors {
+			lineError.Message = fmt.Sprintf("%s (line %d)", lineError.Message, i+1)
+			if lineError.Code == errors.ErrorCodeSchema && !lineError.IsWarning() {
+				lineError.Reason = fmt.Sprintf("The response body for status code '%d' is defined as newline "+
+					"delimited JSON. However, line %d does not meet the schema requirements of the specification",
+					response.StatusCode, i+1)
+			}
+		}
+		validationErrors = append(validationErrors, lineErrors...)
+	}
+	if errors.HasErrors(validationErrors) {
 		return false, validationErrors
 	}
-	return true, nil
+	return true, validationErrors
+}
+
+// isWriteOnlyProperty returns true if the located schema of a property is marked as writeOnly.
+func isWriteOnlyProperty(located *yaml.Node) bool {
+	if located == nil || located.Kind != yaml.MappingNode {
+		return false
+	}
+	for i := 0; i+1 < len(located.Content); i += 2 {
+		if located.Content[i].Value == "writeOnly" {
+			return located.Content[i+1].Value == "true"
+		}
+	}
+	return false
+}
+
+// responseBodySchemaReason explains why a response body failed schema validation. When the body is an array, the
+// index of each element that failed is listed, so a single bad element in a large array can be found.
+func responseBodySchemaReason(statusCode int, decodedObj any, failedItems map[int]bool) string {
+	if _, isArray := decodedObj.([]any); !isArray {
+		return fmt.Sprintf("The response body for status code '%d' is defined as an object. "+
+			"However, it does not meet the schema requirements of the specification", statusCode)
+	}
+	if len(failedItems) == 0 {
+		return fmt.Sprintf("The response body for status code '%d' is defined as an array. "+
+			"However, it does not meet the schema requirements of the specification", statusCode)
+	}
+	indexes := make([]int, 0, len(failedItems))
+	for i := range failedItems {
+		indexes = append(indexes, i)
+	}
+	sort.Ints(indexes)
+	items := make([]string, 0, len(indexes))
+	for _, i := range indexes {
+		items = append(items, strconv.Itoa(i))
+	}
+	return fmt.Sprintf("The response body for status code '%d' is defined as an array. "+
+		"However, the items at index %s do not meet the schema requirements of the specification",
+		statusCode, strings.Join(items, ", "))
 }
@@ -0,0 +1,92 @@
+// Copyright 2023 Princess B33f Heavy Industries / Dave Shanley
+// SPDX-License-Identifier: MIT
+
+package schema_validation
+
+import (
+	"fmt"
+	liberrors "github.com/pb33f/libopenapi-validator/errors"
+	"net/url"
+	"regexp"
+	"sort"
+	"strings"
+)
+
+var (
+	additionalPropertiesRegex       = regexp.MustCompile(`^additionalProperties (.+) not allowed$`)
+	additionalPropertiesSchemaRegex = regexp.MustCompile(`/additionalProperties/[^/]+$`)
+	patternPropertiesSchemaRegex    = regexp.MustCompile(`/patternProperties/([^/]+)/[^/]+$`)
+	unevaluatedPropertiesRegex      = regexp.MustCompile(`/unevaluatedProperties(/[^/]+)?$`)
+)
+
+// SplitAdditionalPropertyFailures will replace a failure for properties that are not allowed by
+// 'additionalProperties: false' (which names all the properties in one reason) with a failure for each property,
+// each with the JSON Pointer of the property as the InstanceLocation. Failures of a property validated against an
+// 'additionalProperties' schema are re-written to name the property that failed, as are failures of a property
+// validated against the schema of a matching 'patternProperties' pattern (which also name the pattern). Properties
+// rejected by 'unevaluatedProperties' (not evaluated by the schema, or any of its allOf, anyOf or oneOf subschemas)
+// are named in the same way.
+func SplitAdditionalPropertyFailures(failures []*liberrors.SchemaValidationFailure) []*liberrors.SchemaValidationFailure {
+	split := make([]*liberrors.SchemaValidationFailure, 0, len(failures))
+	for _, failure := range failures {
+		if additionalPropertiesSchemaRegex.MatchString(keywordLocation(failure)) && failure.InstanceLocation != "" {
+			segments := strings.Split(failure.InstanceLocation, "/")
+			failure.Reason = fmt.Sprintf("additional property '%s' is not valid: %s",
+				unescapePointer(segments[len(segments)-1]), failure.Reason)
+		}
+		if pattern := patternPropertiesSchemaRegex.FindStringSubmatch(keywordLocation(failure)); pattern != nil &&
+			failure.InstanceLocation != "" {
+			segments := strings.Split(failure.InstanceLocation, "/")
+			failure.Reason = fmt.Sprintf("property '%s' matching pattern '%s' is not valid: %s",
+				unescapePointer(segments[len(segments)-1]), patternName(pattern[1]), failure.Reason)
+		}
+		if unevaluated := unevaluatedPropertiesRegex.FindStringSubmatch(keywordLocation(failure)); unevaluated != nil &&
+			failure.InstanceLocation != "" {
+			segments := strings.Split(failure.InstanceLocation, "/")
+			name := unescapePointer(segments[len(segments)-1])
+			if unevaluated[1] == "" && failure.Reason == "not allowed" {
+				failure.Reason = fmt.Sprintf("property '%s' is not allowed, it's not defined by the schema "+
+					"or any of its subschemas", name)
+			} else if unevaluated[1] != "" {
+				failure.Reason = fmt.Sprintf("unevaluated property '%s' is not valid: %s", name, failure.Reason)
+			}
+		}
+		names := additionalPropertiesRegex.FindStringSubmatch(failure.Reason)
+		if names == nil {
+			split = append(split, failure)
+			continue
+		}
+		// the names are listed in no particular order, sort them so the failures are stable.
+		properties := strings.Split(strings.TrimSuffix(strings.TrimPrefix(names[1], "'"), "'"), "', '")
+		sort.Strings(properties)
+		for _, name := range properties {
+			name = strings.ReplaceAll(name, `\'`, "'")
+			property := *failure
+			property.Reason = fmt.Sprintf("property '%s' is not allowed", name)
+			property.InstanceLocation = failure.InstanceLocation + "/" + escapePointer(name)
+
+			// some failures use the Location for the location within the object being validated.
+			if failure.Location == failure.InstanceLocation && failure.DeepLocation != "" {
+				property.Location = property.InstanceLocation
+			}
+			split = append(split, &property)
+		}
+	}
+	return split
+}
+
+func escapePointer(token string) string {
+	return strings.ReplaceAll(strings.ReplaceAll(token, "~", "~0"), "/", "~1")
+}
+
+func unescapePointer(token string) string {
+	return strings.ReplaceAll(strings.ReplaceAll(token, "~1", "/"), "~0", "~")
+}
+
+// patternName will decode a pattern used as a token of a keyword location, which is escaped as a URL fragment.
+func patternName(token string) string {
+	if unescaped, err := url.PathUnescape(token); err == nil {
+		token = unescaped
+	}
+	return unescapePointer(token)
+}
@@ -0,0 +1,114 @@
+// Copyright 2023 Princess B33f Heavy Industries / Dave Shanley
+// SPDX-License-Identifier: MIT
+
+package schema_validation
+
+import (
+	"fmt"
+	liberrors "github.com/pb33f/libopenapi-validator/errors"
+	"github.com/pb33f/libopenapi-validator/helpers"
+	"math/big"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+var uniqueItemsRegex = regexp.MustCompile(`^items at index \d+ and \d+ are equal$`)
+
+// DescribeArrayFailures will re-write the reason of 'uniqueItems' failures, so every group of equal items is named,
+// rather than only the first pair that was found (e.g. "items at index 1 and 3 are equal, and items at index 0, 2
+// and 4 are equal"). Items are compared deeply, so objects with the same properties and values are equal, and
+// numbers are compared by value (1 and 1.0 are equal). The instance is the decoded value that was validated, the
+// array of each failure is found using its InstanceLocation. Other failures are left as they are.
+func DescribeArrayFailures(failures []*liberrors.SchemaValidationFailure, instance any) {
+	for _, failure := range failures {
+		if !uniqueItemsRegex.MatchString(failure.Reason) {
+			continue
+		}
+		value, _ := helpers.ValueAtPointer(instance, failure.InstanceLocation)
+		items, ok := value.([]any)
+		if !ok {
+			continue
+		}
+		var groups []string
+		for _, group := range equalItems(items) {
+			groups = append(groups, "items at index "+joinIndexes(group)+" are equal")
+		}
+		if len(groups) > 0 {
+			failure.Reason = strings.Join(groups, ", and ")
+		}
+	}
+}
+
+// equalItems returns the indexes of each group of items that are equal, in the order the groups first appear.
+func equalItems(items []any) [][]int {
+	var groups [][]int
+	grouped := make(map[int]bool)
+	for i := range items {
+		if grouped[i] {
+			continue
+		}
+		group := []int{i}
+		for j := i + 1; j < len(items); j++ {
+			if !grouped[j] && jsonEqual(items[i], items[j]) {
+				group = append(group, j)
+				grouped[j] = true
+			}
+		}
+		if len(group) > 1 {
+			groups = append(groups, group)
+		}
+	}
+	return groups
+}
+
+// joinIndexes writes a list of indexes as prose, e.g. "0, 2 and 4".
+func joinIndexes(indexes []int) string {
+	written := make([]string, len(indexes))
+	for i, index := range indexes {
+		written[i] = strconv.Itoa(index)
+	}
+	return strings.Join(written[:len(written)-1], ", ") + " and " + written[len(written)-1]
+}
+
+// jsonEqual returns true if two decoded JSON values are equal, using the same rules as 'uniqueItems'.
+func jsonEqual(a, b any) bool {
+	switch x := a.(type) {
+	case map[string]any:
+		y, ok := b.(map[string]any)
+		if !ok || len(x) != len(y) {
+			return false
+		}
+		for k, v := range x {
+			if w, found := y[k]; !found || !jsonEqual(v, w) {
+				return false
+			}
+		}
+		return true
+	case []any:
+		y, ok := b.([]any)
+		if !ok || len(x) != len(y) {
+			return false
+		}
+		for i := range x {
+			if !jsonEqual(x[i], y[i]) {
+				return false
+			}
+		}
+		return true
+	}
+	if n, ok := jsonNumber(a); ok {
+		m, isNumber := jsonNumber(b)
+		return isNumber && n.Cmp(m) == 0
+	}
+	return a == b
+}
+
+// jsonNumber converts a decoded JSON number into a big.Rat, so numbers can be compared exactly.
+func jsonNumber(value any) (*big.Rat, bool) {
+	switch value.(type) {
+	case string, bool, nil, map[string]any, []any:
+		return nil, false
+	}
+	return new(big.Rat).SetString(fmt.Sprint(value))
+}
@@ -0,0 +1,79 @@
+// Copyright 2023 Princess B33f Heavy Industries / Dave Shanley
+// SPDX-License-Identifier: MIT
+
+package schema_validation
+
+import (
+	"fmt"
+	liberrors "github.com/pb33f/libopenapi-validator/errors"
+	"regexp"
+	"sort"
+	"strings"
+)
+
+var conditionalRegex = regexp.MustCompile(`^if-(then|else) failed$`)
+
+// conditionalReasons explain why a branch of an if/then/else was applied, keyed by the branch.
+var conditionalReasons = map[string]string{
+	"then": "the 'if' condition matched, so 'then' was applied",
+	"else": "the 'if' condition did not match, so 'else' was applied",
+}
+
+// DescribeConditionalFailures will re-write the reason of failures for if/then/else schemas, so they explain which
+// branch was applied and why. The 'if-then failed' or 'if-else failed' failure names the branch and the reasons the
+// branch failed, every failure that occurred inside the branch names the branch it came from
+// (e.g. "missing properties: 'address' (the 'if' condition matched, so 'then' was applied)").
+//
+// The failures are not removed from the slice, so the flat list of failures remains the same.
+func DescribeConditionalFailures(failures []*liberrors.SchemaValidationFailure) {
+	branches := make(map[*liberrors.SchemaValidationFailure]string)
+	var conditions []*liberrors.SchemaValidationFailure
+	for _, failure := range failures {
+		if branch := conditionalRegex.FindStringSubmatch(failure.Reason); branch != nil {
+			branches[failure] = branch[1]
+			conditions = append(conditions, failure)
+		}
+	}
+	if len(conditions) == 0 {
+		return
+	}
+
+	// each failure belongs to the innermost branch that contains it.
+	owners := make(map[*liberrors.SchemaValidationFailure]*liberrors.SchemaValidationFailure)
+	for _, failure := range failures {
+		var owner *liberrors.SchemaValidationFailure
+		for _, condition := range conditions {
+			if condition == failure || (owner != nil && len(keywordLocation(owner)) >= len(keywordLocation(condition))) {
+				continue
+			}
+			if strings.HasPrefix(keywordLocation(failure), keywordLocation(condition)+"/") {
+				owner = condition
+			}
+		}
+		if owner != nil {
+			owners[failure] = owner
+		}
+	}
+
+	// describe the innermost first, so nested conditions roll up into their parents.
+	sort.SliceStable(conditions, func(i, j int) bool {
+		return len(keywordLocation(conditions[i])) > len(keywordLocation(conditions[j]))
+	})
+	for _, condition := range conditions {
+		var reasons []string
+		for _, failure := range failures {
+			if owners[failure] == condition {
+				reasons = append(reasons, failure.Reason)
+			}
+		}
+		condition.Reason = conditionalReasons[branches[condition]]
+		if len(reasons) > 0 {
+			condition.Reason = fmt.Sprintf("%s: %s", condition.Reason, strings.Join(reasons, " and "))
+		}
+	}
+	for failure, owner := range owners {
+		if _, isCondition := branches[failure]; !isCondition {
+			failure.Reason = fmt.Sprintf("%s (%s)", failure.Reason, conditionalReasons[branches[owner]])
+		}
+	}
+}
@@ -0,0 +1,69 @@
+// Copyright 2023 Princess B33f Heavy Industries / Dave Shanley
+// SPDX-License-Identifier: MIT
+
+package schema_validation
+
+import (
+	"fmt"
+	liberrors "github.com/pb33f/libopenapi-validator/errors"
+	"github.com/pb33f/libopenapi-validator/helpers"
+	"math/big"
+	"regexp"
+	"strings"
+)
+
+var (
+	numericBoundRegex    = regexp.MustCompile(`^must be (<=|>=|<|>) (\S+) but found (\S+)$`)
+	numericMultipleRegex = regexp.MustCompile(`^(\S+) not multipleOf (\S+)$`)
+	integerTypeRegex     = regexp.MustCompile(`^expected integer, but got number$`)
+)
+
+// numericBoundReasons are the reasons used for each bound, keyed by the operator used by the schema validator.
+var numericBoundReasons = map[string]string{
+	"<=": "value %s exceeds maximum %s",
+	">=": "value %s is less than minimum %s",
+	"<":  "value %s is not less than exclusive maximum %s",
+	">":  "value %s is not greater than exclusive minimum %s",
+}
+
+// DescribeNumericFailures will re-write the reason of failures for the minimum, maximum, exclusiveMinimum,
+// exclusiveMaximum and multipleOf keywords, so each names the keyword, the limit and the value that was sent
+// (e.g. "value 150 exceeds maximum 100"). Other failures are left as they are.
+func DescribeNumericFailures(failures []*liberrors.SchemaValidationFailure) {
+	for _, failure := range failures {
+		if bound := numericBoundRegex.FindStringSubmatch(failure.Reason); bound != nil {
+			failure.Reason = fmt.Sprintf(numericBoundReasons[bound[1]], plainNumber(bound[3]), plainNumber(bound[2]))
+			continue
+		}
+		if multiple := numericMultipleRegex.FindStringSubmatch(failure.Reason); multiple != nil {
+			failure.Reason = fmt.Sprintf("value %s is not a multiple of %s", plainNumber(multiple[1]),
+				plainNumber(multiple[2]))
+		}
+	}
+}
+
+// plainNumber will write a whole number that is written using an exponent (e.g. 9.007199254740992e+15) without
+// one, large limits are written this way by the schema validator. Any other number is returned as it is.
+func plainNumber(number string) string {
+	if !strings.ContainsAny(number, "eE") {
+		return number
+	}
+	if r, ok := new(big.Rat).SetString(number); ok && r.IsInt() {
+		return r.Num().String()
+	}
+	return number
+}
+
+// DescribeIntegerFailures will re-write the reason of failures for numbers that are not integers, when an integer
+// is expected, so each names the value that was sent (e.g. "value 1.5 is not an integer"). The instance is the
+// decoded value that was validated, the value of each failure is found using its InstanceLocation.
+func DescribeIntegerFailures(failures []*liberrors.SchemaValidationFailure, instance any) {
+	for _, failure := range failures {
+		if !integerTypeRegex.MatchString(failure.Reason) {
+			continue
+		}
+		if value, ok := helpers.ValueAtPointer(instance, failure.InstanceLocation); ok {
+			failure.Reason = fmt.Sprintf("value %v is not an integer", value)
+		}
+	}
+}
@@ -0,0 +1,90 @@
+// Copyright 2023 Princess B33f Heavy Industries / Dave Shanley
+// SPDX-License-Identifier: MIT
+
+package schema_validation
+
+import (
+	"fmt"
+	liberrors "github.com/pb33f/libopenapi-validator/errors"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+var subschemaLocationRegex = regexp.MustCompile(`/(oneOf|anyOf)$`)
+
+// GroupSubschemaFailures will attach every failure that occurred inside a oneOf or anyOf subschema to the failure
+// of the oneOf or anyOf itself, keyed by the index of the subschema. The reason of the oneOf or anyOf failure is
+// re-written to explain why each subschema failed, so a 'oneOf failed' is no longer a dead end.
+//
+// The failures are not removed from the slice, so the flat list of failures remains the same.
+func GroupSubschemaFailures(failures []*liberrors.SchemaValidationFailure) {
+	var combinators []*liberrors.SchemaValidationFailure
+	for _, failure := range failures {
+		if subschemaLocationRegex.MatchString(keywordLocation(failure)) {
+			combinators = append(combinators, failure)
+		}
+	}
+	if len(combinators) == 0 {
+		return
+	}
+
+	// each failure belongs to the innermost oneOf or anyOf that contains it.
+	for _, failure := range failures {
+		var owner *liberrors.SchemaValidationFailure
+		index := -1
+		for _, combinator := range combinators {
+			if combinator == failure || (owner != nil && len(keywordLocation(owner)) >= len(keywordLocation(combinator))) {
+				continue
+			}
+			rest, found := strings.CutPrefix(keywordLocation(failure), keywordLocation(combinator)+"/")
+			if !found {
+				continue
+			}
+			if i, err := strconv.Atoi(strings.SplitN(rest, "/", 2)[0]); err == nil {
+				owner, index = combinator, i
+			}
+		}
+		if owner != nil {
+			if owner.SubschemaFailures == nil {
+				owner.SubschemaFailures = make(map[int][]*liberrors.SchemaValidationFailure)
+			}
+			owner.SubschemaFailures[index] = append(owner.SubschemaFailures[index], failure)
+		}
+	}
+
+	// describe the innermost first, so nested oneOf and anyOf reasons roll up into their parents.
+	sort.SliceStable(combinators, func(i, j int) bool {
+		return len(keywordLocation(combinators[i])) > len(keywordLocation(combinators[j]))
+	})
+	for _, combinator := range combinators {
+		if len(combinator.SubschemaFailures) == 0 {
+			continue
+		}
+		indexes := make([]int, 0, len(combinator.SubschemaFailures))
+		for i := range combinator.SubschemaFailures {
+			indexes = append(indexes, i)
+		}
+		sort.Ints(indexes)
+
+		branches := make([]string, 0, len(indexes))
+		for _, i := range indexes {
+			reasons := make([]string, 0, len(combinator.SubschemaFailures[i]))
+			for _, failure := range combinator.SubschemaFailures[i] {
+				reasons = append(reasons, failure.Reason)
+			}
+			branches = append(branches, fmt.Sprintf("subschema %d: %s", i, strings.Join(reasons, " and ")))
+		}
+		combinator.Reason = fmt.Sprintf("%s, %s", combinator.Reason, strings.Join(branches, "; "))
+	}
+}
+
+// keywordLocation returns the location of the failure within the schema, some failures use the Location for the
+// location within the object being validated, and keep the location within the schema as the DeepLocation.
+func keywordLocation(failure *liberrors.SchemaValidationFailure) string {
+	if failure.DeepLocation != "" {
+		return failure.DeepLocation
+	}
+	return failure.Location
+}
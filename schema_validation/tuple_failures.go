@@ -0,0 +1,44 @@
+// Copyright 2023 Princess B33f Heavy Industries / Dave Shanley
+// SPDX-License-Identifier: MIT
+
+package schema_validation
+
+import (
+	"fmt"
+	liberrors "github.com/pb33f/libopenapi-validator/errors"
+	"regexp"
+)
+
+var (
+	prefixItemsRegex = regexp.MustCompile(`/prefixItems/(\d+)/[^/]+$`)
+	itemsFalseRegex  = regexp.MustCompile(`/items$`)
+)
+
+// DescribeTupleFailures will re-write the reason of failures for arrays validated as tuples (OpenAPI 3.1). A failure
+// of a positional 'prefixItems' schema names the index of the item (e.g. "item at index 1 is not valid: expected
+// number, but got string"), and an item rejected by 'items: false' names the index of the item that is not allowed.
+// Other failures are left as they are.
+func DescribeTupleFailures(failures []*liberrors.SchemaValidationFailure) {
+	for _, failure := range failures {
+		if position := prefixItemsRegex.FindStringSubmatch(keywordLocation(failure)); position != nil {
+			failure.Reason = fmt.Sprintf("item at index %s is not valid: %s", position[1], failure.Reason)
+			continue
+		}
+		if itemsFalseRegex.MatchString(keywordLocation(failure)) && failure.Reason == "not allowed" {
+			if index, ok := lastPointerToken(failure.InstanceLocation); ok {
+				failure.Reason = fmt.Sprintf("item at index %s is not allowed, the array does not allow "+
+					"any more items ('items' is false)", index)
+			}
+		}
+	}
+}
+
+// lastPointerToken returns the last token of a JSON Pointer, false is returned if the pointer is empty.
+func lastPointerToken(pointer string) (string, bool) {
+	for i := len(pointer) - 1; i >= 0; i-- {
+		if pointer[i] == '/' {
+			return unescapePointer(pointer[i+1:]), true
+		}
+	}
+	return "", false
+}
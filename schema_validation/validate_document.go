@@ -61,6 +61,7 @@ func ValidateOpenAPIDocument(doc libopenapi.Document) (bool, []*liberrors.Valida
 						Location:         er.InstanceLocation,
 						DeepLocation:     er.KeywordLocation,
 						AbsoluteLocation: er.AbsoluteKeywordLocation,
+						InstanceLocation: er.InstanceLocation,
 						OriginalError:    jk,
 					}
 
@@ -87,6 +88,7 @@ func ValidateOpenAPIDocument(doc libopenapi.Document) (bool, []*liberrors.Valida
 		// add the error to the list
 		validationErrors = append(validationErrors, &liberrors.ValidationError{
 			ValidationType: helpers.Schema,
+			Code:           liberrors.ErrorCodeDocument,
 			Message:        "Document does not pass validation",
 			Reason: fmt.Sprintf("OpenAPI document is not valid according "+
 				"to the %s specification", info.Version),
@@ -0,0 +1,181 @@
+// Copyright 2023 Princess B33f Heavy Industries / Dave Shanley
+// SPDX-License-Identifier: MIT
+
+package schema_validation
+
+import (
+	"encoding/json"
+	"fmt"
+	"github.com/pb33f/libopenapi-validator/config"
+	liberrors "github.com/pb33f/libopenapi-validator/errors"
+	"github.com/pb33f/libopenapi-validator/helpers"
+	"github.com/pb33f/libopenapi/datamodel/high/base"
+	"github.com/pb33f/libopenapi/datamodel/high/v3"
+	"github.com/pb33f/libopenapi/utils"
+	"github.com/santhosh-tekuri/jsonschema/v5"
+	"gopkg.in/yaml.v3"
+)
+
+// ValidateOpenAPIExamples will walk every path (and webhook) of an OpenAPI 3+ model, and validate the examples of
+// every parameter, request body and response against their schema. Both the singular 'example' and each of the
+// named 'examples' are validated, each example that does not match is reported with the path and operation it was
+// found in. Examples using an 'externalValue' are not fetched, and schemas that cannot be compiled are skipped
+// (ValidateOpenAPIModel reports those). The options control how schemas are compiled.
+func ValidateOpenAPIExamples(document *v3.Document, opts ...config.Option) (bool, []*liberrors.ValidationError) {
+	options := config.NewValidationOptions(opts...)
+	var validationErrors []*liberrors.ValidationError
+
+	var pathItems map[string]*v3.PathItem
+	if document.Paths != nil {
+		pathItems = document.Paths.PathItems
+	}
+	for _, pathItems := range []map[string]*v3.PathItem{pathItems, document.Webhooks} {
+		for _, path := range sortedKeys(pathItems) {
+			pathItem := pathItems[path]
+			validationErrors = append(validationErrors,
+				validateExampleParameters("", path, pathItem.Parameters, options)...)
+
+			operations := pathItem.GetOperations()
+			for _, method := range sortedKeys(operations) {
+				validationErrors = append(validationErrors,
+					validateExampleOperation(method, path, operations[method], options)...)
+			}
+		}
+	}
+	if len(validationErrors) > 0 {
+		return false, validationErrors
+	}
+	return true, nil
+}
+
+func validateExampleOperation(method, path string,
+	operation *v3.Operation,
+	options *config.ValidationOptions) []*liberrors.ValidationError {
+
+	validationErrors := validateExampleParameters(method, path, operation.Parameters, options)
+
+	if operation.RequestBody != nil {
+		validationErrors = append(validationErrors, validateExampleContent(method, path, "request body",
+			operation.RequestBody.Content, false, options)...)
+	}
+	if operation.Responses != nil {
+		for _, code := range sortedKeys(operation.Responses.Codes) {
+			validationErrors = append(validationErrors, validateExampleContent(method, path,
+				fmt.Sprintf("response '%s'", code), operation.Responses.Codes[code].Content, true, options)...)
+		}
+		if operation.Responses.Default != nil {
+			validationErrors = append(validationErrors, validateExampleContent(method, path,
+				"default response", operation.Responses.Default.Content, true, options)...)
+		}
+	}
+	return validationErrors
+}
+
+func validateExampleParameters(method, path string,
+	params []*v3.Parameter,
+	options *config.ValidationOptions) []*liberrors.ValidationError {
+
+	var validationErrors []*liberrors.ValidationError
+	for _, param := range params {
+		location := fmt.Sprintf("%s parameter '%s'", param.In, param.Name)
+		if param.Schema != nil {
+			validationErrors = append(validationErrors, validateExamples(method, path, location, param.Schema,
+				param.GoLow().Example.ValueNode, param.Examples, false, options)...)
+		}
+		validationErrors = append(validationErrors,
+			validateExampleContent(method, path, location, param.Content, false, options)...)
+	}
+	return validationErrors
+}
+
+func validateExampleContent(method, path, location string,
+	content map[string]*v3.MediaType,
+	response bool,
+	options *config.ValidationOptions) []*liberrors.ValidationError {
+
+	var validationErrors []*liberrors.ValidationError
+	for _, contentType := range sortedKeys(content) {
+		mediaType := content[contentType]
+		if mediaType.Schema == nil {
+			continue
+		}
+		validationErrors = append(validationErrors, validateExamples(method, path,
+			fmt.Sprintf("%s '%s'", location, contentType), mediaType.Schema,
+			mediaType.GoLow().Example.ValueNode, mediaType.Examples, response, options)...)
+	}
+	return validationErrors
+}
+
+// validateExamples will validate the singular example (if there is one) and the named examples against a schema.
+// Response schemas allow readOnly properties to be required, request schemas allow writeOnly properties.
+func validateExamples(method, path, location string,
+	proxy *base.SchemaProxy,
+	example *yaml.Node,
+	examples map[string]*base.Example,
+	response bool,
+	options *config.ValidationOptions) []*liberrors.ValidationError {
+
+	// the name of each example, the singular example has no name.
+	named := make(map[string]*yaml.Node)
+	if example != nil {
+		named[""] = example
+	}
+	for name, ex := range examples {
+		if ex != nil && ex.GoLow() != nil && ex.GoLow().Value.ValueNode != nil {
+			named[name] = ex.GoLow().Value.ValueNode
+		}
+	}
+	if len(named) == 0 {
+		return nil
+	}
+
+	schema := proxy.Schema()
+	if schema == nil {
+		return nil
+	}
+	renderedSchema, err := helpers.RenderSchemaInline(schema)
+	if err != nil {
+		return nil
+	}
+	jsonSchema, err := utils.ConvertYAMLtoJSON(renderedSchema)
+	if err != nil {
+		return nil
+	}
+	if response {
+		jsonSchema = helpers.PrepareResponseSchema(jsonSchema, false)
+	} else {
+		jsonSchema = helpers.PrepareRequestSchema(jsonSchema)
+	}
+	compiledSchema, err := helpers.NewCompiledSchema(helpers.DocumentValidation, jsonSchema, options)
+	if err != nil {
+		return nil
+	}
+
+	var validationErrors []*liberrors.ValidationError
+	for _, name := range sortedKeys(named) {
+		var value any
+		if err = named[name].Decode(&value); err != nil {
+			continue
+		}
+		// examples are decoded from YAML, re-encode them as JSON so they are validated as JSON values.
+		payload, err := json.Marshal(value)
+		if err != nil {
+			continue
+		}
+		var decoded any
+		_ = json.Unmarshal(payload, &decoded)
+
+		scErrs := compiledSchema.Validate(decoded)
+		if scErrs == nil {
+			continue
+		}
+		jk, ok := scErrs.(*jsonschema.ValidationError)
+		if !ok {
+			continue
+		}
+		failures := extractBasicErrors(jk.BasicOutput().Errors, renderedSchema, decoded, payload, jk, nil)
+		validationErrors = append(validationErrors,
+			liberrors.DocumentExampleInvalid(method, path, location, name, named[name], failures))
+	}
+	return validationErrors
+}
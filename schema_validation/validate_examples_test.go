@@ -0,0 +1,126 @@
+// Copyright 2023 Princess B33f Heavy Industries / Dave Shanley
+// SPDX-License-Identifier: MIT
+
+package schema_validation
+
+import (
+	"github.com/pb33f/libopenapi"
+	"github.com/pb33f/libopenapi-validator/helpers"
+	"github.com/stretchr/testify/assert"
+	"os"
+	"testing"
+)
+
+func TestValidateExamples(t *testing.T) {
+
+	petstore, _ := os.ReadFile("../test_specs/petstorev3.json")
+
+	doc, _ := libopenapi.NewDocument(petstore)
+	m, _ := doc.BuildV3Model()
+
+	valid, errors := ValidateOpenAPIExamples(&m.Model)
+
+	assert.True(t, valid)
+	assert.Len(t, errors, 0)
+}
+
+func TestValidateExamples_Invalid(t *testing.T) {
+
+	spec := `openapi: 3.1.0
+paths:
+  /burgers/{burgerId}:
+    parameters:
+      - name: burgerId
+        in: path
+        required: true
+        example: big-mac
+        schema:
+          type: string
+    post:
+      parameters:
+        - name: patties
+          in: query
+          example: two
+          schema:
+            type: integer
+      requestBody:
+        content:
+          application/json:
+            schema:
+              $ref: '#/components/schemas/Burger'
+            example:
+              name: Big Mac
+              patties: 2
+            examples:
+              quarterPounder:
+                value:
+                  name: Quarter Pounder
+                  patties: 1
+              mystery:
+                value:
+                  patties: lots
+      responses:
+        '200':
+          content:
+            application/json:
+              schema:
+                $ref: '#/components/schemas/Burger'
+              example:
+                id: 1
+                name: Big Mac
+                patties: 2
+        default:
+          content:
+            application/json:
+              schema:
+                type: object
+                required: [code]
+                properties:
+                  code:
+                    type: integer
+              examples:
+                broken:
+                  value:
+                    message: oops
+components:
+  schemas:
+    Burger:
+      type: object
+      required: [id, name, patties]
+      properties:
+        id:
+          type: integer
+          readOnly: true
+        name:
+          type: string
+        patties:
+          type: integer`
+
+	doc, _ := libopenapi.NewDocument([]byte(spec))
+	m, _ := doc.BuildV3Model()
+
+	valid, errors := ValidateOpenAPIExamples(&m.Model)
+
+	assert.False(t, valid)
+	assert.Len(t, errors, 3)
+
+	assert.Equal(t, helpers.DocumentValidation, errors[0].ValidationType)
+	assert.Equal(t, helpers.Example, errors[0].ValidationSubType)
+	assert.Equal(t, "The example of the query parameter 'patties' of the POST operation for "+
+		"'/burgers/{burgerId}' does not match its schema", errors[0].Message)
+	assert.Equal(t, "The example does not validate against its schema: expected integer, but got string",
+		errors[0].Reason)
+	assert.Equal(t, 15, errors[0].SpecLine)
+
+	// the readOnly id is not required by a request.
+	assert.Equal(t, "The example 'mystery' of the request body 'application/json' of the POST operation for "+
+		"'/burgers/{burgerId}' does not match its schema", errors[1].Message)
+	assert.Len(t, errors[1].SchemaValidationErrors, 2)
+	assert.Equal(t, "/patties", errors[1].SchemaValidationErrors[1].InstanceLocation)
+	assert.Equal(t, 33, errors[1].SpecLine)
+
+	assert.Equal(t, "The example 'broken' of the default response 'application/json' of the POST operation for "+
+		"'/burgers/{burgerId}' does not match its schema", errors[2].Message)
+	assert.Equal(t, "The example 'broken' does not validate against its schema: missing properties: 'code'",
+		errors[2].Reason)
+}
@@ -0,0 +1,182 @@
+// Copyright 2023 Princess B33f Heavy Industries / Dave Shanley
+// SPDX-License-Identifier: MIT
+
+package schema_validation
+
+import (
+	"fmt"
+	"github.com/pb33f/libopenapi-validator/config"
+	liberrors "github.com/pb33f/libopenapi-validator/errors"
+	"github.com/pb33f/libopenapi-validator/helpers"
+	"github.com/pb33f/libopenapi/datamodel/high/base"
+	"github.com/pb33f/libopenapi/datamodel/high/v3"
+	"github.com/pb33f/libopenapi/utils"
+	"sort"
+)
+
+// allowedParameterStyles are the styles that can be used by parameters, for each location.
+var allowedParameterStyles = map[string][]string{
+	helpers.Path:   {helpers.MatrixStyle, helpers.LabelStyle, helpers.Simple},
+	helpers.Query:  {helpers.Form, helpers.SpaceDelimited, helpers.PipeDelimited, helpers.DeepObject},
+	helpers.Header: {helpers.Simple},
+	helpers.Cookie: {helpers.Form},
+}
+
+// ValidateOpenAPIModel will walk every path (and webhook) of an OpenAPI 3+ model once, and check the model can be
+// used to validate requests and responses: every schema of a parameter, request body and response must resolve
+// and compile, every parameter must use a style that is allowed for its location, and the path parameters of each
+// operation must match the {name} tokens of its path. Each problem is reported with the path and operation it was
+// found in. The options control how schemas are compiled.
+func ValidateOpenAPIModel(document *v3.Document, opts ...config.Option) (bool, []*liberrors.ValidationError) {
+	options := config.NewValidationOptions(opts...)
+	var validationErrors []*liberrors.ValidationError
+
+	var pathItems map[string]*v3.PathItem
+	if document.Paths != nil {
+		pathItems = document.Paths.PathItems
+	}
+	for i, pathItems := range []map[string]*v3.PathItem{pathItems, document.Webhooks} {
+		for _, path := range sortedKeys(pathItems) {
+			pathItem := pathItems[path]
+			validationErrors = append(validationErrors,
+				validateModelParameters("", path, pathItem.Parameters, options)...)
+
+			operations := pathItem.GetOperations()
+			for _, method := range sortedKeys(operations) {
+				// webhooks are keyed by name, not by a templated path.
+				if i == 0 {
+					validationErrors = append(validationErrors, validateModelPathTemplate(path,
+						helpers.MergeParameters(pathItem.Parameters, operations[method].Parameters))...)
+				}
+				validationErrors = append(validationErrors,
+					validateModelOperation(method, path, operations[method], options)...)
+			}
+		}
+	}
+	if len(validationErrors) > 0 {
+		return false, validationErrors
+	}
+	return true, nil
+}
+
+func validateModelOperation(method, path string,
+	operation *v3.Operation,
+	options *config.ValidationOptions) []*liberrors.ValidationError {
+
+	validationErrors := validateModelParameters(method, path, operation.Parameters, options)
+
+	if operation.RequestBody != nil {
+		validationErrors = append(validationErrors,
+			validateModelContent(method, path, "request body", operation.RequestBody.Content, options)...)
+	}
+	if operation.Responses != nil {
+		for _, code := range sortedKeys(operation.Responses.Codes) {
+			validationErrors = append(validationErrors, validateModelContent(method, path,
+				fmt.Sprintf("response '%s'", code), operation.Responses.Codes[code].Content, options)...)
+		}
+		if operation.Responses.Default != nil {
+			validationErrors = append(validationErrors, validateModelContent(method, path,
+				"default response", operation.Responses.Default.Content, options)...)
+		}
+	}
+	return validationErrors
+}
+
+// validateModelPathTemplate will check every path parameter of an operation matches a {name} in the path, and
+// every {name} is declared as a path parameter.
+func validateModelPathTemplate(path string, params []*v3.Parameter) []*liberrors.ValidationError {
+	var validationErrors []*liberrors.ValidationError
+	undeclared, unused := helpers.ComparePathTemplate(path, params)
+	for _, name := range undeclared {
+		validationErrors = append(validationErrors, liberrors.PathTemplateParameterNotDeclared(name, path))
+	}
+	for _, param := range unused {
+		validationErrors = append(validationErrors, liberrors.PathParameterNotInTemplate(param, path))
+	}
+	return validationErrors
+}
+
+func validateModelParameters(method, path string,
+	params []*v3.Parameter,
+	options *config.ValidationOptions) []*liberrors.ValidationError {
+
+	var validationErrors []*liberrors.ValidationError
+	for _, param := range params {
+		if allowed, ok := allowedParameterStyles[param.In]; ok && param.Style != "" {
+			legal := false
+			for _, style := range allowed {
+				if param.Style == style {
+					legal = true
+					break
+				}
+			}
+			if !legal {
+				validationErrors = append(validationErrors,
+					liberrors.DocumentParameterStyleInvalid(method, path, param, allowed))
+			}
+		}
+		location := fmt.Sprintf("%s parameter '%s'", param.In, param.Name)
+		if param.Schema != nil {
+			if err := validateModelSchema(method, path, location, param.Schema, options); err != nil {
+				validationErrors = append(validationErrors, err)
+			}
+		}
+		validationErrors = append(validationErrors, validateModelContent(method, path, location, param.Content, options)...)
+	}
+	return validationErrors
+}
+
+func validateModelContent(method, path, location string,
+	content map[string]*v3.MediaType,
+	options *config.ValidationOptions) []*liberrors.ValidationError {
+
+	var validationErrors []*liberrors.ValidationError
+	for _, contentType := range sortedKeys(content) {
+		if content[contentType].Schema == nil {
+			continue
+		}
+		if err := validateModelSchema(method, path, fmt.Sprintf("%s '%s'", location, contentType),
+			content[contentType].Schema, options); err != nil {
+			validationErrors = append(validationErrors, err)
+		}
+	}
+	return validationErrors
+}
+
+// validateModelSchema will build, render and compile a schema, the same way it's prepared for validation.
+func validateModelSchema(method, path, location string,
+	proxy *base.SchemaProxy,
+	options *config.ValidationOptions) *liberrors.ValidationError {
+
+	schema, err := proxy.BuildSchema()
+	if err == nil && schema == nil {
+		err = proxy.GetBuildError()
+	}
+	if err != nil || schema == nil {
+		if err == nil {
+			err = fmt.Errorf("schema '%s' cannot be built", proxy.GetReference())
+		}
+		return liberrors.DocumentSchemaUnresolved(method, path, location, proxy, err)
+	}
+	rendered, err := helpers.RenderSchemaInline(schema)
+	if err != nil {
+		return liberrors.DocumentSchemaInvalid(method, path, location, proxy, err)
+	}
+	jsonSchema, err := utils.ConvertYAMLtoJSON(rendered)
+	if err != nil {
+		return liberrors.DocumentSchemaInvalid(method, path, location, proxy, err)
+	}
+	if _, err = helpers.NewCompiledSchema(helpers.DocumentValidation, jsonSchema, options); err != nil {
+		return liberrors.DocumentSchemaInvalid(method, path, location, proxy, err)
+	}
+	return nil
+}
+
+func sortedKeys[T any](m map[string]T) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
@@ -0,0 +1,135 @@
+// Copyright 2023 Princess B33f Heavy Industries / Dave Shanley
+// SPDX-License-Identifier: MIT
+
+package schema_validation
+
+import (
+	"github.com/pb33f/libopenapi"
+	"github.com/pb33f/libopenapi-validator/helpers"
+	"github.com/stretchr/testify/assert"
+	"os"
+	"testing"
+)
+
+func TestValidateModel(t *testing.T) {
+
+	petstore, _ := os.ReadFile("../test_specs/petstorev3.json")
+
+	doc, _ := libopenapi.NewDocument(petstore)
+	m, _ := doc.BuildV3Model()
+
+	valid, errors := ValidateOpenAPIModel(&m.Model)
+
+	assert.True(t, valid)
+	assert.Len(t, errors, 0)
+}
+
+func TestValidateModel_ParameterStyle(t *testing.T) {
+
+	spec := `openapi: 3.1.0
+paths:
+  /burgers/{burgerId}:
+    parameters:
+      - name: burgerId
+        in: path
+        required: true
+        style: form
+        schema:
+          type: string
+    get:
+      parameters:
+        - name: X-Sauce
+          in: header
+          style: simple
+          schema:
+            type: string
+        - name: fries
+          in: query
+          style: label
+          schema:
+            type: string`
+
+	doc, _ := libopenapi.NewDocument([]byte(spec))
+	m, _ := doc.BuildV3Model()
+
+	valid, errors := ValidateOpenAPIModel(&m.Model)
+
+	assert.False(t, valid)
+	assert.Len(t, errors, 2)
+	assert.Equal(t, helpers.DocumentValidation, errors[0].ValidationType)
+	assert.Equal(t, helpers.ParameterStyle, errors[0].ValidationSubType)
+	assert.Equal(t, "The path parameter 'burgerId' of path '/burgers/{burgerId}' uses the 'form' style, "+
+		"which is not allowed", errors[0].Message)
+	assert.Equal(t, 8, errors[0].SpecLine)
+	assert.Equal(t, "The query parameter 'fries' of the GET operation for '/burgers/{burgerId}' uses the 'label' style, "+
+		"which is not allowed", errors[1].Message)
+	assert.Equal(t, "Use a style that is allowed for the location of the parameter: "+
+		"'form', 'spaceDelimited', 'pipeDelimited', 'deepObject'", errors[1].HowToFix)
+}
+
+func TestValidateModel_SchemaCompile(t *testing.T) {
+
+	spec := `openapi: 3.1.0
+paths:
+  /burgers/createBurger:
+    post:
+      requestBody:
+        content:
+          application/json:
+            schema:
+              type: object
+              properties:
+                name:
+                  type: string
+                  pattern: "[a-z"
+      responses:
+        "200":
+          content:
+            application/json:
+              schema:
+                type: string`
+
+	doc, _ := libopenapi.NewDocument([]byte(spec))
+	m, _ := doc.BuildV3Model()
+
+	valid, errors := ValidateOpenAPIModel(&m.Model)
+
+	assert.False(t, valid)
+	assert.Len(t, errors, 1)
+	assert.Equal(t, helpers.Schema, errors[0].ValidationSubType)
+	assert.Equal(t, "The request body 'application/json' schema of the POST operation for "+
+		"'/burgers/createBurger' cannot be compiled", errors[0].Message)
+	assert.Equal(t, 9, errors[0].SpecLine)
+}
+
+func TestValidateModel_PathTemplate(t *testing.T) {
+
+	spec := `openapi: 3.1.0
+paths:
+  /burgers/{burgerID}/sauces/{sauceId}:
+    parameters:
+      - name: sauceId
+        in: path
+        required: true
+        schema:
+          type: string
+    get:
+      parameters:
+        - name: burgerId
+          in: path
+          required: true
+          schema:
+            type: string`
+
+	doc, _ := libopenapi.NewDocument([]byte(spec))
+	m, _ := doc.BuildV3Model()
+
+	valid, errors := ValidateOpenAPIModel(&m.Model)
+
+	assert.False(t, valid)
+	assert.Len(t, errors, 2)
+	assert.Equal(t, "Path parameter 'burgerID' of the path '/burgers/{burgerID}/sauces/{sauceId}' is not defined",
+		errors[0].Message)
+	assert.Equal(t, "Path parameter 'burgerId' is not part of the path '/burgers/{burgerID}/sauces/{sauceId}'",
+		errors[1].Message)
+}
@@ -8,6 +8,7 @@ import (
 	"encoding/json"
 	"errors"
 	"fmt"
+	"github.com/pb33f/libopenapi-validator/config"
 	liberrors "github.com/pb33f/libopenapi-validator/errors"
 	"github.com/pb33f/libopenapi-validator/helpers"
 	"github.com/pb33f/libopenapi/datamodel/high/base"
@@ -20,7 +21,6 @@ import (
 	"regexp"
 	"strconv"
 	"strings"
-	"sync"
 )
 
 // SchemaValidator is an interface that defines the methods for validating a *base.Schema (V3+ Only) object.
@@ -46,30 +46,75 @@ type SchemaValidator interface {
 var instanceLocationRegex = regexp.MustCompile(`^/(\d+)`)
 
 type schemaValidator struct {
-	logger *zap.SugaredLogger
+	logger  *zap.SugaredLogger
+	options *config.ValidationOptions
 }
 
 // NewSchemaValidator will create a new SchemaValidator instance, ready to accept schemas and payloads to validate.
-func NewSchemaValidator() SchemaValidator {
+// Any options supplied will change the default validation behavior.
+func NewSchemaValidator(opts ...config.Option) SchemaValidator {
 	logger, _ := zap.NewProduction()
-	return &schemaValidator{logger: logger.Sugar()}
+	return &schemaValidator{logger: logger.Sugar(), options: config.NewValidationOptions(opts...)}
 }
 
 func (s *schemaValidator) ValidateSchemaString(schema *base.Schema, payload string) (bool, []*liberrors.ValidationError) {
-	return validateSchema(schema, []byte(payload), nil, s.logger)
+	return validateSchema(schema, []byte(payload), nil, s.logger, s.options)
 }
 
 func (s *schemaValidator) ValidateSchemaObject(schema *base.Schema, payload interface{}) (bool, []*liberrors.ValidationError) {
-	return validateSchema(schema, nil, payload, s.logger)
+	return validateSchema(schema, nil, payload, s.logger, s.options)
 }
 
 func (s *schemaValidator) ValidateSchemaBytes(schema *base.Schema, payload []byte) (bool, []*liberrors.ValidationError) {
-	return validateSchema(schema, payload, nil, s.logger)
+	return validateSchema(schema, payload, nil, s.logger, s.options)
 }
 
-var renderLock = &sync.Mutex{}
+// ValidateSchema will validate a value against a schema, without the need for a request, response or validator.
+// The value can be anything that can be marshalled into JSON (such as a map, slice, struct or primitive), or a
+// value that has already been unmarshalled from JSON. The failures have the same shape as the SchemaValidationErrors
+// of the errors returned by the validators, and no failures means the value is valid. Any options supplied will
+// change the default validation behavior (for example, config.WithFormatAssertions).
+func ValidateSchema(schema *base.SchemaProxy, value any, opts ...config.Option) []*liberrors.SchemaValidationFailure {
+	if schema == nil {
+		return []*liberrors.SchemaValidationFailure{{Reason: "there is no schema to validate against",
+			Location: "unavailable"}}
+	}
+	sch, err := schema.BuildSchema()
+	if err == nil && sch == nil {
+		err = schema.GetBuildError()
+	}
+	if err != nil || sch == nil {
+		reason := fmt.Sprintf("the schema '%s' cannot be built", schema.GetReference())
+		if err != nil {
+			reason = fmt.Sprintf("%s: %s", reason, err.Error())
+		}
+		return []*liberrors.SchemaValidationFailure{{Reason: reason, Location: "unavailable"}}
+	}
 
-func validateSchema(schema *base.Schema, payload []byte, decodedObject interface{}, log *zap.SugaredLogger) (bool, []*liberrors.ValidationError) {
+	// the value is round-tripped through JSON, so it only holds the types that JSON Schema can validate.
+	payload, err := json.Marshal(value)
+	if err != nil {
+		return []*liberrors.SchemaValidationFailure{{
+			Reason:   fmt.Sprintf("the value cannot be encoded as JSON: %s", err.Error()),
+			Location: "unavailable",
+		}}
+	}
+	logger, _ := zap.NewProduction()
+	_, validationErrors := validateSchema(sch, payload, nil, logger.Sugar(), config.NewValidationOptions(opts...))
+
+	var failures []*liberrors.SchemaValidationFailure
+	for _, validationError := range validationErrors {
+		failures = append(failures, validationError.SchemaValidationErrors...)
+	}
+	return failures
+}
+
+func validateSchema(
+	schema *base.Schema,
+	payload []byte,
+	decodedObject interface{},
+	log *zap.SugaredLogger,
+	options *config.ValidationOptions) (bool, []*liberrors.ValidationError) {
 
 	var validationErrors []*liberrors.ValidationError
 
@@ -82,22 +127,21 @@ func validateSchema(schema *base.Schema, payload []byte, decodedObject interface
 	schemaIndex := schema.GoLow().Index
 	var renderedSchema []byte
 
-	// render the schema, to be used for validation, stop this from running concurrently, mutations are made to state
-	// and, it will cause async issues.
-	renderLock.Lock()
+	// render the schema, to be used for validation, this can't run concurrently (see helpers.RenderSchemaInline).
 
 	//version := float32(0.0)
 	if schemaIndex != nil {
 		//version = schemaIndex.GetConfig().SpecInfo.VersionNumeric
 
 	}
-	renderedSchema, _ = schema.RenderInline()
-	renderLock.Unlock()
+	renderedSchema, _ = helpers.RenderSchemaInline(schema)
 
 	jsonSchema, _ := utils.ConvertYAMLtoJSON(renderedSchema)
+	jsonSchema = helpers.ConvertExclusiveBounds(helpers.ConvertNullable(jsonSchema))
 
 	if decodedObject == nil && len(payload) > 0 {
-		err := json.Unmarshal(payload, &decodedObject)
+		var err error
+		decodedObject, err = helpers.UnmarshalJSON(payload)
 
 		if err != nil {
 			// cannot decode the request body, so it's not valid
@@ -110,6 +154,7 @@ func validateSchema(schema *base.Schema, payload []byte, decodedObject interface
 			validationErrors = append(validationErrors, &liberrors.ValidationError{
 				ValidationType:         helpers.RequestBodyValidation,
 				ValidationSubType:      helpers.Schema,
+				Code:                   liberrors.ErrorCodeBodyMalformed,
 				Message:                "schema does not pass validation",
 				Reason:                 fmt.Sprintf("The schema cannot be decoded: %s", err.Error()),
 				SpecLine:               1,
@@ -123,6 +168,8 @@ func validateSchema(schema *base.Schema, payload []byte, decodedObject interface
 
 	}
 	compiler := jsonschema.NewCompiler()
+	helpers.ConfigureFormats(compiler, options)
+	helpers.ConfigureContent(compiler)
 
 	// setting this will break existing vacuum OWASP rules, that assume a 2020 validator for if/else/then schema
 	// validations.
@@ -158,6 +205,7 @@ func validateSchema(schema *base.Schema, payload []byte, decodedObject interface
 				validationErrors = append(validationErrors, &liberrors.ValidationError{
 					ValidationType:         helpers.RequestBodyValidation,
 					ValidationSubType:      helpers.Schema,
+					Code:                   liberrors.ErrorCodeBodyMalformed,
 					Message:                "schema does not pass validation",
 					Reason:                 fmt.Sprintf("The schema cannot be decoded: %s", err.Error()),
 					SpecLine:               1,
@@ -172,7 +220,8 @@ func validateSchema(schema *base.Schema, payload []byte, decodedObject interface
 	}
 
 	// 4. validate the object against the schema
-	if jsch != nil && decodedObject != nil {
+	// a payload of null is validated too, as the schema may not allow it.
+	if jsch != nil && (decodedObject != nil || len(payload) > 0) {
 		scErrs := jsch.Validate(decodedObject)
 		if scErrs != nil {
 
@@ -193,6 +242,10 @@ func validateSchema(schema *base.Schema, payload []byte, decodedObject interface
 				schFlatErrs := jk.BasicOutput().Errors
 
 				schemaValidationErrors = extractBasicErrors(schFlatErrs, renderedSchema, decodedObject, payload, jk, schemaValidationErrors)
+
+				// name the value of each number that is not an integer, and every duplicate of an array of unique items.
+				DescribeIntegerFailures(schemaValidationErrors, decodedObject)
+				DescribeArrayFailures(schemaValidationErrors, decodedObject)
 			}
 			line := 1
 			col := 0
@@ -204,6 +257,7 @@ func validateSchema(schema *base.Schema, payload []byte, decodedObject interface
 			// add the error to the list
 			validationErrors = append(validationErrors, &liberrors.ValidationError{
 				ValidationType:         helpers.Schema,
+				Code:                   liberrors.ErrorCodeSchema,
 				Message:                "schema does not pass validation",
 				Reason:                 "Schema failed to validate against the contract requirements",
 				SpecLine:               line,
@@ -259,6 +313,7 @@ func extractBasicErrors(schFlatErrs []jsonschema.BasicError,
 				Location:         er.InstanceLocation,
 				DeepLocation:     er.KeywordLocation,
 				AbsoluteLocation: er.AbsoluteKeywordLocation,
+				InstanceLocation: er.InstanceLocation,
 				ReferenceSchema:  string(renderedSchema),
 				ReferenceObject:  referenceObject,
 				OriginalError:    jk,
@@ -281,5 +336,14 @@ func extractBasicErrors(schFlatErrs []jsonschema.BasicError,
 			schemaValidationErrors = append(schemaValidationErrors, violation)
 		}
 	}
+
+	// name each property that is not allowed and the index of each tuple item that failed, describe numeric
+	// limits that were crossed, explain which branch of an if/then/else was applied, and why each subschema of a
+	// oneOf or anyOf failed.
+	schemaValidationErrors = SplitAdditionalPropertyFailures(schemaValidationErrors)
+	DescribeNumericFailures(schemaValidationErrors)
+	DescribeTupleFailures(schemaValidationErrors)
+	DescribeConditionalFailures(schemaValidationErrors)
+	GroupSubschemaFailures(schemaValidationErrors)
 	return schemaValidationErrors
 }
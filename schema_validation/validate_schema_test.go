@@ -4,8 +4,12 @@
 package schema_validation
 
 import (
+	"encoding/base64"
 	"encoding/json"
+	"fmt"
 	"github.com/pb33f/libopenapi"
+	"github.com/pb33f/libopenapi-validator/config"
+	liberrors "github.com/pb33f/libopenapi-validator/errors"
 	"github.com/stretchr/testify/assert"
 	"gopkg.in/yaml.v3"
 	"testing"
@@ -691,3 +695,521 @@ paths:
 //	assert.Len(t, errors, 0)
 //
 //}
+
+func TestValidateSchema_FormatAssertions(t *testing.T) {
+	spec := `openapi: 3.1.0
+components:
+  schemas:
+    Burger:
+      type: object
+      properties:
+        id:
+          type: string
+          format: uuid
+        cooked:
+          type: string
+          format: date-time`
+
+	doc, _ := libopenapi.NewDocument([]byte(spec))
+
+	m, _ := doc.BuildV3Model()
+	sch := m.Model.Components.Schemas["Burger"].Schema()
+
+	invalid := `{"id": "not-a-uuid", "cooked": "not-a-date"}`
+
+	// formats are annotations by default.
+	valid, errors := NewSchemaValidator().ValidateSchemaString(sch, invalid)
+	assert.True(t, valid)
+	assert.Len(t, errors, 0)
+
+	v := NewSchemaValidator(config.WithFormatAssertions())
+	valid, errors = v.ValidateSchemaString(sch, `{"id": "0b5d4c1e-4f4c-4b0e-9d1c-4c0b5e5d9f3a", "cooked": "2023-10-11T12:00:00Z"}`)
+	assert.True(t, valid)
+	assert.Len(t, errors, 0)
+
+	valid, errors = v.ValidateSchemaString(sch, invalid)
+	assert.False(t, valid)
+	assert.Len(t, errors, 1)
+	assert.Len(t, errors[0].SchemaValidationErrors, 2)
+}
+
+func TestValidateSchema_SubschemaFailures(t *testing.T) {
+	spec := `openapi: 3.1.0
+components:
+  schemas:
+    Topping:
+      oneOf:
+        - type: string
+        - type: object
+          required: [name]
+          properties:
+            name:
+              type: string
+            amount:
+              anyOf:
+                - type: integer
+                - type: string
+                  enum: [some, lots]`
+
+	doc, _ := libopenapi.NewDocument([]byte(spec))
+
+	m, _ := doc.BuildV3Model()
+	sch := m.Model.Components.Schemas["Topping"].Schema()
+
+	valid, errors := NewSchemaValidator().ValidateSchemaString(sch, `{"amount": "loads"}`)
+	assert.False(t, valid)
+	assert.Len(t, errors, 1)
+
+	failures := make(map[string]string)
+	for _, f := range errors[0].SchemaValidationErrors {
+		failures[f.DeepLocation] = f.Reason
+	}
+
+	// the anyOf failure rolls up into the oneOf failure, so branch 1 shows both of its problems.
+	assert.Equal(t, "anyOf failed, subschema 0: expected integer, but got string; "+
+		"subschema 1: value must be one of \"some\", \"lots\"", failures["/oneOf/1/properties/amount/anyOf"])
+	assert.Equal(t, "oneOf failed, subschema 0: expected string, but got object; "+
+		"subschema 1: missing properties: 'name' and "+failures["/oneOf/1/properties/amount/anyOf"],
+		failures["/oneOf"])
+}
+
+func TestValidateSchema_AdditionalProperties(t *testing.T) {
+	spec := `openapi: 3.1.0
+components:
+  schemas:
+    Burger:
+      type: object
+      additionalProperties: false
+      properties:
+        name:
+          type: string
+        sauces:
+          type: object
+          additionalProperties:
+            type: integer`
+
+	doc, _ := libopenapi.NewDocument([]byte(spec))
+
+	m, _ := doc.BuildV3Model()
+	sch := m.Model.Components.Schemas["Burger"].Schema()
+
+	valid, errors := NewSchemaValidator().ValidateSchemaString(sch,
+		`{"name": "Big Mac", "fries": true, "drink/size": "large", "sauces": {"ketchup": 1, "mayo": "lots"}}`)
+	assert.False(t, valid)
+	assert.Len(t, errors, 1)
+
+	failures := make(map[string]string)
+	for _, f := range errors[0].SchemaValidationErrors {
+		failures[f.InstanceLocation] = f.Reason
+	}
+
+	// each unexpected property is named, with its own pointer.
+	assert.Len(t, failures, 3)
+	assert.Equal(t, "property 'fries' is not allowed", failures["/fries"])
+	assert.Equal(t, "property 'drink/size' is not allowed", failures["/drink~1size"])
+	assert.Equal(t, "additional property 'mayo' is not valid: expected integer, but got string",
+		failures["/sauces/mayo"])
+}
+
+func TestValidateSchema_PatternProperties(t *testing.T) {
+	spec := `openapi: 3.1.0
+components:
+  schemas:
+    Burger:
+      type: object
+      additionalProperties: false
+      properties:
+        name:
+          type: string
+      patternProperties:
+        '^x-':
+          type: integer`
+
+	doc, _ := libopenapi.NewDocument([]byte(spec))
+
+	m, _ := doc.BuildV3Model()
+	sch := m.Model.Components.Schemas["Burger"].Schema()
+
+	// a property matching the pattern is not additional.
+	valid, errors := NewSchemaValidator().ValidateSchemaString(sch, `{"name": "Big Mac", "x-calories": 550}`)
+	assert.True(t, valid)
+	assert.Empty(t, errors)
+
+	valid, errors = NewSchemaValidator().ValidateSchemaString(sch,
+		`{"name": "Big Mac", "x-calories": "lots", "fries": true}`)
+	assert.False(t, valid)
+	assert.Len(t, errors, 1)
+
+	failures := make(map[string]string)
+	for _, f := range errors[0].SchemaValidationErrors {
+		failures[f.InstanceLocation] = f.Reason
+	}
+
+	assert.Len(t, failures, 2)
+	assert.Equal(t, "property 'x-calories' matching pattern '^x-' is not valid: expected integer, but got string",
+		failures["/x-calories"])
+	assert.Equal(t, "property 'fries' is not allowed", failures["/fries"])
+}
+
+func TestValidateSchema_IfThenElse(t *testing.T) {
+	spec := `openapi: 3.1.0
+components:
+  schemas:
+    Order:
+      type: object
+      properties:
+        type:
+          type: string
+        address:
+          type: string
+        table:
+          type: integer
+      if:
+        properties:
+          type:
+            const: delivery
+      then:
+        required: [address]
+      else:
+        required: [table]`
+
+	doc, _ := libopenapi.NewDocument([]byte(spec))
+
+	m, _ := doc.BuildV3Model()
+	sch := m.Model.Components.Schemas["Order"].Schema()
+
+	valid, errors := NewSchemaValidator().ValidateSchemaString(sch, `{"type": "delivery", "address": "1 Burger Lane"}`)
+	assert.True(t, valid)
+	assert.Empty(t, errors)
+
+	valid, errors = NewSchemaValidator().ValidateSchemaString(sch, `{"type": "dine-in", "table": 4}`)
+	assert.True(t, valid)
+	assert.Empty(t, errors)
+
+	failures := func(errors []*liberrors.ValidationError) map[string]string {
+		reasons := make(map[string]string)
+		for _, f := range errors[0].SchemaValidationErrors {
+			reasons[f.DeepLocation] = f.Reason
+		}
+		return reasons
+	}
+
+	// the 'if' matches, so the address is required by 'then'.
+	valid, errors = NewSchemaValidator().ValidateSchemaString(sch, `{"type": "delivery", "table": 4}`)
+	assert.False(t, valid)
+	assert.Len(t, errors, 1)
+	assert.Equal(t, map[string]string{
+		"/then":          "the 'if' condition matched, so 'then' was applied: missing properties: 'address'",
+		"/then/required": "missing properties: 'address' (the 'if' condition matched, so 'then' was applied)",
+	}, failures(errors))
+
+	// the 'if' does not match, so the table is required by 'else'.
+	valid, errors = NewSchemaValidator().ValidateSchemaString(sch, `{"type": "dine-in"}`)
+	assert.False(t, valid)
+	assert.Len(t, errors, 1)
+	assert.Equal(t, map[string]string{
+		"/else":          "the 'if' condition did not match, so 'else' was applied: missing properties: 'table'",
+		"/else/required": "missing properties: 'table' (the 'if' condition did not match, so 'else' was applied)",
+	}, failures(errors))
+}
+
+func TestValidateSchema_ContentSchema(t *testing.T) {
+	spec := `openapi: 3.1.0
+components:
+  schemas:
+    Order:
+      type: object
+      properties:
+        token:
+          type: string
+          contentEncoding: base64url
+          contentMediaType: application/json
+          contentSchema:
+            type: object
+            required: [sub]
+            properties:
+              sub:
+                type: string
+              burger:
+                type: object
+                properties:
+                  patties:
+                    type: integer`
+
+	doc, _ := libopenapi.NewDocument([]byte(spec))
+
+	m, _ := doc.BuildV3Model()
+	sch := m.Model.Components.Schemas["Order"].Schema()
+
+	token := func(claims string) string {
+		return fmt.Sprintf(`{"token": "%s"}`, base64.RawURLEncoding.EncodeToString([]byte(claims)))
+	}
+
+	valid, errors := NewSchemaValidator().ValidateSchemaString(sch, token(`{"sub": "dave", "burger": {"patties": 2}}`))
+	assert.True(t, valid)
+	assert.Empty(t, errors)
+
+	// the decoded content is validated against the contentSchema, failures point into the content.
+	valid, errors = NewSchemaValidator().ValidateSchemaString(sch, token(`{"burger": {"patties": "two"}}`))
+	assert.False(t, valid)
+	assert.Len(t, errors, 1)
+
+	failures := make(map[string]string)
+	for _, f := range errors[0].SchemaValidationErrors {
+		failures[f.InstanceLocation] = f.Reason
+	}
+	assert.Equal(t, map[string]string{
+		"/token":                "missing properties: 'sub'",
+		"/token/burger/patties": "expected integer, but got string",
+	}, failures)
+
+	// content that can't be decoded.
+	valid, errors = NewSchemaValidator().ValidateSchemaString(sch, `{"token": "not base64!"}`)
+	assert.False(t, valid)
+	assert.Len(t, errors, 1)
+	assert.Len(t, errors[0].SchemaValidationErrors, 1)
+	assert.Equal(t, "value is not base64url encoded", errors[0].SchemaValidationErrors[0].Reason)
+
+	// content that isn't JSON.
+	valid, errors = NewSchemaValidator().ValidateSchemaString(sch, token("burger"))
+	assert.False(t, valid)
+	assert.Len(t, errors, 1)
+	assert.Equal(t, "value is not of mediatype 'application/json'", errors[0].SchemaValidationErrors[0].Reason)
+}
+
+func TestValidateSchema_PrefixItems(t *testing.T) {
+	spec := `openapi: 3.1.0
+components:
+  schemas:
+    Order:
+      type: array
+      prefixItems:
+        - type: string
+        - type: number
+          maximum: 10
+        - type: boolean
+      items: false`
+
+	doc, _ := libopenapi.NewDocument([]byte(spec))
+
+	m, _ := doc.BuildV3Model()
+	sch := m.Model.Components.Schemas["Order"].Schema()
+
+	valid, errors := NewSchemaValidator().ValidateSchemaString(sch, `["Big Mac", 2, true]`)
+	assert.True(t, valid)
+	assert.Empty(t, errors)
+
+	// the item at position 1 must be a number.
+	valid, errors = NewSchemaValidator().ValidateSchemaString(sch, `["Big Mac", "two", true]`)
+	assert.False(t, valid)
+	assert.Len(t, errors, 1)
+	assert.Len(t, errors[0].SchemaValidationErrors, 1)
+	assert.Equal(t, "item at index 1 is not valid: expected number, but got string",
+		errors[0].SchemaValidationErrors[0].Reason)
+	assert.Equal(t, "/1", errors[0].SchemaValidationErrors[0].InstanceLocation)
+
+	valid, errors = NewSchemaValidator().ValidateSchemaString(sch, `["Big Mac", 20, true]`)
+	assert.False(t, valid)
+	assert.Equal(t, "item at index 1 is not valid: value 20 exceeds maximum 10",
+		errors[0].SchemaValidationErrors[0].Reason)
+
+	// there are no more positions after the prefixItems.
+	valid, errors = NewSchemaValidator().ValidateSchemaString(sch, `["Big Mac", 2, true, "fries"]`)
+	assert.False(t, valid)
+	assert.Len(t, errors, 1)
+	assert.Len(t, errors[0].SchemaValidationErrors, 1)
+	assert.Equal(t, "item at index 3 is not allowed, the array does not allow any more items ('items' is false)",
+		errors[0].SchemaValidationErrors[0].Reason)
+	assert.Equal(t, "/3", errors[0].SchemaValidationErrors[0].InstanceLocation)
+}
+
+func TestValidateSchema_UnevaluatedProperties(t *testing.T) {
+	spec := `openapi: 3.1.0
+components:
+  schemas:
+    Burger:
+      allOf:
+        - type: object
+          properties:
+            name:
+              type: string
+        - type: object
+          properties:
+            patties:
+              type: integer
+      oneOf:
+        - properties:
+            sauce:
+              type: string
+          required: [sauce]
+        - properties:
+            relish:
+              type: string
+          required: [relish]
+      unevaluatedProperties: false`
+
+	doc, _ := libopenapi.NewDocument([]byte(spec))
+
+	m, _ := doc.BuildV3Model()
+	sch := m.Model.Components.Schemas["Burger"].Schema()
+
+	// every property is evaluated by one of the subschemas.
+	valid, errors := NewSchemaValidator().ValidateSchemaString(sch, `{"name": "Big Mac", "patties": 2, "sauce": "mayo"}`)
+	assert.True(t, valid)
+	assert.Empty(t, errors)
+
+	// fries is not covered by any subschema.
+	valid, errors = NewSchemaValidator().ValidateSchemaString(sch,
+		`{"name": "Big Mac", "patties": 2, "sauce": "mayo", "fries": true}`)
+	assert.False(t, valid)
+	assert.Len(t, errors, 1)
+
+	failures := make(map[string]string)
+	for _, f := range errors[0].SchemaValidationErrors {
+		failures[f.InstanceLocation] = f.Reason
+	}
+	assert.Equal(t, map[string]string{
+		"/fries": "property 'fries' is not allowed, it's not defined by the schema or any of its subschemas",
+	}, failures)
+}
+
+func TestValidateSchema_Nullable(t *testing.T) {
+	spec := `openapi: 3.0.3
+components:
+  schemas:
+    Sauce:
+      type: object
+      properties:
+        name:
+          type: string
+    Burger:
+      type: object
+      properties:
+        nullable:
+          type: boolean
+        sauce:
+          nullable: true
+          allOf:
+            - $ref: '#/components/schemas/Sauce'`
+
+	doc, _ := libopenapi.NewDocument([]byte(spec))
+
+	m, _ := doc.BuildV3Model()
+	sch := m.Model.Components.Schemas["Burger"].Schema()
+
+	valid, errors := NewSchemaValidator().ValidateSchemaString(sch, `{"nullable": true, "sauce": null}`)
+	assert.True(t, valid)
+	assert.Len(t, errors, 0)
+
+	// a property named 'nullable' is not the keyword.
+	valid, _ = NewSchemaValidator().ValidateSchemaString(sch, `{"nullable": null}`)
+	assert.False(t, valid)
+}
+
+func TestValidateSchema_ArrayItems(t *testing.T) {
+	spec := `openapi: 3.1.0
+components:
+  schemas:
+    Order:
+      type: object
+      properties:
+        burgers:
+          type: array
+          minItems: 3
+          maxItems: 5
+          uniqueItems: true
+          items:
+            type: object`
+
+	doc, _ := libopenapi.NewDocument([]byte(spec))
+
+	m, _ := doc.BuildV3Model()
+	sch := m.Model.Components.Schemas["Order"].Schema()
+
+	valid, errors := NewSchemaValidator().ValidateSchemaString(sch,
+		`{"burgers": [{"name": "Big Mac"}, {"name": "Whopper", "patties": 2}, {"name": "Whopper", "patties": 1}]}`)
+	assert.True(t, valid)
+	assert.Empty(t, errors)
+
+	// objects with the same properties and values are equal, whatever order the properties are in.
+	valid, errors = NewSchemaValidator().ValidateSchemaString(sch,
+		`{"burgers": [{"name": "Big Mac"}, {"name": "Whopper", "patties": 2}, {"name": "Zinger"},
+			{"patties": 2.0, "name": "Whopper"}]}`)
+	assert.False(t, valid)
+	assert.Len(t, errors, 1)
+	assert.Len(t, errors[0].SchemaValidationErrors, 1)
+	assert.Equal(t, "items at index 1 and 3 are equal", errors[0].SchemaValidationErrors[0].Reason)
+	assert.Equal(t, "/burgers", errors[0].SchemaValidationErrors[0].InstanceLocation)
+
+	// every group of equal items is named.
+	valid, errors = NewSchemaValidator().ValidateSchemaString(sch,
+		`{"burgers": [{"name": "Big Mac"}, {"name": "Zinger"}, {"name": "Big Mac"}, {"name": "Zinger"},
+			{"name": "Big Mac"}]}`)
+	assert.False(t, valid)
+	assert.Equal(t, "items at index 0, 2 and 4 are equal, and items at index 1 and 3 are equal",
+		errors[0].SchemaValidationErrors[0].Reason)
+
+	// the number of items is reported against the limit.
+	valid, errors = NewSchemaValidator().ValidateSchemaString(sch,
+		`{"burgers": [{"name": "Big Mac"}, {"name": "Whopper"}]}`)
+	assert.False(t, valid)
+	assert.Len(t, errors[0].SchemaValidationErrors, 1)
+	assert.Equal(t, "minimum 3 items required, but found 2 items", errors[0].SchemaValidationErrors[0].Reason)
+	assert.Equal(t, "/burgers", errors[0].SchemaValidationErrors[0].InstanceLocation)
+}
+
+func TestValidateSchema_Value(t *testing.T) {
+
+	spec := `openapi: 3.1.0
+components:
+  schemas:
+    Burger:
+      type: object
+      required: [name]
+      properties:
+        name:
+          type: string
+        patties:
+          type: integer
+          minimum: 1
+        cookedAt:
+          type: string
+          format: date-time`
+
+	doc, _ := libopenapi.NewDocument([]byte(spec))
+	m, _ := doc.BuildV3Model()
+	burger := m.Model.Components.Schemas["Burger"]
+
+	type Burger struct {
+		Name     string `json:"name,omitempty"`
+		Patties  int    `json:"patties,omitempty"`
+		CookedAt string `json:"cookedAt,omitempty"`
+	}
+
+	// decoded JSON, and go values (such as structs) are both validated.
+	assert.Empty(t, ValidateSchema(burger, map[string]any{"name": "Big Mac", "patties": 2}))
+	assert.Empty(t, ValidateSchema(burger, Burger{Name: "Big Mac", Patties: 2}))
+
+	failures := ValidateSchema(burger, Burger{Patties: -1})
+	assert.Len(t, failures, 2)
+	assert.Equal(t, "missing properties: 'name'", failures[0].Reason)
+	assert.Equal(t, "", failures[0].InstanceLocation)
+	assert.Equal(t, "value -1 is less than minimum 1", failures[1].Reason)
+	assert.Equal(t, "/patties", failures[1].InstanceLocation)
+
+	// null is validated like any other value.
+	failures = ValidateSchema(burger, nil)
+	assert.Len(t, failures, 1)
+	assert.Equal(t, "expected object, but got null", failures[0].Reason)
+
+	// options change how the value is validated.
+	assert.Empty(t, ValidateSchema(burger, Burger{Name: "Big Mac", CookedAt: "yesterday"}))
+	failures = ValidateSchema(burger, Burger{Name: "Big Mac", CookedAt: "yesterday"}, config.WithFormatAssertions())
+	assert.Len(t, failures, 1)
+	assert.Equal(t, "/cookedAt", failures[0].InstanceLocation)
+
+	// there is nothing to validate against without a schema.
+	failures = ValidateSchema(nil, Burger{Name: "Big Mac"})
+	assert.Len(t, failures, 1)
+	assert.Equal(t, "there is no schema to validate against", failures[0].Reason)
+}
@@ -0,0 +1,7 @@
+// Copyright 2023 Princess B33f Heavy Industries / Dave Shanley
+// SPDX-License-Identifier: MIT
+
+// Package security contains all the logic, models and interfaces for validating the OpenAPI 3+ security
+// requirements of a request. apiKey (header, query and cookie) and http (basic, bearer etc.) credentials are
+// located, and optionally verified.
+package security
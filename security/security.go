@@ -0,0 +1,74 @@
+// Copyright 2023 Princess B33f Heavy Industries / Dave Shanley
+// SPDX-License-Identifier: MIT
+
+package security
+
+import (
+	"context"
+	"github.com/pb33f/libopenapi-validator/config"
+	"github.com/pb33f/libopenapi-validator/errors"
+	"github.com/pb33f/libopenapi-validator/paths"
+	v3 "github.com/pb33f/libopenapi/datamodel/high/v3"
+	"net/http"
+)
+
+// SecurityValidator is an interface that defines the methods for validating the security requirements of a request.
+//
+//	ValidateSecurity will validate the request carries the credentials required by the security requirements
+//
+// Credentials are extracted from the header, query or cookie defined by the security scheme. By default, only the
+// presence of credentials is checked. Verifiers registered using config.WithSecurityVerifier are used to check
+// the credentials of a security scheme are valid.
+type SecurityValidator interface {
+
+	// SetPathItem will set the pathItem for the SecurityValidator, all validations will be performed against this pathItem
+	// otherwise if not set, each validation will perform a lookup for the pathItem based on the *http.Request.
+	// The pathItem is stored on the validator, so SetPathItem is not safe to use while the validator is shared
+	// between goroutines, use ValidateSecurityWithPathItem instead.
+	SetPathItem(path *v3.PathItem, pathValue string)
+
+	// ValidateSecurity validates that the *http.Request carries the credentials required by at least one of the
+	// security requirements of the operation (or the global security requirements, if the operation has none).
+	// It returns a boolean stating true if validation passed (false for failed), and a slice of errors if
+	// validation failed.
+	ValidateSecurity(request *http.Request) (bool, []*errors.ValidationError)
+
+	// ValidateSecurityWithContext is the same as ValidateSecurity, but will not validate the security requirements
+	// when the context is done.
+	ValidateSecurityWithContext(ctx context.Context, request *http.Request) (bool, []*errors.ValidationError)
+
+	// ValidateSecurityWithPathItem is the same as ValidateSecurityWithContext, but uses the supplied pathItem and
+	// pathValue (as returned by paths.FindPath) instead of looking them up, or using the pathItem set by SetPathItem.
+	ValidateSecurityWithPathItem(ctx context.Context, request *http.Request, pathItem *v3.PathItem,
+		pathValue string) (bool, []*errors.ValidationError)
+}
+
+func (v *securityValidator) SetPathItem(path *v3.PathItem, pathValue string) {
+	v.pathItem = path
+	v.pathValue = pathValue
+}
+
+// NewSecurityValidator will create a new SecurityValidator from an OpenAPI 3+ document, any options
+// supplied will change the default validation behavior.
+func NewSecurityValidator(document *v3.Document, opts ...config.Option) SecurityValidator {
+	return &securityValidator{document: document, options: config.NewValidationOptions(opts...)}
+}
+
+// findPath will return the pathItem set using SetPathItem, otherwise the pathItem is located using the request.
+func (v *securityValidator) findPath(request *http.Request) (*v3.PathItem, string, []*errors.ValidationError) {
+	if v.pathItem != nil {
+		return v.pathItem, v.pathValue, nil
+	}
+	pathItem, errs, pathValue := paths.FindPathWithOptions(request, v.document, v.options)
+	if errs != nil {
+		return nil, "", errs
+	}
+	return pathItem, pathValue, nil
+}
+
+type securityValidator struct {
+	document  *v3.Document
+	options   *config.ValidationOptions
+	pathItem  *v3.PathItem
+	pathValue string
+}
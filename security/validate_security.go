@@ -0,0 +1,166 @@
+// Copyright 2023 Princess B33f Heavy Industries / Dave Shanley
+// SPDX-License-Identifier: MIT
+
+package security
+
+import (
+	"context"
+	"fmt"
+	"github.com/pb33f/libopenapi-validator/errors"
+	"github.com/pb33f/libopenapi-validator/helpers"
+	"github.com/pb33f/libopenapi/datamodel/high/base"
+	v3 "github.com/pb33f/libopenapi/datamodel/high/v3"
+	"net/http"
+	"sort"
+	"strings"
+)
+
+func (v *securityValidator) ValidateSecurity(request *http.Request) (bool, []*errors.ValidationError) {
+	return v.ValidateSecurityWithContext(context.Background(), request)
+}
+
+func (v *securityValidator) ValidateSecurityWithContext(
+	ctx context.Context,
+	request *http.Request) (bool, []*errors.ValidationError) {
+
+	pathItem, pathValue, errs := v.findPath(request)
+	if pathItem == nil {
+		return false, errs
+	}
+	return v.ValidateSecurityWithPathItem(ctx, request, pathItem, pathValue)
+}
+
+func (v *securityValidator) ValidateSecurityWithPathItem(
+	ctx context.Context,
+	request *http.Request,
+	pathItem *v3.PathItem,
+	pathValue string) (valid bool, validationErrors []*errors.ValidationError) {
+
+	// errors are labelled with the operation they were found in.
+	defer func() { errors.SetOperation(validationErrors, request, pathItem, pathValue) }()
+
+	if ctx.Err() != nil {
+		return false, []*errors.ValidationError{errors.ValidationCancelled(ctx)}
+	}
+	operation := helpers.ExtractOperation(request, pathItem)
+	if operation == nil {
+		return true, nil
+	}
+
+	// the security of the operation replaces the global security, an empty list removes it.
+	security := operation.Security
+	if security == nil {
+		security = v.document.Security
+	}
+	if len(security) == 0 {
+		return true, nil
+	}
+
+	var schemes map[string]*v3.SecurityScheme
+	if v.document.Components != nil {
+		schemes = v.document.Components.SecuritySchemes
+	}
+
+	// each requirement is an alternative, only one of them needs to be met.
+	var missing []string
+	rejected := false
+	for _, requirement := range security {
+		unmet, rejectedCredential := v.unmetCredentials(request, requirement, schemes)
+		if len(unmet) == 0 {
+			return true, nil
+		}
+		missing = append(missing, strings.Join(unmet, " and "))
+		rejected = rejected || rejectedCredential
+	}
+
+	// a credential was sent, but was not valid, so the request is not missing its credentials.
+	if rejected {
+		return false, []*errors.ValidationError{errors.SecurityCredentialsRejected(request, security, missing)}
+	}
+	return false, []*errors.ValidationError{errors.SecurityRequirementsNotMet(request, security, missing)}
+}
+
+// unmetCredentials will return a description of every credential required by the security requirement that the
+// request does not carry, or that was rejected by the verifier of the security scheme. An empty requirement ({})
+// makes security optional, so nothing is unmet. rejected is true when a credential was rejected by a verifier.
+//
+// oauth2, openIdConnect and mutualTLS credentials can't be recognized by their presence alone, so they are never
+// reported, unless a verifier has been registered for the security scheme.
+func (v *securityValidator) unmetCredentials(request *http.Request,
+	requirement *base.SecurityRequirement,
+	schemes map[string]*v3.SecurityScheme) (unmet []string, rejected bool) {
+
+	names := make([]string, 0, len(requirement.Requirements))
+	for name := range requirement.Requirements {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	for _, name := range names {
+		scheme := schemes[name]
+		if scheme == nil {
+			unmet = append(unmet, fmt.Sprintf("security scheme '%s' to be defined", name))
+			continue
+		}
+		verifier := v.options.SecurityVerifiers[name]
+		credential, present := extractCredential(request, scheme)
+		if !present && verifier == nil && scheme.Type != "apiKey" && scheme.Type != "http" {
+			continue
+		}
+
+		var location string
+		switch scheme.Type {
+		case "apiKey":
+			location = fmt.Sprintf("%s '%s'", scheme.In, scheme.Name)
+		case "http":
+			location = fmt.Sprintf("header 'Authorization' using the '%s' scheme", scheme.Scheme)
+		default:
+			location = "header 'Authorization'"
+		}
+		if !present {
+			unmet = append(unmet, fmt.Sprintf("%s (%s)", location, name))
+			continue
+		}
+		if verifier != nil && !verifier(credential) {
+			unmet = append(unmet, fmt.Sprintf("a valid %s (%s)", location, name))
+			rejected = true
+		}
+	}
+	return unmet, rejected
+}
+
+// extractCredential will extract the credential of a security scheme from the request, returning false if the
+// request does not carry one. The credential of an http scheme is the Authorization header without the scheme,
+// other schemes that are not apiKey schemes use the Authorization header as it is.
+func extractCredential(request *http.Request, scheme *v3.SecurityScheme) (string, bool) {
+	switch scheme.Type {
+	case "apiKey":
+		switch scheme.In {
+		case helpers.Header:
+			value := request.Header.Get(scheme.Name)
+			return value, value != ""
+		case helpers.Query:
+			query := request.URL.Query()
+			return query.Get(scheme.Name), query.Has(scheme.Name)
+		case helpers.Cookie:
+			cookie, err := request.Cookie(scheme.Name)
+			if err != nil {
+				return "", false
+			}
+			return cookie.Value, true
+		}
+		return "", false
+	case "http":
+		auth := strings.SplitN(strings.TrimSpace(request.Header.Get("Authorization")), helpers.Space, 2)
+		if auth[0] == "" || (scheme.Scheme != "" && !strings.EqualFold(auth[0], scheme.Scheme)) {
+			return "", false
+		}
+		if len(auth) < 2 {
+			return "", true
+		}
+		return strings.TrimSpace(auth[1]), true
+	default:
+		value := request.Header.Get("Authorization")
+		return value, value != ""
+	}
+}
@@ -0,0 +1,254 @@
+// Copyright 2023 Princess B33f Heavy Industries / Dave Shanley
+// SPDX-License-Identifier: MIT
+
+package security
+
+import (
+	"github.com/pb33f/libopenapi"
+	"github.com/pb33f/libopenapi-validator/config"
+	"github.com/stretchr/testify/assert"
+	"net/http"
+	"testing"
+)
+
+var securitySpec = `openapi: 3.1.0
+security:
+  - apiKeyHeader: []
+paths:
+  /burgers:
+    get:
+      responses:
+        '200':
+          description: burgers
+    post:
+      security:
+        - bearerAuth: []
+        - apiKeyQuery: []
+          apiKeyCookie: []
+      responses:
+        '200':
+          description: burger
+    delete:
+      security:
+        - {}
+        - bearerAuth: []
+      responses:
+        '200':
+          description: gone
+  /menu:
+    get:
+      security: []
+      responses:
+        '200':
+          description: menu
+components:
+  securitySchemes:
+    apiKeyHeader:
+      type: apiKey
+      in: header
+      name: X-API-Key
+    apiKeyQuery:
+      type: apiKey
+      in: query
+      name: key
+    apiKeyCookie:
+      type: apiKey
+      in: cookie
+      name: session
+    bearerAuth:
+      type: http
+      scheme: bearer`
+
+func TestNewValidator_Security_Global(t *testing.T) {
+
+	doc, _ := libopenapi.NewDocument([]byte(securitySpec))
+	m, _ := doc.BuildV3Model()
+	v := NewSecurityValidator(&m.Model)
+
+	request, _ := http.NewRequest(http.MethodGet, "https://things.com/burgers", nil)
+
+	valid, errors := v.ValidateSecurity(request)
+
+	assert.False(t, valid)
+	assert.Len(t, errors, 1)
+	assert.Equal(t, "GET request for '/burgers' is missing the credentials required by the security requirements",
+		errors[0].Message)
+	assert.Equal(t, "None of the security requirements for the operation are met, "+
+		"expected header 'X-API-Key' (apiKeyHeader)", errors[0].Reason)
+	assert.Equal(t, 3, errors[0].SpecLine)
+
+	request.Header.Set("X-API-Key", "secret")
+
+	valid, errors = v.ValidateSecurity(request)
+
+	assert.True(t, valid)
+	assert.Len(t, errors, 0)
+}
+
+func TestNewValidator_Security_Alternatives(t *testing.T) {
+
+	doc, _ := libopenapi.NewDocument([]byte(securitySpec))
+	m, _ := doc.BuildV3Model()
+	v := NewSecurityValidator(&m.Model)
+
+	// the global requirement is replaced by the operation.
+	request, _ := http.NewRequest(http.MethodPost, "https://things.com/burgers", nil)
+	request.Header.Set("X-API-Key", "secret")
+
+	valid, errors := v.ValidateSecurity(request)
+
+	assert.False(t, valid)
+	assert.Len(t, errors, 1)
+	assert.Equal(t, "None of the security requirements for the operation are met, "+
+		"expected header 'Authorization' using the 'bearer' scheme (bearerAuth), "+
+		"or cookie 'session' (apiKeyCookie) and query 'key' (apiKeyQuery)", errors[0].Reason)
+
+	// both schemes of a requirement are needed.
+	request, _ = http.NewRequest(http.MethodPost, "https://things.com/burgers?key=secret", nil)
+
+	valid, _ = v.ValidateSecurity(request)
+	assert.False(t, valid)
+
+	request.AddCookie(&http.Cookie{Name: "session", Value: "abc"})
+
+	valid, errors = v.ValidateSecurity(request)
+	assert.True(t, valid)
+	assert.Len(t, errors, 0)
+
+	// the scheme of the Authorization header must match.
+	request, _ = http.NewRequest(http.MethodPost, "https://things.com/burgers", nil)
+	request.Header.Set("Authorization", "Basic dXNlcjpwYXNz")
+
+	valid, _ = v.ValidateSecurity(request)
+	assert.False(t, valid)
+
+	request.Header.Set("Authorization", "Bearer abc.def.ghi")
+
+	valid, errors = v.ValidateSecurity(request)
+	assert.True(t, valid)
+	assert.Len(t, errors, 0)
+}
+
+func TestNewValidator_Security_Optional(t *testing.T) {
+
+	doc, _ := libopenapi.NewDocument([]byte(securitySpec))
+	m, _ := doc.BuildV3Model()
+	v := NewSecurityValidator(&m.Model)
+
+	// an empty requirement makes security optional.
+	request, _ := http.NewRequest(http.MethodDelete, "https://things.com/burgers", nil)
+
+	valid, errors := v.ValidateSecurity(request)
+	assert.True(t, valid)
+	assert.Len(t, errors, 0)
+
+	// an empty security list removes the global requirement.
+	request, _ = http.NewRequest(http.MethodGet, "https://things.com/menu", nil)
+
+	valid, errors = v.ValidateSecurity(request)
+	assert.True(t, valid)
+	assert.Len(t, errors, 0)
+}
+
+func TestNewValidator_Security_Verifier(t *testing.T) {
+
+	doc, _ := libopenapi.NewDocument([]byte(securitySpec))
+	m, _ := doc.BuildV3Model()
+
+	var verified []string
+	v := NewSecurityValidator(&m.Model,
+		config.WithSecurityVerifier("bearerAuth", func(credential string) bool {
+			verified = append(verified, credential)
+			return credential == "abc.def.ghi"
+		}),
+		config.WithSecurityVerifier("apiKeyHeader", func(credential string) bool {
+			verified = append(verified, credential)
+			return credential == "secret"
+		}))
+
+	// the credential is extracted from the location defined by the security scheme.
+	request, _ := http.NewRequest(http.MethodPost, "https://things.com/burgers", nil)
+	request.Header.Set("Authorization", "Bearer abc.def.ghi")
+
+	valid, errors := v.ValidateSecurity(request)
+	assert.True(t, valid)
+	assert.Len(t, errors, 0)
+
+	request.Header.Set("Authorization", "Bearer stolen")
+
+	valid, errors = v.ValidateSecurity(request)
+	assert.False(t, valid)
+	assert.Len(t, errors, 1)
+	assert.Equal(t, "POST request for '/burgers' has credentials that were rejected by the security requirements",
+		errors[0].Message)
+	assert.Equal(t, "Check the credentials sent are correct (and have not expired), or send the credentials "+
+		"required by another of the security requirements of the operation", errors[0].HowToFix)
+	assert.Equal(t, "None of the security requirements for the operation are met, "+
+		"expected a valid header 'Authorization' using the 'bearer' scheme (bearerAuth), "+
+		"or cookie 'session' (apiKeyCookie) and query 'key' (apiKeyQuery)", errors[0].Reason)
+
+	request, _ = http.NewRequest(http.MethodGet, "https://things.com/burgers", nil)
+	request.Header.Set("X-API-Key", "guessed")
+
+	valid, errors = v.ValidateSecurity(request)
+	assert.False(t, valid)
+	assert.Equal(t, "GET request for '/burgers' has credentials that were rejected by the security requirements",
+		errors[0].Message)
+	assert.Equal(t, []string{"abc.def.ghi", "stolen", "guessed"}, verified)
+
+	// without any credentials, the credentials are missing, the verifiers are never called.
+	request, _ = http.NewRequest(http.MethodPost, "https://things.com/burgers", nil)
+
+	valid, errors = v.ValidateSecurity(request)
+	assert.False(t, valid)
+	assert.Equal(t, "POST request for '/burgers' is missing the credentials required by the security requirements",
+		errors[0].Message)
+	assert.Equal(t, []string{"abc.def.ghi", "stolen", "guessed"}, verified)
+}
+
+func TestNewValidator_Security_OAuth2(t *testing.T) {
+
+	spec := `openapi: 3.1.0
+paths:
+  /burgers:
+    get:
+      security:
+        - oauth: [read]
+      responses:
+        '200':
+          description: burgers
+components:
+  securitySchemes:
+    oauth:
+      type: oauth2
+      flows:
+        clientCredentials:
+          tokenUrl: https://things.com/token
+          scopes:
+            read: read burgers`
+
+	doc, _ := libopenapi.NewDocument([]byte(spec))
+	m, _ := doc.BuildV3Model()
+
+	// without a verifier, oauth2 credentials can't be checked.
+	request, _ := http.NewRequest(http.MethodGet, "https://things.com/burgers", nil)
+
+	valid, errors := NewSecurityValidator(&m.Model).ValidateSecurity(request)
+	assert.True(t, valid)
+	assert.Len(t, errors, 0)
+
+	v := NewSecurityValidator(&m.Model, config.WithSecurityVerifier("oauth", func(credential string) bool {
+		return credential == "Bearer token"
+	}))
+
+	valid, errors = v.ValidateSecurity(request)
+	assert.False(t, valid)
+	assert.Equal(t, "None of the security requirements for the operation are met, "+
+		"expected header 'Authorization' (oauth)", errors[0].Reason)
+
+	request.Header.Set("Authorization", "Bearer token")
+
+	valid, errors = v.ValidateSecurity(request)
+	assert.True(t, valid)
+	assert.Len(t, errors, 0)
+}
@@ -4,15 +4,25 @@
 package validator
 
 import (
+	"context"
+	stderrors "errors"
 	"github.com/pb33f/libopenapi"
+	"github.com/pb33f/libopenapi-validator/config"
 	"github.com/pb33f/libopenapi-validator/errors"
+	"github.com/pb33f/libopenapi-validator/helpers"
 	"github.com/pb33f/libopenapi-validator/parameters"
 	"github.com/pb33f/libopenapi-validator/paths"
 	"github.com/pb33f/libopenapi-validator/requests"
 	"github.com/pb33f/libopenapi-validator/responses"
 	"github.com/pb33f/libopenapi-validator/schema_validation"
+	"github.com/pb33f/libopenapi-validator/security"
+	"github.com/pb33f/libopenapi/datamodel"
 	"github.com/pb33f/libopenapi/datamodel/high/v3"
+	"github.com/pb33f/libopenapi/index"
+	"gopkg.in/yaml.v3"
 	"net/http"
+	"net/url"
+	"strings"
 	"sync"
 )
 
@@ -22,26 +32,140 @@ import (
 // Validating *http.Request objects against and OpenAPI 3+ document
 // Validating *http.Response objects against an OpenAPI 3+ document
 // Validating an OpenAPI 3+ document against the OpenAPI 3+ specification
+//
+// A Validator is safe for concurrent use, a single instance can be shared by many goroutines (for example HTTP
+// handlers). No state is kept between validations, other than the cache of compiled schemas.
+//
+// Some checks report warnings rather than errors (see ValidationError.IsWarning). Warnings are returned with the
+// errors, but do not fail validation, so true may be returned with warnings.
+//
+// An error that is found more than once is only returned once (see errors.Deduplicate), use
+// config.WithoutErrorDeduplication to return the raw set of errors.
+//
+// OpenAPI 3.0 and 3.1 documents are validated the same way. The 3.0 forms of a schema are converted into their 3.1
+// (JSON Schema) equivalent before validating, 'nullable: true' adds 'null' to the types of a schema, and
+// 'exclusiveMinimum: true' (or 'exclusiveMaximum') turns 'minimum' (or 'maximum') into an exclusive bound. 'example'
+// and 'examples' are never validated against. There are some exceptions, that follow the rules of each version:
+//
+//   - A numeric 'exclusiveMinimum' (or 'exclusiveMaximum') is not valid in 3.0, it is read as false. Without a
+//     'minimum' (or 'maximum') the schema cannot be compiled, and an error is returned for it, whether it's the
+//     schema of a body, a parameter or a header (see ValidationError.Code).
+//   - A boolean 'exclusiveMinimum' (or 'exclusiveMaximum') is not valid in 3.1, it is read as 0.
 type Validator interface {
 
 	// ValidateHttpRequest will validate an *http.Request object against an OpenAPI 3+ document.
 	// The path, query, cookie and header parameters and request body are validated.
 	ValidateHttpRequest(request *http.Request) (bool, []*errors.ValidationError)
 
+	// ValidateHttpRequestWithContext is the same as ValidateHttpRequest, but will stop validating when the context
+	// is done. If the context is cancelled (or the deadline is exceeded) before validation completes, false is
+	// returned with a single error, which can be identified using ValidationError.IsCancelledError.
+	ValidateHttpRequestWithContext(ctx context.Context, request *http.Request) (bool, []*errors.ValidationError)
+
+	// ValidateHttpRequestAgainst will validate an *http.Request object against the operation with the method
+	// (e.g. GET) and templated path (e.g. /pet/{petId}) supplied, as they are defined in the specification. The
+	// request is not routed, use it when the route has already been matched (for example, by a router), so the
+	// validator and the router cannot disagree. If the operation does not exist in the specification, or the method
+	// of the request is different, false is returned with a single error.
+	ValidateHttpRequestAgainst(request *http.Request, method, templatedPath string) (bool, []*errors.ValidationError)
+
+	// ValidateHttpRequestAgainstWithContext is the same as ValidateHttpRequestAgainst, but will stop validating when
+	// the context is done.
+	ValidateHttpRequestAgainstWithContext(ctx context.Context, request *http.Request,
+		method, templatedPath string) (bool, []*errors.ValidationError)
+
+	// ValidateRequestBody will validate only the body of an *http.Request object against an OpenAPI 3+ document.
+	// The path of the request is matched to locate the operation, then the content type and the request body are
+	// validated. Parameters and security requirements are not. The body is restored once it has been read, so it
+	// can be read again.
+	ValidateRequestBody(request *http.Request) (bool, []*errors.ValidationError)
+
+	// ValidateRequestParameters will validate only the path, query, header and cookie parameters of an *http.Request
+	// object against an OpenAPI 3+ document. The body of the request is never read, so large (or streamed) uploads
+	// are left untouched. Security requirements are not validated.
+	ValidateRequestParameters(request *http.Request) (bool, []*errors.ValidationError)
+
+	// ValidateHttpRequestAgainstWebhook will validate an *http.Request object against the operation of a webhook
+	// (OpenAPI 3.1+), using the name the webhook is defined with in 'webhooks'. Webhooks are not matched using the
+	// path of the request. The query, cookie and header parameters, security requirements and request body are
+	// validated.
+	ValidateHttpRequestAgainstWebhook(name string, request *http.Request) (bool, []*errors.ValidationError)
+
+	// ValidateHttpRequestAgainstWebhookWithContext is the same as ValidateHttpRequestAgainstWebhook, but will stop
+	// validating when the context is done.
+	ValidateHttpRequestAgainstWebhookWithContext(ctx context.Context,
+		name string, request *http.Request) (bool, []*errors.ValidationError)
+
+	// ValidateCallback will validate an *http.Request object (sent by the server to a client) against a callback of
+	// an operation, using the name the callback is defined with in 'callbacks'. The keys of a callback are runtime
+	// expressions, the URL of the request is the resolved URL, and is used to select the matching key. The query,
+	// cookie and header parameters, security requirements and request body are validated.
+	ValidateCallback(operation *v3.Operation, callbackName string, request *http.Request) (bool, []*errors.ValidationError)
+
+	// ValidateCallbackWithContext is the same as ValidateCallback, but will stop validating when the context is done.
+	ValidateCallbackWithContext(ctx context.Context, operation *v3.Operation,
+		callbackName string, request *http.Request) (bool, []*errors.ValidationError)
+
 	// ValidateHttpResponse will an *http.Response object against an OpenAPI 3+ document.
 	// The response body is validated. The request is only used to extract the correct reponse from the spec.
 	ValidateHttpResponse(request *http.Request, response *http.Response) (bool, []*errors.ValidationError)
 
+	// ValidateResponse will validate an *http.Response object against the responses of the operation with the method
+	// (e.g. GET) and templated path (e.g. /pet/{petId}) supplied, without the request it was sent for. Use it when
+	// only responses are seen (for example, passive monitoring). The status code, response headers and body are
+	// validated. If the operation does not exist in the specification, false is returned with a single error.
+	ValidateResponse(method, templatedPath string, response *http.Response) (bool, []*errors.ValidationError)
+
 	// ValidateHttpRequestResponse will validate both the *http.Request and *http.Response objects against an OpenAPI 3+ document.
 	// The path, query, cookie and header parameters and request and response body are validated.
 	ValidateHttpRequestResponse(request *http.Request, response *http.Response) (bool, []*errors.ValidationError)
 
-	// ValidateDocument will validate an OpenAPI 3+ document against the 3.0 or 3.1 OpenAPI 3+ specification
+	// ValidateExchanges will validate a batch of recorded requests, and the responses that were sent for them,
+	// against an OpenAPI 3+ document. A result is returned for every exchange (in the same order), with the matched
+	// operation and every error found. Exchanges are validated concurrently and share the compiled schemas, so
+	// thousands of recorded exchanges can be checked efficiently.
+	ValidateExchanges(exchanges []*Exchange) []*ExchangeResult
+
+	// ValidateExchangesWithContext is the same as ValidateExchanges, but will stop validating when the context is
+	// done. Exchanges that were not validated before the context was done have a single cancelled error.
+	ValidateExchangesWithContext(ctx context.Context, exchanges []*Exchange) []*ExchangeResult
+
+	// FindPath will locate the PathItem in the OpenAPI 3+ document that matches the *http.Request. The matched
+	// PathItem, the templated path (e.g. /pet/{petId}) and any routing errors are returned. This is the same lookup
+	// performed when validating requests and responses. The operation can be extracted from the PathItem using
+	// helpers.ExtractOperation.
+	FindPath(request *http.Request) (*v3.PathItem, string, []*errors.ValidationError)
+
+	// WarmSchemaCache will render and compile every parameter, header, request body and response body schema in the
+	// document up front, so the first request (or response) for an operation is not slowed down by compiling the
+	// schemas. Compiled schemas are cached and shared by all validations, calling this is optional.
+	WarmSchemaCache()
+
+	// OperationValidator will return an OperationValidator for the operation with the method (e.g. GET) and the
+	// templated path (e.g. /pet/{petId}) supplied. Requests and responses validated using it are not routed, which
+	// saves looking up the path for every request when the route is already known. An error is returned if the
+	// operation does not exist in the specification.
+	OperationValidator(method, templatedPath string) (OperationValidator, error)
+
+	// ValidateDocument will validate an OpenAPI 3+ document against the 3.0 or 3.1 OpenAPI 3+ specification, and
+	// check the document can be used for validation: every schema must resolve and compile, and every parameter
+	// must use a style that is allowed for its location (see schema_validation.ValidateOpenAPIModel). Call it at
+	// startup to fail fast, rather than on the first matching request. A Validator created from a model (using
+	// NewValidatorFromV3Model) has no document to check against the specification, only the model is checked.
 	ValidateDocument() (bool, []*errors.ValidationError)
 
+	// ValidateExamples will validate every example declared by the parameters, request bodies and responses of the
+	// document against their schema, both 'example' and each of the named 'examples' (see
+	// schema_validation.ValidateOpenAPIExamples). Use it to check the quality of a specification before it's
+	// published, no requests or responses are validated.
+	ValidateExamples() []*errors.ValidationError
+
 	// GetParameterValidator will return a parameters.ParameterValidator instance used to validate parameters
 	GetParameterValidator() parameters.ParameterValidator
 
+	// GetSecurityValidator will return a security.SecurityValidator instance used to validate security requirements
+	GetSecurityValidator() security.SecurityValidator
+
 	// GetRequestBodyValidator will return a parameters.RequestBodyValidator instance used to validate request bodies
 	GetRequestBodyValidator() requests.RequestBodyValidator
 
@@ -49,39 +173,120 @@ type Validator interface {
 	GetResponseBodyValidator() responses.ResponseBodyValidator
 }
 
-// NewValidator will create a new Validator from an OpenAPI 3+ document
-func NewValidator(document libopenapi.Document) (Validator, []error) {
+// NewValidator will create a new Validator from an OpenAPI 3+ document. Options can be supplied to change the
+// default validation behavior, for example:
+//
+//	NewValidator(document, config.WithStrictContentType(), config.WithoutResponseBodyValidation())
+//
+// External references are resolved using config.WithReferenceBasePath (for files) and config.WithRemoteReferences
+// (for HTTP, which is denied by default). These options are applied to the document before the model is built, so
+// they have no effect when the model of the document has already been built. A reference that cannot be resolved
+// is returned as an *errors.ValidationError.
+func NewValidator(document libopenapi.Document, opts ...config.Option) (Validator, []error) {
+	configureReferences(document, config.NewValidationOptions(opts...))
 	m, errs := document.BuildV3Model()
 	if errs != nil {
-		return nil, errs
+		return nil, unresolvedReferenceErrors(errs)
 	}
-	v := NewValidatorFromV3Model(&m.Model)
+	v := NewValidatorFromV3Model(&m.Model, opts...)
 	v.(*validator).document = document
 	return v, nil
 }
 
-// NewValidatorFromV3Model will create a new Validator from an OpenAPI Model
-func NewValidatorFromV3Model(m *v3.Document) Validator {
+// configureReferences will configure the document to resolve file and remote references, when any of the reference
+// options are set. The existing configuration of the document is kept, and only the reference settings change.
+func configureReferences(document libopenapi.Document, options *config.ValidationOptions) {
+	if options.ReferenceBasePath == "" && !options.AllowRemoteReferences {
+		return
+	}
+	configuration := &datamodel.DocumentConfiguration{}
+	if existing := document.GetConfiguration(); existing != nil {
+		*configuration = *existing
+	}
+	if options.ReferenceBasePath != "" {
+		configuration.BasePath = options.ReferenceBasePath
+		configuration.AllowFileReferences = true
+	}
+	if options.AllowRemoteReferences {
+		configuration.AllowRemoteReferences = true
+		if options.RemoteReferenceBaseURL != nil {
+			configuration.BaseURL = options.RemoteReferenceBaseURL
+		}
+		if options.RemoteReferenceHandler != nil {
+			configuration.RemoteURLHandler = options.RemoteReferenceHandler
+		}
+	}
+	document.SetConfiguration(configuration)
+}
+
+// unresolvedReferenceErrors will replace the errors of references that cannot be resolved with a ValidationError
+// that names the reference, other errors are returned as they are.
+func unresolvedReferenceErrors(errs []error) []error {
+	replaced := make([]error, 0, len(errs))
+	for _, err := range errs {
+		var node *yaml.Node
+		var indexingErr *index.IndexingError
+		var resolvingErr *index.ResolvingError
+		if stderrors.As(err, &indexingErr) {
+			node = indexingErr.Node
+		} else if stderrors.As(err, &resolvingErr) && resolvingErr.CircularReference == nil {
+			node = resolvingErr.Node
+		}
+		if reference := referenceValue(node); reference != "" {
+			replaced = append(replaced, errors.DocumentReferenceUnresolved(reference, node, err))
+			continue
+		}
+		replaced = append(replaced, err)
+	}
+	return replaced
+}
+
+// referenceValue returns the value of the $ref held by a node, or an empty string if it does not hold one.
+func referenceValue(node *yaml.Node) string {
+	if node == nil {
+		return ""
+	}
+	for i := 0; i+1 < len(node.Content); i += 2 {
+		if node.Content[i].Value == "$ref" {
+			return node.Content[i+1].Value
+		}
+	}
+	return ""
+}
+
+// NewValidatorFromV3Model will create a new Validator from an OpenAPI Model. Options can be supplied to change
+// the default validation behavior.
+func NewValidatorFromV3Model(m *v3.Document, opts ...config.Option) Validator {
+	options := config.NewValidationOptions(opts...)
+
 	// create a new parameter validator
-	paramValidator := parameters.NewParameterValidator(m)
+	paramValidator := parameters.NewParameterValidator(m, opts...)
+
+	// create a new security validator
+	securityValidator := security.NewSecurityValidator(m, opts...)
 
 	// create a new request body validator
-	reqBodyValidator := requests.NewRequestBodyValidator(m)
+	reqBodyValidator := requests.NewRequestBodyValidator(m, opts...)
 
 	// create a response body validator
-	respBodyValidator := responses.NewResponseBodyValidator(m)
+	respBodyValidator := responses.NewResponseBodyValidator(m, opts...)
 
 	return &validator{
+		options:           options,
 		v3Model:           m,
 		requestValidator:  reqBodyValidator,
 		responseValidator: respBodyValidator,
 		paramValidator:    paramValidator,
+		securityValidator: securityValidator,
 	}
 }
 
 func (v *validator) GetParameterValidator() parameters.ParameterValidator {
 	return v.paramValidator
 }
+func (v *validator) GetSecurityValidator() security.SecurityValidator {
+	return v.securityValidator
+}
 func (v *validator) GetRequestBodyValidator() requests.RequestBodyValidator {
 	return v.requestValidator
 }
@@ -89,100 +294,247 @@ func (v *validator) GetResponseBodyValidator() responses.ResponseBodyValidator {
 	return v.responseValidator
 }
 
+func (v *validator) FindPath(request *http.Request) (*v3.PathItem, string, []*errors.ValidationError) {
+	pathItem, errs, pathValue := paths.FindPathWithOptions(request, v.v3Model, v.options)
+	return pathItem, pathValue, errs
+}
+
+func (v *validator) WarmSchemaCache() {
+	v.paramValidator.WarmSchemaCache()
+	v.requestValidator.WarmSchemaCache()
+	v.responseValidator.WarmSchemaCache()
+}
+
 func (v *validator) ValidateDocument() (bool, []*errors.ValidationError) {
-	return schema_validation.ValidateOpenAPIDocument(v.document)
+	var validationErrors []*errors.ValidationError
+	if v.document != nil {
+		_, validationErrors = schema_validation.ValidateOpenAPIDocument(v.document)
+	}
+	_, modelErrors := schema_validation.ValidateOpenAPIModel(v.v3Model, config.WithExistingOpts(v.options))
+	validationErrors = append(validationErrors, modelErrors...)
+	if len(validationErrors) > 0 {
+		return false, validationErrors
+	}
+	return true, nil
+}
+
+func (v *validator) ValidateExamples() []*errors.ValidationError {
+	_, validationErrors := schema_validation.ValidateOpenAPIExamples(v.v3Model, config.WithExistingOpts(v.options))
+	return validationErrors
 }
 
 func (v *validator) ValidateHttpResponse(
 	request *http.Request,
 	response *http.Response) (bool, []*errors.ValidationError) {
 
-	var pathItem *v3.PathItem
-	var pathValue string
-	var errs []*errors.ValidationError
-
-	pathItem, errs, pathValue = paths.FindPath(request, v.v3Model)
+	pathItem, errs, pathValue := paths.FindPathWithOptions(request, v.v3Model, v.options)
 	if pathItem == nil || errs != nil {
-		v.errors = errs
 		return false, errs
 	}
-	v.foundPath = pathItem
-	v.foundPathValue = pathValue
-
-	responseBodyValidator := v.responseValidator
-	responseBodyValidator.SetPathItem(pathItem, pathValue)
 
 	// validate response
-	_, responseErrors := responseBodyValidator.ValidateResponseBody(request, response)
+	_, responseErrors := v.responseValidator.ValidateResponseBodyWithPathItem(request, response, pathItem, pathValue)
+	responseErrors = v.prepareErrors(responseErrors)
 
-	if len(responseErrors) > 0 {
+	if errors.HasErrors(responseErrors) {
 		return false, responseErrors
 	}
-	v.foundPath = nil
-	v.foundPathValue = ""
-	return true, nil
+	return true, responseErrors
+}
+
+func (v *validator) ValidateResponse(
+	method, templatedPath string,
+	response *http.Response) (bool, []*errors.ValidationError) {
+
+	method = strings.ToUpper(method)
+	pathItem, operation := v.findOperation(method, templatedPath)
+	if operation == nil {
+		return false, []*errors.ValidationError{errors.OperationNotFound(method, templatedPath)}
+	}
+
+	// the request is not known, so it's stood in for by the method and templated path of the operation.
+	request := &http.Request{Method: method, URL: &url.URL{Path: templatedPath}, Header: http.Header{}}
+	_, responseErrors := v.responseValidator.ValidateResponseBodyWithPathItem(request, response, pathItem, templatedPath)
+	responseErrors = v.prepareErrors(responseErrors)
+	return !errors.HasErrors(responseErrors), responseErrors
 }
 
 func (v *validator) ValidateHttpRequestResponse(
 	request *http.Request,
 	response *http.Response) (bool, []*errors.ValidationError) {
 
-	var pathItem *v3.PathItem
-	var pathValue string
-	var errs []*errors.ValidationError
-
-	pathItem, errs, pathValue = paths.FindPath(request, v.v3Model)
+	pathItem, errs, pathValue := paths.FindPathWithOptions(request, v.v3Model, v.options)
 	if pathItem == nil || errs != nil {
-		v.errors = errs
 		return false, errs
 	}
-	v.foundPath = pathItem
-	v.foundPathValue = pathValue
-
-	responseBodyValidator := v.responseValidator
-	responseBodyValidator.SetPathItem(pathItem, pathValue)
 
-	// validate request and response
-	_, requestErrors := v.ValidateHttpRequest(request)
-	_, responseErrors := responseBodyValidator.ValidateResponseBody(request, response)
+	// validate request and response, when failing fast the response is not validated if the request failed.
+	valid, requestErrors := v.validateHttpRequest(context.Background(), request, pathItem, pathValue)
+	if !valid && v.options.FailFast {
+		return false, requestErrors
+	}
+	_, responseErrors := v.responseValidator.ValidateResponseBodyWithPathItem(request, response, pathItem, pathValue)
 
-	if len(requestErrors) > 0 || len(responseErrors) > 0 {
-		return false, append(requestErrors, responseErrors...)
+	validationErrors := v.prepareErrors(append(requestErrors, responseErrors...))
+	if errors.HasErrors(validationErrors) {
+		return false, validationErrors
 	}
-	v.foundPath = nil
-	v.foundPathValue = ""
-	return true, nil
+	return true, validationErrors
 }
 
 func (v *validator) ValidateHttpRequest(request *http.Request) (bool, []*errors.ValidationError) {
+	return v.ValidateHttpRequestWithContext(context.Background(), request)
+}
+
+func (v *validator) ValidateHttpRequestWithContext(
+	ctx context.Context,
+	request *http.Request) (bool, []*errors.ValidationError) {
+
+	if ctx.Err() != nil {
+		return false, []*errors.ValidationError{errors.ValidationCancelled(ctx)}
+	}
 
 	// find path
-	var pathItem *v3.PathItem
-	var pathValue string
-	var errs []*errors.ValidationError
-	if v.foundPath == nil {
-		pathItem, errs, pathValue = paths.FindPath(request, v.v3Model)
-		if pathItem == nil || errs != nil {
-			v.errors = errs
-			return false, errs
+	pathItem, errs, pathValue := paths.FindPathWithOptions(request, v.v3Model, v.options)
+	if pathItem == nil || errs != nil {
+		return false, errs
+	}
+	return v.validateHttpRequest(ctx, request, pathItem, pathValue)
+}
+
+func (v *validator) ValidateHttpRequestAgainst(
+	request *http.Request,
+	method, templatedPath string) (bool, []*errors.ValidationError) {
+	return v.ValidateHttpRequestAgainstWithContext(context.Background(), request, method, templatedPath)
+}
+
+func (v *validator) ValidateHttpRequestAgainstWithContext(
+	ctx context.Context,
+	request *http.Request,
+	method, templatedPath string) (bool, []*errors.ValidationError) {
+
+	if ctx.Err() != nil {
+		return false, []*errors.ValidationError{errors.ValidationCancelled(ctx)}
+	}
+
+	// the route is already known, so the operation is located using the template rather than the request path.
+	method = strings.ToUpper(method)
+	pathItem, operation := v.findOperation(method, templatedPath)
+	if operation == nil {
+		return false, []*errors.ValidationError{errors.OperationNotFound(method, templatedPath)}
+	}
+	if request.Method != method {
+		return false, []*errors.ValidationError{errors.OperationMethodMismatch(method, templatedPath, request)}
+	}
+	return v.validateHttpRequest(ctx, request, pathItem, templatedPath)
+}
+
+func (v *validator) ValidateRequestBody(request *http.Request) (bool, []*errors.ValidationError) {
+	pathItem, errs, pathValue := paths.FindPathWithOptions(request, v.v3Model, v.options)
+	if pathItem == nil || errs != nil {
+		return false, errs
+	}
+	_, validationErrors := v.requestValidator.ValidateRequestBodyWithPathItem(context.Background(),
+		request, pathItem, pathValue)
+	validationErrors = v.prepareErrors(validationErrors)
+	return !errors.HasErrors(validationErrors), validationErrors
+}
+
+func (v *validator) ValidateRequestParameters(request *http.Request) (bool, []*errors.ValidationError) {
+	pathItem, errs, pathValue := paths.FindPathWithOptions(request, v.v3Model, v.options)
+	if pathItem == nil || errs != nil {
+		return false, errs
+	}
+
+	var validationErrors []*errors.ValidationError
+	for _, validate := range v.parameterStages() {
+		valid, paramErrs := validate(context.Background(), request, pathItem, pathValue)
+		validationErrors = append(validationErrors, paramErrs...)
+		if !valid && v.options.FailFast {
+			break
 		}
-		v.foundPath = pathItem
-		v.foundPathValue = pathValue
-	} else {
-		pathItem = v.foundPath
-		pathValue = v.foundPathValue
 	}
+	v.paramValidator.ApplyQueryDefaults(request, pathItem)
+	validationErrors = v.prepareErrors(validationErrors)
+	if errors.HasErrors(validationErrors) {
+		return false, validationErrors
+	}
+	return true, validationErrors
+}
 
-	// create a new parameter validator
-	paramValidator := v.paramValidator
-	paramValidator.SetPathItem(pathItem, pathValue)
+func (v *validator) ValidateHttpRequestAgainstWebhook(
+	name string,
+	request *http.Request) (bool, []*errors.ValidationError) {
+	return v.ValidateHttpRequestAgainstWebhookWithContext(context.Background(), name, request)
+}
+
+func (v *validator) ValidateHttpRequestAgainstWebhookWithContext(
+	ctx context.Context,
+	name string,
+	request *http.Request) (bool, []*errors.ValidationError) {
+
+	if ctx.Err() != nil {
+		return false, []*errors.ValidationError{errors.ValidationCancelled(ctx)}
+	}
+
+	// webhooks are located by name, there is no path to match.
+	pathItem := v.v3Model.Webhooks[name]
+	if pathItem == nil || helpers.ExtractOperation(request, pathItem) == nil {
+		return false, []*errors.ValidationError{errors.WebhookNotFound(name, request)}
+	}
+	return v.validateHttpRequest(ctx, request, pathItem, "")
+}
+
+func (v *validator) ValidateCallback(
+	operation *v3.Operation,
+	callbackName string,
+	request *http.Request) (bool, []*errors.ValidationError) {
+	return v.ValidateCallbackWithContext(context.Background(), operation, callbackName, request)
+}
+
+func (v *validator) ValidateCallbackWithContext(
+	ctx context.Context,
+	operation *v3.Operation,
+	callbackName string,
+	request *http.Request) (bool, []*errors.ValidationError) {
+
+	if ctx.Err() != nil {
+		return false, []*errors.ValidationError{errors.ValidationCancelled(ctx)}
+	}
+
+	// callbacks are located by name, and then by matching the resolved URL against the runtime expressions.
+	var pathItem *v3.PathItem
+	if operation != nil && operation.Callbacks[callbackName] != nil {
+		pathItem = helpers.FindCallbackPathItem(operation.Callbacks[callbackName], request.URL.String())
+	}
+	if pathItem == nil || helpers.ExtractOperation(request, pathItem) == nil {
+		return false, []*errors.ValidationError{errors.CallbackNotFound(callbackName, request)}
+	}
+	return v.validateHttpRequest(ctx, request, pathItem, "")
+}
+
+// validateHttpRequest will validate the parameters and body of the request against the pathItem. All validation state
+// is kept local to the call, so the validator can be shared across goroutines.
+func (v *validator) validateHttpRequest(
+	ctx context.Context,
+	request *http.Request,
+	pathItem *v3.PathItem,
+	pathValue string) (bool, []*errors.ValidationError) {
 
-	// create a new request body validator
 	reqBodyValidator := v.requestValidator
-	reqBodyValidator.SetPathItem(pathItem, pathValue)
 
-	// create some channels to handle async validation
-	doneChan := make(chan bool)
+	v.options.Debug("validating request", "method", request.Method, "template", pathValue,
+		"stages", []string{"path parameters", "cookies", "headers", "query parameters", "security", "request body"})
+
+	if v.options.FailFast {
+		valid, validationErrors := v.validateHttpRequestFailFast(ctx, request, pathItem, pathValue)
+		v.paramValidator.ApplyQueryDefaults(request, pathItem)
+		return valid, validationErrors
+	}
+
+	// create some channels to handle async validation, done is buffered so nothing is left blocked
+	// if the context is done before the validations complete.
+	doneChan := make(chan bool, 1)
 	errChan := make(chan []*errors.ValidationError)
 	controlChan := make(chan bool)
 
@@ -193,12 +545,7 @@ func (v *validator) ValidateHttpRequest(request *http.Request) (bool, []*errors.
 		paramFunctionControlChan := make(chan bool)
 		var paramValidationErrors []*errors.ValidationError
 
-		validations := []validationFunction{
-			paramValidator.ValidatePathParams,
-			paramValidator.ValidateCookieParams,
-			paramValidator.ValidateHeaderParams,
-			paramValidator.ValidateQueryParams,
-		}
+		validations := append(v.parameterStages(), v.securityValidator.ValidateSecurityWithPathItem)
 
 		// listen for validation errors on parameters. everything will run async.
 		paramListener := func(control chan bool, errorChan chan []*errors.ValidationError) {
@@ -221,8 +568,8 @@ func (v *validator) ValidateHttpRequest(request *http.Request) (bool, []*errors.
 			control chan bool,
 			errorChan chan []*errors.ValidationError,
 			validatorFunc validationFunction) {
-			valid, pErrs := validatorFunc(request)
-			if !valid {
+			_, pErrs := validatorFunc(ctx, request, pathItem, pathValue)
+			if len(pErrs) > 0 {
 				errorChan <- pErrs
 			}
 			control <- true
@@ -243,8 +590,8 @@ func (v *validator) ValidateHttpRequest(request *http.Request) (bool, []*errors.
 	}
 
 	requestBodyValidationFunc := func(control chan bool, errorChan chan []*errors.ValidationError) {
-		valid, pErrs := reqBodyValidator.ValidateRequestBody(request)
-		if !valid {
+		_, pErrs := reqBodyValidator.ValidateRequestBodyWithPathItem(ctx, request, pathItem, pathValue)
+		if len(pErrs) > 0 {
 			errorChan <- pErrs
 		}
 		control <- true
@@ -266,25 +613,116 @@ func (v *validator) ValidateHttpRequest(request *http.Request) (bool, []*errors.
 		go asyncFunctions[i](controlChan, errChan)
 	}
 
-	// wait for all the validations to complete
-	<-doneChan
-	v.foundPathValue = ""
-	v.foundPath = nil
+	// wait for all the validations to complete, or for the context to be done.
+	select {
+	case <-doneChan:
+	case <-ctx.Done():
+		return false, []*errors.ValidationError{errors.ValidationCancelled(ctx)}
+	}
 	if len(validationErrors) > 0 {
-		return false, validationErrors
+		// the context was done part way through, the other errors are incomplete.
+		for _, e := range validationErrors {
+			if e.IsCancelledError() {
+				return false, []*errors.ValidationError{e}
+			}
+		}
 	}
-	return true, nil
+	// every validation has finished with the request, so the defaults of missing query parameters can be added.
+	v.paramValidator.ApplyQueryDefaults(request, pathItem)
+
+	validationErrors = append(validationErrors, v.operationDeprecated(request, pathItem, pathValue)...)
+	validationErrors = v.prepareErrors(validationErrors)
+	valid := !errors.HasErrors(validationErrors)
+	v.options.Debug("validated request", "method", request.Method, "template", pathValue,
+		"valid", valid, "errors", len(validationErrors))
+	return valid, validationErrors
+}
+
+// validateHttpRequestFailFast will validate each stage of the request in turn (path parameters, cookies, headers,
+// query parameters, security and then the request body), and stop at the first stage that fails. Only the first
+// error of that stage is returned.
+func (v *validator) validateHttpRequestFailFast(
+	ctx context.Context,
+	request *http.Request,
+	pathItem *v3.PathItem,
+	pathValue string) (bool, []*errors.ValidationError) {
+
+	var warnings []*errors.ValidationError
+	stages := append(v.parameterStages(),
+		v.securityValidator.ValidateSecurityWithPathItem,
+		v.requestValidator.ValidateRequestBodyWithPathItem)
+	for _, validate := range stages {
+		_, stageErrors := validate(ctx, request, pathItem, pathValue)
+		if first := firstError(stageErrors); first != nil {
+			v.options.Debug("validated request, failing fast", "method", request.Method, "template", pathValue)
+			return false, []*errors.ValidationError{first}
+		}
+		warnings = append(warnings, stageErrors...)
+	}
+	warnings = append(warnings, v.operationDeprecated(request, pathItem, pathValue)...)
+	return true, v.prepareErrors(warnings)
+}
+
+// parameterStages returns the validations of the parameters of a request (path parameters, cookies, headers and then
+// query parameters). Every entry point validates them in this order, so when failing fast the same error is returned
+// whichever entry point is used.
+func (v *validator) parameterStages() []validationFunction {
+	return []validationFunction{
+		v.paramValidator.ValidatePathParamsWithPathItem,
+		v.paramValidator.ValidateCookieParamsWithPathItem,
+		v.paramValidator.ValidateHeaderParamsWithPathItem,
+		v.paramValidator.ValidateQueryParamsWithPathItem,
+	}
+}
+
+// operationDeprecated returns a warning when the operation the request is for is deprecated, and deprecation
+// warnings have been asked for.
+func (v *validator) operationDeprecated(request *http.Request, pathItem *v3.PathItem,
+	pathValue string) []*errors.ValidationError {
+	if !v.options.DeprecationWarnings {
+		return nil
+	}
+	operation := helpers.ExtractOperation(request, pathItem)
+	if operation == nil || operation.Deprecated == nil || !*operation.Deprecated {
+		return nil
+	}
+	deprecated := []*errors.ValidationError{errors.OperationDeprecated(operation, request, pathValue)}
+	errors.SetOperation(deprecated, request, pathItem, pathValue)
+	return deprecated
+}
+
+// prepareErrors will remove duplicate errors, unless the raw set of errors has been asked for. When validation fails
+// fast, only the first error is kept (warnings are dropped with the rest).
+func (v *validator) prepareErrors(validationErrors []*errors.ValidationError) []*errors.ValidationError {
+	if v.options.FailFast {
+		if first := firstError(validationErrors); first != nil {
+			return []*errors.ValidationError{first}
+		}
+	}
+	if v.options.SkipErrorDeduplication {
+		return validationErrors
+	}
+	return errors.Deduplicate(validationErrors)
+}
+
+// firstError returns the first of the errors that is not a warning, or nil if there are none.
+func firstError(validationErrors []*errors.ValidationError) *errors.ValidationError {
+	for _, validationError := range validationErrors {
+		if validationError != nil && !validationError.IsWarning() {
+			return validationError
+		}
+	}
+	return nil
 }
 
 type validator struct {
+	options           *config.ValidationOptions
 	v3Model           *v3.Document
 	document          libopenapi.Document
-	foundPath         *v3.PathItem
-	foundPathValue    string
 	paramValidator    parameters.ParameterValidator
+	securityValidator security.SecurityValidator
 	requestValidator  requests.RequestBodyValidator
 	responseValidator responses.ResponseBodyValidator
-	errors            []*errors.ValidationError
 }
 
 var validationLock sync.Mutex
@@ -310,5 +748,6 @@ func runValidation(control, doneChan chan bool,
 	}
 }
 
-type validationFunction func(request *http.Request) (bool, []*errors.ValidationError)
+type validationFunction func(ctx context.Context, request *http.Request, pathItem *v3.PathItem,
+	pathValue string) (bool, []*errors.ValidationError)
 type validationFunctionAsync func(control chan bool, errorChan chan []*errors.ValidationError)
@@ -83,7 +83,7 @@ func ExampleNewValidator_validateHttpRequest() {
 			fmt.Printf("Type: %s, Failure: %s\n", e.ValidationType, e.Message)
 		}
 	}
-	// Output: Type: path, Failure: GET Path '/pet/NotAValidPetId' not found
+	// Output: Type: parameter, Failure: Path parameter 'petId' is not a valid integer
 }
 
 func ExampleNewValidator_validateHttpRequestResponse() {
@@ -5,14 +5,23 @@ package validator
 
 import (
 	"bytes"
+	"context"
 	"encoding/json"
+	stderrors "errors"
+	"fmt"
 	"github.com/pb33f/libopenapi"
+	"github.com/pb33f/libopenapi-validator/config"
+	"github.com/pb33f/libopenapi-validator/errors"
 	"github.com/pb33f/libopenapi-validator/helpers"
 	"github.com/stretchr/testify/assert"
+	"io"
+	"log/slog"
 	"net/http"
 	"net/http/httptest"
 	"os"
+	"sync"
 	"testing"
+	"time"
 )
 
 func TestNewValidator(t *testing.T) {
@@ -51,6 +60,41 @@ func TestNewValidator_ValidateDocument(t *testing.T) {
 	assert.Len(t, errs, 0)
 }
 
+func TestNewValidator_ValidateDocument_Model(t *testing.T) {
+
+	spec := `openapi: 3.1.0
+info:
+  title: burgers
+  version: 1.0.0
+paths:
+  /burgers:
+    get:
+      parameters:
+        - name: X-Sauce
+          in: header
+          schema:
+            type: string
+            pattern: "[a-z"
+      responses:
+        "200":
+          description: burgers`
+
+	doc, _ := libopenapi.NewDocument([]byte(spec))
+	v, _ := NewValidator(doc)
+	valid, errs := v.ValidateDocument()
+	assert.False(t, valid)
+	assert.Len(t, errs, 1)
+	assert.Equal(t, "The header parameter 'X-Sauce' schema of the GET operation for '/burgers' cannot be compiled",
+		errs[0].Message)
+
+	// a validator created from a model only checks the model.
+	m, _ := doc.BuildV3Model()
+	v = NewValidatorFromV3Model(&m.Model)
+	valid, errs = v.ValidateDocument()
+	assert.False(t, valid)
+	assert.Len(t, errs, 1)
+}
+
 func TestNewValidator_BadDoc(t *testing.T) {
 
 	spec := `swagger: 2.0`
@@ -764,7 +808,7 @@ func TestNewValidator_PetStore_PetGet200_PathNotFound(t *testing.T) {
 
 	assert.False(t, valid)
 	assert.Len(t, errors, 1)
-	assert.Equal(t, "GET Path '/pet/IamNotANumber' not found", errors[0].Message)
+	assert.Equal(t, "Path parameter 'petId' is not a valid integer", errors[0].Message)
 }
 
 func TestNewValidator_PetStore_PetGet200(t *testing.T) {
@@ -1134,3 +1178,1742 @@ func TestNewValidator_PetStore_PetFindByStatusGet200_Valid_responseOnly(t *testi
 	assert.True(t, valid)
 	assert.Len(t, errors, 0)
 }
+
+func TestNewValidator_FindPath(t *testing.T) {
+
+	spec := `openapi: 3.1.0
+paths:
+  /burgers/{burgerId}:
+    get:
+      operationId: getBurger
+      parameters:
+        - name: burgerId
+          in: path
+          required: true
+          schema:
+            type: integer`
+
+	doc, _ := libopenapi.NewDocument([]byte(spec))
+
+	v, _ := NewValidator(doc)
+
+	request, _ := http.NewRequest(http.MethodGet, "https://things.com/burgers/1234", nil)
+
+	pathItem, pathValue, errs := v.FindPath(request)
+	assert.NotNil(t, pathItem)
+	assert.Len(t, errs, 0)
+	assert.Equal(t, "/burgers/{burgerId}", pathValue)
+	assert.Equal(t, "getBurger", helpers.ExtractOperation(request, pathItem).OperationId)
+
+	request, _ = http.NewRequest(http.MethodPost, "https://things.com/burgers/1234", nil)
+
+	pathItem, pathValue, errs = v.FindPath(request)
+	assert.Nil(t, pathItem)
+	assert.Empty(t, pathValue)
+	assert.Len(t, errs, 1)
+	assert.Equal(t, "POST Path '/burgers/1234' not found", errs[0].Message)
+}
+
+func TestNewValidator_WithOptions_SkipResponseBodyValidation(t *testing.T) {
+
+	spec := `openapi: 3.1.0
+paths:
+  /burgers/createBurger:
+    post:
+      responses:
+        '200':
+          content:
+            application/json:
+              schema:
+                type: object
+                properties:
+                  patties:
+                    type: integer`
+
+	doc, _ := libopenapi.NewDocument([]byte(spec))
+
+	v, _ := NewValidator(doc, config.WithoutResponseBodyValidation())
+
+	bodyBytes, _ := json.Marshal(map[string]interface{}{"patties": "two"})
+
+	request, _ := http.NewRequest(http.MethodPost, "https://things.com/burgers/createBurger", nil)
+
+	res := httptest.NewRecorder()
+	handler := func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set(helpers.ContentTypeHeader, helpers.JSONContentType)
+		w.WriteHeader(http.StatusOK)
+		_, _ = w.Write(bodyBytes)
+	}
+	handler(res, request)
+
+	valid, errors := v.ValidateHttpResponse(request, res.Result())
+
+	assert.True(t, valid)
+	assert.Len(t, errors, 0)
+}
+
+func TestNewValidator_ValidateHttpRequestWithContext(t *testing.T) {
+
+	spec := `openapi: 3.1.0
+paths:
+  /burgers/createBurger:
+    post:
+      parameters:
+        - name: cheese
+          in: query
+          schema:
+            type: boolean
+      requestBody:
+        content:
+          application/json:
+            schema:
+              type: object
+              properties:
+                name:
+                  type: string`
+
+	doc, _ := libopenapi.NewDocument([]byte(spec))
+
+	v, _ := NewValidator(doc)
+
+	newRequest := func() *http.Request {
+		request, _ := http.NewRequest(http.MethodPost, "https://things.com/burgers/createBurger?cheese=true",
+			bytes.NewBufferString(`{"name":"Big Mac"}`))
+		request.Header.Set("Content-Type", "application/json")
+		return request
+	}
+
+	valid, errs := v.ValidateHttpRequestWithContext(context.Background(), newRequest())
+	assert.True(t, valid)
+	assert.Len(t, errs, 0)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	valid, errs = v.ValidateHttpRequestWithContext(ctx, newRequest())
+	assert.False(t, valid)
+	assert.Len(t, errs, 1)
+	assert.True(t, errs[0].IsCancelledError())
+
+	// a cancelled context is not a validation failure of the parameters or the body.
+	valid, errs = v.GetParameterValidator().ValidateQueryParamsWithContext(ctx, newRequest())
+	assert.False(t, valid)
+	assert.True(t, errs[0].IsCancelledError())
+
+	valid, errs = v.GetRequestBodyValidator().ValidateRequestBodyWithContext(ctx, newRequest())
+	assert.False(t, valid)
+	assert.True(t, errs[0].IsCancelledError())
+}
+
+func TestNewValidator_ConcurrentValidation(t *testing.T) {
+
+	spec := `openapi: 3.1.0
+paths:
+  /burgers/{burgerId}:
+    put:
+      parameters:
+        - name: burgerId
+          in: path
+          required: true
+          schema:
+            type: integer
+        - name: cheese
+          in: query
+          schema:
+            type: boolean
+        - name: X-Chef
+          in: header
+          required: true
+          schema:
+            type: string
+      requestBody:
+        content:
+          application/json:
+            schema:
+              type: object
+              required: [name]
+              properties:
+                name:
+                  type: string
+      responses:
+        '200':
+          content:
+            application/json:
+              schema:
+                type: object
+                required: [name]
+                properties:
+                  name:
+                    type: string`
+
+	doc, _ := libopenapi.NewDocument([]byte(spec))
+
+	v, _ := NewValidator(doc)
+
+	// half the requests are valid, half are not, none of them should see the results of another.
+	var wg sync.WaitGroup
+	for i := 0; i < 100; i++ {
+		wg.Add(1)
+		go func(valid bool) {
+			defer wg.Done()
+
+			url := "https://things.com/burgers/1?cheese=true"
+			body := `{"name": "Big Mac"}`
+			if !valid {
+				url = "https://things.com/burgers/1?cheese=maybe"
+				body = `{"name": 1}`
+			}
+			request, _ := http.NewRequest(http.MethodPut, url, bytes.NewBufferString(body))
+			request.Header.Set("Content-Type", "application/json")
+			request.Header.Set("X-Chef", "Ronald")
+
+			res := httptest.NewRecorder()
+			res.Header().Set("Content-Type", "application/json")
+			res.WriteHeader(http.StatusOK)
+			_, _ = res.WriteString(body)
+
+			ok, errs := v.ValidateHttpRequestResponse(request, res.Result())
+			assert.Equal(t, valid, ok)
+			if valid {
+				assert.Len(t, errs, 0)
+			} else {
+				assert.Len(t, errs, 3)
+			}
+		}(i%2 == 0)
+	}
+	wg.Wait()
+}
+
+func TestNewValidator_ProblemJSON(t *testing.T) {
+
+	spec := `openapi: 3.1.0
+paths:
+  /burgers/createBurger:
+    post:
+      requestBody:
+        content:
+          application/json:
+            schema:
+              type: object
+              properties:
+                name:
+                  type: string
+                patties:
+                  type: integer
+      responses:
+        '200':
+          content:
+            application/json:
+              schema:
+                type: object
+                properties:
+                  patties:
+                    type: integer`
+
+	doc, _ := libopenapi.NewDocument([]byte(spec))
+
+	v, _ := NewValidator(doc)
+
+	request, _ := http.NewRequest(http.MethodPost, "https://things.com/burgers/createBurger",
+		bytes.NewBufferString(`{"name": 1, "patties": "two"}`))
+	request.Header.Set(helpers.ContentTypeHeader, helpers.JSONContentType)
+
+	_, errs := v.ValidateHttpRequest(request)
+
+	problemJSON, err := errors.ToProblemJSON(errs)
+	assert.NoError(t, err)
+
+	var problem errors.ProblemDetails
+	_ = json.Unmarshal(problemJSON, &problem)
+	assert.Equal(t, "about:blank", problem.Type)
+	assert.Equal(t, "Bad Request", problem.Title)
+	assert.Equal(t, http.StatusBadRequest, problem.Status)
+	assert.Len(t, problem.Errors, 2)
+
+	pointers := make(map[string]string)
+	for _, e := range problem.Errors {
+		pointers[e.Pointer] = e.Reason
+	}
+	assert.Equal(t, "expected string, but got number", pointers["/name"])
+	assert.Equal(t, "expected integer, but got string", pointers["/patties"])
+
+	// response errors are the fault of the server.
+	res := httptest.NewRecorder()
+	res.Header().Set(helpers.ContentTypeHeader, helpers.JSONContentType)
+	res.WriteHeader(http.StatusOK)
+	_, _ = res.WriteString(`{"patties": "two"}`)
+
+	_, errs = v.ValidateHttpResponse(request, res.Result())
+
+	problemJSON, _ = errors.ToProblemJSON(errs)
+	_ = json.Unmarshal(problemJSON, &problem)
+	assert.Equal(t, http.StatusInternalServerError, problem.Status)
+	assert.Equal(t, "Internal Server Error", problem.Title)
+
+	// the status can be overridden.
+	problemJSON, _ = errors.ToProblemJSONWithStatus(errs, http.StatusBadGateway)
+	_ = json.Unmarshal(problemJSON, &problem)
+	assert.Equal(t, http.StatusBadGateway, problem.Status)
+	assert.Equal(t, "/patties", problem.Errors[0].Pointer)
+}
+
+func TestNewValidator_ValidationErrorJSON(t *testing.T) {
+
+	spec := `openapi: 3.1.0
+paths:
+  /burgers/createBurger:
+    post:
+      operationId: createBurger
+      parameters:
+        - name: chef
+          in: query
+          required: true
+          schema:
+            type: string
+      requestBody:
+        content:
+          application/json:
+            schema:
+              type: object
+              properties:
+                patties:
+                  oneOf:
+                    - type: integer
+                    - type: boolean`
+
+	doc, _ := libopenapi.NewDocument([]byte(spec))
+
+	v, _ := NewValidator(doc)
+
+	request, _ := http.NewRequest(http.MethodPost, "https://things.com/burgers/createBurger",
+		bytes.NewBufferString(`{"patties": "two"}`))
+	request.Header.Set(helpers.ContentTypeHeader, helpers.JSONContentType)
+
+	_, errs := v.ValidateHttpRequest(request)
+	assert.Len(t, errs, 2)
+
+	encoded, err := json.Marshal(errs)
+	assert.NoError(t, err)
+
+	// the field names are part of the contract.
+	var raw []map[string]any
+	_ = json.Unmarshal(encoded, &raw)
+	assert.Len(t, raw, 2)
+	for _, e := range raw {
+		for _, field := range []string{"message", "reason", "validationType", "validationSubType",
+			"specLine", "specColumn", "howToFix", "operationId", "method", "path"} {
+			assert.Contains(t, e, field)
+		}
+	}
+
+	// errors that are not caused by a schema have no schema failures.
+	assert.NotContains(t, raw[0], "validationErrors")
+	failures := raw[1]["validationErrors"].([]any)
+	assert.Len(t, failures, 3)
+	assert.Equal(t, "/patties", failures[0].(map[string]any)["instanceLocation"])
+	assert.Contains(t, failures[0].(map[string]any), "subschemaFailures")
+
+	// round-trip.
+	var decoded []*errors.ValidationError
+	assert.NoError(t, json.Unmarshal(encoded, &decoded))
+	assert.Len(t, decoded, 2)
+	for i := range errs {
+		assert.Equal(t, errs[i].Message, decoded[i].Message)
+		assert.Equal(t, errs[i].Reason, decoded[i].Reason)
+		assert.Equal(t, errs[i].HowToFix, decoded[i].HowToFix)
+		assert.Equal(t, errs[i].OperationId, decoded[i].OperationId)
+		assert.Len(t, decoded[i].SchemaValidationErrors, len(errs[i].SchemaValidationErrors))
+		for j, failure := range errs[i].SchemaValidationErrors {
+			assert.Equal(t, failure.Reason, decoded[i].SchemaValidationErrors[j].Reason)
+			assert.Equal(t, failure.InstanceLocation, decoded[i].SchemaValidationErrors[j].InstanceLocation)
+			assert.Equal(t, failure.Location, decoded[i].SchemaValidationErrors[j].Location)
+			assert.Len(t, decoded[i].SchemaValidationErrors[j].SubschemaFailures, len(failure.SubschemaFailures))
+		}
+	}
+
+	// empty and nil failures are omitted.
+	encoded, _ = json.Marshal(&errors.ValidationError{Message: "nope",
+		SchemaValidationErrors: []*errors.SchemaValidationFailure{nil}})
+	assert.NotContains(t, string(encoded), "validationErrors")
+}
+
+func TestNewValidator_Warnings(t *testing.T) {
+
+	spec := `openapi: 3.1.0
+paths:
+  /burgers/{burgerId}:
+    get:
+      parameters:
+        - name: burgerId
+          in: path
+          required: true
+          schema:
+            type: string
+        - name: X-Sauce
+          in: header
+          deprecated: true
+          schema:
+            type: string
+        - name: loyalty
+          in: cookie
+          deprecated: true
+          schema:
+            type: string
+      responses:
+        '200':
+          content:
+            application/json:
+              schema:
+                type: object
+                properties:
+                  name:
+                    type: string
+                  recipe:
+                    type: string
+                    writeOnly: true`
+
+	doc, _ := libopenapi.NewDocument([]byte(spec))
+
+	v, _ := NewValidator(doc, config.WithDeprecationWarnings(), config.WithWarnWriteOnly())
+
+	request, _ := http.NewRequest(http.MethodGet, "https://things.com/burgers/big-mac", nil)
+	request.Header.Set("X-Sauce", "ketchup")
+	request.AddCookie(&http.Cookie{Name: "loyalty", Value: "gold"})
+
+	valid, errs := v.ValidateHttpRequest(request)
+	assert.True(t, valid)
+	assert.Len(t, errs, 2)
+	for _, e := range errs {
+		assert.True(t, e.IsWarning())
+	}
+	assert.False(t, errors.HasErrors(errs))
+
+	res := httptest.NewRecorder()
+	res.Header().Set(helpers.ContentTypeHeader, helpers.JSONContentType)
+	res.WriteHeader(http.StatusOK)
+	_, _ = res.WriteString(`{"name": "Big Mac", "recipe": "secret"}`)
+
+	valid, errs = v.ValidateHttpRequestResponse(request, res.Result())
+	assert.True(t, valid)
+	assert.Len(t, errs, 3)
+
+	// a warning does not hide an error.
+	res = httptest.NewRecorder()
+	res.Header().Set(helpers.ContentTypeHeader, helpers.JSONContentType)
+	res.WriteHeader(http.StatusOK)
+	_, _ = res.WriteString(`{"name": 1, "recipe": "secret"}`)
+
+	valid, errs = v.ValidateHttpResponse(request, res.Result())
+	assert.False(t, valid)
+	assert.Len(t, errs, 2)
+	assert.True(t, errors.HasErrors(errs))
+}
+
+func TestNewValidator_DeprecationWarnings(t *testing.T) {
+
+	spec := `openapi: 3.1.0
+paths:
+  /burgers/{burgerId}:
+    get:
+      operationId: getBurger
+      deprecated: true
+      x-replaced-by: getBurgerV2
+      parameters:
+        - name: burgerId
+          in: path
+          required: true
+          schema:
+            type: string
+        - name: sauce
+          in: query
+          deprecated: true
+          x-replaced-by: condiment
+          schema:
+            type: string
+        - name: condiment
+          in: query
+          schema:
+            type: string`
+
+	doc, _ := libopenapi.NewDocument([]byte(spec))
+
+	// deprecations are ignored by default.
+	v, _ := NewValidator(doc)
+	request, _ := http.NewRequest(http.MethodGet, "https://things.com/burgers/big-mac?sauce=ketchup", nil)
+	valid, errs := v.ValidateHttpRequest(request)
+	assert.True(t, valid)
+	assert.Len(t, errs, 0)
+
+	v, _ = NewValidator(doc, config.WithDeprecationWarnings())
+	valid, errs = v.ValidateHttpRequest(request)
+	assert.True(t, valid)
+	assert.Len(t, errs, 2)
+
+	warnings := make(map[string]*errors.ValidationError)
+	for _, e := range errs {
+		assert.True(t, e.IsWarning())
+		assert.Equal(t, "getBurger", e.OperationId)
+		warnings[e.Message] = e
+	}
+	assert.Equal(t, "The GET operation for '/burgers/{burgerId}' is marked as deprecated in the specification, "+
+		"however it's used by the request to '/burgers/big-mac', it's replaced by 'getBurgerV2'",
+		warnings["GET operation for '/burgers/{burgerId}' is deprecated"].Reason)
+	assert.Equal(t, helpers.Deprecated, warnings["GET operation for '/burgers/{burgerId}' is deprecated"].ValidationSubType)
+	assert.Equal(t, "The query parameter 'sauce' is marked as deprecated in the specification, "+
+		"however it's sent with the request, it's replaced by 'condiment'",
+		warnings["Query parameter 'sauce' is deprecated"].Reason)
+
+	// the replacement is not deprecated, only the operation is.
+	request, _ = http.NewRequest(http.MethodGet, "https://things.com/burgers/big-mac?condiment=ketchup", nil)
+	valid, errs = v.ValidateHttpRequest(request)
+	assert.True(t, valid)
+	assert.Len(t, errs, 1)
+	assert.Equal(t, 6, errs[0].SpecLine)
+}
+
+func TestNewValidator_ValidateExamples(t *testing.T) {
+
+	spec := `openapi: 3.1.0
+paths:
+  /burgers:
+    get:
+      responses:
+        '200':
+          content:
+            application/json:
+              schema:
+                type: array
+                items:
+                  type: string
+              examples:
+                menu:
+                  value: [Big Mac, Whopper]
+                broken:
+                  value: [1, 2]`
+
+	doc, _ := libopenapi.NewDocument([]byte(spec))
+
+	v, _ := NewValidator(doc)
+	errs := v.ValidateExamples()
+	assert.Len(t, errs, 1)
+	assert.Equal(t, "The example 'broken' of the response '200' 'application/json' of the GET operation for "+
+		"'/burgers' does not match its schema", errs[0].Message)
+	assert.Len(t, errs[0].SchemaValidationErrors, 2)
+}
+
+func TestNewValidator_ExternalReferences(t *testing.T) {
+
+	spec, _ := os.ReadFile("test_specs/external_refs/openapi.yaml")
+
+	// file references cannot be resolved without a base path.
+	doc, _ := libopenapi.NewDocument(spec)
+	v, errs := NewValidator(doc)
+	assert.Nil(t, v)
+	assert.NotEmpty(t, errs)
+
+	// each reference is reported with its location, in no particular order.
+	var lines []int
+	for _, err := range errs {
+		var unresolved *errors.ValidationError
+		if assert.ErrorAs(t, err, &unresolved) {
+			assert.Equal(t, "The reference './schemas/pet.yaml#/Pet' cannot be resolved", unresolved.Message)
+			assert.Equal(t, helpers.Reference, unresolved.ValidationSubType)
+			lines = append(lines, unresolved.SpecLine)
+		}
+	}
+	assert.ElementsMatch(t, []int{12, 18}, lines)
+
+	doc, _ = libopenapi.NewDocument(spec)
+	v, errs = NewValidator(doc, config.WithReferenceBasePath("test_specs/external_refs"))
+	assert.Empty(t, errs)
+
+	request, _ := http.NewRequest(http.MethodPost, "https://things.com/pets",
+		bytes.NewBufferString(`{"name": "Rex", "tag": "good boy"}`))
+	request.Header.Set(helpers.ContentTypeHeader, helpers.JSONContentType)
+	valid, errs2 := v.ValidateHttpRequest(request)
+	assert.True(t, valid)
+	assert.Len(t, errs2, 0)
+
+	request, _ = http.NewRequest(http.MethodPost, "https://things.com/pets",
+		bytes.NewBufferString(`{"tag": "a very good boy"}`))
+	request.Header.Set(helpers.ContentTypeHeader, helpers.JSONContentType)
+	valid, errs2 = v.ValidateHttpRequest(request)
+	assert.False(t, valid)
+	assert.Len(t, errs2, 1)
+	assert.Len(t, errs2[0].SchemaValidationErrors, 2)
+}
+
+func TestNewValidator_RemoteReferences(t *testing.T) {
+
+	spec := []byte(`openapi: 3.1.0
+info:
+  title: Pets
+  version: 1.0.0
+paths:
+  /pets:
+    post:
+      requestBody:
+        content:
+          application/json:
+            schema:
+              $ref: 'https://schemas.things.com/pet.yaml#/Pet'`)
+
+	pet, _ := os.ReadFile("test_specs/external_refs/schemas/pet.yaml")
+	var fetched []string
+	handler := func(url string) (*http.Response, error) {
+		fetched = append(fetched, url)
+		return &http.Response{StatusCode: http.StatusOK, Body: io.NopCloser(bytes.NewReader(pet))}, nil
+	}
+
+	// remote references are denied by default.
+	doc, _ := libopenapi.NewDocument(spec)
+	_, errs := NewValidator(doc)
+	assert.NotEmpty(t, errs)
+	assert.Len(t, fetched, 0)
+
+	doc, _ = libopenapi.NewDocument(spec)
+	v, errs := NewValidator(doc, config.WithRemoteReferenceHandler(handler))
+	assert.Empty(t, errs)
+	assert.NotEmpty(t, fetched)
+
+	request, _ := http.NewRequest(http.MethodPost, "https://things.com/pets", bytes.NewBufferString(`{}`))
+	request.Header.Set(helpers.ContentTypeHeader, helpers.JSONContentType)
+	valid, validationErrs := v.ValidateHttpRequest(request)
+	assert.False(t, valid)
+	assert.Len(t, validationErrs, 1)
+}
+
+func TestNewValidator_ValidateHttpRequest_Security(t *testing.T) {
+
+	spec := `openapi: 3.1.0
+paths:
+  /burgers/createBurger:
+    post:
+      security:
+        - apiKey: []
+components:
+  securitySchemes:
+    apiKey:
+      type: apiKey
+      in: header
+      name: X-API-Key`
+
+	doc, _ := libopenapi.NewDocument([]byte(spec))
+
+	v, _ := NewValidator(doc, config.WithSecurityVerifier("apiKey", func(credential string) bool {
+		return credential == "secret"
+	}))
+
+	request, _ := http.NewRequest(http.MethodPost, "https://things.com/burgers/createBurger", nil)
+
+	valid, errs := v.ValidateHttpRequest(request)
+	assert.False(t, valid)
+	assert.Len(t, errs, 1)
+	assert.Equal(t, helpers.SecurityValidation, errs[0].ValidationType)
+
+	request.Header.Set("X-API-Key", "secret")
+
+	valid, errs = v.ValidateHttpRequest(request)
+	assert.True(t, valid)
+	assert.Len(t, errs, 0)
+}
+
+func TestNewValidator_ValidateHttpRequest_ApplyDefaultsWithQuerySecurity(t *testing.T) {
+
+	spec := `openapi: 3.1.0
+paths:
+  /burgers:
+    get:
+      parameters:
+        - name: limit
+          in: query
+          schema:
+            type: integer
+            default: 20
+      security:
+        - apiKey: []
+components:
+  securitySchemes:
+    apiKey:
+      type: apiKey
+      in: query
+      name: key`
+
+	doc, _ := libopenapi.NewDocument([]byte(spec))
+
+	// the security stage reads the query while the query stage validates the default, the default is only added
+	// to the request once both are done (run with -race to check the request is not changed while it's read).
+	for _, opts := range [][]config.Option{
+		{config.WithApplyDefaults()},
+		{config.WithApplyDefaults(), config.WithFailFast()},
+	} {
+		v, _ := NewValidator(doc, opts...)
+
+		request, _ := http.NewRequest(http.MethodGet, "https://things.com/burgers?key=secret", nil)
+		valid, errs := v.ValidateHttpRequest(request)
+		assert.True(t, valid)
+		assert.Len(t, errs, 0)
+		assert.Equal(t, "20", request.URL.Query().Get("limit"))
+		assert.Equal(t, "secret", request.URL.Query().Get("key"))
+
+		request, _ = http.NewRequest(http.MethodGet, "https://things.com/burgers", nil)
+		valid, errs = v.ValidateHttpRequest(request)
+		assert.False(t, valid)
+		assert.Len(t, errs, 1)
+		assert.Equal(t, helpers.SecurityValidation, errs[0].ValidationType)
+		assert.Equal(t, "limit=20", request.URL.RawQuery)
+
+		request, _ = http.NewRequest(http.MethodGet, "https://things.com/burgers?key=secret", nil)
+		valid, _ = v.ValidateRequestParameters(request)
+		assert.True(t, valid)
+		assert.Equal(t, "20", request.URL.Query().Get("limit"))
+	}
+}
+
+func TestNewValidator_ValidateHttpRequestAgainstWebhook(t *testing.T) {
+
+	spec := `openapi: 3.1.0
+webhooks:
+  newBurger:
+    post:
+      parameters:
+        - name: X-Signature
+          in: header
+          required: true
+          schema:
+            type: string
+      requestBody:
+        content:
+          application/json:
+            schema:
+              type: object
+              required: [name]
+              properties:
+                name:
+                  type: string
+      responses:
+        '200':
+          description: received`
+
+	doc, _ := libopenapi.NewDocument([]byte(spec))
+
+	v, _ := NewValidator(doc)
+
+	// the path of the request doesn't matter, the webhook is located by name.
+	request, _ := http.NewRequest(http.MethodPost, "https://hooks.things.com/anything",
+		bytes.NewBufferString(`{"name": "Big Mac"}`))
+	request.Header.Set(helpers.ContentTypeHeader, helpers.JSONContentType)
+	request.Header.Set("X-Signature", "abc")
+
+	valid, errs := v.ValidateHttpRequestAgainstWebhook("newBurger", request)
+	assert.True(t, valid)
+	assert.Len(t, errs, 0)
+
+	request, _ = http.NewRequest(http.MethodPost, "https://hooks.things.com/anything",
+		bytes.NewBufferString(`{"name": 1}`))
+	request.Header.Set(helpers.ContentTypeHeader, helpers.JSONContentType)
+
+	valid, errs = v.ValidateHttpRequestAgainstWebhook("newBurger", request)
+	assert.False(t, valid)
+	assert.Len(t, errs, 2)
+
+	// unknown webhooks, and unknown methods are reported.
+	valid, errs = v.ValidateHttpRequestAgainstWebhook("oldBurger", request)
+	assert.False(t, valid)
+	assert.Len(t, errs, 1)
+	assert.Equal(t, "POST webhook 'oldBurger' not found", errs[0].Message)
+	assert.Equal(t, helpers.WebhookValidation, errs[0].ValidationType)
+
+	request, _ = http.NewRequest(http.MethodGet, "https://hooks.things.com/anything", nil)
+	valid, errs = v.ValidateHttpRequestAgainstWebhook("newBurger", request)
+	assert.False(t, valid)
+	assert.Equal(t, "GET webhook 'newBurger' not found", errs[0].Message)
+}
+
+func TestNewValidator_ValidateCallback(t *testing.T) {
+
+	spec := `openapi: 3.1.0
+paths:
+  /burgers/subscribe:
+    post:
+      callbacks:
+        burgerReady:
+          '{$request.body#/callbackUrl}/ready':
+            post:
+              requestBody:
+                content:
+                  application/json:
+                    schema:
+                      type: object
+                      required: [orderId]
+                      properties:
+                        orderId:
+                          type: integer
+          '{$request.body#/callbackUrl}/cancelled':
+            post:
+              requestBody:
+                content:
+                  application/json:
+                    schema:
+                      type: object
+                      required: [reason]`
+
+	doc, _ := libopenapi.NewDocument([]byte(spec))
+	m, _ := doc.BuildV3Model()
+
+	v := NewValidatorFromV3Model(&m.Model)
+	operation := m.Model.Paths.PathItems["/burgers/subscribe"].Post
+
+	send := func(url, body string) (bool, []*errors.ValidationError) {
+		request, _ := http.NewRequest(http.MethodPost, url, bytes.NewBufferString(body))
+		request.Header.Set(helpers.ContentTypeHeader, helpers.JSONContentType)
+		return v.ValidateCallback(operation, "burgerReady", request)
+	}
+
+	// the resolved URL selects the matching runtime expression.
+	valid, errs := send("https://client.com/hooks/ready", `{"orderId": 123}`)
+	assert.True(t, valid)
+	assert.Len(t, errs, 0)
+
+	valid, errs = send("https://client.com/hooks/ready", `{"orderId": "123"}`)
+	assert.False(t, valid)
+	assert.Len(t, errs, 1)
+	assert.Equal(t, "expected integer, but got string", errs[0].SchemaValidationErrors[0].Reason)
+
+	valid, errs = send("https://client.com/hooks/cancelled", `{"orderId": 123}`)
+	assert.False(t, valid)
+	assert.Len(t, errs, 1)
+	assert.Equal(t, "missing properties: 'reason'", errs[0].SchemaValidationErrors[0].Reason)
+
+	valid, errs = send("https://client.com/hooks/eaten", `{}`)
+	assert.False(t, valid)
+	assert.Len(t, errs, 1)
+	assert.Equal(t, "POST callback 'burgerReady' not found", errs[0].Message)
+}
+
+func TestNewValidator_ValidateRequestBody(t *testing.T) {
+
+	spec := `openapi: 3.1.0
+paths:
+  /burgers/createBurger:
+    post:
+      parameters:
+        - name: X-Store
+          in: header
+          required: true
+          schema:
+            type: string
+      requestBody:
+        content:
+          application/json:
+            schema:
+              type: object
+              properties:
+                name:
+                  type: string`
+
+	doc, _ := libopenapi.NewDocument([]byte(spec))
+
+	v, _ := NewValidator(doc)
+
+	// the required header is missing, but parameters are not validated.
+	request, _ := http.NewRequest(http.MethodPost, "https://things.com/burgers/createBurger",
+		bytes.NewBufferString(`{"name": "Big Mac"}`))
+	request.Header.Set(helpers.ContentTypeHeader, helpers.JSONContentType)
+
+	valid, errors := v.ValidateRequestBody(request)
+	assert.True(t, valid)
+	assert.Len(t, errors, 0)
+
+	// the body can be read again.
+	body, _ := io.ReadAll(request.Body)
+	assert.Equal(t, `{"name": "Big Mac"}`, string(body))
+
+	request, _ = http.NewRequest(http.MethodPost, "https://things.com/burgers/createBurger",
+		bytes.NewBufferString(`{"name": 12}`))
+	request.Header.Set(helpers.ContentTypeHeader, helpers.JSONContentType)
+
+	valid, errors = v.ValidateRequestBody(request)
+	assert.False(t, valid)
+	assert.Len(t, errors, 1)
+	assert.Equal(t, helpers.RequestBodyValidation, errors[0].ValidationType)
+
+	// the content type is checked.
+	request, _ = http.NewRequest(http.MethodPost, "https://things.com/burgers/createBurger",
+		bytes.NewBufferString(`name=Big+Mac`))
+	request.Header.Set(helpers.ContentTypeHeader, helpers.FormURLEncodedContentType)
+
+	valid, errors = v.ValidateRequestBody(request)
+	assert.False(t, valid)
+	assert.Len(t, errors, 1)
+	assert.Equal(t, helpers.RequestBodyContentType, errors[0].ValidationSubType)
+}
+
+func TestNewValidator_ValidateRequestParameters(t *testing.T) {
+
+	spec := `openapi: 3.1.0
+paths:
+  /burgers/{burgerId}/upload:
+    post:
+      parameters:
+        - name: burgerId
+          in: path
+          required: true
+          schema:
+            type: string
+        - name: size
+          in: query
+          schema:
+            type: integer
+        - name: X-Store
+          in: header
+          required: true
+          schema:
+            type: string
+      requestBody:
+        required: true
+        content:
+          application/json:
+            schema:
+              type: object`
+
+	doc, _ := libopenapi.NewDocument([]byte(spec))
+
+	v, _ := NewValidator(doc)
+
+	// the body is invalid, but it's never read.
+	body := &readTracker{Reader: bytes.NewBufferString(`not json`)}
+	request, _ := http.NewRequest(http.MethodPost, "https://things.com/burgers/big-mac/upload?size=2", body)
+	request.Header.Set(helpers.ContentTypeHeader, helpers.JSONContentType)
+	request.Header.Set("X-Store", "downtown")
+
+	valid, errors := v.ValidateRequestParameters(request)
+	assert.True(t, valid)
+	assert.Len(t, errors, 0)
+	assert.False(t, body.read)
+
+	request, _ = http.NewRequest(http.MethodPost, "https://things.com/burgers/big-mac/upload?size=large", body)
+
+	valid, errors = v.ValidateRequestParameters(request)
+	assert.False(t, valid)
+	assert.Len(t, errors, 2)
+	assert.False(t, body.read)
+}
+
+type readTracker struct {
+	io.Reader
+	read bool
+}
+
+func (r *readTracker) Read(p []byte) (int, error) {
+	r.read = true
+	return r.Reader.Read(p)
+}
+
+func TestNewValidator_ErrorsHaveOperation(t *testing.T) {
+
+	spec := `openapi: 3.1.0
+paths:
+  /burgers/{burgerId}:
+    get:
+      operationId: getBurger
+      parameters:
+        - name: burgerId
+          in: path
+          required: true
+          schema:
+            type: string
+        - name: size
+          in: query
+          schema:
+            type: integer
+      responses:
+        '200':
+          content:
+            application/json:
+              schema:
+                type: object
+                properties:
+                  name:
+                    type: string
+    delete:
+      parameters:
+        - name: burgerId
+          in: path
+          required: true
+          schema:
+            type: string
+        - name: force
+          in: query
+          schema:
+            type: boolean`
+
+	doc, _ := libopenapi.NewDocument([]byte(spec))
+
+	v, _ := NewValidator(doc)
+
+	request, _ := http.NewRequest(http.MethodGet, "https://things.com/burgers/big-mac?size=large", nil)
+	res := httptest.NewRecorder()
+	res.Header().Set(helpers.ContentTypeHeader, helpers.JSONContentType)
+	res.WriteHeader(http.StatusOK)
+	_, _ = res.WriteString(`{"name": 12}`)
+
+	valid, errs := v.ValidateHttpRequestResponse(request, res.Result())
+	assert.False(t, valid)
+	assert.Len(t, errs, 2)
+	for _, err := range errs {
+		assert.Equal(t, "getBurger", err.OperationId)
+		assert.Equal(t, http.MethodGet, err.Method)
+		assert.Equal(t, "/burgers/{burgerId}", err.Path)
+	}
+	assert.Equal(t, helpers.ParameterValidation, errs[0].ValidationType)
+	assert.Equal(t, helpers.ResponseBodyValidation, errs[1].ValidationType)
+
+	// no operationId is declared, the method and path are still set.
+	request, _ = http.NewRequest(http.MethodDelete, "https://things.com/burgers/big-mac?force=maybe", nil)
+	valid, errs = v.ValidateHttpRequest(request)
+	assert.False(t, valid)
+	assert.Len(t, errs, 1)
+	assert.Empty(t, errs[0].OperationId)
+	assert.Equal(t, http.MethodDelete, errs[0].Method)
+	assert.Equal(t, "/burgers/{burgerId}", errs[0].Path)
+}
+
+func TestNewValidator_CircularSchema(t *testing.T) {
+
+	spec := `openapi: 3.1.0
+paths:
+  /trees:
+    post:
+      requestBody:
+        content:
+          application/json:
+            schema:
+              $ref: '#/components/schemas/TreeNode'
+      responses:
+        '200':
+          content:
+            application/json:
+              schema:
+                $ref: '#/components/schemas/TreeNode'
+components:
+  schemas:
+    TreeNode:
+      type: object
+      required: [value]
+      properties:
+        value:
+          type: integer
+        children:
+          type: array
+          items:
+            $ref: '#/components/schemas/TreeNode'`
+
+	doc, _ := libopenapi.NewDocument([]byte(spec))
+
+	v, errs := NewValidator(doc)
+	assert.Empty(t, errs)
+
+	tree := `{"value": 1, "children": [{"value": 2, "children": [{"value": 3, "children": [{"value": 4,
+		"children": [{"value": 5}]}]}]}, {"value": 6}]}`
+
+	request, _ := http.NewRequest(http.MethodPost, "https://things.com/trees", bytes.NewBufferString(tree))
+	request.Header.Set("Content-Type", "application/json")
+
+	valid, validationErrors := v.ValidateHttpRequest(request)
+	assert.True(t, valid)
+	assert.Empty(t, validationErrors)
+
+	res := httptest.NewRecorder()
+	handler := func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusOK)
+		_, _ = w.Write([]byte(tree))
+	}
+	handler(res, request)
+
+	valid, validationErrors = v.ValidateHttpResponse(request, res.Result())
+	assert.True(t, valid)
+	assert.Empty(t, validationErrors)
+
+	deep := `{"value": 1, "children": [{"value": 2, "children": [{"value": 3, "children": [{"value": 4,
+		"children": [{"value": "five"}]}]}]}]}`
+
+	request, _ = http.NewRequest(http.MethodPost, "https://things.com/trees", bytes.NewBufferString(deep))
+	request.Header.Set("Content-Type", "application/json")
+
+	valid, validationErrors = v.ValidateHttpRequest(request)
+	assert.False(t, valid)
+	assert.Len(t, validationErrors, 1)
+	assert.Len(t, validationErrors[0].SchemaValidationErrors, 1)
+	assert.Equal(t, "/children/0/children/0/children/0/children/0/value",
+		validationErrors[0].SchemaValidationErrors[0].InstanceLocation)
+}
+
+func TestNewValidator_Observer(t *testing.T) {
+
+	doc, _ := libopenapi.NewDocument([]byte(batchSpec))
+
+	var lock sync.Mutex
+	stages := make(map[string]int)
+	observe := func(stage string) func(time.Duration) {
+		return func(duration time.Duration) {
+			lock.Lock()
+			defer lock.Unlock()
+			assert.GreaterOrEqual(t, duration, time.Duration(0))
+			stages[stage]++
+		}
+	}
+
+	v, _ := NewValidator(doc, config.WithObserver(config.Observer{
+		OnSchemaCompiled: observe("compiled"),
+		OnBodyParsed:     observe("parsed"),
+		OnValidated:      observe("validated"),
+	}))
+
+	for i := 0; i < 2; i++ {
+		exchange := recordedExchange("/burgers", `{"name": "Big Mac"}`, `{"name": "Big Mac"}`)
+		valid, errs := v.ValidateHttpRequestResponse(exchange.Request, exchange.Response)
+		assert.True(t, valid)
+		assert.Empty(t, errs)
+	}
+
+	// the request and response schemas are only compiled once, every body is parsed and validated.
+	assert.Equal(t, map[string]int{"compiled": 2, "parsed": 4, "validated": 4}, stages)
+
+	// callbacks that are not set are ignored.
+	v, _ = NewValidator(doc, config.WithObserver(config.Observer{OnValidated: observe("only")}))
+	exchange := recordedExchange("/burgers", `{"name": "Big Mac"}`, `{"name": "Big Mac"}`)
+	valid, _ := v.ValidateHttpRequestResponse(exchange.Request, exchange.Response)
+	assert.True(t, valid)
+	assert.Equal(t, 2, stages["only"])
+}
+
+func TestNewValidator_Logger(t *testing.T) {
+
+	doc, _ := libopenapi.NewDocument([]byte(batchSpec))
+
+	var buf bytes.Buffer
+	logger := slog.New(slog.NewTextHandler(&buf, &slog.HandlerOptions{Level: slog.LevelDebug}))
+	v, _ := NewValidator(doc, config.WithLogger(logger))
+
+	exchange := recordedExchange("/burgers", `{"name": "Big Mac"}`, `{"name": "Big Mac"}`)
+	valid, errs := v.ValidateHttpRequestResponse(exchange.Request, exchange.Response)
+	assert.True(t, valid)
+	assert.Empty(t, errs)
+
+	logged := buf.String()
+	assert.Contains(t, logged, "msg=\"matched path\" method=POST path=/burgers template=/burgers")
+	assert.Contains(t, logged, "msg=\"selected request body media type\" method=POST template=/burgers "+
+		"contentType=application/json mediaType=application/json")
+	assert.Contains(t, logged, "msg=\"selected response\"")
+	assert.Contains(t, logged, "msg=\"validated request\" method=POST template=/burgers valid=true errors=0")
+
+	// debug messages are dropped when the level of the logger is higher.
+	buf.Reset()
+	logger = slog.New(slog.NewTextHandler(&buf, &slog.HandlerOptions{Level: slog.LevelInfo}))
+	v, _ = NewValidator(doc, config.WithLogger(logger))
+	exchange = recordedExchange("/burgers", `{"name": "Big Mac"}`, `{"name": "Big Mac"}`)
+	valid, _ = v.ValidateHttpRequestResponse(exchange.Request, exchange.Response)
+	assert.True(t, valid)
+	assert.Empty(t, buf.String())
+}
+
+func TestNewValidator_ValidateResponse(t *testing.T) {
+
+	spec := `openapi: 3.1.0
+paths:
+  /burgers/{burgerId}:
+    get:
+      responses:
+        "200":
+          headers:
+            X-Calories:
+              required: true
+              schema:
+                type: integer
+          content:
+            application/json:
+              schema:
+                type: object
+                required: [name]
+                properties:
+                  name:
+                    type: string`
+
+	doc, _ := libopenapi.NewDocument([]byte(spec))
+	v, _ := NewValidator(doc)
+
+	response := func(code int, calories, body string) *http.Response {
+		header := http.Header{helpers.ContentTypeHeader: {helpers.JSONContentType}}
+		if calories != "" {
+			header.Set("X-Calories", calories)
+		}
+		return &http.Response{StatusCode: code, Header: header, Body: io.NopCloser(bytes.NewBufferString(body))}
+	}
+
+	valid, errs := v.ValidateResponse("get", "/burgers/{burgerId}", response(200, "540", `{"name": "Big Mac"}`))
+	assert.True(t, valid)
+	assert.Len(t, errs, 0)
+
+	// the headers and body are validated.
+	valid, errs = v.ValidateResponse(http.MethodGet, "/burgers/{burgerId}", response(200, "", `{"name": 1}`))
+	assert.False(t, valid)
+	assert.Len(t, errs, 2)
+
+	// and so is the status code.
+	valid, errs = v.ValidateResponse(http.MethodGet, "/burgers/{burgerId}", response(404, "", `{}`))
+	assert.False(t, valid)
+	assert.Len(t, errs, 1)
+	assert.Equal(t, "GET operation request response code '404' does not exist", errs[0].Message)
+
+	// the operation must exist.
+	valid, errs = v.ValidateResponse(http.MethodPost, "/burgers/{burgerId}", response(200, "540", `{}`))
+	assert.False(t, valid)
+	assert.Len(t, errs, 1)
+	assert.Equal(t, "POST operation for '/burgers/{burgerId}' not found", errs[0].Message)
+}
+
+func TestNewValidator_DeduplicateErrors(t *testing.T) {
+
+	spec := `openapi: 3.1.0
+paths:
+  /burgers:
+    post:
+      parameters:
+        - name: X-Chef
+          in: header
+          required: true
+          schema:
+            type: string
+        - name: X-Chef
+          in: header
+          required: true
+          schema:
+            type: string
+      requestBody:
+        content:
+          application/json:
+            schema:
+              allOf:
+                - type: object
+                  required: [name]
+                - type: object
+                  required: [name]`
+
+	doc, _ := libopenapi.NewDocument([]byte(spec))
+
+	request := func() *http.Request {
+		request, _ := http.NewRequest(http.MethodPost, "https://things.com/burgers", bytes.NewBufferString(`{}`))
+		request.Header.Set("Content-Type", "application/json")
+		return request
+	}
+
+	// parameters and the body are validated concurrently, so the errors are counted by message.
+	messages := func(errs []*errors.ValidationError) map[string]int {
+		counted := make(map[string]int)
+		for _, e := range errs {
+			counted[e.Message]++
+		}
+		return counted
+	}
+	failures := func(errs []*errors.ValidationError) []*errors.SchemaValidationFailure {
+		for _, e := range errs {
+			if e.ValidationType == helpers.RequestBodyValidation {
+				return e.SchemaValidationErrors
+			}
+		}
+		return nil
+	}
+
+	// the header is declared twice, and name is required twice, but each is only reported once.
+	v, _ := NewValidator(doc)
+	valid, errs := v.ValidateHttpRequest(request())
+	assert.False(t, valid)
+	assert.Len(t, errs, 2)
+	assert.Equal(t, 1, messages(errs)["Header parameter 'X-Chef' is missing"])
+	assert.Equal(t, 1, messages(errs)["POST request body for '/burgers' failed to validate schema"])
+	if assert.Len(t, failures(errs), 2) {
+		assert.Equal(t, "allOf failed", failures(errs)[0].Reason)
+		assert.Equal(t, "missing properties: 'name'", failures(errs)[1].Reason)
+	}
+
+	// the raw set of errors includes the duplicates.
+	v, _ = NewValidator(doc, config.WithoutErrorDeduplication())
+	valid, errs = v.ValidateHttpRequest(request())
+	assert.False(t, valid)
+	assert.Len(t, errs, 3)
+	assert.Equal(t, 2, messages(errs)["Header parameter 'X-Chef' is missing"])
+	assert.Len(t, failures(errs), 4)
+}
+
+func TestNewValidator_OpenAPI30And31(t *testing.T) {
+
+	spec30 := `openapi: 3.0.3
+paths:
+  /burgers:
+    post:
+      parameters:
+        - name: limit
+          in: query
+          schema:
+            type: integer
+            minimum: 0
+            exclusiveMinimum: true
+            nullable: true
+        - name: X-Size
+          in: header
+          schema:
+            type: integer
+            minimum: 1
+            exclusiveMinimum: false
+            nullable: true
+      requestBody:
+        content:
+          application/json:
+            schema:
+              type: object
+              properties:
+                name:
+                  type: string
+                  nullable: true
+                  example: Big Mac
+                patties:
+                  type: number
+                  minimum: 1
+                  exclusiveMinimum: false
+                  maximum: 5
+                  exclusiveMaximum: true
+      responses:
+        '200':
+          description: ok`
+
+	spec31 := `openapi: 3.1.0
+paths:
+  /burgers:
+    post:
+      parameters:
+        - name: limit
+          in: query
+          schema:
+            type: [integer, "null"]
+            exclusiveMinimum: 0
+        - name: X-Size
+          in: header
+          schema:
+            type: [integer, "null"]
+            minimum: 1
+      requestBody:
+        content:
+          application/json:
+            schema:
+              type: object
+              properties:
+                name:
+                  type: [string, "null"]
+                  examples: [Big Mac]
+                patties:
+                  type: number
+                  minimum: 1
+                  exclusiveMaximum: 5
+      responses:
+        '200':
+          description: ok`
+
+	tests := []struct {
+		query, size, body string
+		valid             bool
+	}{
+		{"", "", `{"name": "Big Mac", "patties": 2}`, true},
+		{"limit=1", "1", `{"name": null, "patties": 1}`, true},
+		{"limit=", "", `{}`, true},
+		{"limit=null", "null", `{}`, true},
+		{"limit=0", "", `{}`, false},
+		{"", "0", `{}`, false},
+		{"", "", `{"patties": 5}`, false},
+		{"", "", `{"patties": 0.5}`, false},
+		{"", "", `{"name": 1}`, false},
+	}
+
+	for _, spec := range []string{spec30, spec31} {
+		doc, _ := libopenapi.NewDocument([]byte(spec))
+		v, _ := NewValidator(doc)
+
+		for _, tt := range tests {
+			request, _ := http.NewRequest(http.MethodPost, "https://things.com/burgers?"+tt.query,
+				bytes.NewBufferString(tt.body))
+			request.Header.Set("Content-Type", "application/json")
+			if tt.size != "" || tt.query == "limit=" {
+				request.Header.Set("X-Size", tt.size)
+			}
+
+			// both versions of the specification accept (and reject) the same requests.
+			valid, errs := v.ValidateHttpRequest(request)
+			assert.Equal(t, tt.valid, valid, "%s: %s %s %s", doc.GetVersion(), tt.query, tt.size, tt.body)
+			assert.Equal(t, tt.valid, len(errs) == 0)
+		}
+	}
+}
+
+func TestNewValidator_MultiError(t *testing.T) {
+
+	spec := `openapi: 3.1.0
+paths:
+  /burgers:
+    post:
+      requestBody:
+        content:
+          application/json:
+            schema:
+              type: object
+              properties:
+                patties:
+                  type: integer
+                  minimum: 1`
+
+	doc, _ := libopenapi.NewDocument([]byte(spec))
+	v, _ := NewValidator(doc)
+
+	validate := func(body string) error {
+		request, _ := http.NewRequest(http.MethodPost, "https://things.com/burgers", bytes.NewBufferString(body))
+		request.Header.Set("Content-Type", "application/json")
+		_, errs := v.ValidateHttpRequest(request)
+		return errors.NewMultiError(errs)
+	}
+
+	// a valid request is not an error.
+	assert.NoError(t, validate(`{"patties": 2}`))
+
+	// the message and the first reason are combined into a single line.
+	err := validate(`{"patties": 0}`)
+	assert.EqualError(t, err, "POST request body for '/burgers' failed to validate schema: value 0 is less than minimum 1")
+
+	// the structured error can still be found.
+	var validationError *errors.ValidationError
+	if assert.True(t, stderrors.As(err, &validationError)) {
+		assert.Equal(t, helpers.RequestBodyValidation, validationError.ValidationType)
+		assert.Len(t, validationError.SchemaValidationErrors, 1)
+		assert.EqualError(t, validationError, err.Error())
+	}
+	assert.True(t, stderrors.Is(err, validationError))
+
+	// more than one error is counted.
+	err = errors.NewMultiError([]*errors.ValidationError{
+		{Message: "Query parameter 'limit' is missing", Reason: "The query parameter 'limit' is required"},
+		{Message: "Header parameter 'X-Chef' is missing"},
+	})
+	assert.EqualError(t, err, "2 validation errors: Query parameter 'limit' is missing: The query parameter "+
+		"'limit' is required; Header parameter 'X-Chef' is missing")
+}
+
+func TestNewValidator_ErrorCodes(t *testing.T) {
+
+	spec := `openapi: 3.1.0
+paths:
+  /burgers:
+    post:
+      parameters:
+        - name: limit
+          in: query
+          required: true
+          schema:
+            type: integer
+      requestBody:
+        content:
+          application/json:
+            schema:
+              type: object
+              required: [name]
+      responses:
+        '201':
+          description: created`
+
+	doc, _ := libopenapi.NewDocument([]byte(spec))
+	v, _ := NewValidator(doc)
+
+	codes := func(errs []*errors.ValidationError) []errors.ErrorCode {
+		var found []errors.ErrorCode
+		for _, e := range errs {
+			found = append(found, e.Code)
+		}
+		return found
+	}
+
+	request, _ := http.NewRequest(http.MethodPost, "https://things.com/burgers", bytes.NewBufferString(`{}`))
+	request.Header.Set("Content-Type", "application/json")
+	_, errs := v.ValidateHttpRequest(request)
+	assert.ElementsMatch(t, []errors.ErrorCode{errors.ErrorCodeQueryParamMissing, errors.ErrorCodeSchema}, codes(errs))
+
+	request, _ = http.NewRequest(http.MethodPost, "https://things.com/burgers?limit=ten",
+		bytes.NewBufferString(`{"name": "Big Mac"}`))
+	request.Header.Set("Content-Type", "application/xml")
+	_, errs = v.ValidateHttpRequest(request)
+	assert.ElementsMatch(t, []errors.ErrorCode{errors.ErrorCodeParamType, errors.ErrorCodeContentType}, codes(errs))
+
+	request, _ = http.NewRequest(http.MethodGet, "https://things.com/fries", nil)
+	_, errs = v.ValidateHttpRequest(request)
+	assert.Equal(t, []errors.ErrorCode{errors.ErrorCodePathNotFound}, codes(errs))
+
+	request, _ = http.NewRequest(http.MethodPost, "https://things.com/burgers?limit=1", nil)
+	_, errs = v.ValidateHttpResponse(request, &http.Response{StatusCode: http.StatusTeapot, Header: http.Header{}})
+	assert.Equal(t, []errors.ErrorCode{errors.ErrorCodeResponseCode}, codes(errs))
+}
+
+func TestNewValidator_DeduplicateErrorsEntryPoints(t *testing.T) {
+
+	spec := `openapi: 3.1.0
+paths:
+  /burgers:
+    post:
+      requestBody:
+        content:
+          application/json:
+            schema:
+              allOf:
+                - type: object
+                  required: [name]
+                - type: object
+                  required: [name]
+      responses:
+        '201':
+          description: created
+          content:
+            application/json:
+              schema:
+                allOf:
+                  - type: object
+                    required: [id]
+                  - type: object
+                    required: [id]`
+
+	doc, _ := libopenapi.NewDocument([]byte(spec))
+
+	request := func() *http.Request {
+		request, _ := http.NewRequest(http.MethodPost, "https://things.com/burgers", bytes.NewBufferString(`{}`))
+		request.Header.Set("Content-Type", "application/json")
+		return request
+	}
+	response := func() *http.Response {
+		return &http.Response{
+			StatusCode: http.StatusCreated,
+			Header:     http.Header{"Content-Type": []string{"application/json"}},
+			Body:       io.NopCloser(bytes.NewBufferString(`{}`)),
+		}
+	}
+
+	// the required property is declared twice, but it's only reported once by each entry point.
+	for failures, opts := range map[int][]config.Option{2: nil, 4: {config.WithoutErrorDeduplication()}} {
+		v, _ := NewValidator(doc, opts...)
+
+		valid, errs := v.ValidateRequestBody(request())
+		assert.False(t, valid)
+		if assert.Len(t, errs, 1) {
+			assert.Len(t, errs[0].SchemaValidationErrors, failures)
+		}
+
+		operationValidator, _ := v.OperationValidator(http.MethodPost, "/burgers")
+		valid, errs = operationValidator.ValidateResponse(request(), response())
+		assert.False(t, valid)
+		if assert.Len(t, errs, 1) {
+			assert.Len(t, errs[0].SchemaValidationErrors, failures)
+		}
+	}
+}
+
+func TestNewValidator_FailFast(t *testing.T) {
+
+	spec := `openapi: 3.1.0
+paths:
+  /burgers:
+    post:
+      parameters:
+        - name: limit
+          in: query
+          required: true
+          schema:
+            type: integer
+        - name: X-Chef
+          in: header
+          required: true
+          schema:
+            type: string
+      requestBody:
+        content:
+          application/json:
+            schema:
+              type: object
+              required: [name]
+      responses:
+        '201':
+          description: created
+          content:
+            application/json:
+              schema:
+                type: object
+                required: [id]`
+
+	doc, _ := libopenapi.NewDocument([]byte(spec))
+
+	request := func() *http.Request {
+		request, _ := http.NewRequest(http.MethodPost, "https://things.com/burgers?limit=ten",
+			bytes.NewBufferString(`{}`))
+		request.Header.Set("Content-Type", "application/json")
+		return request
+	}
+	response := &http.Response{
+		StatusCode: http.StatusCreated,
+		Header:     http.Header{"Content-Type": []string{"application/json"}},
+		Body:       io.NopCloser(bytes.NewBufferString(`{}`)),
+	}
+
+	// every error is collected by default.
+	v, _ := NewValidator(doc)
+	valid, errs := v.ValidateHttpRequest(request())
+	assert.False(t, valid)
+	assert.Len(t, errs, 3)
+
+	// the header stage is validated before the query parameters and the body.
+	v, _ = NewValidator(doc, config.WithFailFast())
+	valid, errs = v.ValidateHttpRequest(request())
+	assert.False(t, valid)
+	if assert.Len(t, errs, 1) {
+		assert.Equal(t, "Header parameter 'X-Chef' is missing", errs[0].Message)
+	}
+
+	// the response is not validated once the request has failed.
+	valid, errs = v.ValidateHttpRequestResponse(request(), response)
+	assert.False(t, valid)
+	if assert.Len(t, errs, 1) {
+		assert.Equal(t, errors.ErrorCodeHeaderParamMissing, errs[0].Code)
+	}
+
+	// a valid request moves on to the response.
+	validRequest := request()
+	validRequest.URL.RawQuery = "limit=1"
+	validRequest.Header.Set("X-Chef", "Ronald")
+	validRequest.Body = io.NopCloser(bytes.NewBufferString(`{"name": "Big Mac"}`))
+	valid, errs = v.ValidateHttpRequestResponse(validRequest, response)
+	assert.False(t, valid)
+	if assert.Len(t, errs, 1) {
+		assert.Equal(t, errors.ErrorCodeSchema, errs[0].Code)
+		assert.Equal(t, helpers.ResponseBodyValidation, errs[0].ValidationType)
+	}
+}
+
+func TestNewValidator_FailFastEntryPoints(t *testing.T) {
+
+	spec := `openapi: 3.1.0
+paths:
+  /burgers:
+    post:
+      parameters:
+        - name: limit
+          in: query
+          required: true
+          schema:
+            type: integer
+        - name: X-Chef
+          in: header
+          required: true
+          schema:
+            type: string
+      responses:
+        '201':
+          description: created
+          headers:
+            X-Id:
+              required: true
+              schema:
+                type: string
+          content:
+            application/json:
+              schema:
+                type: object
+                required: [id]`
+
+	doc, _ := libopenapi.NewDocument([]byte(spec))
+	v, _ := NewValidator(doc, config.WithFailFast())
+
+	request, _ := http.NewRequest(http.MethodPost, "https://things.com/burgers?limit=ten", nil)
+	response := func() *http.Response {
+		return &http.Response{
+			StatusCode: http.StatusCreated,
+			Header:     http.Header{"Content-Type": []string{"application/json"}},
+			Body:       io.NopCloser(bytes.NewBufferString(`{}`)),
+		}
+	}
+
+	// the parameters are validated in the same order, whichever entry point is used.
+	_, requestErrs := v.ValidateHttpRequest(request)
+	_, parameterErrs := v.ValidateRequestParameters(request)
+	if assert.Len(t, requestErrs, 1) && assert.Len(t, parameterErrs, 1) {
+		assert.Equal(t, "Header parameter 'X-Chef' is missing", requestErrs[0].Message)
+		assert.Equal(t, requestErrs[0].Message, parameterErrs[0].Message)
+	}
+
+	// the missing header and the body both fail, only the first error is returned.
+	valid, responseErrs := v.ValidateHttpResponse(request, response())
+	assert.False(t, valid)
+	assert.Len(t, responseErrs, 1)
+
+	operationValidator, _ := v.OperationValidator(http.MethodPost, "/burgers")
+	valid, operationErrs := operationValidator.ValidateResponse(request, response())
+	assert.False(t, valid)
+	if assert.Len(t, operationErrs, 1) && assert.Len(t, responseErrs, 1) {
+		assert.Equal(t, responseErrs[0].Message, operationErrs[0].Message)
+	}
+}
+
+func TestNewValidator_OpenAPI30And31_BooleanBoundWithoutMinimum(t *testing.T) {
+
+	spec := `openapi: %s
+paths:
+  /burgers:
+    get:
+      parameters:
+        - name: limit
+          in: query
+          schema:
+            type: integer
+            exclusiveMinimum: true
+      responses:
+        '200':
+          description: ok
+          headers:
+            X-Size:
+              schema:
+                type: integer
+                exclusiveMinimum: true`
+
+	for _, version := range []string{"3.0.3", "3.1.0"} {
+		doc, _ := libopenapi.NewDocument([]byte(fmt.Sprintf(spec, version)))
+		v, _ := NewValidator(doc)
+
+		request, _ := http.NewRequest(http.MethodGet, "https://things.com/burgers?limit=5", nil)
+		response := &http.Response{StatusCode: http.StatusOK, Header: http.Header{"X-Size": {"5"}},
+			Body: io.NopCloser(bytes.NewBufferString(""))}
+
+		valid, errs := v.ValidateHttpRequestResponse(request, response)
+		if version == "3.0.3" {
+			// without a minimum, the 3.0 bound cannot be converted, so the schema of the query parameter (and the
+			// response header) cannot be compiled. It's reported the same way as a body schema.
+			assert.False(t, valid)
+			assert.Len(t, errs, 2)
+			for _, err := range errs {
+				assert.Equal(t, errors.ErrorCodeDocument, err.Code)
+				assert.Equal(t, errors.HowToFixSchemaCompile, err.HowToFix)
+			}
+			continue
+		}
+
+		// in 3.1 the boolean is read as 0, so it's an exclusive minimum of 0.
+		assert.True(t, valid)
+		assert.Len(t, errs, 0)
+
+		request, _ = http.NewRequest(http.MethodGet, "https://things.com/burgers?limit=0", nil)
+		valid, errs = v.ValidateHttpRequest(request)
+		assert.False(t, valid)
+		assert.Len(t, errs, 1)
+		assert.Equal(t, errors.ErrorCodeParamSchema, errs[0].Code)
+	}
+}